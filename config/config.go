@@ -0,0 +1,281 @@
+// Package config loads the settings of the application from the environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings of the application.
+type Config struct {
+	// DBDriver is the storage backend to use. See storage.Config for the available drivers.
+	DBDriver string
+	// DBDSN is the data source name of the storage backend. It's redacted as it may carry the password.
+	DBDSN string `redact:"true"`
+	// DBConnectAttempts is the number of times to try connecting to the storage backend at startup.
+	DBConnectAttempts int
+	// DBConnectBackoff is the wait before the first connection retry; it's doubled on every retry.
+	DBConnectBackoff time.Duration
+	// DBQueryTimeout is the maximum duration of each query to the storage backend, independent of RequestTimeout. There's no limit if it's 0.
+	DBQueryTimeout time.Duration
+	// LogLevel is the minimum level of the log entries to output. See logging.Configure for the available levels.
+	LogLevel string
+	// LogFormat is the format of the log entries, either "text" or "json".
+	LogFormat string
+	// RequestTimeout is the maximum duration for handling a request.
+	RequestTimeout time.Duration
+	// DrainTimeout is how long the server waits for the requests in flight to finish when shutting down, before cutting them off.
+	// It waits as long as it takes if it's 0. See endpoint.Drain.
+	DrainTimeout time.Duration
+	// ReadOnly starts the server in read-only mode, in which the requests that may mutate the TodoItems are rejected.
+	ReadOnly bool
+	// BasePath is the prefix of the paths of all the endpoints, e.g., "/api/v1". It's empty if the endpoints are served at the root.
+	BasePath string
+	// UniqueDescriptions tells what to do when creating a TodoItem whose description duplicates an incomplete one.
+	// See core.ParseDuplicatePolicy for the available values.
+	UniqueDescriptions string
+	// ListDelete tells what to do when deleting a List that still has TodoItems.
+	// See core.ParseListDeletePolicy for the available values.
+	ListDelete string
+	// AdminToken is the bearer token required by the administrative endpoints. They are not guarded if it's empty.
+	AdminToken string `redact:"true"`
+	// DefaultFilter is which TodoItems are listed if the completed status is not specified. See endpoint.SetDefaultFilter for the available values.
+	DefaultFilter string
+	// ItemsCacheTTL is how long the responses listing the TodoItems are cached at most; they are not cached if it's 0. See endpoint.ItemsCache.
+	ItemsCacheTTL time.Duration
+	// DefaultSort is the order in which the TodoItems are listed if the order is not specified. See endpoint.SetDefaultSort for the available values.
+	DefaultSort string
+	// Timezone is the IANA name of the time zone of the clients that don't tell theirs, e.g., "Asia/Tokyo".
+	// It's the local time zone of the server if empty. See endpoint.SetDefaultTimezone.
+	Timezone string
+	// MassDeleteThreshold is the fraction of all TodoItems above which a deletion requires confirmation.
+	MassDeleteThreshold float64
+	// MaxPageSize is the maximum number of TodoItems in a page; larger limits are clamped to it. See endpoint.SetMaxPageSize.
+	MaxPageSize int
+	// MaxConcurrentPerIP is the maximum number of requests a client can have in flight at once. There's no limit if it's 0.
+	// See endpoint.ConcurrencyLimit.
+	MaxConcurrentPerIP int
+	// CORSMaxAge is how many seconds the browsers cache the answers to the preflight requests. The browsers decide if it's 0. See endpoint.CORS.
+	CORSMaxAge int
+	// WriteBreakerThreshold is the number of consecutive failed writes after which the writes are suspended for WriteBreakerCooldown.
+	// They are never suspended if it's 0. See endpoint.WriteBreaker.
+	WriteBreakerThreshold int
+	// WriteBreakerCooldown is how long the writes are suspended before one is tried again.
+	WriteBreakerCooldown time.Duration
+	// AllowReset enables the administrative endpoints that permanently delete all the TodoItems and that create random ones. They are meant for development only.
+	AllowReset bool
+	// MaxBodyBytes is the maximum size of the request bodies, except for the ones of the import endpoint.
+	MaxBodyBytes int64
+	// MaxImportBytes is the maximum size of the request bodies of the import endpoint, which carry all the TodoItems.
+	MaxImportBytes int64
+	// CompletedRetention is how long the completed TodoItems are kept before they are pruned. They are never pruned if it's 0.
+	CompletedRetention time.Duration
+	// StrictJSON rejects the JSON bodies with unknown fields instead of ignoring the fields.
+	StrictJSON bool
+	// PrettyJSON indents all the JSON responses, which is easier to read during development. See endpoint.SetPrettyJSON.
+	PrettyJSON bool
+	// TrustedProxies are the addresses, or the CIDRs, of the reverse proxies whose X-Forwarded-For and X-Real-IP headers are believed.
+	// See endpoint.SetTrustedProxies.
+	TrustedProxies []string
+	// TLSCert and TLSKey are the paths of the certificate and its private key to serve HTTPS with, for the deployments not behind a proxy terminating TLS.
+	// Plain HTTP is served if both are empty; they have to be set together. See endpoint.NewServer.
+	TLSCert string
+	TLSKey  string
+}
+
+// Load reads the settings from the environment, falling back to the defaults for those that are not set.
+// An error is returned if a setting is malformed.
+//
+//	TODOLIST_DB_DRIVER            (default: "mysql")
+//	TODOLIST_DB_DSN               (default: "root:root@/todolist?charset=utf8&parseTime=True&loc=Local")
+//	TODOLIST_DB_CONNECT_ATTEMPTS  (default: "5")
+//	TODOLIST_DB_CONNECT_BACKOFF   (default: "1s")
+//	TODOLIST_DB_QUERY_TIMEOUT     (default: "0", i.e., no limit)
+//	TODOLIST_LOG_LEVEL            (default: "info")
+//	TODOLIST_LOG_FORMAT           (default: "text")
+//	TODOLIST_REQUEST_TIMEOUT      (default: "15s")
+//	TODOLIST_DRAIN_TIMEOUT        (default: "30s"; "0" waits as long as it takes)
+//	TODOLIST_READ_ONLY            (default: "false")
+//	TODOLIST_BASE_PATH            (default: "")
+//	TODOLIST_UNIQUE_DESCRIPTIONS  (default: "off")
+//	TODOLIST_LIST_DELETE          (default: "block")
+//	TODOLIST_ADMIN_TOKEN          (default: "")
+//	TODOLIST_DEFAULT_FILTER       (default: "all")
+//	TODOLIST_DEFAULT_SORT         (default: "id:asc")
+//	TODOLIST_ITEMS_CACHE_TTL      (default: "0", i.e., not cached)
+//	TODOLIST_TIMEZONE             (default: "", i.e., the local time zone of the server)
+//	TODOLIST_MASS_DELETE_THRESHOLD (default: "0.5")
+//	TODOLIST_MAX_PAGE_SIZE        (default: "500")
+//	TODOLIST_MAX_CONCURRENT_PER_IP (default: "0", i.e., no limit)
+//	TODOLIST_CORS_MAX_AGE         (default: "0", i.e., up to the browsers)
+//	TODOLIST_WRITE_BREAKER_THRESHOLD (default: "5"; "0" never suspends the writes)
+//	TODOLIST_WRITE_BREAKER_COOLDOWN (default: "30s")
+//	TODOLIST_ALLOW_RESET          (default: "false")
+//	TODOLIST_MAX_BODY_BYTES       (default: "1048576")
+//	TODOLIST_MAX_IMPORT_BYTES     (default: "33554432")
+//	TODOLIST_COMPLETED_RETENTION  (default: "0", i.e., never pruned; also accepts days, e.g., "30d")
+//	TODOLIST_STRICT_JSON          (default: "false")
+//	TODOLIST_PRETTY_JSON          (default: "false")
+//	TODOLIST_TRUSTED_PROXIES      (default: "", i.e., none; comma-separated, e.g., "10.0.0.0/8,192.168.1.1")
+//	TODOLIST_TLS_CERT             (default: "", i.e., plain HTTP)
+//	TODOLIST_TLS_KEY              (default: "", i.e., plain HTTP)
+func Load() (Config, error) {
+	cfg := Config{
+		DBDriver:           getenv("TODOLIST_DB_DRIVER", "mysql"),
+		DBDSN:              getenv("TODOLIST_DB_DSN", "root:root@/todolist?charset=utf8&parseTime=True&loc=Local"),
+		LogLevel:           getenv("TODOLIST_LOG_LEVEL", "info"),
+		LogFormat:          getenv("TODOLIST_LOG_FORMAT", "text"),
+		BasePath:           strings.TrimSuffix(getenv("TODOLIST_BASE_PATH", ""), "/"),
+		UniqueDescriptions: getenv("TODOLIST_UNIQUE_DESCRIPTIONS", "off"),
+		ListDelete:         getenv("TODOLIST_LIST_DELETE", "block"),
+		AdminToken:         getenv("TODOLIST_ADMIN_TOKEN", ""),
+		DefaultFilter:      getenv("TODOLIST_DEFAULT_FILTER", "all"),
+		DefaultSort:        getenv("TODOLIST_DEFAULT_SORT", "id:asc"),
+		Timezone:           getenv("TODOLIST_TIMEZONE", ""),
+		TLSCert:            getenv("TODOLIST_TLS_CERT", ""),
+		TLSKey:             getenv("TODOLIST_TLS_KEY", ""),
+	}
+
+	var err error
+	cfg.DBConnectAttempts, err = strconv.Atoi(getenv("TODOLIST_DB_CONNECT_ATTEMPTS", "5"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_DB_CONNECT_ATTEMPTS: %w", err)
+	}
+	cfg.DBConnectBackoff, err = time.ParseDuration(getenv("TODOLIST_DB_CONNECT_BACKOFF", "1s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_DB_CONNECT_BACKOFF: %w", err)
+	}
+	cfg.DBQueryTimeout, err = time.ParseDuration(getenv("TODOLIST_DB_QUERY_TIMEOUT", "0"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_DB_QUERY_TIMEOUT: %w", err)
+	}
+	cfg.ItemsCacheTTL, err = time.ParseDuration(getenv("TODOLIST_ITEMS_CACHE_TTL", "0"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_ITEMS_CACHE_TTL: %w", err)
+	}
+	cfg.RequestTimeout, err = time.ParseDuration(getenv("TODOLIST_REQUEST_TIMEOUT", "15s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_REQUEST_TIMEOUT: %w", err)
+	}
+	cfg.DrainTimeout, err = time.ParseDuration(getenv("TODOLIST_DRAIN_TIMEOUT", "30s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_DRAIN_TIMEOUT: %w", err)
+	}
+	cfg.ReadOnly, err = strconv.ParseBool(getenv("TODOLIST_READ_ONLY", "false"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_READ_ONLY: %w", err)
+	}
+	cfg.AllowReset, err = strconv.ParseBool(getenv("TODOLIST_ALLOW_RESET", "false"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_ALLOW_RESET: %w", err)
+	}
+	cfg.MaxBodyBytes, err = strconv.ParseInt(getenv("TODOLIST_MAX_BODY_BYTES", "1048576"), 10, 64)
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_MAX_BODY_BYTES: %w", err)
+	}
+	cfg.MaxImportBytes, err = strconv.ParseInt(getenv("TODOLIST_MAX_IMPORT_BYTES", "33554432"), 10, 64)
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_MAX_IMPORT_BYTES: %w", err)
+	}
+	cfg.CompletedRetention, err = parseDays(getenv("TODOLIST_COMPLETED_RETENTION", "0"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_COMPLETED_RETENTION: %w", err)
+	}
+	cfg.StrictJSON, err = strconv.ParseBool(getenv("TODOLIST_STRICT_JSON", "false"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_STRICT_JSON: %w", err)
+	}
+	cfg.PrettyJSON, err = strconv.ParseBool(getenv("TODOLIST_PRETTY_JSON", "false"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_PRETTY_JSON: %w", err)
+	}
+	cfg.MassDeleteThreshold, err = strconv.ParseFloat(getenv("TODOLIST_MASS_DELETE_THRESHOLD", "0.5"), 64)
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_MASS_DELETE_THRESHOLD: %w", err)
+	}
+	cfg.MaxPageSize, err = strconv.Atoi(getenv("TODOLIST_MAX_PAGE_SIZE", "500"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_MAX_PAGE_SIZE: %w", err)
+	}
+	cfg.MaxConcurrentPerIP, err = strconv.Atoi(getenv("TODOLIST_MAX_CONCURRENT_PER_IP", "0"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_MAX_CONCURRENT_PER_IP: %w", err)
+	}
+	cfg.CORSMaxAge, err = strconv.Atoi(getenv("TODOLIST_CORS_MAX_AGE", "0"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_CORS_MAX_AGE: %w", err)
+	}
+	if cfg.CORSMaxAge < 0 {
+		return Config{}, fmt.Errorf("TODOLIST_CORS_MAX_AGE: %d is negative", cfg.CORSMaxAge)
+	}
+	cfg.WriteBreakerThreshold, err = strconv.Atoi(getenv("TODOLIST_WRITE_BREAKER_THRESHOLD", "5"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_WRITE_BREAKER_THRESHOLD: %w", err)
+	}
+	cfg.WriteBreakerCooldown, err = time.ParseDuration(getenv("TODOLIST_WRITE_BREAKER_COOLDOWN", "30s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("TODOLIST_WRITE_BREAKER_COOLDOWN: %w", err)
+	}
+	for _, proxy := range strings.Split(getenv("TODOLIST_TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			cfg.TrustedProxies = append(cfg.TrustedProxies, proxy)
+		}
+	}
+	if cfg.BasePath != "" && !strings.HasPrefix(cfg.BasePath, "/") {
+		return Config{}, fmt.Errorf("TODOLIST_BASE_PATH: %q does not start with \"/\"", cfg.BasePath)
+	}
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return Config{}, errors.New("TODOLIST_TLS_CERT and TODOLIST_TLS_KEY: both or neither have to be set")
+	}
+	return cfg, nil
+}
+
+// parseDays is time.ParseDuration that also accepts a whole number of days, e.g., "30d".
+func parseDays(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// getenv returns the value of the environment variable named by the key, or the fallback if the variable is not set or empty.
+func getenv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Redacted replaces the settings tagged `redact:"true"` in Effective, e.g., the secrets.
+const Redacted = "[REDACTED]"
+
+// Effective returns the settings keyed by the names of their fields, for diagnostics.
+// The settings tagged `redact:"true"` are replaced by Redacted unless they are empty, so that the secrets never leak,
+// and the durations are formatted like "1.5s".
+func (c Config) Effective() map[string]any {
+	settings := map[string]any{}
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i).Interface()
+		switch {
+		case field.Tag.Get("redact") == "true":
+			if !v.Field(i).IsZero() {
+				value = Redacted
+			}
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			value = value.(time.Duration).String()
+		}
+		settings[field.Name] = value
+	}
+	return settings
+}
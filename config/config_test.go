@@ -0,0 +1,253 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"todolist/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadDefaults Given no environment variables are set, when Load is called, then the default settings are returned.
+func TestLoadDefaults(t *testing.T) {
+	// arrange
+	t.Setenv("TODOLIST_DB_DRIVER", "")
+	t.Setenv("TODOLIST_DB_DSN", "")
+	t.Setenv("TODOLIST_DB_CONNECT_ATTEMPTS", "")
+	t.Setenv("TODOLIST_DB_CONNECT_BACKOFF", "")
+	t.Setenv("TODOLIST_DB_QUERY_TIMEOUT", "")
+	t.Setenv("TODOLIST_LOG_LEVEL", "")
+	t.Setenv("TODOLIST_LOG_FORMAT", "")
+	t.Setenv("TODOLIST_REQUEST_TIMEOUT", "")
+	t.Setenv("TODOLIST_READ_ONLY", "")
+	t.Setenv("TODOLIST_BASE_PATH", "")
+	t.Setenv("TODOLIST_UNIQUE_DESCRIPTIONS", "")
+	t.Setenv("TODOLIST_LIST_DELETE", "")
+	t.Setenv("TODOLIST_TRUSTED_PROXIES", "")
+	t.Setenv("TODOLIST_TLS_CERT", "")
+	t.Setenv("TODOLIST_TLS_KEY", "")
+	t.Setenv("TODOLIST_ADMIN_TOKEN", "")
+	t.Setenv("TODOLIST_DEFAULT_FILTER", "")
+	t.Setenv("TODOLIST_DEFAULT_SORT", "")
+	t.Setenv("TODOLIST_ITEMS_CACHE_TTL", "")
+	t.Setenv("TODOLIST_TIMEZONE", "")
+	t.Setenv("TODOLIST_MASS_DELETE_THRESHOLD", "")
+	t.Setenv("TODOLIST_MAX_PAGE_SIZE", "")
+	t.Setenv("TODOLIST_MAX_CONCURRENT_PER_IP", "")
+	t.Setenv("TODOLIST_CORS_MAX_AGE", "")
+	t.Setenv("TODOLIST_WRITE_BREAKER_THRESHOLD", "")
+	t.Setenv("TODOLIST_WRITE_BREAKER_COOLDOWN", "")
+	t.Setenv("TODOLIST_ALLOW_RESET", "")
+	t.Setenv("TODOLIST_MAX_BODY_BYTES", "")
+	t.Setenv("TODOLIST_MAX_IMPORT_BYTES", "")
+	t.Setenv("TODOLIST_COMPLETED_RETENTION", "")
+	t.Setenv("TODOLIST_STRICT_JSON", "")
+	t.Setenv("TODOLIST_PRETTY_JSON", "")
+	t.Setenv("TODOLIST_DRAIN_TIMEOUT", "")
+
+	// act
+	got, err := config.Load()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "mysql", got.DBDriver)
+		assert.Equal(t, "root:root@/todolist?charset=utf8&parseTime=True&loc=Local", got.DBDSN)
+		assert.Equal(t, 5, got.DBConnectAttempts)
+		assert.Equal(t, time.Second, got.DBConnectBackoff)
+		assert.Zero(t, got.DBQueryTimeout)
+		assert.Equal(t, "info", got.LogLevel)
+		assert.Equal(t, "text", got.LogFormat)
+		assert.Equal(t, 15*time.Second, got.RequestTimeout)
+		assert.Equal(t, 30*time.Second, got.DrainTimeout)
+		assert.False(t, got.ReadOnly)
+		assert.Equal(t, "", got.BasePath)
+		assert.Equal(t, "off", got.UniqueDescriptions)
+		assert.Equal(t, "block", got.ListDelete)
+		assert.Equal(t, "", got.AdminToken)
+		assert.Equal(t, "all", got.DefaultFilter)
+		assert.Equal(t, "id:asc", got.DefaultSort)
+		assert.Zero(t, got.ItemsCacheTTL)
+		assert.Empty(t, got.Timezone)
+		assert.Equal(t, 0.5, got.MassDeleteThreshold)
+		assert.Equal(t, 500, got.MaxPageSize)
+		assert.Zero(t, got.MaxConcurrentPerIP)
+		assert.Zero(t, got.CORSMaxAge)
+		assert.Equal(t, 5, got.WriteBreakerThreshold)
+		assert.Equal(t, 30*time.Second, got.WriteBreakerCooldown)
+		assert.False(t, got.AllowReset)
+		assert.Equal(t, int64(1<<20), got.MaxBodyBytes)
+		assert.Equal(t, int64(32<<20), got.MaxImportBytes)
+		assert.Zero(t, got.CompletedRetention)
+		assert.False(t, got.StrictJSON)
+		assert.False(t, got.PrettyJSON)
+		assert.Empty(t, got.TrustedProxies)
+		assert.Empty(t, got.TLSCert)
+		assert.Empty(t, got.TLSKey)
+	}
+}
+
+// TestLoadFromEnv Given the environment variables are set, when Load is called, then the settings are taken from the environment.
+func TestLoadFromEnv(t *testing.T) {
+	// arrange
+	t.Setenv("TODOLIST_DB_DRIVER", "sqlite")
+	t.Setenv("TODOLIST_DB_DSN", "todolist.db")
+	t.Setenv("TODOLIST_DB_CONNECT_ATTEMPTS", "10")
+	t.Setenv("TODOLIST_DB_CONNECT_BACKOFF", "500ms")
+	t.Setenv("TODOLIST_DB_QUERY_TIMEOUT", "2s")
+	t.Setenv("TODOLIST_LOG_LEVEL", "debug")
+	t.Setenv("TODOLIST_LOG_FORMAT", "json")
+	t.Setenv("TODOLIST_REQUEST_TIMEOUT", "3s")
+	t.Setenv("TODOLIST_READ_ONLY", "true")
+	t.Setenv("TODOLIST_BASE_PATH", "/api/v1/")
+	t.Setenv("TODOLIST_UNIQUE_DESCRIPTIONS", "conflict")
+	t.Setenv("TODOLIST_LIST_DELETE", "cascade")
+	t.Setenv("TODOLIST_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1,")
+	t.Setenv("TODOLIST_TLS_CERT", "/etc/todolist/cert.pem")
+	t.Setenv("TODOLIST_TLS_KEY", "/etc/todolist/key.pem")
+	t.Setenv("TODOLIST_ADMIN_TOKEN", "secret")
+	t.Setenv("TODOLIST_DEFAULT_FILTER", "active")
+	t.Setenv("TODOLIST_DEFAULT_SORT", "position")
+	t.Setenv("TODOLIST_ITEMS_CACHE_TTL", "10s")
+	t.Setenv("TODOLIST_TIMEZONE", "Asia/Tokyo")
+	t.Setenv("TODOLIST_MASS_DELETE_THRESHOLD", "0.8")
+	t.Setenv("TODOLIST_MAX_PAGE_SIZE", "100")
+	t.Setenv("TODOLIST_MAX_CONCURRENT_PER_IP", "4")
+	t.Setenv("TODOLIST_CORS_MAX_AGE", "600")
+	t.Setenv("TODOLIST_WRITE_BREAKER_THRESHOLD", "3")
+	t.Setenv("TODOLIST_WRITE_BREAKER_COOLDOWN", "1m")
+	t.Setenv("TODOLIST_ALLOW_RESET", "true")
+	t.Setenv("TODOLIST_MAX_BODY_BYTES", "2048")
+	t.Setenv("TODOLIST_MAX_IMPORT_BYTES", "4096")
+	t.Setenv("TODOLIST_COMPLETED_RETENTION", "30d")
+	t.Setenv("TODOLIST_STRICT_JSON", "true")
+	t.Setenv("TODOLIST_PRETTY_JSON", "true")
+	t.Setenv("TODOLIST_DRAIN_TIMEOUT", "5s")
+
+	// act
+	got, err := config.Load()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sqlite", got.DBDriver)
+		assert.Equal(t, "todolist.db", got.DBDSN)
+		assert.Equal(t, 10, got.DBConnectAttempts)
+		assert.Equal(t, 500*time.Millisecond, got.DBConnectBackoff)
+		assert.Equal(t, 2*time.Second, got.DBQueryTimeout)
+		assert.Equal(t, "debug", got.LogLevel)
+		assert.Equal(t, "json", got.LogFormat)
+		assert.Equal(t, 3*time.Second, got.RequestTimeout)
+		assert.Equal(t, 5*time.Second, got.DrainTimeout)
+		assert.True(t, got.ReadOnly)
+		assert.Equal(t, "/api/v1", got.BasePath)
+		assert.Equal(t, "conflict", got.UniqueDescriptions)
+		assert.Equal(t, "cascade", got.ListDelete)
+		assert.Equal(t, "secret", got.AdminToken)
+		assert.Equal(t, "active", got.DefaultFilter)
+		assert.Equal(t, "position", got.DefaultSort)
+		assert.Equal(t, 10*time.Second, got.ItemsCacheTTL)
+		assert.Equal(t, "Asia/Tokyo", got.Timezone)
+		assert.Equal(t, 0.8, got.MassDeleteThreshold)
+		assert.Equal(t, 100, got.MaxPageSize)
+		assert.Equal(t, 4, got.MaxConcurrentPerIP)
+		assert.Equal(t, 600, got.CORSMaxAge)
+		assert.Equal(t, 3, got.WriteBreakerThreshold)
+		assert.Equal(t, time.Minute, got.WriteBreakerCooldown)
+		assert.True(t, got.AllowReset)
+		assert.Equal(t, int64(2048), got.MaxBodyBytes)
+		assert.Equal(t, int64(4096), got.MaxImportBytes)
+		assert.Equal(t, 30*24*time.Hour, got.CompletedRetention)
+		assert.True(t, got.StrictJSON)
+		assert.True(t, got.PrettyJSON)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, got.TrustedProxies)
+		assert.Equal(t, "/etc/todolist/cert.pem", got.TLSCert)
+		assert.Equal(t, "/etc/todolist/key.pem", got.TLSKey)
+	}
+}
+
+// TestLoadMalformed Given a malformed duration in the environment, when Load is called, then an error is returned.
+func TestLoadMalformed(t *testing.T) {
+	// arrange
+	t.Setenv("TODOLIST_REQUEST_TIMEOUT", "soon")
+
+	// act
+	_, err := config.Load()
+
+	// assert
+	assert.Error(t, err)
+}
+
+// TestLoadRelativeBasePath Given a base path not starting with a slash in the environment, when Load is called, then an error is returned.
+func TestLoadRelativeBasePath(t *testing.T) {
+	// arrange
+	t.Setenv("TODOLIST_BASE_PATH", "api/v1")
+
+	// act
+	_, err := config.Load()
+
+	// assert
+	assert.Error(t, err)
+}
+
+// TestLoadNegativeCORSMaxAge Given a negative CORS max age in the environment, when Load is called, then an error is returned.
+func TestLoadNegativeCORSMaxAge(t *testing.T) {
+	// arrange
+	t.Setenv("TODOLIST_CORS_MAX_AGE", "-1")
+
+	// act
+	_, err := config.Load()
+
+	// assert
+	assert.Error(t, err)
+}
+
+// TestLoadTLSCertWithoutKey Given a TLS certificate but no key in the environment, when Load is called, then an error is returned.
+func TestLoadTLSCertWithoutKey(t *testing.T) {
+	// arrange
+	t.Setenv("TODOLIST_TLS_CERT", "/etc/todolist/cert.pem")
+	t.Setenv("TODOLIST_TLS_KEY", "")
+
+	// act
+	_, err := config.Load()
+
+	// assert
+	assert.Error(t, err)
+}
+
+// TestEffective Given the environment variables are set, including the secrets, when Effective is called on the loaded settings,
+// then the settings are keyed by their names, with the durations formatted and the secrets redacted.
+func TestEffective(t *testing.T) {
+	// arrange
+	t.Setenv("TODOLIST_LOG_LEVEL", "debug")
+	t.Setenv("TODOLIST_REQUEST_TIMEOUT", "3s")
+	t.Setenv("TODOLIST_DB_DSN", "root:hunter2@/todolist")
+	t.Setenv("TODOLIST_ADMIN_TOKEN", "secret")
+	cfg, err := config.Load()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// act
+	got := cfg.Effective()
+
+	// assert
+	assert.Equal(t, "debug", got["LogLevel"])
+	assert.Equal(t, "3s", got["RequestTimeout"])
+	assert.Equal(t, config.Redacted, got["DBDSN"])
+	assert.Equal(t, config.Redacted, got["AdminToken"])
+	assert.NotContains(t, fmt.Sprint(got), "hunter2")
+	assert.NotContains(t, fmt.Sprint(got), "secret")
+}
+
+// TestEffectiveEmptySecret Given a secret is not set, when Effective is called, then it's left empty instead of redacted, so that it can be told apart from a set one.
+func TestEffectiveEmptySecret(t *testing.T) {
+	// arrange
+	cfg := config.Config{}
+
+	// act
+	got := cfg.Effective()
+
+	// assert
+	assert.Equal(t, "", got["AdminToken"])
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxAttachments is the maximum number of URLs attached to a TodoItem.
+const MaxAttachments = 10
+
+// MaxAttachmentLength is the maximum length of an attached URL.
+const MaxAttachmentLength = 2048
+
+// errNoAttachments is returned by AddAttachment and RemoveAttachment if the accessor is not an AttachmentAccessor.
+var errNoAttachments = errors.New("the storage does not support attachments")
+
+// validateAttachment records the URL as an invalid field unless it's a well-formed absolute http or https URL of at most MaxAttachmentLength.
+func validateAttachment(fields fieldErrors, rawURL string) {
+	if rawURL == "" {
+		fields["url"] = "required"
+		return
+	}
+	if len(rawURL) > MaxAttachmentLength {
+		fields["url"] = fmt.Sprintf("longer than %d characters", MaxAttachmentLength)
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fields["url"] = fmt.Sprintf("%q is not an http or https URL", rawURL)
+	}
+}
+
+// AddAttachment attaches the URL to the TodoItem with the id and returns the TodoItem along with all its attachments. Attaching a URL again does nothing.
+// A ValidationError is returned if the URL is not a well-formed http or https URL, a TodoItemNotFoundError if there's no such TodoItem,
+// and a ConflictError if the TodoItem already has MaxAttachments attachments.
+func (c *TheCore) AddAttachment(id ItemID, rawURL string) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id, "url": rawURL}).Info("CORE: Adding attachment to TodoItem.")
+	fields := fieldErrors{}
+	validateAttachment(fields, rawURL)
+	if err := fields.err(); err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, err
+	}
+	return c.updateAttachments(id, func(attachments AttachmentAccessor) error {
+		return attachments.AddAttachment(id, rawURL, MaxAttachments)
+	})
+}
+
+// RemoveAttachment detaches the URL from the TodoItem with the id and returns the TodoItem along with the rest of its attachments.
+// Detaching a URL that is not attached does nothing. A TodoItemNotFoundError is returned if there's no such TodoItem.
+func (c *TheCore) RemoveAttachment(id ItemID, rawURL string) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id, "url": rawURL}).Info("CORE: Removing attachment from TodoItem.")
+	return c.updateAttachments(id, func(attachments AttachmentAccessor) error {
+		return attachments.RemoveAttachment(id, rawURL)
+	})
+}
+
+// updateAttachments calls fn with the AttachmentAccessor of a transaction to change the attachments of the TodoItem with the id,
+// and returns the TodoItem along with its attachments afterwards. Like the other mutations, a change bumps the update time of the TodoItem,
+// is recorded in the audit log in the same transaction, if kept, and is told to the sinks once committed; nothing is done if the attachments are unchanged.
+func (c *TheCore) updateAttachments(id ItemID, fn func(AttachmentAccessor) error) (TodoItem, error) {
+	var todo TodoItem
+	changed := false
+	err := c.auditedTransaction(func(tx StorageAccessor) ([]auditChange, error) {
+		attachments, ok := tx.(AttachmentAccessor)
+		if !ok {
+			return nil, errNoAttachments
+		}
+		txCore := *c
+		txCore.accessor = tx
+		txCore.sinks = nil
+		old, err := txCore.GetItem(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(attachments); err != nil {
+			return nil, err
+		}
+		urls, err := attachments.ReadAttachments([]ItemID{id})
+		if err != nil {
+			return nil, err
+		}
+		if slices.Equal(old.Attachments, urls[id]) {
+			todo = old
+			return nil, nil
+		}
+		// NOTE: Saved as is, only to bump the update time, e.g., for If-Modified-Since.
+		todo, err = tx.UpdateWith(id, func(*TodoItem) {})
+		if err != nil {
+			return nil, err
+		}
+		todo.Attachments = urls[id]
+		changed = true
+		return []auditChange{auditUpdated(old, todo)}, nil
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	if changed {
+		c.emitUpdated(todo)
+	}
+	return todo, nil
+}
+
+// withAttachments fills in the attachments of the TodoItems if the accessor is an AttachmentAccessor.
+func (c *TheCore) withAttachments(todos []TodoItem) error {
+	attachments, ok := c.accessor.(AttachmentAccessor)
+	if !ok || len(todos) == 0 {
+		return nil
+	}
+	ids := make([]ItemID, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	var urls map[ItemID][]string
+	err := c.retryOnReconnect(func() (err error) {
+		urls, err = attachments.ReadAttachments(ids)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		todos[i].Attachments = urls[todos[i].ID]
+	}
+	return nil
+}
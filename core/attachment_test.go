@@ -0,0 +1,280 @@
+package core_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	core "todolist/core"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// attachingAccessor is a stub of a StorageAccessor that stores the attachments as well, whose transactions run on itself.
+type attachingAccessor struct {
+	*MockStorageAccessor
+	*MockAttachmentAccessor
+}
+
+func newAttachingAccessor(t *testing.T) *attachingAccessor {
+	ctrl := gomock.NewController(t)
+	accessor := &attachingAccessor{NewMockStorageAccessor(ctrl), NewMockAttachmentAccessor(ctrl)}
+	accessor.MockStorageAccessor.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(core.StorageAccessor) error) error { return fn(accessor) }).
+		AnyTimes()
+	return accessor
+}
+
+// expectAttachmentChange Expects the item to be read with the attachments before, then fn to change them, and the item to be read with the attachments after;
+// the item is saved to bump its update time if the attachments differ.
+func (a *attachingAccessor) expectAttachmentChange(stored core.TodoItem, before, after []string, change *gomock.Call) {
+	calls := []any{
+		a.MockStorageAccessor.EXPECT().
+			Read(gomock.Any()).
+			Return([]core.TodoItem{stored}),
+		a.MockAttachmentAccessor.EXPECT().
+			ReadAttachments([]core.ItemID{stored.ID}).
+			Return(map[core.ItemID][]string{stored.ID: before}, nil),
+		change,
+		a.MockAttachmentAccessor.EXPECT().
+			ReadAttachments([]core.ItemID{stored.ID}).
+			Return(map[core.ItemID][]string{stored.ID: after}, nil),
+	}
+	if !slices.Equal(before, after) {
+		stored.UpdatedAt = updatedAt
+		calls = append(calls, a.MockStorageAccessor.EXPECT().
+			UpdateWith(stored.ID, gomock.Any()).
+			Return(stored, nil))
+	}
+	gomock.InOrder(calls...)
+}
+
+// updatedAt is the update time of the items saved by expectAttachmentChange.
+var updatedAt = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// TestAddAttachment Given an accessor that stores the attachments, when AddAttachment is called with a URL,
+// then the URL is attached, the update time of the item is bumped, the sink is notified, and the item is returned with all its attachments.
+func TestAddAttachment(t *testing.T) {
+	// arrange
+	accessor := newAttachingAccessor(t)
+	url := "https://example.com/spec.pdf"
+	stored := core.TodoItem{ID: 1, Description: "Test description"}
+	accessor.expectAttachmentChange(stored, []string{"http://example.com"}, []string{"http://example.com", url},
+		accessor.MockAttachmentAccessor.EXPECT().
+			AddAttachment(1, url, core.MaxAttachments).
+			Return(nil))
+	sink := &fakeSink{}
+	theCore := core.NewCore(accessor, sink)
+
+	// act
+	got, err := theCore.AddAttachment(1, url)
+
+	// assert
+	if assert.NoError(t, err) {
+		want := core.TodoItem{ID: 1, Description: "Test description", UpdatedAt: updatedAt, Attachments: []string{"http://example.com", url}}
+		assert.Equal(t, want, got)
+		assert.Equal(t, []core.TodoItem{want}, sink.updated)
+	}
+}
+
+// TestAddAttachmentAgain Given an accessor that stores the attachments, when AddAttachment is called with a URL already attached,
+// then the item is neither saved nor told to the sink, and is returned as is.
+func TestAddAttachmentAgain(t *testing.T) {
+	// arrange
+	accessor := newAttachingAccessor(t)
+	url := "https://example.com/spec.pdf"
+	stored := core.TodoItem{ID: 1, Description: "Test description"}
+	accessor.expectAttachmentChange(stored, []string{url}, []string{url},
+		accessor.MockAttachmentAccessor.EXPECT().
+			AddAttachment(1, url, core.MaxAttachments).
+			Return(nil))
+	sink := &fakeSink{}
+	theCore := core.NewCore(accessor, sink)
+
+	// act
+	got, err := theCore.AddAttachment(1, url)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.TodoItem{ID: 1, Description: "Test description", Attachments: []string{url}}, got)
+		assert.Empty(t, sink.updated)
+	}
+}
+
+// attachingAuditingAccessor is a stub of a StorageAccessor that stores the attachments and keeps an audit log as well, whose transactions run on itself.
+type attachingAuditingAccessor struct {
+	*attachingAccessor
+	*MockAuditAccessor
+}
+
+// TestAddAttachmentAudited Given an accessor that stores the attachments and keeps an audit log, when AddAttachment is called on a core acting as alice,
+// then an update entry with the attachments before and after is recorded.
+func TestAddAttachmentAudited(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	accessor := &attachingAuditingAccessor{&attachingAccessor{NewMockStorageAccessor(ctrl), NewMockAttachmentAccessor(ctrl)}, NewMockAuditAccessor(ctrl)}
+	accessor.MockStorageAccessor.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(core.StorageAccessor) error) error { return fn(accessor) }).
+		AnyTimes()
+	url := "https://example.com/spec.pdf"
+	accessor.expectAttachmentChange(core.TodoItem{ID: 1, Description: "Test description"}, nil, []string{url},
+		accessor.MockAttachmentAccessor.EXPECT().
+			AddAttachment(1, url, core.MaxAttachments).
+			Return(nil))
+	var got core.AuditEntry
+	accessor.MockAuditAccessor.EXPECT().
+		RecordAudit(gomock.Any()).
+		DoAndReturn(func(entry core.AuditEntry) error {
+			got = entry
+			return nil
+		})
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.WithActor("alice").AddAttachment(1, url)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, got.ItemID)
+		assert.Equal(t, core.AuditUpdate, got.Action)
+		assert.Equal(t, "alice", got.Actor)
+		assert.JSONEq(t, `{"attachments": {"old": null, "new": ["https://example.com/spec.pdf"]}}`, string(got.Diff))
+	}
+}
+
+// TestAddAttachmentInvalid Given an accessor that stores the attachments, when AddAttachment is called with a URL that is not a well-formed http or https URL,
+// then a ValidationError on the url is returned without touching the storage.
+func TestAddAttachmentInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":        "",
+		"relative":     "/spec.pdf",
+		"no host":      "https:///spec.pdf",
+		"other scheme": "ftp://example.com/spec.pdf",
+		"malformed":    "https://example.com/%zz",
+		"too long":     "https://example.com/" + strings.Repeat("a", core.MaxAttachmentLength),
+	}
+	for name, url := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			accessor := newAttachingAccessor(t)
+			accessor.MockAttachmentAccessor.EXPECT().
+				AddAttachment(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+			theCore := core.NewCore(accessor)
+
+			// act
+			_, err := theCore.AddAttachment(1, url)
+
+			// assert
+			var validationErr core.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Contains(t, validationErr.Fields, "url")
+			}
+		})
+	}
+}
+
+// TestAddAttachmentMax Given an accessor that rejects the URL as the item already has the maximum number of attachments,
+// when AddAttachment is called, then the ConflictError is returned as is.
+func TestAddAttachmentMax(t *testing.T) {
+	// arrange
+	accessor := newAttachingAccessor(t)
+	conflictErr := core.ConflictError{Message: "too many attachments"}
+	accessor.MockStorageAccessor.EXPECT().
+		Read(gomock.Any()).
+		Return([]core.TodoItem{{ID: 1, Description: "Test description"}})
+	accessor.MockAttachmentAccessor.EXPECT().
+		ReadAttachments([]core.ItemID{1}).
+		Return(map[core.ItemID][]string{}, nil)
+	accessor.MockAttachmentAccessor.EXPECT().
+		AddAttachment(1, "https://example.com", core.MaxAttachments).
+		Return(conflictErr)
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.AddAttachment(1, "https://example.com")
+
+	// assert
+	assert.Equal(t, conflictErr, err)
+}
+
+// TestAddAttachmentNotFound Given an accessor that stores the attachments but not the item, when AddAttachment is called,
+// then a TodoItemNotFoundError is returned without attaching the URL.
+func TestAddAttachmentNotFound(t *testing.T) {
+	// arrange
+	accessor := newAttachingAccessor(t)
+	accessor.MockStorageAccessor.EXPECT().
+		Read(gomock.Any()).
+		Return(nil)
+	accessor.MockAttachmentAccessor.EXPECT().
+		AddAttachment(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.AddAttachment(1, "https://example.com")
+
+	// assert
+	assert.Equal(t, core.TodoItemNotFoundError{ID: 1}, err)
+}
+
+// TestRemoveAttachment Given an accessor that stores the attachments, when RemoveAttachment is called with a URL,
+// then the URL is detached, the sink is notified, and the item is returned with the rest of its attachments.
+func TestRemoveAttachment(t *testing.T) {
+	// arrange
+	accessor := newAttachingAccessor(t)
+	stored := core.TodoItem{ID: 1, Description: "Test description"}
+	accessor.expectAttachmentChange(stored, []string{"https://example.com"}, nil,
+		accessor.MockAttachmentAccessor.EXPECT().
+			RemoveAttachment(1, "https://example.com").
+			Return(nil))
+	sink := &fakeSink{}
+	theCore := core.NewCore(accessor, sink)
+
+	// act
+	got, err := theCore.RemoveAttachment(1, "https://example.com")
+
+	// assert
+	if assert.NoError(t, err) {
+		want := core.TodoItem{ID: 1, Description: "Test description", UpdatedAt: updatedAt}
+		assert.Equal(t, want, got)
+		assert.Equal(t, []core.TodoItem{want}, sink.updated)
+	}
+}
+
+// TestAddAttachmentUnsupported Given an accessor that doesn't store the attachments, when AddAttachment is called, then a StorageError is returned.
+func TestAddAttachmentUnsupported(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.expectTransaction()
+
+	// act
+	_, err := e.core.AddAttachment(1, "https://example.com")
+
+	// assert
+	assert.ErrorAs(t, err, new(core.StorageError))
+}
+
+// TestGetItemsWithAttachments Given an accessor that stores the attachments, when GetItems is called,
+// then the items are returned with their attachments, if any.
+func TestGetItemsWithAttachments(t *testing.T) {
+	// arrange
+	accessor := newAttachingAccessor(t)
+	accessor.MockStorageAccessor.EXPECT().
+		Read(gomock.Any()).
+		Return([]core.TodoItem{{ID: 1}, {ID: 2}})
+	accessor.MockAttachmentAccessor.EXPECT().
+		ReadAttachments([]core.ItemID{1, 2}).
+		Return(map[core.ItemID][]string{2: {"https://example.com"}}, nil)
+	theCore := core.NewCore(accessor)
+
+	// act
+	got := theCore.GetItems(false)
+
+	// assert
+	assert.Equal(t, []core.TodoItem{{ID: 1}, {ID: 2, Attachments: []string{"https://example.com"}}}, got)
+}
@@ -0,0 +1,375 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditAction is the kind of a mutation recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntry records a mutation of a TodoItem, who made it, and when.
+type AuditEntry struct {
+	ID     int         `json:"id"`
+	ItemID ItemID      `json:"item_id"`
+	Action AuditAction `json:"action"`
+	// Actor is who made the mutation, as told by WithActor. It's empty if unknown.
+	Actor string `json:"actor"`
+	// Diff is the fields of the TodoItem that are changed, each with its old and new value, which is null before a creation or after a deletion:
+	//
+	//	{"completed": {"old": false, "new": true}, "completed_at": {"old": null, "new": "..."}}
+	//
+	// The update time is left out, as it changes with every mutation.
+	Diff json.RawMessage `json:"diff"`
+	At   time.Time       `json:"at"`
+}
+
+// errNoAuditLog is returned by GetAuditLog if the accessor is not an AuditAccessor.
+var errNoAuditLog = errors.New("the storage does not keep an audit log")
+
+// WithActor returns a copy of the core that records the actor as who made the mutations in the audit log, e.g., the user of a request.
+// The copy shares everything else with the core.
+func (c *TheCore) WithActor(actor string) Core {
+	actorCore := *c
+	actorCore.actor = actor
+	return &actorCore
+}
+
+// GetAuditLog returns the audit log of the TodoItem with the id, or of all the TodoItems if the id is 0, oldest first.
+// A StorageError is returned if the storage doesn't keep an audit log.
+func (c *TheCore) GetAuditLog(itemID ItemID) ([]AuditEntry, error) {
+	log.WithFields(log.Fields{"item_id": itemID}).Info("CORE: Getting audit log.")
+	audit, ok := c.accessor.(AuditAccessor)
+	if !ok {
+		log.Warn("CORE: ", errNoAuditLog)
+		return nil, wrapStorageError(errNoAuditLog)
+	}
+	var entries []AuditEntry
+	err := c.retryOnReconnect(func() (err error) {
+		entries, err = audit.ReadAudit(itemID)
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	return entries, nil
+}
+
+// audited returns the accessor for the core to mutate the TodoItems with. If the accessor is an AuditAccessor,
+// every mutation is recorded in the audit log in the same transaction as the mutation, so that neither is kept without the other.
+// NOTE: Import and Reset replace the TodoItems wholesale and are not itemized, nor are the TodoItems deleted along with their List.
+func (c *TheCore) audited() StorageAccessor {
+	if _, ok := c.accessor.(AuditAccessor); !ok {
+		return c.accessor
+	}
+	return &auditingAccessor{StorageAccessor: c.accessor, actor: c.actor, clock: c.clock}
+}
+
+// auditedTransaction calls fn in a transaction, recording the changes that fn returns in the audit log of the transaction if the accessor is an AuditAccessor.
+// It's for the mutations that don't go through a single method of the accessor returned by audited.
+func (c *TheCore) auditedTransaction(fn func(tx StorageAccessor) ([]auditChange, error)) error {
+	if _, ok := c.accessor.(AuditAccessor); ok {
+		return (&auditingAccessor{StorageAccessor: c.accessor, actor: c.actor, clock: c.clock}).record(fn)
+	}
+	return c.accessor.Transaction(func(tx StorageAccessor) error {
+		_, err := fn(tx)
+		return err
+	})
+}
+
+// auditingAccessor is a StorageAccessor that records the mutations made through it in the audit log; see TheCore.audited.
+type auditingAccessor struct {
+	StorageAccessor
+	actor string
+	clock Clock
+}
+
+// record calls fn in a transaction, recording the changes that fn returns in the audit log of the transaction.
+func (a *auditingAccessor) record(fn func(tx StorageAccessor) ([]auditChange, error)) error {
+	return a.StorageAccessor.Transaction(func(tx StorageAccessor) error {
+		audit, ok := tx.(AuditAccessor)
+		if !ok {
+			return errNoAuditLog
+		}
+		changes, err := fn(tx)
+		if err != nil {
+			return err
+		}
+		now := a.clock.Now()
+		for _, change := range changes {
+			entry, err := change.entry(a.actor, now)
+			if err != nil {
+				return err
+			}
+			if err := audit.RecordAudit(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// auditChange is the states of a TodoItem before and after a mutation; the former is nil for a creation and the latter for a deletion.
+type auditChange struct {
+	old, new *TodoItem
+}
+
+func auditCreated(todo TodoItem) auditChange {
+	return auditChange{new: &todo}
+}
+
+func auditUpdated(old, new TodoItem) auditChange {
+	return auditChange{old: &old, new: &new}
+}
+
+func auditDeleted(todo TodoItem) auditChange {
+	return auditChange{old: &todo}
+}
+
+// entry returns the AuditEntry of the change made by the actor at the time.
+func (change auditChange) entry(actor string, at time.Time) (AuditEntry, error) {
+	entry := AuditEntry{Actor: actor, At: at}
+	switch {
+	case change.old == nil:
+		entry.ItemID, entry.Action = change.new.ID, AuditCreate
+	case change.new == nil:
+		entry.ItemID, entry.Action = change.old.ID, AuditDelete
+	default:
+		entry.ItemID, entry.Action = change.new.ID, AuditUpdate
+	}
+	diff, err := auditDiff(change.old, change.new)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	entry.Diff = diff
+	return entry, nil
+}
+
+// auditDiff returns the fields that differ between the old and the new TodoItem, in the format of AuditEntry.Diff.
+func auditDiff(old, new *TodoItem) (json.RawMessage, error) {
+	oldFields, err := fieldsOf(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := fieldsOf(new)
+	if err != nil {
+		return nil, err
+	}
+	type fieldChange struct {
+		Old json.RawMessage `json:"old"`
+		New json.RawMessage `json:"new"`
+	}
+	diff := map[string]fieldChange{}
+	for _, fields := range []map[string]json.RawMessage{oldFields, newFields} {
+		for name := range fields {
+			if name == "updated_at" || string(oldFields[name]) == string(newFields[name]) {
+				continue
+			}
+			diff[name] = fieldChange{Old: nullIfMissing(oldFields[name]), New: nullIfMissing(newFields[name])}
+		}
+	}
+	return json.Marshal(diff)
+}
+
+// fieldsOf returns the JSON fields of the TodoItem, or none if it's nil.
+func fieldsOf(todo *TodoItem) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if todo == nil {
+		return fields, nil
+	}
+	b, err := json.Marshal(todo)
+	if err != nil {
+		return nil, err
+	}
+	return fields, json.Unmarshal(b, &fields)
+}
+
+func nullIfMissing(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return value
+}
+
+func (a *auditingAccessor) Create(todo *TodoItem) (ItemID, error) {
+	err := a.record(func(tx StorageAccessor) ([]auditChange, error) {
+		if _, err := tx.Create(todo); err != nil {
+			return nil, err
+		}
+		return []auditChange{auditCreated(*todo)}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return todo.ID, nil
+}
+
+func (a *auditingAccessor) CreateAll(todos []TodoItem) error {
+	return a.record(func(tx StorageAccessor) ([]auditChange, error) {
+		if err := tx.CreateAll(todos); err != nil {
+			return nil, err
+		}
+		changes := make([]auditChange, 0, len(todos))
+		for _, todo := range todos {
+			changes = append(changes, auditCreated(todo))
+		}
+		return changes, nil
+	})
+}
+
+func (a *auditingAccessor) CreateUnique(todo *TodoItem) (bool, error) {
+	var isNew bool
+	err := a.record(func(tx StorageAccessor) (changes []auditChange, err error) {
+		isNew, err = tx.CreateUnique(todo)
+		if err != nil || !isNew {
+			return nil, err
+		}
+		return []auditChange{auditCreated(*todo)}, nil
+	})
+	return isNew, err
+}
+
+func (a *auditingAccessor) UpdateWith(id ItemID, modify func(*TodoItem)) (TodoItem, error) {
+	var todo TodoItem
+	err := a.record(func(tx StorageAccessor) (changes []auditChange, err error) {
+		var old TodoItem
+		todo, err = tx.UpdateWith(id, func(todo *TodoItem) {
+			old = *todo
+			modify(todo)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []auditChange{auditUpdated(old, todo)}, nil
+	})
+	return todo, err
+}
+
+func (a *auditingAccessor) Upsert(id ItemID, modify func(*TodoItem)) (TodoItem, bool, error) {
+	var todo TodoItem
+	var isNew bool
+	err := a.record(func(tx StorageAccessor) (changes []auditChange, err error) {
+		var old TodoItem
+		todo, isNew, err = tx.Upsert(id, func(todo *TodoItem) {
+			old = *todo
+			modify(todo)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if isNew {
+			return []auditChange{auditCreated(todo)}, nil
+		}
+		return []auditChange{auditUpdated(old, todo)}, nil
+	})
+	return todo, isNew, err
+}
+
+func (a *auditingAccessor) UpdateCompleted(ids []ItemID, completed bool, at time.Time) (int, error) {
+	var n int
+	err := a.record(func(tx StorageAccessor) (changes []auditChange, err error) {
+		olds := tx.ReadByIDs(ids)
+		n, err = tx.UpdateCompleted(ids, completed, at)
+		if err != nil {
+			return nil, err
+		}
+		return changesOf(olds, tx.ReadByIDs(ids)), nil
+	})
+	return n, err
+}
+
+func (a *auditingAccessor) Reorder(ids []ItemID) error {
+	return a.record(func(tx StorageAccessor) ([]auditChange, error) {
+		olds := tx.ReadByIDs(ids)
+		if err := tx.Reorder(ids); err != nil {
+			return nil, err
+		}
+		return changesOf(olds, tx.ReadByIDs(ids)), nil
+	})
+}
+
+// changesOf returns the updates from the old TodoItems to the new ones with the same ids, skipping the ones that are not changed.
+func changesOf(olds, news []TodoItem) []auditChange {
+	byID := make(map[ItemID]TodoItem, len(olds))
+	for _, old := range olds {
+		byID[old.ID] = old
+	}
+	var changes []auditChange
+	for _, todo := range news {
+		old, ok := byID[todo.ID]
+		if !ok {
+			continue
+		}
+		if diff, err := auditDiff(&old, &todo); err == nil && string(diff) == "{}" {
+			continue
+		}
+		changes = append(changes, auditUpdated(old, todo))
+	}
+	return changes
+}
+
+func (a *auditingAccessor) Delete(id ItemID) error {
+	return a.record(func(tx StorageAccessor) ([]auditChange, error) {
+		olds := tx.ReadByIDs([]ItemID{id})
+		if err := tx.Delete(id); err != nil {
+			return nil, err
+		}
+		if len(olds) == 0 {
+			return nil, nil
+		}
+		return []auditChange{auditDeleted(olds[0])}, nil
+	})
+}
+
+func (a *auditingAccessor) DeleteCompleted() ([]TodoItem, error) {
+	var todos []TodoItem
+	err := a.record(func(tx StorageAccessor) (changes []auditChange, err error) {
+		todos, err = tx.DeleteCompleted()
+		for _, todo := range todos {
+			changes = append(changes, auditDeleted(todo))
+		}
+		return changes, err
+	})
+	return todos, err
+}
+
+func (a *auditingAccessor) DeleteCompletedBefore(before time.Time) (int, error) {
+	var n int
+	err := a.record(func(tx StorageAccessor) (changes []auditChange, err error) {
+		// NOTE: The TodoItems are read first, as only the number of them is known once they're deleted.
+		olds := tx.Read(func(todo TodoItem) bool {
+			return todo.CompletedAt != nil && todo.CompletedAt.Before(before)
+		})
+		n, err = tx.DeleteCompletedBefore(before)
+		if err != nil {
+			return nil, err
+		}
+		for _, old := range olds {
+			changes = append(changes, auditDeleted(old))
+		}
+		return changes, nil
+	})
+	return n, err
+}
+
+func (a *auditingAccessor) Restore(todos []TodoItem) ([]TodoItem, error) {
+	var restored []TodoItem
+	err := a.record(func(tx StorageAccessor) (changes []auditChange, err error) {
+		restored, err = tx.Restore(todos)
+		for _, todo := range restored {
+			changes = append(changes, auditCreated(todo))
+		}
+		return changes, err
+	})
+	return restored, err
+}
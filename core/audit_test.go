@@ -0,0 +1,115 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	core "todolist/core"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// auditingAccessor is a stub of a StorageAccessor that keeps an audit log as well, whose transactions run on itself.
+type auditingAccessor struct {
+	*MockStorageAccessor
+	*MockAuditAccessor
+}
+
+func newAuditingAccessor(t *testing.T) *auditingAccessor {
+	ctrl := gomock.NewController(t)
+	accessor := &auditingAccessor{NewMockStorageAccessor(ctrl), NewMockAuditAccessor(ctrl)}
+	accessor.MockStorageAccessor.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(core.StorageAccessor) error) error { return fn(accessor) }).
+		AnyTimes()
+	return accessor
+}
+
+// TestUpdateItemAudited Given an accessor that keeps an audit log, when UpdateItem is called on a core acting as alice,
+// then an update entry is recorded with the actor, the time, and the changed fields.
+func TestUpdateItemAudited(t *testing.T) {
+	// arrange
+	accessor := newAuditingAccessor(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	accessor.MockStorageAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(&stored)
+			return stored, nil
+		})
+	var got core.AuditEntry
+	accessor.MockAuditAccessor.EXPECT().
+		RecordAudit(gomock.Any()).
+		DoAndReturn(func(entry core.AuditEntry) error {
+			got = entry
+			return nil
+		})
+	theCore := core.NewCore(accessor)
+	theCore.SetClock(core.NewFakeClock(now))
+
+	// act
+	_, err := theCore.WithActor("alice").UpdateItem(1, true)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, got.ItemID)
+		assert.Equal(t, core.AuditUpdate, got.Action)
+		assert.Equal(t, "alice", got.Actor)
+		assert.Equal(t, now, got.At)
+		want := `{"completed": {"old": false, "new": true}, "completed_at": {"old": null, "new": "2024-01-01T12:00:00Z"}}`
+		assert.JSONEq(t, want, string(got.Diff))
+	}
+}
+
+// TestCreateItemAuditFails Given an accessor that fails to record the audit entry, when CreateItem is called, then the error is returned, so that the transaction is rolled back.
+func TestCreateItemAuditFails(t *testing.T) {
+	// arrange
+	accessor := newAuditingAccessor(t)
+	accessor.MockStorageAccessor.EXPECT().
+		Create(gomock.Any()).
+		Return(1, nil)
+	accessor.MockAuditAccessor.EXPECT().
+		RecordAudit(gomock.Any()).
+		Return(errors.New("disk full"))
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.CreateItem("some description", "")
+
+	// assert
+	assert.ErrorAs(t, err, new(core.StorageError))
+}
+
+// TestGetAuditLog Given an accessor that keeps an audit log, when GetAuditLog is called, then the entries of the accessor are returned.
+func TestGetAuditLog(t *testing.T) {
+	// arrange
+	accessor := newAuditingAccessor(t)
+	entries := []core.AuditEntry{{ID: 1, ItemID: 2, Action: core.AuditDelete}}
+	accessor.MockAuditAccessor.EXPECT().
+		ReadAudit(2).
+		Return(entries, nil)
+	theCore := core.NewCore(accessor)
+
+	// act
+	got, err := theCore.GetAuditLog(2)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, entries, got)
+	}
+}
+
+// TestGetAuditLogUnsupported Given an accessor that doesn't keep an audit log, when GetAuditLog is called, then a StorageError is returned.
+func TestGetAuditLogUnsupported(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.GetAuditLog(0)
+
+	// assert
+	assert.ErrorAs(t, err, new(core.StorageError))
+}
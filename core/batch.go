@@ -0,0 +1,156 @@
+package core
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxBatchOps is the maximum number of operations in a batch of Batch.
+const MaxBatchOps = 100
+
+// The operations of a BatchOp.
+const (
+	// BatchCreate creates a TodoItem like CreateItem, from the description and the color.
+	BatchCreate = "create"
+	// BatchUpdate sets the completed status of the TodoItem with the id like UpdateItem.
+	BatchUpdate = "update"
+	// BatchDelete deletes the TodoItem with the id like DeleteItem.
+	BatchDelete = "delete"
+)
+
+// BatchOp is an operation in a batch of Batch. Which fields are used depends on Op; see BatchCreate, BatchUpdate, and BatchDelete.
+type BatchOp struct {
+	Op          string `json:"op"`
+	ID          ItemID `json:"id,omitempty"`
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Completed   *bool  `json:"completed,omitempty"`
+}
+
+// BatchResult is the outcome of a BatchOp.
+type BatchResult struct {
+	Op string `json:"op"`
+	// ID is the id of the TodoItem operated on, including the one created.
+	ID ItemID `json:"id"`
+	// Item is the TodoItem as created or updated. It's nil for BatchDelete and if the operation failed.
+	Item *TodoItem `json:"item,omitempty"`
+	// Err is why the operation failed, or nil if it succeeded.
+	Err error `json:"-"`
+}
+
+// BatchError is returned by an atomic Batch if one of the operations fails, in which case none of them takes effect.
+type BatchError struct {
+	// Index is the position of the failed operation in the batch, starting from 0.
+	Index int
+	Op    string
+	Err   error
+}
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s) failed: %v", e.Index, e.Op, e.Err)
+}
+
+func (e BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Batch applies the operations in order and returns their results, in the same order.
+//
+// If atomic, the operations are applied in a single transaction: either all of them take effect or none does,
+// and a BatchError wrapping the error of the first failed operation is returned. The EventSinks are only notified once all of them took effect,
+// and only of the changes that the operations would have been notified of on their own, e.g., not of the deletion of a TodoItem that doesn't exist.
+// Otherwise, every operation is applied on its own, and the failed ones are reported by the Err of their results.
+//
+// A ValidationError is returned without applying any operation if there are more than MaxBatchOps operations.
+func (c *TheCore) Batch(ops []BatchOp, atomic bool) ([]BatchResult, error) {
+	log.WithFields(log.Fields{"count": len(ops), "atomic": atomic}).Info("CORE: Applying a batch of operations.")
+	if len(ops) > MaxBatchOps {
+		err := ValidationError{Message: fmt.Sprintf("at most %d operations can be applied at once", MaxBatchOps)}
+		log.Warn("CORE: ", err)
+		return nil, err
+	}
+	if !atomic {
+		results := make([]BatchResult, len(ops))
+		for i, op := range ops {
+			results[i] = c.apply(op)
+		}
+		return results, nil
+	}
+
+	var results []BatchResult
+	var held heldEvents
+	err := c.accessor.Transaction(func(tx StorageAccessor) error {
+		// NOTE: The events are held back until the transaction commits, since the operations may still be undone.
+		txCore := *c
+		txCore.accessor = tx
+		held = heldEvents{}
+		txCore.sinks = []EventSink{&held}
+		results = make([]BatchResult, len(ops))
+		for i, op := range ops {
+			results[i] = txCore.apply(op)
+			if results[i].Err != nil {
+				return BatchError{Index: i, Op: op.Op, Err: results[i].Err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	held.replay(c.sinks)
+	return results, nil
+}
+
+// heldEvents is an EventSink that holds the events back, in order, until they're replayed to other sinks.
+type heldEvents struct {
+	events []func(EventSink)
+}
+
+func (h *heldEvents) ItemCreated(todo TodoItem) {
+	h.events = append(h.events, func(sink EventSink) { sink.ItemCreated(todo) })
+}
+
+func (h *heldEvents) ItemUpdated(todo TodoItem) {
+	h.events = append(h.events, func(sink EventSink) { sink.ItemUpdated(todo) })
+}
+
+func (h *heldEvents) ItemDeleted(id ItemID) {
+	h.events = append(h.events, func(sink EventSink) { sink.ItemDeleted(id) })
+}
+
+// replay tells the held events to the sinks, in the order they happened.
+func (h *heldEvents) replay(sinks []EventSink) {
+	for _, event := range h.events {
+		for _, sink := range sinks {
+			event(sink)
+		}
+	}
+}
+
+// apply applies a single operation of Batch.
+func (c *TheCore) apply(op BatchOp) BatchResult {
+	result := BatchResult{Op: op.Op, ID: op.ID}
+	var todo TodoItem
+	switch op.Op {
+	case BatchCreate:
+		todo, result.Err = c.CreateItem(op.Description, op.Color)
+	case BatchUpdate:
+		if op.Completed == nil {
+			result.Err = ValidationError{Message: "validation failed", Fields: map[string]string{"completed": "required"}}
+			break
+		}
+		todo, result.Err = c.UpdateItem(op.ID, *op.Completed)
+	case BatchDelete:
+		result.Err = c.DeleteItem(op.ID)
+		return result
+	default:
+		result.Err = ValidationError{Message: fmt.Sprintf("unknown operation %q", op.Op)}
+	}
+	if result.Err == nil {
+		result.ID = todo.ID
+		result.Item = &todo
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	core "todolist/core"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// expectTransaction Expects Transaction to be called, running the function against the mock storage accessor and returning its error as the transaction would.
+func (e *testEnv) expectTransaction() *gomock.Call {
+	return e.mockAccessor.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(core.StorageAccessor) error) error {
+			return fn(e.mockAccessor)
+		})
+}
+
+// TestBatch Given a batch that creates, updates, and deletes items, when Batch is called atomically, then the operations are applied in order in a transaction,
+// their results are returned, and the sink is notified once all of them took effect.
+func TestBatch(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	stored := core.TodoItem{ID: 1, Description: "Buy milk"}
+	completed := true
+	e.expectTransaction()
+	gomock.InOrder(
+		e.mockAccessor.EXPECT().
+			Create(gomock.Any()).
+			DoAndReturn(func(item *core.TodoItem) (core.ItemID, error) {
+				item.ID = 3
+				return 3, nil
+			}),
+		e.expectUpdateWith(&stored),
+		e.mockAccessor.EXPECT().Delete(core.ItemID(2)).Return(nil),
+	)
+
+	// act
+	got, err := e.core.Batch([]core.BatchOp{
+		{Op: core.BatchCreate, Description: "Call mom"},
+		{Op: core.BatchUpdate, ID: 1, Completed: &completed},
+		{Op: core.BatchDelete, ID: 2},
+	}, true)
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 3) {
+		assert.Equal(t, core.BatchResult{Op: core.BatchCreate, ID: 3, Item: &core.TodoItem{ID: 3, Description: "Call mom"}}, got[0])
+		assert.Equal(t, 1, got[1].ID)
+		assert.True(t, got[1].Item.Completed)
+		assert.Equal(t, core.BatchResult{Op: core.BatchDelete, ID: 2}, got[2])
+		assert.Equal(t, []core.TodoItem{*got[0].Item}, sink.created)
+		assert.Equal(t, []core.TodoItem{*got[1].Item}, sink.updated)
+		assert.Equal(t, []core.ItemID{2}, sink.deleted)
+	}
+}
+
+// TestBatchDeleteMissing Given a batch that deletes an item that doesn't exist, when Batch is called atomically, then the operation succeeds,
+// as DeleteItem does, but the sink is not notified of a deletion that didn't happen.
+func TestBatchDeleteMissing(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	e.expectTransaction()
+	e.mockAccessor.EXPECT().
+		Delete(core.ItemID(2)).
+		Return(core.TodoItemNotFoundError{ID: 2})
+
+	// act
+	got, err := e.core.Batch([]core.BatchOp{{Op: core.BatchDelete, ID: 2}}, true)
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 1) {
+		assert.NoError(t, got[0].Err)
+	}
+	assert.Empty(t, sink.deleted)
+}
+
+// TestBatchAtomicFailure Given an atomic batch whose second operation fails, when Batch is called, then a BatchError wrapping the error is returned,
+// the operations after it are not applied, and the sink is not notified.
+func TestBatchAtomicFailure(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	completed := true
+	e.expectTransaction()
+	e.mockAccessor.EXPECT().
+		Create(gomock.Any()).
+		Return(core.ItemID(1), nil)
+	e.mockAccessor.EXPECT().
+		UpdateWith(core.ItemID(2), gomock.Any()).
+		Return(core.TodoItem{}, core.TodoItemNotFoundError{ID: 2})
+
+	// act
+	_, err := e.core.Batch([]core.BatchOp{
+		{Op: core.BatchCreate, Description: "Call mom"},
+		{Op: core.BatchUpdate, ID: 2, Completed: &completed},
+		{Op: core.BatchDelete, ID: 1},
+	}, true)
+
+	// assert
+	var batchErr core.BatchError
+	if assert.ErrorAs(t, err, &batchErr) {
+		assert.Equal(t, 1, batchErr.Index)
+		assert.Equal(t, core.BatchUpdate, batchErr.Op)
+		assert.ErrorAs(t, err, new(core.TodoItemNotFoundError))
+	}
+	assert.Empty(t, sink.created)
+	assert.Empty(t, sink.updated)
+	assert.Empty(t, sink.deleted)
+}
+
+// TestBatchNotAtomic Given a batch whose first operation fails, when Batch is called non-atomically, then the other operations are still applied
+// and the error is reported in the result of the failed one.
+func TestBatchNotAtomic(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	e.mockAccessor.EXPECT().
+		Delete(core.ItemID(1)).
+		Return(errors.New("some error"))
+	e.mockAccessor.EXPECT().
+		Create(gomock.Any()).
+		DoAndReturn(func(item *core.TodoItem) (core.ItemID, error) {
+			item.ID = 2
+			return 2, nil
+		})
+
+	// act
+	got, err := e.core.Batch([]core.BatchOp{
+		{Op: core.BatchDelete, ID: 1},
+		{Op: core.BatchCreate, Description: "Call mom"},
+		{Op: "rename", ID: 1},
+	}, false)
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 3) {
+		assert.Error(t, got[0].Err)
+		assert.NoError(t, got[1].Err)
+		assert.IsType(t, core.ValidationError{}, got[2].Err)
+		assert.Len(t, sink.created, 1)
+		assert.Empty(t, sink.deleted)
+	}
+}
+
+// TestBatchTooManyOps Given more than MaxBatchOps operations, when Batch is called, then a ValidationError is returned without applying any of them.
+func TestBatchTooManyOps(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	ops := make([]core.BatchOp, core.MaxBatchOps+1)
+
+	// act
+	_, err := e.core.Batch(ops, true)
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
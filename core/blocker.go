@@ -0,0 +1,92 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// errNoBlockers is returned by AddBlockers if the accessor is not a BlockerAccessor.
+var errNoBlockers = errors.New("the storage does not support the dependencies between TodoItems")
+
+// AddBlockers records that the TodoItem with the id can't be completed until the TodoItems with the blocker ids are, and returns all the blockers of the TodoItem.
+// A ValidationError is returned if there's no blocker id, more than MaxBatchIDs of them, or the TodoItem is among them;
+// a TodoItemNotFoundError if any of the TodoItems doesn't exist; and a ConflictError if the dependencies would form a cycle.
+// NOTE: The TodoItems are not locked, so two concurrent calls blocking a pair of TodoItems by each other may both succeed and form a cycle.
+func (c *TheCore) AddBlockers(id ItemID, blockerIDs []ItemID) ([]TodoItem, error) {
+	log.WithFields(log.Fields{"id": id, "blocker_ids": blockerIDs}).Info("CORE: Adding blockers of TodoItem.")
+	var err error
+	switch {
+	case len(blockerIDs) == 0:
+		err = ValidationError{Message: "at least one blocker id is required"}
+	case len(blockerIDs) > MaxBatchIDs:
+		err = ValidationError{Message: fmt.Sprintf("at most %d blockers can be added at once", MaxBatchIDs)}
+	case slices.Contains(blockerIDs, id):
+		err = ValidationError{Message: fmt.Sprintf("TodoItem with id %d can't block itself", id)}
+	}
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, err
+	}
+	blockers, ok := c.accessor.(BlockerAccessor)
+	if !ok {
+		log.Warn("CORE: ", errNoBlockers)
+		return nil, wrapStorageError(errNoBlockers)
+	}
+	err = blockers.AddBlockers(id, blockerIDs)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	todos, err := blockers.ReadBlockers(id)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	return todos, nil
+}
+
+// GetBlockedItems returns the TodoItems that are blocked by at least one incomplete TodoItem, ordered by id.
+// Nothing is blocked if the accessor is not a BlockerAccessor.
+func (c *TheCore) GetBlockedItems() ([]TodoItem, error) {
+	log.Info("CORE: Getting blocked TodoItems.")
+	blockers, ok := c.accessor.(BlockerAccessor)
+	if !ok {
+		return nil, nil
+	}
+	var todos []TodoItem
+	err := c.retryOnReconnect(func() error {
+		var err error
+		todos, err = blockers.ReadBlocked()
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	return todos, nil
+}
+
+// checkUnblocked returns a ConflictError if the accessor is a BlockerAccessor and any of the blockers of the TodoItem with the id is incomplete.
+func (c *TheCore) checkUnblocked(id ItemID) error {
+	blockers, ok := c.accessor.(BlockerAccessor)
+	if !ok {
+		return nil
+	}
+	todos, err := blockers.ReadBlockers(id)
+	if err != nil {
+		return err
+	}
+	var pending []ItemID
+	for _, todo := range todos {
+		if !todo.Completed {
+			pending = append(pending, todo.ID)
+		}
+	}
+	if len(pending) > 0 {
+		return ConflictError{Message: fmt.Sprintf("TodoItem with id %d is blocked by the incomplete TodoItems %v", id, pending)}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package core_test
+
+import (
+	"testing"
+
+	core "todolist/core"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// blockingAccessor is a stub of a StorageAccessor that stores the blockers as well.
+type blockingAccessor struct {
+	*MockStorageAccessor
+	*MockBlockerAccessor
+}
+
+func newBlockingAccessor(t *testing.T) *blockingAccessor {
+	ctrl := gomock.NewController(t)
+	return &blockingAccessor{NewMockStorageAccessor(ctrl), NewMockBlockerAccessor(ctrl)}
+}
+
+// expectTransaction Expects Transaction to be called, running the function with the accessor itself as the accessor of the transaction.
+func (a *blockingAccessor) expectTransaction() *gomock.Call {
+	return a.MockStorageAccessor.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(core.StorageAccessor) error) error {
+			return fn(a)
+		})
+}
+
+// TestAddBlockers Given an accessor that stores the blockers, when AddBlockers is called, then the blockers are added and all the blockers of the item are returned.
+func TestAddBlockers(t *testing.T) {
+	// arrange
+	accessor := newBlockingAccessor(t)
+	blockers := []core.TodoItem{{ID: 2, Description: "blocker 1"}, {ID: 3, Description: "blocker 2"}}
+	gomock.InOrder(
+		accessor.MockBlockerAccessor.EXPECT().
+			AddBlockers(1, []core.ItemID{3}).
+			Return(nil),
+		accessor.MockBlockerAccessor.EXPECT().
+			ReadBlockers(1).
+			Return(blockers, nil),
+	)
+	theCore := core.NewCore(accessor)
+
+	// act
+	got, err := theCore.AddBlockers(1, []core.ItemID{3})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, blockers, got)
+	}
+}
+
+// TestAddBlockersInvalid Given an accessor that stores the blockers, when AddBlockers is called without blockers or with the item itself as a blocker,
+// then a ValidationError is returned without touching the storage.
+func TestAddBlockersInvalid(t *testing.T) {
+	tests := map[string][]core.ItemID{
+		"no blocker": nil,
+		"itself":     {2, 1},
+	}
+	for name, blockerIDs := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			accessor := newBlockingAccessor(t)
+			accessor.MockBlockerAccessor.EXPECT().
+				AddBlockers(gomock.Any(), gomock.Any()).
+				Times(0)
+			theCore := core.NewCore(accessor)
+
+			// act
+			_, err := theCore.AddBlockers(1, blockerIDs)
+
+			// assert
+			assert.ErrorAs(t, err, new(core.ValidationError))
+		})
+	}
+}
+
+// TestAddBlockersCycle Given an accessor that rejects the blockers as they would form a cycle, when AddBlockers is called, then the ConflictError is returned as is.
+func TestAddBlockersCycle(t *testing.T) {
+	// arrange
+	accessor := newBlockingAccessor(t)
+	conflictErr := core.ConflictError{Message: "cycle"}
+	accessor.MockBlockerAccessor.EXPECT().
+		AddBlockers(1, []core.ItemID{2}).
+		Return(conflictErr)
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.AddBlockers(1, []core.ItemID{2})
+
+	// assert
+	assert.Equal(t, conflictErr, err)
+}
+
+// TestAddBlockersUnsupported Given an accessor that doesn't store the blockers, when AddBlockers is called, then a StorageError is returned.
+func TestAddBlockersUnsupported(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.AddBlockers(1, []core.ItemID{2})
+
+	// assert
+	assert.ErrorAs(t, err, new(core.StorageError))
+}
+
+// TestGetBlockedItemsUnsupported Given an accessor that doesn't store the blockers, when GetBlockedItems is called, then no item is blocked.
+func TestGetBlockedItemsUnsupported(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	got, err := e.core.GetBlockedItems()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
+
+// TestUpdateItemBlocked Given an item blocked by an incomplete item and a completed one, when UpdateItem is called to complete it,
+// then the blockers are read in the transaction of the update, and a ConflictError is returned, rolling it back, without the item being changed.
+func TestUpdateItemBlocked(t *testing.T) {
+	// arrange
+	accessor := newBlockingAccessor(t)
+	accessor.expectTransaction()
+	accessor.MockBlockerAccessor.EXPECT().
+		ReadBlockers(1).
+		Return([]core.TodoItem{{ID: 2, Completed: true}, {ID: 3}}, nil)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	want := stored
+	accessor.MockStorageAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(&stored)
+			return stored, nil
+		})
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.UpdateItem(1, true)
+
+	// assert
+	var conflictErr core.ConflictError
+	if assert.ErrorAs(t, err, &conflictErr) {
+		assert.Contains(t, conflictErr.Message, "[3]")
+	}
+	assert.Equal(t, want, stored)
+}
+
+// TestUpdateItemUnblocked Given an item whose blockers are all completed, when UpdateItem is called to complete it, then the item is completed.
+func TestUpdateItemUnblocked(t *testing.T) {
+	// arrange
+	accessor := newBlockingAccessor(t)
+	accessor.expectTransaction()
+	accessor.MockBlockerAccessor.EXPECT().
+		ReadBlockers(1).
+		Return([]core.TodoItem{{ID: 2, Completed: true}}, nil)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	accessor.MockStorageAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(&stored)
+			return stored, nil
+		})
+	theCore := core.NewCore(accessor)
+
+	// act
+	got, err := theCore.UpdateItem(1, true)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.True(t, got.Completed)
+	}
+}
+
+// TestForceUpdateItemBlocked Given an item blocked by an incomplete item, when ForceUpdateItem is called to complete it,
+// then the item is completed without checking its blockers.
+func TestForceUpdateItemBlocked(t *testing.T) {
+	// arrange
+	accessor := newBlockingAccessor(t)
+	accessor.MockBlockerAccessor.EXPECT().
+		ReadBlockers(gomock.Any()).
+		Times(0)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	accessor.MockStorageAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(&stored)
+			return stored, nil
+		})
+	theCore := core.NewCore(accessor)
+
+	// act
+	got, err := theCore.ForceUpdateItem(1, true)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.True(t, got.Completed)
+	}
+}
+
+// TestToggleItemBlocked Given an incomplete item blocked by an incomplete item, when ToggleItem is called,
+// then whether it's completed is decided on the item locked by the update, and a ConflictError is returned without the item being changed.
+func TestToggleItemBlocked(t *testing.T) {
+	// arrange
+	accessor := newBlockingAccessor(t)
+	accessor.expectTransaction()
+	accessor.MockBlockerAccessor.EXPECT().
+		ReadBlockers(1).
+		Return([]core.TodoItem{{ID: 2}}, nil)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	want := stored
+	accessor.MockStorageAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(&stored)
+			return stored, nil
+		})
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.ToggleItem(1)
+
+	// assert
+	assert.ErrorAs(t, err, new(core.ConflictError))
+	assert.Equal(t, want, stored)
+}
+
+// TestToggleItemBlockedReopens Given a completed item blocked by an incomplete item, when ToggleItem is called, then the item is reopened.
+func TestToggleItemBlockedReopens(t *testing.T) {
+	// arrange
+	accessor := newBlockingAccessor(t)
+	accessor.expectTransaction()
+	accessor.MockBlockerAccessor.EXPECT().
+		ReadBlockers(1).
+		Return([]core.TodoItem{{ID: 2}}, nil)
+	stored := core.TodoItem{ID: 1, Description: "some description", Completed: true}
+	accessor.MockStorageAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(&stored)
+			return stored, nil
+		})
+	theCore := core.NewCore(accessor)
+
+	// act
+	got, err := theCore.ToggleItem(1)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.False(t, got.Completed)
+	}
+}
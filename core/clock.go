@@ -0,0 +1,75 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. TheCore reads the time from its Clock instead of calling time.Now directly,
+// so that the time-dependent behaviors can be tested deterministically with a FakeClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the Clock of the wall time. This is the default.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock that only moves when told to. It's safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock that stays at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to now.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// IDGenerator hands out the ids of the TodoItems created by TheCore, for the storages that don't generate ids themselves.
+// Without an IDGenerator, the ids are left to the storage, e.g., the autoincrement of the database.
+type IDGenerator interface {
+	NextID() ItemID
+}
+
+// SequentialIDGenerator is an IDGenerator that counts up from a starting id. It's safe for concurrent use.
+type SequentialIDGenerator struct {
+	mu   sync.Mutex
+	next ItemID
+}
+
+// NewSequentialIDGenerator returns a SequentialIDGenerator whose first id is start.
+func NewSequentialIDGenerator(start ItemID) *SequentialIDGenerator {
+	return &SequentialIDGenerator{next: start}
+}
+
+func (g *SequentialIDGenerator) NextID() ItemID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id := g.next
+	g.next++
+	return id
+}
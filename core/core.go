@@ -1,84 +1,932 @@
 package core
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 // Core is the interface that declares the core functionality of the application.
 type Core interface {
-	CreateItem(description string) TodoItem
-	UpdateItem(id int, completed bool) (TodoItem, error)
-	DeleteItem(id int) error
+	CreateItem(description, color string) (TodoItem, error)
+	CreateItems(items []TodoItem) ([]TodoItem, error)
+	UpdateItem(id ItemID, completed bool) (TodoItem, error)
+	ForceUpdateItem(id ItemID, completed bool) (TodoItem, error)
+	SetItemColor(id ItemID, color string) (TodoItem, error)
+	CloneItem(id ItemID) (TodoItem, error)
+	MoveItem(id ItemID, listID int) (TodoItem, error)
+	UpsertItem(item TodoItem) (TodoItem, bool, error)
+	ToggleItem(id ItemID) (TodoItem, error)
+	StartItem(id ItemID) (TodoItem, error)
+	StopItem(id ItemID) (TodoItem, error)
+	GetTimeSpent(id ItemID) (TimeSpent, error)
+	SetItemsCompleted(ids []ItemID, completed bool) (int, error)
+	AddBlockers(id ItemID, blockerIDs []ItemID) ([]TodoItem, error)
+	AddAttachment(id ItemID, url string) (TodoItem, error)
+	RemoveAttachment(id ItemID, url string) (TodoItem, error)
+	DeleteItem(id ItemID) error
+	Batch(ops []BatchOp, atomic bool) ([]BatchResult, error)
+	DeleteCompleted(maxFraction float64) ([]TodoItem, error)
+	PruneCompletedOlderThan(d time.Duration) (int, error)
+	RestoreItems(items []TodoItem) ([]TodoItem, error)
+	Reorder(ids []ItemID) error
+	ReplaceInDescriptions(r DescriptionReplacement) ([]TodoItem, error)
+	GetItem(id ItemID) (TodoItem, error)
+	ExistsItem(id ItemID) (bool, error)
 	GetItems(completed bool) []TodoItem
+	GetItemsByIDs(ids []ItemID) []TodoItem
+	GetBlockedItems() ([]TodoItem, error)
+	GetItemsFiltered(f ItemFilter) ([]TodoItem, error)
+	GetItemsByStatus(status ItemStatus) ([]TodoItem, error)
+	GetItemsAfter(cursor ItemID, limit int) (todos []TodoItem, nextCursor ItemID)
+	GetItemsPage(offset, limit int) (todos []TodoItem, total int, e error)
+	Query(q Query) (QueryResult, error)
+	GetItemsCompletedBetween(start, end time.Time) []TodoItem
+	GetItemsCompletedOn(date time.Time) []TodoItem
+	GetChangesSince(since time.Time) (changed []TodoItem, deletedIDs []ItemID, e error)
+	SuggestDescriptions(prefix string, limit int) []string
+	Summary() (SummaryStats, error)
+	Export() (Snapshot, error)
+	ExportStream(fn func(TodoItem) error) error
+	Import(snapshot Snapshot) (int, error)
+	Reset() error
+	Reindex() (ReindexReport, error)
+	GetAuditLog(itemID ItemID) ([]AuditEntry, error)
+	StorageStats() (BackendStats, error)
+}
+
+// ListCore is the interface that declares the management of the Lists, which group the TodoItems.
+type ListCore interface {
+	CreateList(name string) (List, error)
+	GetList(id int) (List, error)
+	GetLists() ([]List, error)
+	RenameList(id int, name string) (List, error)
+	DeleteList(id int) error
 }
 
 // NOTE: TheCore is meant to be used as the only implementation of the Core interface. Defining the functionalities as methods allows for being replaced by a mock core in the tests.
 
 // TheCore is the implementation of the Core interface.
 type TheCore struct {
-	accessor StorageAccessor
+	accessor        StorageAccessor
+	duplicatePolicy DuplicatePolicy
+	sinks           []EventSink
+	clock           Clock
+	ids             IDGenerator
+	// actor is who makes the mutations, recorded in the audit log; see WithActor.
+	actor string
+}
+
+// NewCore returns a core that stores the TodoItems with the accessor and notifies the sinks of the mutations.
+// It uses the RealClock and leaves the ids to the storage until told otherwise with SetClock and SetIDGenerator.
+func NewCore(accessor StorageAccessor, sinks ...EventSink) *TheCore {
+	return &TheCore{accessor: accessor, sinks: sinks, clock: RealClock{}}
+}
+
+// WithContext returns a copy of the core whose storage operations are cancelled once ctx is done, e.g., when the request it serves times out.
+// The core itself is returned if the accessor is not a ContextAccessor. The copy shares everything else with the core.
+func (c *TheCore) WithContext(ctx context.Context) Core {
+	contextual, ok := c.accessor.(ContextAccessor)
+	if !ok {
+		return c
+	}
+	ctxCore := *c
+	ctxCore.accessor = contextual.WithContext(ctx)
+	return &ctxCore
+}
+
+// DuplicatePolicy tells what CreateItem does if there's already an incomplete TodoItem with the same description,
+// compared trimmed and case-insensitively.
+type DuplicatePolicy int
+
+const (
+	// AllowDuplicates creates the TodoItem anyway. This is the default.
+	AllowDuplicates DuplicatePolicy = iota
+	// ReturnExisting returns the existing TodoItem instead of creating a new one.
+	ReturnExisting
+	// RejectDuplicates returns a ConflictError.
+	RejectDuplicates
+)
+
+// ParseDuplicatePolicy parses the name of a DuplicatePolicy, which is one of "off" (AllowDuplicates), "existing" (ReturnExisting), and "conflict" (RejectDuplicates).
+// An empty name means "off".
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch name {
+	case "", "off":
+		return AllowDuplicates, nil
+	case "existing":
+		return ReturnExisting, nil
+	case "conflict":
+		return RejectDuplicates, nil
+	default:
+		return 0, fmt.Errorf("unknown duplicate policy %q", name)
+	}
 }
 
-func NewCore(accessor StorageAccessor) *TheCore {
-	return &TheCore{accessor: accessor}
+// SetDuplicatePolicy sets what CreateItem does with duplicated descriptions. See DuplicatePolicy.
+func (c *TheCore) SetDuplicatePolicy(policy DuplicatePolicy) {
+	c.duplicatePolicy = policy
 }
 
+// SetClock sets the Clock from which the completion times of the TodoItems are read.
+func (c *TheCore) SetClock(clock Clock) {
+	c.clock = clock
+}
+
+// SetIDGenerator sets the IDGenerator that assigns the ids of the created TodoItems. A nil generator leaves the ids to the storage.
+func (c *TheCore) SetIDGenerator(ids IDGenerator) {
+	c.ids = ids
+}
+
+// MaxBatchIDs is the maximum number of ids that can be fetched or updated at once with GetItemsByIDs or SetItemsCompleted.
+const MaxBatchIDs = 100
+
+// MaxSuggestions is the maximum number of descriptions returned by SuggestDescriptions.
+const MaxSuggestions = 10
+
+// DefaultPageSize is the number of TodoItems in a page if the limit is not specified.
+const DefaultPageSize = 20
+
+// ItemID is the type of the ids of the TodoItems.
+// NOTE: It's an alias of int for now, so that the code that still takes the ids as int keeps working while it's migrated to ItemID.
+// The ids are ordered integers throughout, e.g., for the cursors of GetItemsAfter and Query and for ReadChangedSince; UUID keys are not supported.
+type ItemID = int
+
 type TodoItem struct {
-	ID          int
-	Description string
-	Completed   bool
+	ID          ItemID `json:"id" xml:"id"`
+	Description string `json:"description" xml:"description"`
+	Completed   bool   `json:"completed" xml:"completed"`
+	// Position is the place of the TodoItem in the manual order set by Reorder.
+	Position int `json:"position" xml:"position"`
+	// Color is the label of the TodoItem, either a hex code like "#1e90ff" or one of the Palette. It's empty if the TodoItem is not labeled.
+	Color string `json:"color" xml:"color,omitempty"`
+	// ListID is the id of the List the TodoItem belongs to. It's 0 if the TodoItem is in the default list, which is not a List.
+	ListID int `json:"list_id" xml:"list_id,omitempty"`
+	// CompletedAt is the time when the TodoItem was last completed. It's nil, and omitted from the JSON and the XML, if the TodoItem is not completed.
+	CompletedAt *time.Time `json:"completed_at,omitempty" xml:"completed_at,omitempty"`
+	// StartedAt is the time when the work on the TodoItem was started with Core.StartItem. It's nil, and omitted, if the TodoItem is not started or is stopped.
+	// It's kept when the TodoItem is completed, which ends the work at the completion time, and when it's reopened, which resumes the work.
+	StartedAt *time.Time `json:"started_at,omitempty" xml:"started_at,omitempty"`
+	// TimeSpent is the time worked on the TodoItem before StartedAt, accumulated every time the work is stopped; see TimeSpentAt for the total.
+	// It's kept in the JSON, in whole seconds, so that it survives an export and import, but omitted if there's none.
+	TimeSpent Seconds `json:"time_spent_seconds,omitempty" xml:"-"`
+	// Attachments is the URLs attached to the TodoItem with Core.AddAttachment, in the order they were attached. It's omitted if there's none.
+	Attachments []string  `json:"attachments,omitempty" xml:"attachment,omitempty"`
+	CreatedAt   time.Time `json:"created_at" xml:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// Seconds is a time.Duration that's encoded in JSON as a number of whole seconds, the precision the storage keeps.
+type Seconds time.Duration
+
+func (s Seconds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(time.Duration(s).Round(time.Second) / time.Second))
+}
+
+func (s *Seconds) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*s = Seconds(time.Duration(n) * time.Second)
+	return nil
+}
+
+// Palette is the named colors accepted as the Color of a TodoItem besides the hex codes.
+var Palette = []string{"red", "orange", "yellow", "green", "blue", "purple", "pink", "gray"}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validateColor records the color as an invalid field unless it's empty, a hex code of the form "#RRGGBB", or one of the Palette.
+func validateColor(fields fieldErrors, color string) {
+	if color == "" || hexColor.MatchString(color) || slices.Contains(Palette, color) {
+		return
+	}
+	fields["color"] = fmt.Sprintf("%q is neither a hex code like #RRGGBB nor one of %v", color, Palette)
+}
+
+// ItemStatus is where a TodoItem is in its workflow; see TodoItem.Status.
+type ItemStatus string
+
+const (
+	StatusTodo       ItemStatus = "todo"
+	StatusInProgress ItemStatus = "in_progress"
+	StatusCompleted  ItemStatus = "completed"
+)
+
+// ParseItemStatus returns the ItemStatus named by s, or a ValidationError if s names none.
+func ParseItemStatus(s string) (ItemStatus, error) {
+	switch status := ItemStatus(s); status {
+	case StatusTodo, StatusInProgress, StatusCompleted:
+		return status, nil
+	}
+	return "", ValidationError{Message: fmt.Sprintf("unknown status %q, expected %s, %s, or %s", s, StatusTodo, StatusInProgress, StatusCompleted)}
+}
+
+// Status returns StatusCompleted if the TodoItem is completed, StatusInProgress if it's started but not completed, and StatusTodo otherwise.
+func (todo TodoItem) Status() ItemStatus {
+	switch {
+	case todo.Completed:
+		return StatusCompleted
+	case todo.StartedAt != nil:
+		return StatusInProgress
+	}
+	return StatusTodo
+}
+
+// TimeSpentAt returns the total time worked on the TodoItem as of now, i.e., TimeSpent along with the time since StartedAt,
+// which ends at the completion time if the TodoItem is completed.
+func (todo TodoItem) TimeSpentAt(now time.Time) time.Duration {
+	spent := time.Duration(todo.TimeSpent)
+	if todo.StartedAt != nil {
+		end := now
+		if todo.Completed && todo.CompletedAt != nil {
+			end = *todo.CompletedAt
+		}
+		spent += max(end.Sub(*todo.StartedAt), 0)
+	}
+	return spent
+}
+
+// ItemFilter is the criteria of GetItemsFiltered and Query. The criteria that are nil are not applied; the others are all applied together.
+type ItemFilter struct {
+	Completed *bool
+	// CreatedAfter keeps the TodoItems created after the time, exclusively.
+	CreatedAfter *time.Time
+	// CreatedBefore keeps the TodoItems created before the time, inclusively.
+	CreatedBefore *time.Time
+	// ListID keeps the TodoItems in the list.
+	ListID *int
+	// Status keeps the TodoItems in the status. It refines Completed, e.g., StatusTodo and StatusInProgress are both incomplete.
+	Status *ItemStatus
+}
+
+// SortOrder is the order of the TodoItems returned by Query.
+type SortOrder int
+
+const (
+	// SortByID orders the TodoItems by id. This is the default.
+	SortByID SortOrder = iota
+	// SortByPosition orders the TodoItems by the manual order set by Reorder, breaking the ties by id.
+	SortByPosition
+)
+
+// Query combines the criteria, the order, and the window of the TodoItems to get with Core.Query.
+// The window is either offset-based, with Offset, or cursor-based, with After; not both.
+type Query struct {
+	// Filter keeps the TodoItems that meet all its criteria.
+	Filter ItemFilter
+	// Sort is the order of the TodoItems.
+	Sort SortOrder
+	// Descending reverses the order of Sort, including the ties broken by id. It can't be combined with After.
+	Descending bool
+	// Offset is the number of TodoItems to skip.
+	Offset int
+	// After keeps the TodoItems whose id is greater than the cursor. It's only valid with SortByID.
+	After ItemID
+	// Limit is the maximum number of TodoItems to get. There's no limit if it's 0.
+	Limit int
+}
+
+// QueryResult is the TodoItems got with Core.Query.
+type QueryResult struct {
+	Items []TodoItem
+	// Total is the number of TodoItems that meet the filter of the query, regardless of the window.
+	Total int
+	// NextCursor is the id of the last TodoItem, to pass as After to get the next window.
+	// It's 0 if there's no more TodoItems or the TodoItems are not sorted by id.
+	NextCursor ItemID
+}
+
+// SummaryStats is the aggregated statistics of all TodoItems.
+type SummaryStats struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Active    int `json:"active"`
+	// CompletionRate is the fraction of completed TodoItems, ranging from 0 to 1. It's 0 if there are no TodoItems.
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// Snapshot is the entire dataset, used to back up and restore the TodoItems with Export and Import.
+type Snapshot struct {
+	Items []TodoItem `json:"items"`
 }
 
 type TodoItemNotFoundError struct {
-	ID int
+	ID ItemID
 }
 
 func (e TodoItemNotFoundError) Error() string {
 	return fmt.Sprintf("TodoItem with id %d not found", e.ID)
 }
 
-func (c *TheCore) CreateItem(description string) TodoItem {
-	log.WithFields(log.Fields{"description": description}).Info("CORE: Adding new TodoItem.")
-	todo := TodoItem{Description: description, Completed: false}
-	_, err := c.accessor.Create(&todo)
+type ListNotFoundError struct {
+	ID int
+}
+
+func (e ListNotFoundError) Error() string {
+	return fmt.Sprintf("List with id %d not found", e.ID)
+}
+
+// ValidationError is returned if the input of an operation is invalid regardless of the stored TodoItems.
+type ValidationError struct {
+	Message string
+	// Fields maps the names of the invalid fields of the input to why they are invalid. It's empty if the error is not about particular fields.
+	Fields map[string]string
+}
+
+func (e ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	problems := make([]string, len(names))
+	for i, name := range names {
+		problems[i] = name + ": " + e.Fields[name]
+	}
+	return e.Message + " (" + strings.Join(problems, "; ") + ")"
+}
+
+// fieldErrors collects why the fields of an input are invalid, so that all of them are reported at once instead of only the first one.
+type fieldErrors map[string]string
+
+// err returns a ValidationError with the invalid fields, or nil if there's none.
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return ValidationError{Message: "validation failed", Fields: f}
+}
+
+// ConflictError is returned if an operation conflicts with the current state of the stored TodoItems.
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
+// StorageError is returned if the storage accessor fails for reasons other than the ones above, e.g., the database is unreachable.
+type StorageError struct {
+	Err error
+}
+
+func (e StorageError) Error() string {
+	return "storage failure: " + e.Err.Error()
+}
+
+func (e StorageError) Unwrap() error {
+	return e.Err
+}
+
+// wrapStorageError wraps the error of the storage accessor into a StorageError, unless it's already one of the errors above.
+func wrapStorageError(err error) error {
+	if errors.As(err, new(TodoItemNotFoundError)) ||
+		errors.As(err, new(ListNotFoundError)) ||
+		errors.As(err, new(BatchError)) ||
+		errors.As(err, new(ValidationError)) ||
+		errors.As(err, new(ConflictError)) ||
+		errors.As(err, new(StorageError)) {
+		return err
+	}
+	return StorageError{Err: err}
+}
+
+// isConnectionError tells whether the error is caused by a broken connection to the storage backend rather than by the operation itself.
+func isConnectionError(err error) bool {
+	// NOTE: The errors of the contexts are net.Errors as well, but a query cancelled by its timeout doesn't mean the connection is broken.
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, new(net.Error))
+}
+
+// retryOnReconnect calls op and, if it fails with a connection error and the accessor is a Reconnector, reconnects and calls op once more.
+// The error of op is returned as is if the reconnection fails.
+// NOTE: Only the reads are retried, since a write that fails with a broken connection may have taken effect nonetheless.
+func (c *TheCore) retryOnReconnect(op func() error) error {
+	err := op()
+	reconnector, ok := c.accessor.(Reconnector)
+	if err == nil || !ok || !isConnectionError(err) {
+		return err
+	}
+	log.Warn("CORE: Reconnecting to the storage after a connection error. ", err)
+	if rerr := reconnector.Reconnect(); rerr != nil {
+		log.Warn("CORE: ", rerr)
+		return err
+	}
+	return op()
+}
+
+// CreateItem creates a new TodoItem with the description and the color, which may be empty, and returns it.
+// The description is normalized with NormalizeDescription before anything else.
+// A ValidationError listing all the invalid fields is returned if the description is blank or the color is invalid; see Palette.
+// Unless the DuplicatePolicy is AllowDuplicates, an incomplete TodoItem with the same description is returned instead, keeping its color,
+// or a ConflictError is returned if the policy is RejectDuplicates.
+func (c *TheCore) CreateItem(description, color string) (TodoItem, error) {
+	log.WithFields(log.Fields{"description": description, "color": color}).Info("CORE: Adding new TodoItem.")
+	description = NormalizeDescription(description)
+	fields := fieldErrors{}
+	if description == "" {
+		fields["description"] = "required"
+	}
+	validateColor(fields, color)
+	if err := fields.err(); err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, err
+	}
+	todo := TodoItem{Description: description, Completed: false, Color: color}
+	if c.ids != nil {
+		// NOTE: The id is consumed even if the creation fails or an existing TodoItem is returned, which only leaves a gap.
+		todo.ID = c.ids.NextID()
+	}
+	if c.duplicatePolicy == AllowDuplicates {
+		_, err := c.audited().Create(&todo)
+		if err != nil {
+			log.Warn("CORE: ", err)
+			return TodoItem{}, wrapStorageError(err)
+		}
+		c.emitCreated(todo)
+		return todo, nil
+	}
+
+	created, err := c.audited().CreateUnique(&todo)
 	if err != nil {
-		log.Fatal("CORE: ", err)
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
 	}
-	return todo
+	if !created {
+		if c.duplicatePolicy == RejectDuplicates {
+			err := ConflictError{Message: fmt.Sprintf("an incomplete TodoItem with description %q already exists with id %d", todo.Description, todo.ID)}
+			log.Warn("CORE: ", err)
+			return TodoItem{}, err
+		}
+		log.WithFields(log.Fields{"id": todo.ID}).Info("CORE: Returning the existing TodoItem with the same description.")
+		return todo, nil
+	}
+	c.emitCreated(todo)
+	return todo, nil
 }
 
-func (c *TheCore) UpdateItem(id int, completed bool) (TodoItem, error) {
-	todos := c.accessor.Read(func(todo TodoItem) bool {
-		return todo.ID == id
+// CreateItems creates the TodoItems at once, from their descriptions and completed statuses, and returns them; either all of them are created or none is.
+// The descriptions are normalized with NormalizeDescription and the completed TodoItems are completed now.
+// A ValidationError is returned if any of the descriptions is blank.
+// NOTE: The DuplicatePolicy is not applied, since the TodoItems are created in bulk, e.g., by an import.
+func (c *TheCore) CreateItems(items []TodoItem) ([]TodoItem, error) {
+	log.WithFields(log.Fields{"count": len(items)}).Info("CORE: Adding new TodoItems.")
+	if len(items) == 0 {
+		return nil, nil
+	}
+	now := c.clock.Now()
+	todos := make([]TodoItem, 0, len(items))
+	for i, item := range items {
+		description := NormalizeDescription(item.Description)
+		if description == "" {
+			err := ValidationError{Message: fmt.Sprintf("description of item %d is blank", i+1)}
+			log.Warn("CORE: ", err)
+			return nil, err
+		}
+		todo := TodoItem{Description: description}
+		setCompleted(&todo, item.Completed, now)
+		if c.ids != nil {
+			todo.ID = c.ids.NextID()
+		}
+		todos = append(todos, todo)
+	}
+	err := c.audited().CreateAll(todos)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	for _, todo := range todos {
+		c.emitCreated(todo)
+	}
+	return todos, nil
+}
+
+// UpdateItem sets the completed status of the TodoItem with the specified id and returns the updated item.
+// A ConflictError is returned instead of completing the TodoItem if any of its blockers is incomplete; see AddBlockers and ForceUpdateItem.
+// NOTE: The blockers are not locked, so one that is reopened meanwhile doesn't stop the completion.
+func (c *TheCore) UpdateItem(id ItemID, completed bool) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id, "completed": completed}).Info("CORE: Updating TodoItem.")
+	return c.updateItem(id, func(TodoItem) bool { return completed }, completed)
+}
+
+// ForceUpdateItem is UpdateItem that completes the TodoItem even if it's blocked.
+func (c *TheCore) ForceUpdateItem(id ItemID, completed bool) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id, "completed": completed}).Info("CORE: Updating TodoItem regardless of its blockers.")
+	return c.updateItem(id, func(TodoItem) bool { return completed }, false)
+}
+
+// updateItem sets the completed status of the TodoItem with the specified id to the one that completed tells from the TodoItem as stored,
+// and returns the updated item. If checkBlockers, a ConflictError is returned instead of completing the TodoItem if any of its blockers is incomplete.
+func (c *TheCore) updateItem(id ItemID, completed func(TodoItem) bool, checkBlockers bool) (TodoItem, error) {
+	var todo TodoItem
+	var err error
+	if _, ok := c.accessor.(BlockerAccessor); ok && checkBlockers {
+		todo, err = c.updateUnblocked(id, completed)
+	} else {
+		todo, err = c.audited().UpdateWith(id, func(todo *TodoItem) {
+			setCompleted(todo, completed(*todo), c.clock.Now())
+		})
+	}
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	c.emitUpdated(todo)
+	return todo, nil
+}
+
+// updateUnblocked is updateItem that checks the blockers, in a single transaction: the blockers are read through the transaction,
+// and whether the TodoItem is completed is decided on the TodoItem locked by UpdateWith, so that one reopened meanwhile is not completed while blocked.
+// The ConflictError is returned from the transaction, rolling back the update.
+func (c *TheCore) updateUnblocked(id ItemID, completed func(TodoItem) bool) (TodoItem, error) {
+	var todo TodoItem
+	err := c.accessor.Transaction(func(tx StorageAccessor) error {
+		txCore := *c
+		txCore.accessor = tx
+		txCore.sinks = nil
+		blocked := txCore.checkUnblocked(id)
+		if blocked != nil && !errors.As(blocked, new(ConflictError)) {
+			return blocked
+		}
+		var conflict error
+		var err error
+		todo, err = txCore.audited().UpdateWith(id, func(todo *TodoItem) {
+			complete := completed(*todo)
+			if complete && blocked != nil {
+				conflict = blocked
+				return
+			}
+			setCompleted(todo, complete, c.clock.Now())
+		})
+		if err != nil {
+			return err
+		}
+		return conflict
 	})
-	if len(todos) == 0 {
-		err := TodoItemNotFoundError{ID: id}
+	return todo, err
+}
+
+// SetItemColor sets the color of the TodoItem with the specified id and returns the updated item. An empty color removes the label.
+// A ValidationError is returned if the color is invalid; see Palette.
+func (c *TheCore) SetItemColor(id ItemID, color string) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id, "color": color}).Info("CORE: Setting color of TodoItem.")
+	fields := fieldErrors{}
+	validateColor(fields, color)
+	if err := fields.err(); err != nil {
 		log.Warn("CORE: ", err)
 		return TodoItem{}, err
 	}
-	if len(todos) > 1 {
-		log.Fatal("CORE: Multiple TodoItems with the same id.")
+	todo, err := c.audited().UpdateWith(id, func(todo *TodoItem) {
+		todo.Color = color
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
 	}
-	todo := todos[0]
-	todo.Completed = completed
+	c.emitUpdated(todo)
+	return todo, nil
+}
 
-	log.WithFields(log.Fields{"id": id, "completed": completed}).Info("CORE: Updating TodoItem.")
-	err := c.accessor.Update(todo)
+// CopySuffix is appended to the description of the TodoItems cloned by CloneItem.
+const CopySuffix = " (copy)"
+
+// CloneItem creates an incomplete copy of the TodoItem with the specified id, with CopySuffix appended to the description, and returns it.
+// The copy has its own id and timestamps, and keeps the color and the list. A TodoItemNotFoundError is returned if there's no such TodoItem.
+// NOTE: The copy is created regardless of the DuplicatePolicy, since cloning is an explicit request for a duplicate.
+func (c *TheCore) CloneItem(id ItemID) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id}).Info("CORE: Cloning TodoItem.")
+	source, err := c.GetItem(id)
 	if err != nil {
+		return TodoItem{}, err
+	}
+	todo := TodoItem{Description: source.Description + CopySuffix, Completed: false, Color: source.Color, ListID: source.ListID}
+	if c.ids != nil {
+		todo.ID = c.ids.NextID()
+	}
+	_, err = c.audited().Create(&todo)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	c.emitCreated(todo)
+	return todo, nil
+}
+
+// MoveItem moves the TodoItem with the specified id to the List and returns the updated item. A list id of 0 moves it back to the default list.
+// A ValidationError is returned if the list id is negative, or if the accessor is a ListAccessor and there's no such List.
+// NOTE: The List is not locked, so a TodoItem moved while its List is being deleted may be left in a List that no longer exists.
+func (c *TheCore) MoveItem(id ItemID, listID int) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id, "list_id": listID}).Info("CORE: Moving TodoItem to list.")
+	fields := fieldErrors{}
+	if err := c.validateListID(fields, listID); err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	if err := fields.err(); err != nil {
 		log.Warn("CORE: ", err)
 		return TodoItem{}, err
 	}
+	todo, err := c.audited().UpdateWith(id, func(todo *TodoItem) {
+		todo.ListID = listID
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	c.emitUpdated(todo)
+	return todo, nil
+}
+
+// validateListID records the list id as an invalid field if it's negative, or if the accessor is a ListAccessor and there's no such List.
+// The error of reading the List is returned otherwise.
+func (c *TheCore) validateListID(fields fieldErrors, listID int) error {
+	if listID < 0 {
+		fields["list_id"] = fmt.Sprintf("%d is negative", listID)
+		return nil
+	}
+	lists, ok := c.accessor.(ListAccessor)
+	if !ok || listID == 0 {
+		return nil
+	}
+	_, err := lists.ReadList(listID)
+	if errors.As(err, new(ListNotFoundError)) {
+		fields["list_id"] = err.Error()
+		return nil
+	}
+	return err
+}
+
+// UpsertItem sets the description, color, list, and completed status of the TodoItem with the id of the item, and returns the updated item.
+// If there's no such TodoItem, it's created with the id instead, so that a client can refer to it by the id it chose before knowing whether it exists;
+// an item with an id of 0 is always created with a new id. Whether the TodoItem is created is returned as well.
+// The position of an existing TodoItem is kept. Like CreateItem, a ValidationError is returned if any of the fields is invalid,
+// and a ConflictError is returned if the TodoItem with the id is deleted.
+func (c *TheCore) UpsertItem(item TodoItem) (TodoItem, bool, error) {
+	log.WithFields(log.Fields{"id": item.ID, "description": item.Description}).Info("CORE: Upserting TodoItem.")
+	description := NormalizeDescription(item.Description)
+	fields := fieldErrors{}
+	if item.ID < 0 {
+		fields["id"] = fmt.Sprintf("%d is negative", item.ID)
+	}
+	if description == "" {
+		fields["description"] = "required"
+	}
+	validateColor(fields, item.Color)
+	if err := c.validateListID(fields, item.ListID); err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, false, wrapStorageError(err)
+	}
+	if err := fields.err(); err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, false, err
+	}
+	id := item.ID
+	if id == 0 && c.ids != nil {
+		id = c.ids.NextID()
+	}
+	now := c.clock.Now()
+	todo, created, err := c.audited().Upsert(id, func(todo *TodoItem) {
+		todo.Description = description
+		todo.Color = item.Color
+		todo.ListID = item.ListID
+		setCompleted(todo, item.Completed, now)
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, false, wrapStorageError(err)
+	}
+	if created {
+		c.emitCreated(todo)
+	} else {
+		c.emitUpdated(todo)
+	}
+	return todo, created, nil
+}
+
+// ToggleItem flips the completed status of the TodoItem with the specified id and returns the updated item.
+// Like UpdateItem, a ConflictError is returned instead of completing the TodoItem if any of its blockers is incomplete.
+func (c *TheCore) ToggleItem(id ItemID) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id}).Info("CORE: Toggling TodoItem.")
+	// NOTE: A completed TodoItem can still be reopened while its blockers are incomplete.
+	return c.updateItem(id, func(todo TodoItem) bool { return !todo.Completed }, true)
+}
+
+// StartItem marks the TodoItem with the specified id as in progress, recording now as when it's started, and returns the updated item.
+// Starting a TodoItem that is already in progress keeps when it's started. A ConflictError is returned if the TodoItem is completed.
+func (c *TheCore) StartItem(id ItemID) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id}).Info("CORE: Starting TodoItem.")
+	return c.setStarted(id, true)
+}
+
+// StopItem marks the TodoItem with the specified id as not in progress, adding the time since it's started to its TimeSpent, and returns the updated item.
+// Stopping a TodoItem that is not in progress does nothing. A ConflictError is returned if the TodoItem is completed.
+func (c *TheCore) StopItem(id ItemID) (TodoItem, error) {
+	log.WithFields(log.Fields{"id": id}).Info("CORE: Stopping TodoItem.")
+	return c.setStarted(id, false)
+}
+
+// setStarted starts or stops the TodoItem with the specified id; see StartItem and StopItem.
+func (c *TheCore) setStarted(id ItemID, started bool) (TodoItem, error) {
+	now := c.clock.Now()
+	var todo TodoItem
+	err := c.accessor.Transaction(func(tx StorageAccessor) error {
+		txCore := *c
+		txCore.accessor = tx
+		txCore.sinks = nil
+		// NOTE: The completed status is checked on the TodoItem locked by UpdateWith, so that one completed meanwhile is not started or stopped;
+		// the update, if any, is then rolled back along with the transaction.
+		var conflict error
+		var err error
+		todo, err = txCore.audited().UpdateWith(id, func(todo *TodoItem) {
+			switch {
+			case todo.Completed:
+				conflict = ConflictError{Message: fmt.Sprintf("TodoItem with id %d is completed", id)}
+			case !started:
+				todo.TimeSpent = Seconds(todo.TimeSpentAt(now))
+				todo.StartedAt = nil
+			case todo.StartedAt == nil:
+				todo.StartedAt = &now
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return conflict
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	c.emitUpdated(todo)
 	return todo, nil
 }
 
-func (c *TheCore) DeleteItem(id int) error {
+// TimeSpent is how long a TodoItem has been worked on; see Core.GetTimeSpent.
+type TimeSpent struct {
+	Total time.Duration
+	// Running tells whether the TodoItem is in progress, i.e., Total is still growing.
+	Running bool
+}
+
+// GetTimeSpent returns the total time worked on the TodoItem with the specified id, including the time since it's started if it's in progress,
+// or a TodoItemNotFoundError if there's no such item.
+func (c *TheCore) GetTimeSpent(id ItemID) (TimeSpent, error) {
+	log.WithFields(log.Fields{"id": id}).Info("CORE: Getting time spent on TodoItem.")
+	todo, err := c.GetItem(id)
+	if err != nil {
+		return TimeSpent{}, err
+	}
+	return TimeSpent{Total: todo.TimeSpentAt(c.clock.Now()), Running: todo.Status() == StatusInProgress}, nil
+}
+
+// SetItemsCompleted sets the completed status of the TodoItems with the specified ids and returns the number of TodoItems whose status is changed.
+// Ids that don't exist are ignored. A ValidationError is returned if there are more than MaxBatchIDs ids.
+func (c *TheCore) SetItemsCompleted(ids []ItemID, completed bool) (int, error) {
+	log.WithFields(log.Fields{"ids": ids, "completed": completed}).Info("CORE: Updating TodoItems.")
+	if len(ids) > MaxBatchIDs {
+		err := ValidationError{Message: fmt.Sprintf("at most %d ids can be updated at once", MaxBatchIDs)}
+		log.Warn("CORE: ", err)
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	n, err := c.audited().UpdateCompleted(ids, completed, c.clock.Now())
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return 0, wrapStorageError(err)
+	}
+	return n, nil
+}
+
+// DeleteItem deletes the TodoItem. Deleting an item that does not exist, or has already been deleted, succeeds, so that a retried delete is safe.
+func (c *TheCore) DeleteItem(id ItemID) error {
 	log.WithFields(log.Fields{"id": id}).Info("CORE: Deleting TodoItem.")
-	err := c.accessor.Delete(id)
+	err := c.audited().Delete(id)
+	if errors.As(err, &TodoItemNotFoundError{}) {
+		log.WithFields(log.Fields{"id": id}).Info("CORE: TodoItem already deleted.")
+		return nil
+	}
 	if err != nil {
 		log.Warn("CORE: ", err)
-		return err
+		return wrapStorageError(err)
+	}
+	c.emitDeleted(id)
+	return nil
+}
+
+// DeleteCompleted deletes all the completed TodoItems and returns them as they were, so that they can be brought back with RestoreItems.
+// As a safety valve, if more than maxFraction of all TodoItems would be deleted, nothing is deleted and a ConflictError is returned;
+// a maxFraction of 1 or more means no limit. The TodoItems are counted and deleted in one transaction.
+func (c *TheCore) DeleteCompleted(maxFraction float64) ([]TodoItem, error) {
+	log.WithFields(log.Fields{"max_fraction": maxFraction}).Info("CORE: Deleting completed TodoItems.")
+	var todos []TodoItem
+	var conflict error
+	err := c.accessor.Transaction(func(tx StorageAccessor) error {
+		txCore := *c
+		txCore.accessor = tx
+		txCore.sinks = nil
+		total, err := tx.Count(ItemFilter{})
+		if err != nil {
+			return err
+		}
+		todos, err = txCore.audited().DeleteCompleted()
+		if err != nil {
+			return err
+		}
+		if maxFraction < 1 && total > 0 && float64(len(todos))/float64(total) > maxFraction {
+			// Rolls back the deletion.
+			conflict = ConflictError{Message: "mass deletion requires confirm"}
+			return conflict
+		}
+		return nil
+	})
+	if conflict != nil {
+		log.Warn("CORE: ", conflict)
+		return nil, conflict
+	}
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	for _, todo := range todos {
+		c.emitDeleted(todo.ID)
+	}
+	return todos, nil
+}
+
+// PruneCompletedOlderThan deletes the TodoItems completed longer than d ago and returns the number of deleted TodoItems.
+// A ValidationError is returned if d is not positive.
+func (c *TheCore) PruneCompletedOlderThan(d time.Duration) (int, error) {
+	if d <= 0 {
+		err := ValidationError{Message: fmt.Sprintf("retention %v is not positive", d)}
+		log.Warn("CORE: ", err)
+		return 0, err
+	}
+	before := c.clock.Now().Add(-d)
+	log.WithFields(log.Fields{"before": before}).Info("CORE: Pruning completed TodoItems.")
+	n, err := c.audited().DeleteCompletedBefore(before)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return 0, wrapStorageError(err)
+	}
+	return n, nil
+}
+
+// RestoreItems brings back the TodoItems deleted by DeleteCompleted, or DeleteItem, from their snapshots and returns the restored TodoItems.
+// A TodoItem keeps its id if it's still kept as deleted by the storage, and is re-created with a new id otherwise.
+// Either all of them are restored or none is; a ConflictError is returned if any of the ids belongs to a TodoItem that is not deleted.
+func (c *TheCore) RestoreItems(items []TodoItem) ([]TodoItem, error) {
+	log.WithFields(log.Fields{"count": len(items)}).Info("CORE: Restoring TodoItems.")
+	if len(items) == 0 {
+		return nil, nil
+	}
+	todos, err := c.audited().Restore(items)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	for _, todo := range todos {
+		c.emitCreated(todo)
+	}
+	return todos, nil
+}
+
+// Reorder places the TodoItems in the order of ids, i.e., the position of each TodoItem is set to its index in ids.
+// If any of the ids doesn't exist, no position is changed. A ValidationError is returned if an id appears more than once.
+func (c *TheCore) Reorder(ids []ItemID) error {
+	log.WithFields(log.Fields{"ids": ids}).Info("CORE: Reordering TodoItems.")
+	seen := make(map[ItemID]bool)
+	for _, id := range ids {
+		if seen[id] {
+			err := ValidationError{Message: fmt.Sprintf("id %d appears more than once", id)}
+			log.Warn("CORE: ", err)
+			return err
+		}
+		seen[id] = true
+	}
+	err := c.audited().Reorder(ids)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return wrapStorageError(err)
 	}
 	return nil
 }
@@ -88,5 +936,401 @@ func (c *TheCore) GetItems(completed bool) []TodoItem {
 	todos := c.accessor.Read(func(todo TodoItem) bool {
 		return todo.Completed == completed
 	})
+	if err := c.withAttachments(todos); err != nil {
+		log.Warn("CORE: ", err)
+	}
 	return todos
 }
+
+// ExistsItem tells whether there's a TodoItem with the specified id, which is cheaper than GetItem as the TodoItem is not read.
+func (c *TheCore) ExistsItem(id ItemID) (bool, error) {
+	log.WithFields(log.Fields{"id": id}).Info("CORE: Checking existence of TodoItem.")
+	var exists bool
+	err := c.retryOnReconnect(func() (err error) {
+		exists, err = c.accessor.Exists(id)
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return false, wrapStorageError(err)
+	}
+	return exists, nil
+}
+
+// GetItemsByIDs returns the TodoItems with the specified ids. Duplicated ids are only fetched once and ids that don't exist are ignored.
+// At most MaxBatchIDs distinct ids are fetched; the rest are dropped.
+func (c *TheCore) GetItemsByIDs(ids []ItemID) []TodoItem {
+	seen := make(map[ItemID]bool)
+	var uniqueIDs []ItemID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+	if len(uniqueIDs) > MaxBatchIDs {
+		log.Warn("CORE: Too many ids requested, only the first ", MaxBatchIDs, " are fetched.")
+		uniqueIDs = uniqueIDs[:MaxBatchIDs]
+	}
+	if len(uniqueIDs) == 0 {
+		return nil
+	}
+	log.Info("CORE: Getting TodoItems by ids. ids=", uniqueIDs)
+	todos := c.accessor.ReadByIDs(uniqueIDs)
+	if err := c.withAttachments(todos); err != nil {
+		log.Warn("CORE: ", err)
+	}
+	return todos
+}
+
+// GetItemsFiltered returns the TodoItems that meet all the criteria of the filter, ordered by id.
+// A ValidationError is returned if the creation times of the filter make an inverted range, i.e., CreatedAfter is later than CreatedBefore,
+// or if the status of the filter is unknown.
+func (c *TheCore) GetItemsFiltered(f ItemFilter) ([]TodoItem, error) {
+	log.WithFields(log.Fields{"completed": f.Completed, "created_after": f.CreatedAfter, "created_before": f.CreatedBefore, "list_id": f.ListID, "status": f.Status}).Info("CORE: Getting filtered TodoItems.")
+	if err := validateFilter(f); err != nil {
+		log.Warn("CORE: ", err)
+		return nil, err
+	}
+	var todos []TodoItem
+	err := c.retryOnReconnect(func() (err error) {
+		todos, err = c.accessor.ReadFiltered(f)
+		return err
+	})
+	if err == nil {
+		err = c.withAttachments(todos)
+	}
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	return todos, nil
+}
+
+// GetItemsByStatus returns the TodoItems in the status, ordered by id. A ValidationError is returned if the status is unknown.
+func (c *TheCore) GetItemsByStatus(status ItemStatus) ([]TodoItem, error) {
+	return c.GetItemsFiltered(ItemFilter{Status: &status})
+}
+
+// validateFilter returns a ValidationError if the creation times of the filter make an inverted range.
+func validateFilter(f ItemFilter) error {
+	if f.Status != nil {
+		if _, err := ParseItemStatus(string(*f.Status)); err != nil {
+			return err
+		}
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && f.CreatedAfter.After(*f.CreatedBefore) {
+		return ValidationError{Message: fmt.Sprintf("created_after %v is later than created_before %v", f.CreatedAfter.Format(time.RFC3339), f.CreatedBefore.Format(time.RFC3339))}
+	}
+	return nil
+}
+
+// Query returns the TodoItems that meet the filter of the query, in its order, within its window.
+// It's the single way to combine the filtering, the sorting, and the pagination, which the other getters only do one at a time.
+// A ValidationError is returned if the query is invalid, i.e., the offset, the cursor, or the limit is negative,
+// both the offset and the cursor are given, the cursor is given with an order other than SortByID, or the filter is invalid (see GetItemsFiltered).
+func (c *TheCore) Query(q Query) (QueryResult, error) {
+	log.WithFields(log.Fields{"filter": q.Filter, "sort": q.Sort, "descending": q.Descending, "offset": q.Offset, "after": q.After, "limit": q.Limit}).Info("CORE: Querying TodoItems.")
+	var err error
+	switch {
+	case q.Offset < 0:
+		err = ValidationError{Message: fmt.Sprintf("offset %d is negative", q.Offset)}
+	case q.After < 0:
+		err = ValidationError{Message: fmt.Sprintf("cursor %d is negative", q.After)}
+	case q.Limit < 0:
+		err = ValidationError{Message: fmt.Sprintf("limit %d is negative", q.Limit)}
+	case q.Offset > 0 && q.After > 0:
+		err = ValidationError{Message: "offset and cursor can't be combined"}
+	case q.After > 0 && q.Sort != SortByID:
+		err = ValidationError{Message: "cursor requires sorting by id"}
+	case q.After > 0 && q.Descending:
+		err = ValidationError{Message: "cursor requires the ascending order"}
+	default:
+		err = validateFilter(q.Filter)
+	}
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return QueryResult{}, err
+	}
+
+	window := q
+	if q.Limit > 0 {
+		// Read one more item to tell whether there's a next window.
+		window.Limit = q.Limit + 1
+	}
+	var todos []TodoItem
+	var total int
+	err = c.retryOnReconnect(func() (err error) {
+		todos, total, err = c.accessor.ReadQuery(window)
+		return err
+	})
+	if err == nil {
+		err = c.withAttachments(todos)
+	}
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return QueryResult{}, wrapStorageError(err)
+	}
+	result := QueryResult{Items: todos, Total: total}
+	if q.Limit > 0 && len(todos) > q.Limit {
+		result.Items = todos[:q.Limit]
+		if q.Sort == SortByID {
+			result.NextCursor = result.Items[q.Limit-1].ID
+		}
+	}
+	return result, nil
+}
+
+// GetItemsCompletedBetween returns the TodoItems completed within [start, end).
+func (c *TheCore) GetItemsCompletedBetween(start, end time.Time) []TodoItem {
+	log.WithFields(log.Fields{"start": start, "end": end}).Info("CORE: Getting TodoItems completed between.")
+	return c.accessor.ReadCompletedBetween(start, end)
+}
+
+// GetItemsCompletedOn returns the TodoItems completed on the day of the date, which runs from its midnight to the next one in the location of the date.
+// The time of day of the date doesn't matter.
+func (c *TheCore) GetItemsCompletedOn(date time.Time) []TodoItem {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	// NOTE: Not adding 24 hours, since a day can be shorter or longer on the transitions of daylight saving time.
+	end := start.AddDate(0, 0, 1)
+	return c.GetItemsCompletedBetween(start, end)
+}
+
+// GetChangesSince returns the TodoItems created or updated after since, and the ids of the TodoItems deleted after since.
+// This allows the clients to synchronize their local copies without fetching all TodoItems again.
+func (c *TheCore) GetChangesSince(since time.Time) (changed []TodoItem, deletedIDs []ItemID, e error) {
+	log.WithFields(log.Fields{"since": since}).Info("CORE: Getting changes since.")
+	err := c.retryOnReconnect(func() (err error) {
+		changed, deletedIDs, err = c.accessor.ReadChangedSince(since)
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, nil, wrapStorageError(err)
+	}
+	return changed, deletedIDs, nil
+}
+
+// GetItemsAfter returns at most limit TodoItems whose id is greater than cursor, ordered by id.
+// The id of the last item is returned as the cursor of the next page; it's 0 if this is the last page.
+// If limit is not positive, DefaultPageSize is used.
+func (c *TheCore) GetItemsAfter(cursor ItemID, limit int) (todos []TodoItem, nextCursor ItemID) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	log.WithFields(log.Fields{"cursor": cursor, "limit": limit}).Info("CORE: Getting TodoItems after cursor.")
+	// Read one more item to tell whether there's a next page.
+	todos = c.accessor.ReadAfter(cursor, limit+1)
+	if len(todos) > limit {
+		todos = todos[:limit]
+		nextCursor = todos[limit-1].ID
+	}
+	return todos, nextCursor
+}
+
+// GetItemsPage returns at most limit TodoItems ordered by id, skipping the first offset ones, and the total number of TodoItems.
+// If limit is not positive, DefaultPageSize is used. A ValidationError is returned if offset is negative.
+func (c *TheCore) GetItemsPage(offset, limit int) (todos []TodoItem, total int, e error) {
+	if offset < 0 {
+		err := ValidationError{Message: fmt.Sprintf("offset %d is negative", offset)}
+		log.Warn("CORE: ", err)
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	log.WithFields(log.Fields{"offset": offset, "limit": limit}).Info("CORE: Getting a page of TodoItems.")
+	err := c.retryOnReconnect(func() (err error) {
+		todos, total, err = c.accessor.ReadPage(offset, limit)
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, 0, wrapStorageError(err)
+	}
+	return todos, total, nil
+}
+
+// SuggestDescriptions returns at most limit distinct descriptions that start with the prefix, case-insensitively, with the most recently created ones first.
+// The limit is capped at MaxSuggestions; a non-positive limit also means MaxSuggestions.
+func (c *TheCore) SuggestDescriptions(prefix string, limit int) []string {
+	if limit <= 0 || limit > MaxSuggestions {
+		limit = MaxSuggestions
+	}
+	log.WithFields(log.Fields{"prefix": prefix, "limit": limit}).Info("CORE: Suggesting descriptions.")
+	return c.accessor.ReadDescriptionsWithPrefix(prefix, limit)
+}
+
+// Summary returns the aggregated statistics of all TodoItems.
+func (c *TheCore) Summary() (SummaryStats, error) {
+	log.Info("CORE: Summarizing TodoItems.")
+	var total, completed int
+	err := c.retryOnReconnect(func() (err error) {
+		if total, err = c.accessor.Count(ItemFilter{}); err != nil {
+			return err
+		}
+		done := true
+		completed, err = c.accessor.Count(ItemFilter{Completed: &done})
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return SummaryStats{}, wrapStorageError(err)
+	}
+	stats := SummaryStats{Total: total, Completed: completed, Active: total - completed}
+	if stats.Total > 0 {
+		stats.CompletionRate = float64(completed) / float64(stats.Total)
+	}
+	return stats, nil
+}
+
+// Export returns all the TodoItems, ordered by id.
+func (c *TheCore) Export() (Snapshot, error) {
+	log.Info("CORE: Exporting TodoItems.")
+	var todos []TodoItem
+	err := c.retryOnReconnect(func() (err error) {
+		todos, err = c.accessor.ReadAll()
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return Snapshot{}, wrapStorageError(err)
+	}
+	if todos == nil {
+		todos = []TodoItem{}
+	}
+	return Snapshot{Items: todos}, nil
+}
+
+// ExportStream is Export that calls fn with the TodoItems one at a time instead of returning them all at once, so that the export of many TodoItems doesn't take much memory.
+// It stops at the first error returned by fn.
+func (c *TheCore) ExportStream(fn func(TodoItem) error) error {
+	log.Info("CORE: Streaming the export of TodoItems.")
+	err := c.accessor.ReadStream(func(TodoItem) bool { return true }, fn)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return wrapStorageError(err)
+	}
+	return nil
+}
+
+// Import replaces all the TodoItems with the ones in the snapshot, keeping their ids, and returns the number of imported TodoItems.
+// Every TodoItem is validated before the storage is touched; a ValidationError is returned if any of them is invalid,
+// i.e., its id is not positive or appears more than once, it has a completion time but is not completed, or its time spent is negative.
+func (c *TheCore) Import(snapshot Snapshot) (int, error) {
+	log.WithFields(log.Fields{"count": len(snapshot.Items)}).Info("CORE: Importing TodoItems.")
+	seen := make(map[ItemID]bool)
+	for _, todo := range snapshot.Items {
+		var err error
+		switch {
+		case todo.ID <= 0:
+			err = ValidationError{Message: fmt.Sprintf("id %d is not positive", todo.ID)}
+		case seen[todo.ID]:
+			err = ValidationError{Message: fmt.Sprintf("id %d appears more than once", todo.ID)}
+		case !todo.Completed && todo.CompletedAt != nil:
+			err = ValidationError{Message: fmt.Sprintf("TodoItem with id %d has a completion time but is not completed", todo.ID)}
+		case todo.TimeSpent < 0:
+			err = ValidationError{Message: fmt.Sprintf("TodoItem with id %d has a negative time spent", todo.ID)}
+		}
+		if err != nil {
+			log.Warn("CORE: ", err)
+			return 0, err
+		}
+		seen[todo.ID] = true
+	}
+	err := c.accessor.ReplaceAll(snapshot.Items)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return 0, wrapStorageError(err)
+	}
+	return len(snapshot.Items), nil
+}
+
+// Reset permanently deletes all the TodoItems and starts the ids over. It's meant for development and testing only.
+func (c *TheCore) Reset() error {
+	log.Warn("CORE: Resetting all TodoItems.")
+	err := c.accessor.Reset()
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return wrapStorageError(err)
+	}
+	return nil
+}
+
+// Reindex repairs the fields of the TodoItems derived from the others and reports what's fixed.
+func (c *TheCore) Reindex() (ReindexReport, error) {
+	log.Warn("CORE: Reindexing TodoItems.")
+	report, err := c.accessor.Reindex()
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return ReindexReport{}, wrapStorageError(err)
+	}
+	return report, nil
+}
+
+// StorageStats returns the statistics of the storage backend in use.
+func (c *TheCore) StorageStats() (BackendStats, error) {
+	var stats BackendStats
+	err := c.retryOnReconnect(func() (err error) {
+		stats, err = c.accessor.Stats()
+		return err
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return BackendStats{}, wrapStorageError(err)
+	}
+	return stats, nil
+}
+
+func (c *TheCore) emitCreated(todo TodoItem) {
+	for _, sink := range c.sinks {
+		sink.ItemCreated(todo)
+	}
+}
+
+func (c *TheCore) emitUpdated(todo TodoItem) {
+	for _, sink := range c.sinks {
+		sink.ItemUpdated(todo)
+	}
+}
+
+func (c *TheCore) emitDeleted(id ItemID) {
+	for _, sink := range c.sinks {
+		sink.ItemDeleted(id)
+	}
+}
+
+// setCompleted sets the completed status of the TodoItem. The completion time, now, is recorded when the TodoItem becomes completed and cleared when it becomes incomplete.
+// A started TodoItem that is reopened resumes the work from now, with the work until its completion added to its TimeSpent.
+func setCompleted(todo *TodoItem, completed bool, now time.Time) {
+	if completed && !todo.Completed {
+		todo.CompletedAt = &now
+	} else if !completed {
+		if todo.Completed && todo.StartedAt != nil {
+			todo.TimeSpent = Seconds(todo.TimeSpentAt(now))
+			todo.StartedAt = &now
+		}
+		todo.CompletedAt = nil
+	}
+	todo.Completed = completed
+}
+
+// GetItem returns the TodoItem with the specified id, or a TodoItemNotFoundError if there's no such item.
+func (c *TheCore) GetItem(id ItemID) (TodoItem, error) {
+	todos := c.accessor.Read(func(todo TodoItem) bool {
+		return todo.ID == id
+	})
+	if len(todos) == 0 {
+		err := TodoItemNotFoundError{ID: id}
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	if len(todos) > 1 {
+		log.Fatal("CORE: Multiple TodoItems with the same id.")
+	}
+	if err := c.withAttachments(todos); err != nil {
+		log.Warn("CORE: ", err)
+		return TodoItem{}, wrapStorageError(err)
+	}
+	return todos[0], nil
+}
@@ -1,10 +1,14 @@
 package core_test
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	core "todolist/core"
 
@@ -35,6 +39,16 @@ func newTestEnv(t *testing.T) *testEnv {
 	return &testEnv{t, ctrl, mockAccessor, theCore}
 }
 
+// expectUpdateWith Expects UpdateWith to be called on the item, applying the modification to the stored item in place as the storage accessor would.
+func (e *testEnv) expectUpdateWith(stored *core.TodoItem) *gomock.Call {
+	return e.mockAccessor.EXPECT().
+		UpdateWith(stored.ID, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(stored)
+			return *stored, nil
+		})
+}
+
 // TestCreateItem Given a description and the storage accessor returns an id, when CreateItem is called, then the item is created and returned with the id set.
 func TestCreateItem(t *testing.T) {
 	// arrange
@@ -49,109 +63,1964 @@ func TestCreateItem(t *testing.T) {
 
 	// act
 	want := core.TodoItem{ID: 1, Description: "some description", Completed: false}
-	got := e.core.CreateItem(want.Description)
+	got, err := e.core.CreateItem(want.Description, "")
 
 	// assert
-	assert.Equal(t, want, got)
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
 }
 
-// TestUpdateItem Given an item of a specific id is returned by the storage accessor, when UpdateItem is called, then the item is updated and returned with the new completed status.
-func TestUpdateItem(t *testing.T) {
+// TestCreateItemError Given the storage accessor fails to create the item, when CreateItem is called, then a StorageError wrapping the error is returned.
+func TestCreateItemError(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
+	storageErr := errors.New("error")
 	e.mockAccessor.EXPECT().
-		Read(gomock.Any()).
-		DoAndReturn(func(func(core.TodoItem) bool) []core.TodoItem {
-			return []core.TodoItem{
-				{ID: 1, Description: "some description", Completed: false},
-			}
-		})
+		Create(gomock.Any()).
+		Return(0, storageErr)
+
+	// act
+	_, err := e.core.CreateItem("some description", "")
+
+	// assert
+	assert.IsType(t, core.StorageError{}, err)
+	assert.ErrorIs(t, err, storageErr)
+}
+
+// TestCreateItemReturnExisting Given the ReturnExisting policy and the storage accessor finds an existing item with the same description, when CreateItem is called, then the existing item is returned.
+func TestCreateItemReturnExisting(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.core.SetDuplicatePolicy(core.ReturnExisting)
+	existing := core.TodoItem{ID: 1, Description: "Some description", Completed: false}
 	e.mockAccessor.EXPECT().
-		Update(gomock.Any()).
-		Return(nil)
+		CreateUnique(gomock.Any()).
+		DoAndReturn(func(item *core.TodoItem) (bool, error) {
+			*item = existing
+			return false, nil
+		})
 
 	// act
-	want := core.TodoItem{ID: 1, Description: "some description", Completed: true}
-	got, err := e.core.UpdateItem(want.ID, want.Completed)
+	got, err := e.core.CreateItem("some description", "")
 
-	// assert: the item should be updated and returned without error
+	// assert
 	if assert.NoError(t, err) {
-		assert.Equal(t, want, got)
+		assert.Equal(t, existing, got)
 	}
 }
 
-// TestUpdateItemNotFound Given an item of a specific id is not returned by the storage accessor, when UpdateItem is called, then an ItemNotFoundError is returned.
-func TestUpdateItemNotFound(t *testing.T) {
+// TestCreateItemRejectDuplicates Given the RejectDuplicates policy and the storage accessor finds an existing item with the same description, when CreateItem is called, then a ConflictError is returned.
+func TestCreateItemRejectDuplicates(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
+	e.core.SetDuplicatePolicy(core.RejectDuplicates)
 	e.mockAccessor.EXPECT().
-		Read(gomock.Any()).
-		DoAndReturn(func(func(core.TodoItem) bool) []core.TodoItem {
-			return []core.TodoItem{}
+		CreateUnique(gomock.Any()).
+		DoAndReturn(func(item *core.TodoItem) (bool, error) {
+			*item = core.TodoItem{ID: 1, Description: "Some description", Completed: false}
+			return false, nil
 		})
 
 	// act
-	id := 1
-	completed := true
-	_, err := e.core.UpdateItem(id, completed)
+	_, err := e.core.CreateItem("some description", "")
 
-	// assert: an error should be returned
-	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+	// assert
+	assert.IsType(t, core.ConflictError{}, err)
 }
 
-// TestDeleteItem Given an id and the storage accessor returns no error, when DeleteItem is called, then no error is returned.
-func TestDeleteItem(t *testing.T) {
+// TestCreateItemRejectDuplicatesNoDuplicate Given the RejectDuplicates policy and the storage accessor creates the item, when CreateItem is called, then the created item is returned.
+func TestCreateItemRejectDuplicatesNoDuplicate(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
+	e.core.SetDuplicatePolicy(core.RejectDuplicates)
 	e.mockAccessor.EXPECT().
-		Delete(gomock.Any()).
-		Return(nil)
+		CreateUnique(gomock.Any()).
+		DoAndReturn(func(item *core.TodoItem) (bool, error) {
+			item.ID = 1
+			return true, nil
+		})
 
 	// act
-	id := 1
-	err := e.core.DeleteItem(id)
+	want := core.TodoItem{ID: 1, Description: "some description", Completed: false}
+	got, err := e.core.CreateItem(want.Description, "")
 
 	// assert
-	assert.NoError(t, err)
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
 }
 
-// TestDeleteItemError Given an id and the storage accessor returns an error, when DeleteItem is called, then the error is returned.
-func TestDeleteItemError(t *testing.T) {
+// TestCreateItemColor Given a color, when CreateItem is called, then the item is created with the color if it's a hex code or a named color, or a ValidationError is returned without touching the storage otherwise.
+func TestCreateItemColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		color   string
+		wantErr bool
+	}{
+		{"none", "", false},
+		{"hex", "#1E90ff", false},
+		{"named", "purple", false},
+		{"short hex", "#fff", true},
+		{"not hex", "#12345g", true},
+		{"unknown name", "chartreuse", true},
+		{"named in upper case", "Red", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			if !tt.wantErr {
+				e.mockAccessor.EXPECT().
+					Create(&core.TodoItem{Description: "some description", Color: tt.color}).
+					Return(1, nil)
+			}
+
+			// act
+			got, err := e.core.CreateItem("some description", tt.color)
+
+			// assert
+			if tt.wantErr {
+				assert.IsType(t, core.ValidationError{}, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.color, got.Color)
+			}
+		})
+	}
+}
+
+// TestNormalizeDescription Given descriptions with messy whitespace, control characters, emoji, or decomposed characters, when NormalizeDescription is called,
+// then the whitespace should be collapsed and trimmed, the control characters removed, the emoji kept, and the characters composed.
+func TestNormalizeDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"tabs", "\tbuy\tmilk\t", "buy milk"},
+		{"double spaces", "buy  milk   today", "buy milk today"},
+		{"line breaks", "buy\r\nmilk", "buy milk"},
+		{"control character", "buy\x00 milk\x1b\x7f", "buy milk"},
+		{"emoji", "  ☕ coffee  with 👩‍💻 ", "☕ coffee with 👩‍💻"},
+		{"decomposed", "cafe\u0301", "caf\u00e9"},
+		{"blank", " \t\x07 ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got := core.NormalizeDescription(tt.s)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestCreateItemNormalizesDescription Given a description with messy whitespace, when CreateItem is called, then the normalized description should be stored.
+func TestCreateItemNormalizesDescription(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
 	e.mockAccessor.EXPECT().
-		Delete(gomock.Any()).
-		Return(errors.New("error"))
+		Create(&core.TodoItem{Description: "some description"}).
+		Return(1, nil)
 
 	// act
-	id := 1
-	err := e.core.DeleteItem(id)
+	got, err := e.core.CreateItem("  some\t\tdescription ", "")
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "some description", got.Description)
+	}
+}
+
+// TestCreateItems Given some items, one of which is completed, when CreateItems is called, then all of them are created at once with the normalized descriptions,
+// the completed one is completed now, and the sink is notified of each of them.
+func TestCreateItems(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	e.core.SetClock(core.NewFakeClock(now))
+	want := []core.TodoItem{
+		{ID: 1, Description: "Buy milk"},
+		{ID: 2, Description: "Call mom", Completed: true, CompletedAt: &now},
+	}
+	e.mockAccessor.EXPECT().
+		CreateAll(gomock.Any()).
+		DoAndReturn(func(todos []core.TodoItem) error {
+			for i := range todos {
+				todos[i].ID = i + 1
+			}
+			return nil
+		})
+
+	// act
+	got, err := e.core.CreateItems([]core.TodoItem{
+		{Description: " Buy  milk "},
+		{Description: "Call mom", Completed: true},
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+		assert.Equal(t, want, sink.created)
+	}
+}
+
+// TestCreateItemsBlank Given some items, one of which has a blank description, when CreateItems is called, then a ValidationError is returned without creating any of them.
+func TestCreateItemsBlank(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.CreateItems([]core.TodoItem{
+		{Description: "Buy milk"},
+		{Description: " \t "},
+	})
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
+
+// TestCreateItemInvalidFields Given a blank description and an invalid color, when CreateItem is called,
+// then a ValidationError should be returned with both fields, and nothing should be created.
+func TestCreateItemInvalidFields(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.CreateItem("  ", "teal")
+
+	// assert
+	var validationErr core.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "required", validationErr.Fields["description"])
+		assert.Contains(t, validationErr.Fields["color"], `"teal"`)
+		assert.Len(t, validationErr.Fields, 2)
+		assert.Regexp(t, `^validation failed \(color: .*; description: required\)$`, err.Error())
+	}
+}
+
+// TestSetItemColor Given an item of a specific id is stored, when SetItemColor is called, then the color of the item is replaced.
+func TestSetItemColor(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	stored := core.TodoItem{ID: 1, Description: "some description", Color: "red"}
+	e.expectUpdateWith(&stored)
+
+	// act
+	got, err := e.core.SetItemColor(stored.ID, "#00ff00")
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.TodoItem{ID: 1, Description: "some description", Color: "#00ff00"}, got)
+	}
+}
+
+// TestSetItemColorInvalid Given an invalid color, when SetItemColor is called, then a ValidationError is returned without touching the storage.
+func TestSetItemColorInvalid(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.SetItemColor(1, "rgb(0, 0, 0)")
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
+
+// expectUpsert Expects Upsert to be called with the id, applying the modification to the stored item as the storage accessor would, or to an empty item if stored is nil.
+func (e *testEnv) expectUpsert(id core.ItemID, stored *core.TodoItem) *gomock.Call {
+	return e.mockAccessor.EXPECT().
+		Upsert(id, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, bool, error) {
+			if stored == nil {
+				todo := core.TodoItem{ID: id}
+				modify(&todo)
+				return todo, true, nil
+			}
+			modify(stored)
+			return *stored, false, nil
+		})
+}
+
+// TestUpsertItemCreates Given no item with the id, when UpsertItem is called, then the item is created with the id and the sink is notified of the creation.
+func TestUpsertItemCreates(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	e.core.SetClock(core.NewFakeClock(now))
+	e.expectUpsert(5, nil)
+
+	// act
+	got, created, err := e.core.UpsertItem(core.TodoItem{ID: 5, Description: " Buy  milk ", Completed: true, Color: "red"})
+
+	// assert
+	if assert.NoError(t, err) {
+		want := core.TodoItem{ID: 5, Description: "Buy milk", Completed: true, Color: "red", CompletedAt: &now}
+		assert.True(t, created)
+		assert.Equal(t, want, got)
+		assert.Equal(t, []core.TodoItem{want}, sink.created)
+		assert.Empty(t, sink.updated)
+	}
+}
+
+// TestUpsertItemUpdates Given an item, when UpsertItem is called with its id, then all the fields but the position are updated and the sink is notified of the update.
+func TestUpsertItemUpdates(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	stored := core.TodoItem{ID: 1, Description: "Buy milk", Position: 3, Color: "red", ListID: 2}
+	e.expectUpsert(1, &stored)
+
+	// act
+	got, created, err := e.core.UpsertItem(core.TodoItem{ID: 1, Description: "Buy bread", Position: 7})
+
+	// assert
+	if assert.NoError(t, err) {
+		want := core.TodoItem{ID: 1, Description: "Buy bread", Position: 3}
+		assert.False(t, created)
+		assert.Equal(t, want, got)
+		assert.Equal(t, []core.TodoItem{want}, sink.updated)
+		assert.Empty(t, sink.created)
+	}
+}
+
+// TestUpsertItemInvalid Given an item with a negative id and a blank description, when UpsertItem is called, then a ValidationError on both fields is returned without touching the storage.
+func TestUpsertItemInvalid(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, _, err := e.core.UpsertItem(core.TodoItem{ID: -1, Description: " "})
+
+	// assert
+	var validationErr core.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Contains(t, validationErr.Fields, "id")
+		assert.Contains(t, validationErr.Fields, "description")
+	}
+}
+
+// TestMoveItem Given an item in the default list, when MoveItem is called with another list, then the item is moved to the list and returned.
+func TestMoveItem(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	e.expectUpdateWith(&stored)
+
+	// act
+	got, err := e.core.MoveItem(stored.ID, 2)
+
+	// assert
+	if assert.NoError(t, err) {
+		want := core.TodoItem{ID: 1, Description: "some description", ListID: 2}
+		assert.Equal(t, want, got)
+		assert.Equal(t, []core.TodoItem{want}, sink.updated)
+	}
+}
+
+// TestMoveItemNegativeList Given a negative list id, when MoveItem is called, then a ValidationError on the list id is returned without touching the storage.
+func TestMoveItemNegativeList(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.MoveItem(1, -1)
 
 	// assert
+	var validationErr core.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Contains(t, validationErr.Fields, "list_id")
+	}
+}
+
+// TestParseDuplicatePolicy Given the names of the policies, when ParseDuplicatePolicy is called, then the corresponding policies are returned, or an error for an unknown name.
+func TestParseDuplicatePolicy(t *testing.T) {
+	for name, want := range map[string]core.DuplicatePolicy{
+		"":         core.AllowDuplicates,
+		"off":      core.AllowDuplicates,
+		"existing": core.ReturnExisting,
+		"conflict": core.RejectDuplicates,
+	} {
+		got, err := core.ParseDuplicatePolicy(name)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, want, got, name)
+		}
+	}
+	_, err := core.ParseDuplicatePolicy("unknown")
 	assert.Error(t, err)
-	// NOTE: There's no guarantee that the error is the same error that was returned by the storage accessor.
 }
 
-// TestGetItems Given items are returned by the storage accessor, when GetItems is called, then the items are returned.
-func TestGetItems(t *testing.T) {
+// TestGetItem Given an item of a specific id is returned by the storage accessor, when GetItem is called, then the item is returned.
+func TestGetItem(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
-	mockItems := [2]core.TodoItem{
-		{ID: 1, Description: "some description", Completed: false},
-		{ID: 2, Description: "another description", Completed: true},
+	want := core.TodoItem{ID: 1, Description: "some description", Completed: false}
+	e.mockAccessor.EXPECT().
+		Read(gomock.Any()).
+		Return([]core.TodoItem{want})
+
+	// act
+	got, err := e.core.GetItem(want.ID)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
+}
+
+// TestExistsItem Given the storage accessor tells whether an item exists, when ExistsItem is called for an existing and a missing id, then the answer is returned as is.
+func TestExistsItem(t *testing.T) {
+	for _, exists := range []bool{true, false} {
+		// arrange
+		e := newTestEnv(t)
+		e.mockAccessor.EXPECT().
+			Exists(1).
+			Return(exists, nil)
+
+		// act
+		got, err := e.core.ExistsItem(1)
+
+		// assert
+		if assert.NoError(t, err) {
+			assert.Equal(t, exists, got)
+		}
+	}
+}
+
+// TestReindex Given the storage accessor fixes some derived fields, when Reindex is called, then the report of the storage accessor is returned.
+func TestReindex(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	report := core.ReindexReport{DescriptionKeys: 1, CompletedAt: 2}
+	e.mockAccessor.EXPECT().
+		Reindex().
+		Return(report, nil)
+
+	// act
+	got, err := e.core.Reindex()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, report, got)
 	}
+}
+
+// TestCloneItem Given an item of a specific id is stored, when CloneItem is called, then a new incomplete item should be created
+// with the description suffixed and the color copied.
+func TestCloneItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := core.TodoItem{ID: 1, Description: "some description", Completed: true, Color: "red", CompletedAt: &completedAt, CreatedAt: completedAt}
 	e.mockAccessor.EXPECT().
 		Read(gomock.Any()).
-		DoAndReturn(func(func(core.TodoItem) bool) []core.TodoItem {
-			// With completed = false.
-			return []core.TodoItem{mockItems[0]}
+		Return([]core.TodoItem{source})
+	e.mockAccessor.EXPECT().
+		Create(&core.TodoItem{Description: "some description (copy)", Color: "red"}).
+		DoAndReturn(func(todo *core.TodoItem) (core.ItemID, error) {
+			todo.ID = 2
+			return 2, nil
 		})
 
 	// act
-	completed := false
-	want := []core.TodoItem{mockItems[0]}
-	got := e.core.GetItems(completed)
+	got, err := e.core.CloneItem(source.ID)
 
 	// assert
-	assert.Equal(t, want, got)
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.TodoItem{ID: 2, Description: "some description (copy)", Color: "red"}, got)
+	}
+}
+
+// TestCloneItemNotFound Given an item of a specific id is not stored, when CloneItem is called, then a TodoItemNotFoundError should be returned and nothing should be created.
+func TestCloneItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		Read(gomock.Any()).
+		Return([]core.TodoItem{})
+
+	// act
+	_, err := e.core.CloneItem(1)
+
+	// assert
+	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+}
+
+// TestGetItemNotFound Given an item of a specific id is not returned by the storage accessor, when GetItem is called, then an ItemNotFoundError is returned.
+func TestGetItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		Read(gomock.Any()).
+		Return([]core.TodoItem{})
+
+	// act
+	_, err := e.core.GetItem(1)
+
+	// assert
+	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+}
+
+// TestUpdateItem Given an item of a specific id is stored, when UpdateItem is called, then the item is updated and returned with the new completed status.
+func TestUpdateItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.expectUpdateWith(&core.TodoItem{ID: 1, Description: "some description", Completed: false})
+
+	// act
+	want := core.TodoItem{ID: 1, Description: "some description", Completed: true}
+	before := time.Now()
+	got, err := e.core.UpdateItem(want.ID, want.Completed)
+	after := time.Now()
+
+	// assert: the item should be updated and returned without error, with the completion time recorded
+	if assert.NoError(t, err) && assert.NotNil(t, got.CompletedAt) {
+		assert.WithinRange(t, *got.CompletedAt, before, after)
+		got.CompletedAt = nil
+		assert.Equal(t, want, got)
+	}
+}
+
+// TestUpdateItemUncomplete Given a completed item of a specific id is stored, when UpdateItem is called to mark it incomplete, then the completion time is cleared.
+func TestUpdateItemUncomplete(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stored := core.TodoItem{ID: 1, Description: "some description", Completed: true, CompletedAt: &completedAt}
+	e.expectUpdateWith(&stored)
+
+	// act
+	got, err := e.core.UpdateItem(1, false)
+
+	// assert
+	if assert.NoError(t, err) {
+		want := core.TodoItem{ID: 1, Description: "some description", Completed: false, CompletedAt: nil}
+		assert.Equal(t, want, got)
+		assert.Equal(t, want, stored)
+	}
+}
+
+// TestUpdateItemAlreadyCompleted Given a completed item of a specific id is stored, when UpdateItem is called to mark it completed again, then the original completion time is kept.
+func TestUpdateItemAlreadyCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e.expectUpdateWith(&core.TodoItem{ID: 1, Description: "some description", Completed: true, CompletedAt: &completedAt})
+
+	// act
+	got, err := e.core.UpdateItem(1, true)
+
+	// assert
+	if assert.NoError(t, err) && assert.NotNil(t, got.CompletedAt) {
+		assert.Equal(t, completedAt, *got.CompletedAt)
+	}
+}
+
+// TestUpdateItemNotFound Given an item of a specific id is not stored, when UpdateItem is called, then an ItemNotFoundError is returned.
+func TestUpdateItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		Return(core.TodoItem{}, core.TodoItemNotFoundError{ID: 1})
+
+	// act
+	id := 1
+	completed := true
+	_, err := e.core.UpdateItem(id, completed)
+
+	// assert: an error should be returned
+	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+}
+
+// TestToggleItem Given an incomplete item of a specific id is stored, when ToggleItem is called twice, then the item is first completed and then marked incomplete again.
+func TestToggleItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	stored := core.TodoItem{ID: 1, Description: "some description", Completed: false}
+	e.expectUpdateWith(&stored).Times(2)
+
+	// act & assert: incomplete -> complete
+	got, err := e.core.ToggleItem(stored.ID)
+	if assert.NoError(t, err) {
+		assert.True(t, got.Completed)
+		assert.NotNil(t, got.CompletedAt)
+	}
+
+	// act & assert: complete -> incomplete
+	got, err = e.core.ToggleItem(stored.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.TodoItem{ID: 1, Description: "some description", Completed: false}, got)
+	}
+}
+
+// TestToggleItemNotFound Given an item of a specific id is not stored, when ToggleItem is called, then an ItemNotFoundError is returned.
+func TestToggleItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		Return(core.TodoItem{}, core.TodoItemNotFoundError{ID: 1})
+
+	// act
+	_, err := e.core.ToggleItem(1)
+
+	// assert
+	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+}
+
+// expectRead Expects Read to be called once, returning the stored item.
+func (e *testEnv) expectRead(stored *core.TodoItem) *gomock.Call {
+	return e.mockAccessor.EXPECT().
+		Read(gomock.Any()).
+		DoAndReturn(func(func(core.TodoItem) bool) []core.TodoItem {
+			return []core.TodoItem{*stored}
+		})
+}
+
+// TestStartItem Given an incomplete item is stored, when StartItem is called twice and then StopItem,
+// then the item is in progress since the time of the first start, and back to todo after the stop.
+func TestStartItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := core.NewFakeClock(now)
+	e.core.SetClock(clock)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	e.expectTransaction().Times(3)
+	e.expectUpdateWith(&stored).Times(3)
+
+	// act & assert: todo -> in progress
+	got, err := e.core.StartItem(stored.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.StatusInProgress, got.Status())
+		assert.Equal(t, &now, got.StartedAt)
+	}
+
+	// act & assert: started again, keeping the time
+	clock.Advance(time.Hour)
+	got, err = e.core.StartItem(stored.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &now, got.StartedAt)
+	}
+
+	// act & assert: in progress -> todo
+	got, err = e.core.StopItem(stored.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.StatusTodo, got.Status())
+		assert.Nil(t, got.StartedAt)
+	}
+}
+
+// TestStartItemCompleted Given a completed item is stored, when StartItem or StopItem is called, then the completed status is checked within the update,
+// and a ConflictError is returned, rolling back the transaction, without the item being changed.
+func TestStartItemCompleted(t *testing.T) {
+	for name, act := range map[string]func(core.Core, core.ItemID) (core.TodoItem, error){
+		"start": core.Core.StartItem,
+		"stop":  core.Core.StopItem,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			stored := core.TodoItem{ID: 1, Description: "some description", Completed: true}
+			want := stored
+			e.expectTransaction()
+			e.expectUpdateWith(&stored)
+
+			// act
+			_, err := act(e.core, stored.ID)
+
+			// assert
+			assert.ErrorAs(t, err, new(core.ConflictError))
+			assert.Equal(t, want, stored)
+		})
+	}
+}
+
+// TestGetTimeSpent Given an incomplete item is stored, when it's started and stopped a few times, and started again without being stopped,
+// then GetTimeSpent accumulates the time of every start and stop, along with the live time since the last start while it's running.
+func TestGetTimeSpent(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	clock := core.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e.core.SetClock(clock)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	e.expectRead(&stored).AnyTimes()
+	e.expectTransaction().AnyTimes()
+	e.expectUpdateWith(&stored).AnyTimes()
+	work := func(d time.Duration) {
+		e.core.StartItem(stored.ID)
+		clock.Advance(d)
+		e.core.StopItem(stored.ID)
+		// The time between the works is not counted.
+		clock.Advance(time.Hour)
+	}
+
+	// act
+	work(30 * time.Minute)
+	work(10 * time.Minute)
+	stopped, errStopped := e.core.GetTimeSpent(stored.ID)
+	e.core.StartItem(stored.ID)
+	clock.Advance(5 * time.Minute)
+	running, errRunning := e.core.GetTimeSpent(stored.ID)
+
+	// assert
+	if assert.NoError(t, errStopped) {
+		assert.Equal(t, core.TimeSpent{Total: 40 * time.Minute, Running: false}, stopped)
+	}
+	if assert.NoError(t, errRunning) {
+		assert.Equal(t, core.TimeSpent{Total: 45 * time.Minute, Running: true}, running)
+	}
+}
+
+// TestGetTimeSpentCompleted Given an item in progress, when it's completed and then reopened later,
+// then the time while it's completed is not counted, and the work is resumed from the reopening.
+func TestGetTimeSpentCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	clock := core.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e.core.SetClock(clock)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	e.expectRead(&stored).AnyTimes()
+	e.expectTransaction().AnyTimes()
+	e.expectUpdateWith(&stored).AnyTimes()
+	e.core.StartItem(stored.ID)
+	clock.Advance(20 * time.Minute)
+
+	// act
+	e.core.ForceUpdateItem(stored.ID, true)
+	clock.Advance(time.Hour)
+	completed, errCompleted := e.core.GetTimeSpent(stored.ID)
+	e.core.ForceUpdateItem(stored.ID, false)
+	clock.Advance(5 * time.Minute)
+	reopened, errReopened := e.core.GetTimeSpent(stored.ID)
+
+	// assert
+	if assert.NoError(t, errCompleted) {
+		assert.Equal(t, core.TimeSpent{Total: 20 * time.Minute, Running: false}, completed)
+	}
+	if assert.NoError(t, errReopened) {
+		assert.Equal(t, core.TimeSpent{Total: 25 * time.Minute, Running: true}, reopened)
+	}
+}
+
+// TestGetItemsByStatus Given the storage accessor returns the filtered items, when GetItemsByStatus is called, then the items are filtered by the status.
+func TestGetItemsByStatus(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	status := core.StatusInProgress
+	items := []core.TodoItem{{ID: 2, Description: "in progress"}}
+	e.mockAccessor.EXPECT().
+		ReadFiltered(core.ItemFilter{Status: &status}).
+		Return(items, nil)
+
+	// act
+	got, err := e.core.GetItemsByStatus(core.StatusInProgress)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, items, got)
+	}
+}
+
+// TestGetItemsByStatusUnknown Given an unknown status, when GetItemsByStatus is called, then a ValidationError is returned without reading the storage.
+func TestGetItemsByStatusUnknown(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		ReadFiltered(gomock.Any()).
+		Times(0)
+
+	// act
+	_, err := e.core.GetItemsByStatus("paused")
+
+	// assert
+	assert.ErrorAs(t, err, new(core.ValidationError))
+}
+
+// TestItemStatus Given items in each state, when Status is called, then a completed item is completed even if it was started, and a started incomplete one is in progress.
+func TestItemStatus(t *testing.T) {
+	started := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := map[core.ItemStatus]core.TodoItem{
+		core.StatusTodo:       {},
+		core.StatusInProgress: {StartedAt: &started},
+		core.StatusCompleted:  {Completed: true, StartedAt: &started},
+	}
+	for want, todo := range tests {
+		assert.Equal(t, want, todo.Status())
+	}
+}
+
+// TestSetItemsCompleted Given the storage accessor changes the status of some items, when SetItemsCompleted is called, then the number of changed items is returned.
+func TestSetItemsCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	ids := []int{1, 2, 3}
+	e.mockAccessor.EXPECT().
+		UpdateCompleted(ids, true, gomock.Any()).
+		Return(2, nil)
+
+	// act
+	got, err := e.core.SetItemsCompleted(ids, true)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, got)
+	}
+}
+
+// TestSetItemsCompletedNoIDs Given no ids, when SetItemsCompleted is called, then the storage accessor is not touched and 0 is returned.
+func TestSetItemsCompletedNoIDs(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	got, err := e.core.SetItemsCompleted(nil, true)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Zero(t, got)
+	}
+}
+
+// TestSetItemsCompletedTooMany Given more than MaxBatchIDs ids, when SetItemsCompleted is called, then the storage accessor is not touched and a ValidationError is returned.
+func TestSetItemsCompletedTooMany(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	ids := make([]int, core.MaxBatchIDs+1)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	// act
+	_, err := e.core.SetItemsCompleted(ids, true)
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
+
+// TestReorder Given the storage accessor reorders without error, when Reorder is called, then the ids are passed to the storage accessor in order and no error is returned.
+func TestReorder(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	ids := []int{3, 1, 2}
+	e.mockAccessor.EXPECT().
+		Reorder(ids).
+		Return(nil)
+
+	// act
+	err := e.core.Reorder(ids)
+
+	// assert
+	assert.NoError(t, err)
+}
+
+// TestReorderNotFound Given the storage accessor fails to find one of the items, when Reorder is called, then the error is returned.
+func TestReorderNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		Reorder(gomock.Any()).
+		Return(core.TodoItemNotFoundError{ID: 4})
+
+	// act
+	err := e.core.Reorder([]int{3, 4})
+
+	// assert
+	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+}
+
+// TestReorderDuplicateIDs Given an id appears more than once, when Reorder is called, then the storage accessor is not touched and a ValidationError is returned.
+func TestReorderDuplicateIDs(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	err := e.core.Reorder([]int{3, 1, 3})
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
+
+// TestDeleteItem Given an id and the storage accessor returns no error, when DeleteItem is called, then no error is returned.
+func TestDeleteItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		Delete(gomock.Any()).
+		Return(nil)
+
+	// act
+	id := 1
+	err := e.core.DeleteItem(id)
+
+	// assert
+	assert.NoError(t, err)
+}
+
+// TestDeleteItemError Given an id and the storage accessor returns an error, when DeleteItem is called, then a StorageError is returned.
+func TestDeleteItemError(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		Delete(gomock.Any()).
+		Return(errors.New("error"))
+
+	// act
+	id := 1
+	err := e.core.DeleteItem(id)
+
+	// assert
+	assert.IsType(t, core.StorageError{}, err)
+	// NOTE: There's no guarantee that the error is the same error that was returned by the storage accessor.
+}
+
+// TestDeleteItemNotFound Given an id and the storage accessor fails to find the item, when DeleteItem is called, then no error is returned.
+func TestDeleteItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		Delete(gomock.Any()).
+		Return(core.TodoItemNotFoundError{ID: 1})
+
+	// act
+	err := e.core.DeleteItem(1)
+
+	// assert
+	assert.NoError(t, err)
+}
+
+// TestDeleteItemTwice Given an item, when DeleteItem is called twice with its id, then both calls succeed and the sink is notified only once.
+func TestDeleteItemTwice(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	gomock.InOrder(
+		e.mockAccessor.EXPECT().Delete(core.ItemID(1)).Return(nil),
+		e.mockAccessor.EXPECT().Delete(core.ItemID(1)).Return(core.TodoItemNotFoundError{ID: 1}),
+	)
+
+	// act
+	first := e.core.DeleteItem(1)
+	second := e.core.DeleteItem(1)
+
+	// assert
+	assert.NoError(t, first)
+	assert.NoError(t, second)
+	assert.Equal(t, []core.ItemID{1}, sink.deleted)
+}
+
+// TestGetItems Given items are returned by the storage accessor, when GetItems is called, then the items are returned.
+func TestGetItems(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	mockItems := [2]core.TodoItem{
+		{ID: 1, Description: "some description", Completed: false},
+		{ID: 2, Description: "another description", Completed: true},
+	}
+	e.mockAccessor.EXPECT().
+		Read(gomock.Any()).
+		DoAndReturn(func(func(core.TodoItem) bool) []core.TodoItem {
+			// With completed = false.
+			return []core.TodoItem{mockItems[0]}
+		})
+
+	// act
+	completed := false
+	want := []core.TodoItem{mockItems[0]}
+	got := e.core.GetItems(completed)
+
+	// assert
+	assert.Equal(t, want, got)
+}
+
+// TestGetItemsByIDs Given duplicated ids, when GetItemsByIDs is called, then the storage accessor is asked for each id only once and the found items are returned.
+func TestGetItemsByIDs(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	found := []core.TodoItem{
+		{ID: 1, Description: "some description", Completed: false},
+		{ID: 3, Description: "another description", Completed: true},
+	}
+	e.mockAccessor.EXPECT().
+		ReadByIDs([]int{1, 3, 5}).
+		Return(found)
+
+	// act
+	got := e.core.GetItemsByIDs([]int{1, 3, 1, 5, 3})
+
+	// assert
+	assert.Equal(t, found, got)
+}
+
+// TestGetItemsByIDsTooMany Given more than MaxBatchIDs distinct ids, when GetItemsByIDs is called, then only the first MaxBatchIDs ids are fetched.
+func TestGetItemsByIDsTooMany(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	var ids []int
+	for i := 1; i <= core.MaxBatchIDs+10; i++ {
+		ids = append(ids, i)
+	}
+	e.mockAccessor.EXPECT().
+		ReadByIDs(ids[:core.MaxBatchIDs]).
+		Return(nil)
+
+	// act
+	got := e.core.GetItemsByIDs(ids)
+
+	// assert
+	assert.Empty(t, got)
+}
+
+// completedFilter is the filter that Summary counts the completed items with.
+var completedFilter = func() core.ItemFilter {
+	completed := true
+	return core.ItemFilter{Completed: &completed}
+}()
+
+// TestSummary Given the numbers of all and completed items counted by the storage accessor, when Summary is called, then the totals and the completion rate are returned.
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name      string
+		completed int
+		active    int
+		want      core.SummaryStats
+	}{
+		{"empty", 0, 0, core.SummaryStats{Total: 0, Completed: 0, Active: 0, CompletionRate: 0}},
+		{"all completed", 3, 0, core.SummaryStats{Total: 3, Completed: 3, Active: 0, CompletionRate: 1}},
+		{"mixed", 1, 3, core.SummaryStats{Total: 4, Completed: 1, Active: 3, CompletionRate: 0.25}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			e.mockAccessor.EXPECT().Count(core.ItemFilter{}).Return(tt.completed+tt.active, nil)
+			e.mockAccessor.EXPECT().Count(completedFilter).Return(tt.completed, nil)
+
+			// act
+			got, err := e.core.Summary()
+
+			// assert
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+// reconnectingAccessor is a stub of a StorageAccessor that can reconnect, counting the reconnections.
+type reconnectingAccessor struct {
+	*MockStorageAccessor
+	reconnects int
+	err        error
+}
+
+func (a *reconnectingAccessor) Reconnect() error {
+	a.reconnects++
+	return a.err
+}
+
+// TestSummaryReconnect Given an accessor whose connection is broken, when Summary is called, then the accessor should be reconnected and the count retried once.
+func TestSummaryReconnect(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	accessor := &reconnectingAccessor{MockStorageAccessor: NewMockStorageAccessor(ctrl)}
+	gomock.InOrder(
+		accessor.EXPECT().Count(core.ItemFilter{}).Return(0, driver.ErrBadConn),
+		accessor.EXPECT().Count(core.ItemFilter{}).Return(4, nil),
+		accessor.EXPECT().Count(completedFilter).Return(1, nil),
+	)
+	theCore := core.NewCore(accessor)
+
+	// act
+	got, err := theCore.Summary()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.SummaryStats{Total: 4, Completed: 1, Active: 3, CompletionRate: 0.25}, got)
+	}
+	assert.Equal(t, 1, accessor.reconnects)
+}
+
+// TestSummaryReconnectFails Given an accessor whose connection is broken and can't be reconnected, when Summary is called,
+// then the count should not be retried and the connection error should be returned as a StorageError.
+func TestSummaryReconnectFails(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	accessor := &reconnectingAccessor{MockStorageAccessor: NewMockStorageAccessor(ctrl), err: errors.New("connection refused")}
+	accessor.EXPECT().Count(gomock.Any()).Return(0, driver.ErrBadConn).Times(1)
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.Summary()
+
+	// assert
+	assert.ErrorAs(t, err, new(core.StorageError))
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 1, accessor.reconnects)
+}
+
+// TestSummaryNoReconnectOnTimeout Given an accessor that can reconnect, when Summary fails because the query times out,
+// then the accessor should not be reconnected, since the connection is not broken.
+func TestSummaryNoReconnectOnTimeout(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	accessor := &reconnectingAccessor{MockStorageAccessor: NewMockStorageAccessor(ctrl)}
+	accessor.EXPECT().Count(gomock.Any()).Return(0, context.DeadlineExceeded).Times(1)
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.Summary()
+
+	// assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, accessor.reconnects)
+}
+
+// TestSummaryNoReconnect Given an accessor that can reconnect, when Summary fails for reasons other than the connection,
+// then the accessor should not be reconnected.
+func TestSummaryNoReconnect(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	accessor := &reconnectingAccessor{MockStorageAccessor: NewMockStorageAccessor(ctrl)}
+	accessor.EXPECT().Count(gomock.Any()).Return(0, errors.New("syntax error")).Times(1)
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.Summary()
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, 0, accessor.reconnects)
+}
+
+// TestGetItemsAfter Given 7 items in the storage, when GetItemsAfter is called repeatedly with the returned cursor, then every item is returned exactly once, in order, and the last page has no next cursor.
+func TestGetItemsAfter(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	var stored []core.TodoItem
+	for id := 1; id <= 7; id++ {
+		stored = append(stored, core.TodoItem{ID: id, Description: "some description"})
+	}
+	e.mockAccessor.EXPECT().
+		ReadAfter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(cursor, limit int) []core.TodoItem {
+			var todos []core.TodoItem
+			for _, todo := range stored {
+				if todo.ID > cursor && len(todos) < limit {
+					todos = append(todos, todo)
+				}
+			}
+			return todos
+		}).
+		AnyTimes()
+
+	// act
+	var got []core.TodoItem
+	var cursors []int
+	cursor := 0
+	for {
+		todos, next := e.core.GetItemsAfter(cursor, 3)
+		got = append(got, todos...)
+		cursors = append(cursors, next)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	// assert
+	assert.Equal(t, stored, got)
+	assert.Equal(t, []int{3, 6, 0}, cursors)
+}
+
+// TestGetItemsAfterDefaultLimit Given a non-positive limit, when GetItemsAfter is called, then the default page size is used.
+func TestGetItemsAfterDefaultLimit(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		ReadAfter(0, core.DefaultPageSize+1).
+		Return(nil)
+
+	// act
+	got, next := e.core.GetItemsAfter(0, 0)
+
+	// assert
+	assert.Empty(t, got)
+	assert.Zero(t, next)
+}
+
+// TestGetItemsCompletedBetween Given items completed within the range are returned by the storage accessor, when GetItemsCompletedBetween is called, then the items are returned.
+func TestGetItemsCompletedBetween(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	items := []core.TodoItem{
+		{ID: 1, Description: "some description", Completed: true, CompletedAt: &completedAt},
+	}
+	e.mockAccessor.EXPECT().
+		ReadCompletedBetween(start, end).
+		Return(items)
+
+	// act
+	got := e.core.GetItemsCompletedBetween(start, end)
+
+	// assert
+	assert.Equal(t, items, got)
+}
+
+// TestGetItemsCompletedOn Given a date in a time zone, when GetItemsCompletedOn is called, then the storage accessor is asked for the items completed between the midnights of the day in that time zone.
+func TestGetItemsCompletedOn(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database is not available: ", err)
+	}
+	tests := []struct {
+		name       string
+		date       time.Time
+		start, end time.Time
+	}{
+		{
+			"late at night ahead of UTC",
+			time.Date(2024, 1, 1, 23, 30, 0, 0, time.FixedZone("", 8*60*60)),
+			time.Date(2023, 12, 31, 16, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			"daylight saving time starts",
+			time.Date(2024, 3, 10, 12, 0, 0, 0, newYork),
+			time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			e.mockAccessor.EXPECT().
+				ReadCompletedBetween(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(start, end time.Time) []core.TodoItem {
+					assert.True(t, tt.start.Equal(start), "expected start %v, got %v", tt.start, start)
+					assert.True(t, tt.end.Equal(end), "expected end %v, got %v", tt.end, end)
+					return nil
+				})
+
+			// act
+			e.core.GetItemsCompletedOn(tt.date)
+		})
+	}
+}
+
+// TestSuggestDescriptions Given a limit above MaxSuggestions, when SuggestDescriptions is called, then the storage accessor is asked for at most MaxSuggestions descriptions.
+func TestSuggestDescriptions(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	descriptions := []string{"buy milk", "buy eggs"}
+	e.mockAccessor.EXPECT().
+		ReadDescriptionsWithPrefix("buy", core.MaxSuggestions).
+		Return(descriptions)
+
+	// act
+	got := e.core.SuggestDescriptions("buy", core.MaxSuggestions+5)
+
+	// assert
+	assert.Equal(t, descriptions, got)
+}
+
+// TestGetChangesSince Given the storage accessor returns changed items and deleted ids, when GetChangesSince is called, then they are returned.
+func TestGetChangesSince(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	changed := []core.TodoItem{
+		{ID: 1, Description: "some description", UpdatedAt: since.Add(time.Hour)},
+	}
+	e.mockAccessor.EXPECT().
+		ReadChangedSince(since).
+		Return(changed, []int{2}, nil)
+
+	// act
+	gotChanged, gotDeleted, err := e.core.GetChangesSince(since)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, changed, gotChanged)
+		assert.Equal(t, []int{2}, gotDeleted)
+	}
+}
+
+// TestExport Given the storage accessor returns all items, when Export is called, then a snapshot of the items is returned.
+func TestExport(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	items := []core.TodoItem{
+		{ID: 1, Description: "some description", Completed: false},
+		{ID: 3, Description: "another description", Completed: true},
+	}
+	e.mockAccessor.EXPECT().
+		ReadAll().
+		Return(items, nil)
+
+	// act
+	got, err := e.core.Export()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.Snapshot{Items: items}, got)
+	}
+}
+
+// TestReset Given the storage accessor fails to reset, when Reset is called, then a StorageError wrapping the error is returned.
+func TestReset(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	storageErr := errors.New("error")
+	e.mockAccessor.EXPECT().
+		Reset().
+		Return(storageErr)
+
+	// act
+	err := e.core.Reset()
+
+	// assert
+	assert.Equal(t, core.StorageError{Err: storageErr}, err)
+}
+
+// TestStorageStats Given the storage accessor reports its statistics, when StorageStats is called, then they are returned as they are.
+func TestStorageStats(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	want := core.BackendStats{Driver: "mysql", Items: 3, OpenConnections: 2}
+	e.mockAccessor.EXPECT().
+		Stats().
+		Return(want, nil)
+
+	// act
+	got, err := e.core.StorageStats()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
+}
+
+// TestImport Given a snapshot of valid items, when Import is called, then all items are replaced with them and the number of items is returned.
+func TestImport(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []core.TodoItem{
+		{ID: 1, Description: "some description", Completed: false},
+		{ID: 3, Description: "another description", Completed: true, CompletedAt: &completedAt},
+	}
+	e.mockAccessor.EXPECT().
+		ReplaceAll(items).
+		Return(nil)
+
+	// act
+	got, err := e.core.Import(core.Snapshot{Items: items})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, got)
+	}
+}
+
+// TestImportInvalid Given a snapshot with an invalid item, when Import is called, then a ValidationError is returned without touching the storage.
+func TestImportInvalid(t *testing.T) {
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		items []core.TodoItem
+	}{
+		{"non-positive id", []core.TodoItem{{ID: 1}, {ID: 0}}},
+		{"duplicated id", []core.TodoItem{{ID: 1}, {ID: 1}}},
+		{"completion time of incomplete item", []core.TodoItem{{ID: 1, Completed: false, CompletedAt: &completedAt}}},
+		{"negative time spent", []core.TodoItem{{ID: 1, TimeSpent: core.Seconds(-time.Minute)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+
+			// act
+			_, err := e.core.Import(core.Snapshot{Items: tt.items})
+
+			// assert
+			assert.IsType(t, core.ValidationError{}, err)
+		})
+	}
+}
+
+// TestTimeSpentJSON Given a TodoItem with time spent, when it's encoded to JSON and decoded back, then the time spent should be kept in whole seconds.
+func TestTimeSpentJSON(t *testing.T) {
+	// arrange
+	todo := core.TodoItem{ID: 1, Description: "some description", TimeSpent: core.Seconds(90*time.Second + 400*time.Millisecond)}
+
+	// act
+	data, errMarshal := json.Marshal(todo)
+	var got core.TodoItem
+	errUnmarshal := json.Unmarshal(data, &got)
+
+	// assert
+	assert.NoError(t, errMarshal)
+	assert.Contains(t, string(data), `"time_spent_seconds":90`)
+	if assert.NoError(t, errUnmarshal) {
+		assert.Equal(t, core.Seconds(90*time.Second), got.TimeSpent)
+	}
+}
+
+// TestGetItemsPage Given the storage accessor returns a page of items and the total, when GetItemsPage is called without a limit, then the page is read with DefaultPageSize and returned with the total.
+func TestGetItemsPage(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	items := []core.TodoItem{{ID: 21, Description: "some description"}}
+	e.mockAccessor.EXPECT().
+		ReadPage(20, core.DefaultPageSize).
+		Return(items, 21, nil)
+
+	// act
+	got, total, err := e.core.GetItemsPage(20, 0)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, items, got)
+		assert.Equal(t, 21, total)
+	}
+}
+
+// TestGetItemsPageNegativeOffset Given a negative offset, when GetItemsPage is called, then a ValidationError is returned.
+func TestGetItemsPageNegativeOffset(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, _, err := e.core.GetItemsPage(-1, 10)
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
+
+// TestDeleteCompleted Given the storage accessor deletes some completed items, when DeleteCompleted is called without a limit, then the deleted items are returned.
+func TestDeleteCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	items := []core.TodoItem{{ID: 1, Description: "some description", Completed: true}}
+	e.expectTransaction()
+	e.mockAccessor.EXPECT().
+		Count(core.ItemFilter{}).
+		Return(1, nil)
+	e.mockAccessor.EXPECT().
+		DeleteCompleted().
+		Return(items, nil)
+
+	// act
+	got, err := e.core.DeleteCompleted(1)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, items, got)
+	}
+}
+
+// TestDeleteCompletedAtMaxFraction Given the completed items are just the max fraction of all, when DeleteCompleted is called, then they are deleted.
+func TestDeleteCompletedAtMaxFraction(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	items := []core.TodoItem{{ID: 1, Description: "some description", Completed: true}}
+	e.expectTransaction()
+	e.mockAccessor.EXPECT().
+		Count(core.ItemFilter{}).
+		Return(2, nil)
+	e.mockAccessor.EXPECT().
+		DeleteCompleted().
+		Return(items, nil)
+
+	// act
+	got, err := e.core.DeleteCompleted(0.5)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, items, got)
+	}
+}
+
+// TestDeleteCompletedOverMaxFraction Given the completed items are just more than the max fraction of all, when DeleteCompleted is called,
+// then a ConflictError is returned from the transaction, so that the deletion is rolled back, and the sink is not notified.
+func TestDeleteCompletedOverMaxFraction(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	items := make([]core.TodoItem, 501)
+	for i := range items {
+		items[i] = core.TodoItem{ID: i + 1, Completed: true}
+	}
+	e.mockAccessor.EXPECT().
+		Transaction(gomock.Any()).
+		DoAndReturn(func(fn func(core.StorageAccessor) error) error {
+			err := fn(e.mockAccessor)
+			assert.IsType(t, core.ConflictError{}, err)
+			return err
+		})
+	e.mockAccessor.EXPECT().
+		Count(core.ItemFilter{}).
+		Return(1001, nil)
+	e.mockAccessor.EXPECT().
+		DeleteCompleted().
+		Return(items, nil)
+
+	// act
+	got, err := e.core.DeleteCompleted(0.5)
+
+	// assert
+	assert.IsType(t, core.ConflictError{}, err)
+	assert.Nil(t, got)
+	assert.Empty(t, sink.deleted)
+}
+
+// TestRestoreItems Given some deleted items, when RestoreItems is called with them, then they are restored by the storage accessor and returned.
+func TestRestoreItems(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	items := []core.TodoItem{{ID: 1, Description: "some description", Completed: true}}
+	e.mockAccessor.EXPECT().
+		Restore(items).
+		Return(items, nil)
+
+	// act
+	got, err := e.core.RestoreItems(items)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, items, got)
+	}
+}
+
+// TestRestoreItemsNone Given no items, when RestoreItems is called, then the storage accessor is not called.
+func TestRestoreItemsNone(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	got, err := e.core.RestoreItems(nil)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
+
+// TestGetItemsFiltered Given the storage accessor returns the items meeting the filter, when GetItemsFiltered is called, then the filter is passed through and the items are returned.
+func TestGetItemsFiltered(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	completed := false
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := core.ItemFilter{Completed: &completed, CreatedAfter: &after}
+	items := []core.TodoItem{{ID: 2, Description: "some description"}}
+	e.mockAccessor.EXPECT().
+		ReadFiltered(f).
+		Return(items, nil)
+
+	// act
+	got, err := e.core.GetItemsFiltered(f)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, items, got)
+	}
+}
+
+// TestGetItemsFilteredInvertedRange Given a filter whose CreatedAfter is later than its CreatedBefore, when GetItemsFiltered is called,
+// then a ValidationError is returned without reading the storage.
+func TestGetItemsFilteredInvertedRange(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	after := before.Add(time.Second)
+
+	// act
+	_, err := e.core.GetItemsFiltered(core.ItemFilter{CreatedAfter: &after, CreatedBefore: &before})
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
+
+// TestQuery Given the storage accessor has more items than the limit, when Query is called with a filter, a sort, and a window,
+// then one more item than the limit should be read to tell that there's a next window, and the extra item should be dropped.
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		sort           core.SortOrder
+		wantNextCursor core.ItemID
+	}{
+		{"by id", core.SortByID, 2},
+		{"by position", core.SortByPosition, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			completed := false
+			q := core.Query{Filter: core.ItemFilter{Completed: &completed}, Sort: tt.sort, Offset: 4, Limit: 2}
+			window := q
+			window.Limit = 3
+			e.mockAccessor.EXPECT().
+				ReadQuery(window).
+				Return([]core.TodoItem{{ID: 1}, {ID: 2}, {ID: 3}}, 10, nil)
+
+			// act
+			got, err := e.core.Query(q)
+
+			// assert
+			if assert.NoError(t, err) {
+				assert.Equal(t, core.QueryResult{Items: []core.TodoItem{{ID: 1}, {ID: 2}}, Total: 10, NextCursor: tt.wantNextCursor}, got)
+			}
+		})
+	}
+}
+
+// TestQueryLastWindow Given the storage accessor has no more items than the limit, when Query is called, then there should be no next cursor.
+func TestQueryLastWindow(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.mockAccessor.EXPECT().
+		ReadQuery(core.Query{After: 2, Limit: 3}).
+		Return([]core.TodoItem{{ID: 3}, {ID: 4}}, 4, nil)
+
+	// act
+	got, err := e.core.Query(core.Query{After: 2, Limit: 2})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.QueryResult{Items: []core.TodoItem{{ID: 3}, {ID: 4}}, Total: 4}, got)
+	}
+}
+
+// TestQueryInvalid Given an invalid query, when Query is called, then a ValidationError should be returned without reading the storage.
+func TestQueryInvalid(t *testing.T) {
+	after := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		q    core.Query
+	}{
+		{"negative offset", core.Query{Offset: -1}},
+		{"negative cursor", core.Query{After: -1}},
+		{"negative limit", core.Query{Limit: -1}},
+		{"offset and cursor", core.Query{Offset: 10, After: 5}},
+		{"cursor sorted by position", core.Query{After: 5, Sort: core.SortByPosition}},
+		{"cursor in descending order", core.Query{After: 5, Descending: true}},
+		{"inverted range", core.Query{Filter: core.ItemFilter{CreatedAfter: &after, CreatedBefore: &before}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+
+			// act
+			_, err := e.core.Query(tt.q)
+
+			// assert
+			assert.IsType(t, core.ValidationError{}, err)
+		})
+	}
+}
+
+// fakeSink is an EventSink that records the events it's notified of.
+type fakeSink struct {
+	created []core.TodoItem
+	updated []core.TodoItem
+	deleted []core.ItemID
+}
+
+func (s *fakeSink) ItemCreated(todo core.TodoItem) { s.created = append(s.created, todo) }
+
+func (s *fakeSink) ItemUpdated(todo core.TodoItem) { s.updated = append(s.updated, todo) }
+
+func (s *fakeSink) ItemDeleted(id core.ItemID) { s.deleted = append(s.deleted, id) }
+
+// newTestEnvWithSink is newTestEnv with a fakeSink attached to the core.
+func newTestEnvWithSink(t *testing.T) (*testEnv, *fakeSink) {
+	e := newTestEnv(t)
+	sink := &fakeSink{}
+	e.core = core.NewCore(e.mockAccessor, sink)
+	return e, sink
+}
+
+// TestEventSinkItemCreated Given a sink attached to the core, when CreateItem succeeds, then the sink is notified of the created item.
+func TestEventSinkItemCreated(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	e.mockAccessor.EXPECT().
+		Create(gomock.Any()).
+		DoAndReturn(func(item *core.TodoItem) (core.ItemID, error) {
+			item.ID = 1
+			return 1, nil
+		})
+
+	// act
+	todo, err := e.core.CreateItem("some description", "")
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.TodoItem{todo}, sink.created)
+		assert.Empty(t, sink.updated)
+		assert.Empty(t, sink.deleted)
+	}
+}
+
+// TestEventSinkItemUpdated Given a sink attached to the core, when ToggleItem succeeds, then the sink is notified of the updated item.
+func TestEventSinkItemUpdated(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	stored := core.TodoItem{ID: 1, Description: "some description", Completed: false}
+	e.expectUpdateWith(&stored)
+
+	// act
+	todo, err := e.core.ToggleItem(1)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.TodoItem{todo}, sink.updated)
+		assert.True(t, sink.updated[0].Completed)
+		assert.Empty(t, sink.created)
+	}
+}
+
+// TestEventSinkItemDeleted Given a sink attached to the core, when DeleteItem succeeds, then the sink is notified of the id of the deleted item.
+func TestEventSinkItemDeleted(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	e.mockAccessor.EXPECT().
+		Delete(1).
+		Return(nil)
+
+	// act
+	err := e.core.DeleteItem(1)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{1}, sink.deleted)
+	}
+}
+
+// TestEventSinkNotNotifiedOnError Given a sink attached to the core, when DeleteItem fails, then the sink is not notified.
+func TestEventSinkNotNotifiedOnError(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	e.mockAccessor.EXPECT().
+		Delete(1).
+		Return(errors.New("some error"))
+
+	// act
+	err := e.core.DeleteItem(1)
+
+	// assert
+	assert.Error(t, err)
+	assert.Empty(t, sink.deleted)
+}
+
+// TestNopEventSink Given the NopEventSink attached to the core, when CreateItem succeeds, then nothing happens.
+func TestNopEventSink(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.core = core.NewCore(e.mockAccessor, core.NopEventSink{})
+	e.mockAccessor.EXPECT().
+		Create(gomock.Any()).
+		Return(1, nil)
+
+	// act
+	_, err := e.core.CreateItem("some description", "")
+
+	// assert
+	assert.NoError(t, err)
+}
+
+// TestToggleItemWithFakeClock Given the core reads the time from a fake clock, when ToggleItem completes an item, then the completion time is exactly the time of the clock.
+func TestToggleItemWithFakeClock(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	clock := core.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	e.core.SetClock(clock)
+	clock.Advance(time.Hour)
+	stored := core.TodoItem{ID: 1, Description: "some description", Completed: false}
+	e.expectUpdateWith(&stored)
+
+	// act
+	got, err := e.core.ToggleItem(stored.ID)
+
+	// assert
+	if assert.NoError(t, err) {
+		want := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		if assert.NotNil(t, got.CompletedAt) {
+			assert.Equal(t, want, *got.CompletedAt)
+		}
+	}
+}
+
+// TestSetItemsCompletedWithFakeClock Given the core reads the time from a fake clock, when SetItemsCompleted is called, then the storage accessor is given the time of the clock as the completion time.
+func TestSetItemsCompletedWithFakeClock(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	e.core.SetClock(core.NewFakeClock(now))
+	ids := []int{1, 2}
+	e.mockAccessor.EXPECT().
+		UpdateCompleted(ids, true, now).
+		Return(2, nil)
+
+	// act
+	got, err := e.core.SetItemsCompleted(ids, true)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, got)
+	}
+}
+
+// TestCreateItemWithIDGenerator Given the core assigns the ids with a generator, when CreateItem is called twice, then the items are passed to the storage accessor with the generated ids.
+func TestCreateItemWithIDGenerator(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.core.SetIDGenerator(core.NewSequentialIDGenerator(42))
+	var ids []int
+	e.mockAccessor.EXPECT().
+		Create(gomock.Any()).
+		DoAndReturn(func(item *core.TodoItem) (int, error) {
+			ids = append(ids, item.ID)
+			return item.ID, nil
+		}).
+		Times(2)
+
+	// act
+	first, err1 := e.core.CreateItem("first", "")
+	second, err2 := e.core.CreateItem("second", "")
+
+	// assert
+	if assert.NoError(t, err1) && assert.NoError(t, err2) {
+		assert.Equal(t, []int{42, 43}, ids)
+		assert.Equal(t, 42, first.ID)
+		assert.Equal(t, 43, second.ID)
+	}
+}
+
+// TestFakeClock Given a fake clock, when it's advanced and set, then it tells the time accordingly and nothing else moves it.
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := core.NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	clock.Advance(90 * time.Minute)
+	assert.Equal(t, start.Add(90*time.Minute), clock.Now())
+
+	later := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock.Set(later)
+	assert.Equal(t, later, clock.Now())
+}
+
+// TestPruneCompletedOlderThan Given the core reads the time from a fake clock, when PruneCompletedOlderThan is called, then the storage accessor is asked to delete the items completed before the retention and the number of deleted items is returned.
+func TestPruneCompletedOlderThan(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.core.SetClock(core.NewFakeClock(time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)))
+	e.mockAccessor.EXPECT().
+		DeleteCompletedBefore(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)).
+		Return(3, nil)
+
+	// act
+	got, err := e.core.PruneCompletedOlderThan(30 * 24 * time.Hour)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, got)
+	}
+}
+
+// TestPruneCompletedOlderThanNotPositive Given a retention that is not positive, when PruneCompletedOlderThan is called, then a ValidationError is returned without touching the storage.
+func TestPruneCompletedOlderThanNotPositive(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.PruneCompletedOlderThan(0)
+
+	// assert
+	assert.IsType(t, core.ValidationError{}, err)
+}
+
+// TestPruneCompleted Given a positive retention, when PruneCompleted is run, then the items are pruned on every tick until the context is done.
+func TestPruneCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	e.mockAccessor.EXPECT().
+		DeleteCompletedBefore(gomock.Any()).
+		DoAndReturn(func(time.Time) (int, error) {
+			calls++
+			if calls == 2 {
+				cancel()
+			}
+			return 1, nil
+		}).
+		Times(2)
+
+	// act
+	done := make(chan struct{})
+	go func() {
+		core.PruneCompleted(ctx, e.core, time.Hour, time.Millisecond)
+		close(done)
+	}()
+
+	// assert
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected PruneCompleted to return once the context is done")
+	}
+}
+
+// TestPruneCompletedDisabled Given a retention of 0, when PruneCompleted is run, then it returns right away without touching the storage.
+func TestPruneCompletedDisabled(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	core.PruneCompleted(context.Background(), e.core, 0, time.Millisecond)
+}
+
+// TestExportStream Given the storage accessor streams all items, when ExportStream is called, then the callback is called with each item.
+func TestExportStream(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	items := []core.TodoItem{
+		{ID: 1, Description: "some description", Completed: false},
+		{ID: 3, Description: "another description", Completed: true},
+	}
+	e.mockAccessor.EXPECT().
+		ReadStream(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(where func(core.TodoItem) bool, fn func(core.TodoItem) error) error {
+			for _, item := range items {
+				if where(item) {
+					if err := fn(item); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+
+	// act
+	var got []core.TodoItem
+	err := e.core.ExportStream(func(todo core.TodoItem) error {
+		got = append(got, todo)
+		return nil
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, items, got)
+	}
 }
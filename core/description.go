@@ -0,0 +1,22 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeDescription cleans up a description as typed by a user, so that the same text is always stored the same way:
+// the ASCII control characters other than whitespace are removed, every run of whitespace, including tabs and line breaks, becomes a single space,
+// the description is trimmed, and the result is in Unicode NFC. Emoji and all the other printable characters are kept as they are.
+func NormalizeDescription(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= unicode.MaxASCII && unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	return norm.NFC.String(s)
+}
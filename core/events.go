@@ -0,0 +1,25 @@
+package core
+
+// EventSink is notified of the mutations of the TodoItems after they succeed, e.g., to publish them to webhooks or to collect metrics.
+// Each TodoItem created, updated, or deleted by the core is reported on its own; the ones of a transaction, e.g., of an atomic Batch,
+// are reported once it's committed, and none of them if it's rolled back.
+//
+// NOTE: The bulk mutations that return no TodoItems, i.e., SetItemsCompleted, PruneCompletedOlderThan, Reorder, Import, and Reset,
+// are not reported, as the core would have to read the affected TodoItems back for that. The sinks that mirror the TodoItems have to reload them.
+type EventSink interface {
+	// ItemCreated is called with the TodoItem created, including a copy or a TodoItem brought back by RestoreItems.
+	ItemCreated(todo TodoItem)
+	// ItemUpdated is called with the TodoItem updated, e.g., its completion, description, color, List, time tracking, or attachments.
+	ItemUpdated(todo TodoItem)
+	// ItemDeleted is called with the id of the TodoItem deleted, including along with its List by ListCore.DeleteList.
+	ItemDeleted(id ItemID)
+}
+
+// NopEventSink is an EventSink that ignores all events. It can be embedded to implement only some of the methods.
+type NopEventSink struct{}
+
+func (NopEventSink) ItemCreated(TodoItem) {}
+
+func (NopEventSink) ItemUpdated(TodoItem) {}
+
+func (NopEventSink) ItemDeleted(ItemID) {}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// List is a named group of TodoItems. The TodoItems refer to their List with ListID.
+type List struct {
+	ID        int       `json:"id" xml:"id"`
+	Name      string    `json:"name" xml:"name"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// ListDeletePolicy tells what DeleteList does if the List still has TodoItems.
+type ListDeletePolicy int
+
+const (
+	// BlockNonEmptyLists keeps the List and returns a ConflictError. This is the default.
+	BlockNonEmptyLists ListDeletePolicy = iota
+	// CascadeToItems deletes the TodoItems of the List along with it.
+	CascadeToItems
+)
+
+// ParseListDeletePolicy parses the name of a ListDeletePolicy, which is either "block" (BlockNonEmptyLists) or "cascade" (CascadeToItems).
+// An empty name means "block".
+func ParseListDeletePolicy(name string) (ListDeletePolicy, error) {
+	switch name {
+	case "", "block":
+		return BlockNonEmptyLists, nil
+	case "cascade":
+		return CascadeToItems, nil
+	default:
+		return 0, fmt.Errorf("unknown list delete policy %q", name)
+	}
+}
+
+// TheListCore is the implementation of the ListCore interface.
+type TheListCore struct {
+	accessor     ListAccessor
+	deletePolicy ListDeletePolicy
+	sinks        []EventSink
+}
+
+// NewListCore creates a ListCore on top of the accessor. The sinks are notified of the TodoItems deleted along with their Lists.
+func NewListCore(accessor ListAccessor, sinks ...EventSink) *TheListCore {
+	return &TheListCore{accessor: accessor, sinks: sinks}
+}
+
+// SetDeletePolicy sets what DeleteList does if the List still has TodoItems. The default is BlockNonEmptyLists.
+func (c *TheListCore) SetDeletePolicy(policy ListDeletePolicy) {
+	c.deletePolicy = policy
+}
+
+// validateListName normalizes the name like a description and records it as an invalid field if it's blank.
+func validateListName(fields fieldErrors, name string) string {
+	name = NormalizeDescription(name)
+	if name == "" {
+		fields["name"] = "required"
+	}
+	return name
+}
+
+// CreateList creates a new List with the name, normalized with NormalizeDescription, and returns it.
+// A ValidationError is returned if the name is blank.
+func (c *TheListCore) CreateList(name string) (List, error) {
+	log.WithFields(log.Fields{"name": name}).Info("CORE: Adding new List.")
+	fields := fieldErrors{}
+	list := List{Name: validateListName(fields, name)}
+	if err := fields.err(); err != nil {
+		log.Warn("CORE: ", err)
+		return List{}, err
+	}
+	if err := c.accessor.CreateList(&list); err != nil {
+		log.Warn("CORE: ", err)
+		return List{}, wrapStorageError(err)
+	}
+	return list, nil
+}
+
+// GetList returns the List with the specified id, or a ListNotFoundError if there's no such List.
+func (c *TheListCore) GetList(id int) (List, error) {
+	log.WithFields(log.Fields{"id": id}).Info("CORE: Getting List.")
+	list, err := c.accessor.ReadList(id)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return List{}, wrapStorageError(err)
+	}
+	return list, nil
+}
+
+// GetLists returns all the Lists, ordered by id.
+func (c *TheListCore) GetLists() ([]List, error) {
+	log.Info("CORE: Getting Lists.")
+	lists, err := c.accessor.ReadLists()
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return nil, wrapStorageError(err)
+	}
+	return lists, nil
+}
+
+// RenameList sets the name of the List with the specified id, normalized with NormalizeDescription, and returns the updated List.
+// A ValidationError is returned if the name is blank.
+func (c *TheListCore) RenameList(id int, name string) (List, error) {
+	log.WithFields(log.Fields{"id": id, "name": name}).Info("CORE: Renaming List.")
+	fields := fieldErrors{}
+	name = validateListName(fields, name)
+	if err := fields.err(); err != nil {
+		log.Warn("CORE: ", err)
+		return List{}, err
+	}
+	list, err := c.accessor.RenameList(id, name)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return List{}, wrapStorageError(err)
+	}
+	return list, nil
+}
+
+// DeleteList deletes the List with the specified id. What happens to its TodoItems depends on the ListDeletePolicy:
+// either a ConflictError is returned if there's any, or they are deleted as well.
+func (c *TheListCore) DeleteList(id int) error {
+	log.WithFields(log.Fields{"id": id, "cascade": c.deletePolicy == CascadeToItems}).Info("CORE: Deleting List.")
+	deletedIDs, err := c.accessor.DeleteList(id, c.deletePolicy == CascadeToItems)
+	if err != nil {
+		log.Warn("CORE: ", err)
+		return wrapStorageError(err)
+	}
+	for _, itemID := range deletedIDs {
+		for _, sink := range c.sinks {
+			sink.ItemDeleted(itemID)
+		}
+	}
+	return nil
+}
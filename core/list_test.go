@@ -0,0 +1,213 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	core "todolist/core"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// listTestEnv is the test environment of the ListCore, with a fakeSink attached.
+type listTestEnv struct {
+	mockAccessor *MockListAccessor
+	core         *core.TheListCore
+	sink         *fakeSink
+}
+
+func newListTestEnv(t *testing.T) *listTestEnv {
+	ctrl := gomock.NewController(t)
+	mockAccessor := NewMockListAccessor(ctrl)
+	sink := &fakeSink{}
+	return &listTestEnv{mockAccessor, core.NewListCore(mockAccessor, sink), sink}
+}
+
+// TestParseListDeletePolicy Given the names of the policies, when ParseListDeletePolicy is called, then the corresponding policies are returned, or an error for an unknown name.
+func TestParseListDeletePolicy(t *testing.T) {
+	for name, want := range map[string]core.ListDeletePolicy{
+		"":        core.BlockNonEmptyLists,
+		"block":   core.BlockNonEmptyLists,
+		"cascade": core.CascadeToItems,
+	} {
+		got, err := core.ParseListDeletePolicy(name)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, want, got, name)
+		}
+	}
+	_, err := core.ParseListDeletePolicy("unknown")
+	assert.Error(t, err)
+}
+
+// TestCreateList Given a name with messy whitespace, when CreateList is called, then the list is created with the normalized name and returned.
+func TestCreateList(t *testing.T) {
+	// arrange
+	e := newListTestEnv(t)
+	e.mockAccessor.EXPECT().
+		CreateList(&core.List{Name: "Groceries for the week"}).
+		DoAndReturn(func(list *core.List) error {
+			list.ID = 1
+			return nil
+		})
+
+	// act
+	got, err := e.core.CreateList("  Groceries  for the week ")
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.List{ID: 1, Name: "Groceries for the week"}, got)
+	}
+}
+
+// TestCreateListBlankName Given a blank name, when CreateList is called, then a ValidationError on the name is returned without touching the storage.
+func TestCreateListBlankName(t *testing.T) {
+	// arrange
+	e := newListTestEnv(t)
+
+	// act
+	_, err := e.core.CreateList(" \t ")
+
+	// assert
+	var validationErr core.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, map[string]string{"name": "required"}, validationErr.Fields)
+	}
+}
+
+// TestGetListNotFound Given the storage accessor fails to find the list, when GetList is called, then the ListNotFoundError is returned as is.
+func TestGetListNotFound(t *testing.T) {
+	// arrange
+	e := newListTestEnv(t)
+	e.mockAccessor.EXPECT().
+		ReadList(1).
+		Return(core.List{}, core.ListNotFoundError{ID: 1})
+
+	// act
+	_, err := e.core.GetList(1)
+
+	// assert
+	assert.Equal(t, core.ListNotFoundError{ID: 1}, err)
+}
+
+// TestGetListsError Given the storage accessor fails to read the lists, when GetLists is called, then a StorageError wrapping the error is returned.
+func TestGetListsError(t *testing.T) {
+	// arrange
+	e := newListTestEnv(t)
+	storageErr := errors.New("error")
+	e.mockAccessor.EXPECT().
+		ReadLists().
+		Return(nil, storageErr)
+
+	// act
+	_, err := e.core.GetLists()
+
+	// assert
+	assert.IsType(t, core.StorageError{}, err)
+	assert.ErrorIs(t, err, storageErr)
+}
+
+// TestRenameList Given a list, when RenameList is called with a new name, then the renamed list is returned.
+func TestRenameList(t *testing.T) {
+	// arrange
+	e := newListTestEnv(t)
+	e.mockAccessor.EXPECT().
+		RenameList(1, "Shopping").
+		Return(core.List{ID: 1, Name: "Shopping"}, nil)
+
+	// act
+	got, err := e.core.RenameList(1, " Shopping ")
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.List{ID: 1, Name: "Shopping"}, got)
+	}
+}
+
+// TestDeleteListBlocked Given the default policy and the storage accessor refuses to delete a list with items, when DeleteList is called,
+// then the ConflictError is returned and the sink is not notified.
+func TestDeleteListBlocked(t *testing.T) {
+	// arrange
+	e := newListTestEnv(t)
+	e.mockAccessor.EXPECT().
+		DeleteList(1, false).
+		Return(nil, core.ConflictError{Message: "List with id 1 still has 2 TodoItems"})
+
+	// act
+	err := e.core.DeleteList(1)
+
+	// assert
+	assert.IsType(t, core.ConflictError{}, err)
+	assert.Empty(t, e.sink.deleted)
+}
+
+// TestDeleteListCascade Given the CascadeToItems policy, when DeleteList is called, then the items are deleted along with the list and the sink is notified of each of them.
+func TestDeleteListCascade(t *testing.T) {
+	// arrange
+	e := newListTestEnv(t)
+	e.core.SetDeletePolicy(core.CascadeToItems)
+	e.mockAccessor.EXPECT().
+		DeleteList(1, true).
+		Return([]core.ItemID{2, 5}, nil)
+
+	// act
+	err := e.core.DeleteList(1)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{2, 5}, e.sink.deleted)
+	}
+}
+
+// listingAccessor is a stub of a StorageAccessor that stores the Lists as well.
+type listingAccessor struct {
+	*MockStorageAccessor
+	*MockListAccessor
+}
+
+// TestMoveItemToMissingList Given an accessor that stores the lists, when MoveItem is called with a list that doesn't exist,
+// then a ValidationError on the list id is returned and the item is not updated.
+func TestMoveItemToMissingList(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	accessor := &listingAccessor{NewMockStorageAccessor(ctrl), NewMockListAccessor(ctrl)}
+	accessor.MockListAccessor.EXPECT().
+		ReadList(2).
+		Return(core.List{}, core.ListNotFoundError{ID: 2})
+	theCore := core.NewCore(accessor)
+
+	// act
+	_, err := theCore.MoveItem(1, 2)
+
+	// assert
+	var validationErr core.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Contains(t, validationErr.Fields, "list_id")
+	}
+}
+
+// TestMoveItemToList Given an accessor that stores the lists, when MoveItem is called with an existing list, then the item is moved to the list.
+func TestMoveItemToList(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	accessor := &listingAccessor{NewMockStorageAccessor(ctrl), NewMockListAccessor(ctrl)}
+	accessor.MockListAccessor.EXPECT().
+		ReadList(2).
+		Return(core.List{ID: 2, Name: "Work"}, nil)
+	stored := core.TodoItem{ID: 1, Description: "some description"}
+	accessor.MockStorageAccessor.EXPECT().
+		UpdateWith(1, gomock.Any()).
+		DoAndReturn(func(_ int, modify func(*core.TodoItem)) (core.TodoItem, error) {
+			modify(&stored)
+			return stored, nil
+		})
+	theCore := core.NewCore(accessor)
+
+	// act
+	got, err := theCore.MoveItem(1, 2)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, got.ListID)
+	}
+}
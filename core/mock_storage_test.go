@@ -11,6 +11,7 @@ package core_test
 
 import (
 	reflect "reflect"
+	time "time"
 
 	core "todolist/core"
 
@@ -40,11 +41,26 @@ func (m *MockStorageAccessor) EXPECT() *MockStorageAccessorMockRecorder {
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockStorageAccessor) Count(f core.ItemFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", f)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockStorageAccessorMockRecorder) Count(f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockStorageAccessor)(nil).Count), f)
+}
+
 // Create mocks base method.
-func (m *MockStorageAccessor) Create(arg0 *core.TodoItem) (int, error) {
+func (m *MockStorageAccessor) Create(arg0 *core.TodoItem) (core.ItemID, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", arg0)
-	ret0, _ := ret[0].(int)
+	ret0, _ := ret[0].(core.ItemID)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -55,8 +71,37 @@ func (mr *MockStorageAccessorMockRecorder) Create(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStorageAccessor)(nil).Create), arg0)
 }
 
+// CreateAll mocks base method.
+func (m *MockStorageAccessor) CreateAll(todos []core.TodoItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAll", todos)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAll indicates an expected call of CreateAll.
+func (mr *MockStorageAccessorMockRecorder) CreateAll(todos any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAll", reflect.TypeOf((*MockStorageAccessor)(nil).CreateAll), todos)
+}
+
+// CreateUnique mocks base method.
+func (m *MockStorageAccessor) CreateUnique(arg0 *core.TodoItem) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUnique", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUnique indicates an expected call of CreateUnique.
+func (mr *MockStorageAccessorMockRecorder) CreateUnique(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUnique", reflect.TypeOf((*MockStorageAccessor)(nil).CreateUnique), arg0)
+}
+
 // Delete mocks base method.
-func (m *MockStorageAccessor) Delete(id int) error {
+func (m *MockStorageAccessor) Delete(id core.ItemID) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", id)
 	ret0, _ := ret[0].(error)
@@ -69,6 +114,51 @@ func (mr *MockStorageAccessorMockRecorder) Delete(id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStorageAccessor)(nil).Delete), id)
 }
 
+// DeleteCompleted mocks base method.
+func (m *MockStorageAccessor) DeleteCompleted() ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompleted")
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCompleted indicates an expected call of DeleteCompleted.
+func (mr *MockStorageAccessorMockRecorder) DeleteCompleted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompleted", reflect.TypeOf((*MockStorageAccessor)(nil).DeleteCompleted))
+}
+
+// DeleteCompletedBefore mocks base method.
+func (m *MockStorageAccessor) DeleteCompletedBefore(before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompletedBefore", before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCompletedBefore indicates an expected call of DeleteCompletedBefore.
+func (mr *MockStorageAccessorMockRecorder) DeleteCompletedBefore(before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompletedBefore", reflect.TypeOf((*MockStorageAccessor)(nil).DeleteCompletedBefore), before)
+}
+
+// Exists mocks base method.
+func (m *MockStorageAccessor) Exists(id core.ItemID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockStorageAccessorMockRecorder) Exists(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockStorageAccessor)(nil).Exists), id)
+}
+
 // Read mocks base method.
 func (m *MockStorageAccessor) Read(where func(core.TodoItem) bool) []core.TodoItem {
 	m.ctrl.T.Helper()
@@ -83,6 +173,255 @@ func (mr *MockStorageAccessorMockRecorder) Read(where any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStorageAccessor)(nil).Read), where)
 }
 
+// ReadAfter mocks base method.
+func (m *MockStorageAccessor) ReadAfter(cursor core.ItemID, limit int) []core.TodoItem {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAfter", cursor, limit)
+	ret0, _ := ret[0].([]core.TodoItem)
+	return ret0
+}
+
+// ReadAfter indicates an expected call of ReadAfter.
+func (mr *MockStorageAccessorMockRecorder) ReadAfter(cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAfter", reflect.TypeOf((*MockStorageAccessor)(nil).ReadAfter), cursor, limit)
+}
+
+// ReadAll mocks base method.
+func (m *MockStorageAccessor) ReadAll() ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAll")
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll.
+func (mr *MockStorageAccessorMockRecorder) ReadAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockStorageAccessor)(nil).ReadAll))
+}
+
+// ReadByIDs mocks base method.
+func (m *MockStorageAccessor) ReadByIDs(ids []core.ItemID) []core.TodoItem {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByIDs", ids)
+	ret0, _ := ret[0].([]core.TodoItem)
+	return ret0
+}
+
+// ReadByIDs indicates an expected call of ReadByIDs.
+func (mr *MockStorageAccessorMockRecorder) ReadByIDs(ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByIDs", reflect.TypeOf((*MockStorageAccessor)(nil).ReadByIDs), ids)
+}
+
+// ReadChangedSince mocks base method.
+func (m *MockStorageAccessor) ReadChangedSince(since time.Time) ([]core.TodoItem, []core.ItemID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadChangedSince", since)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].([]core.ItemID)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadChangedSince indicates an expected call of ReadChangedSince.
+func (mr *MockStorageAccessorMockRecorder) ReadChangedSince(since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChangedSince", reflect.TypeOf((*MockStorageAccessor)(nil).ReadChangedSince), since)
+}
+
+// ReadCompletedBetween mocks base method.
+func (m *MockStorageAccessor) ReadCompletedBetween(start, end time.Time) []core.TodoItem {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCompletedBetween", start, end)
+	ret0, _ := ret[0].([]core.TodoItem)
+	return ret0
+}
+
+// ReadCompletedBetween indicates an expected call of ReadCompletedBetween.
+func (mr *MockStorageAccessorMockRecorder) ReadCompletedBetween(start, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCompletedBetween", reflect.TypeOf((*MockStorageAccessor)(nil).ReadCompletedBetween), start, end)
+}
+
+// ReadDescriptionsWithPrefix mocks base method.
+func (m *MockStorageAccessor) ReadDescriptionsWithPrefix(prefix string, limit int) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDescriptionsWithPrefix", prefix, limit)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ReadDescriptionsWithPrefix indicates an expected call of ReadDescriptionsWithPrefix.
+func (mr *MockStorageAccessorMockRecorder) ReadDescriptionsWithPrefix(prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDescriptionsWithPrefix", reflect.TypeOf((*MockStorageAccessor)(nil).ReadDescriptionsWithPrefix), prefix, limit)
+}
+
+// ReadFiltered mocks base method.
+func (m *MockStorageAccessor) ReadFiltered(f core.ItemFilter) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFiltered", f)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFiltered indicates an expected call of ReadFiltered.
+func (mr *MockStorageAccessorMockRecorder) ReadFiltered(f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFiltered", reflect.TypeOf((*MockStorageAccessor)(nil).ReadFiltered), f)
+}
+
+// ReadPage mocks base method.
+func (m *MockStorageAccessor) ReadPage(offset, limit int) ([]core.TodoItem, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPage", offset, limit)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadPage indicates an expected call of ReadPage.
+func (mr *MockStorageAccessorMockRecorder) ReadPage(offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPage", reflect.TypeOf((*MockStorageAccessor)(nil).ReadPage), offset, limit)
+}
+
+// ReadQuery mocks base method.
+func (m *MockStorageAccessor) ReadQuery(q core.Query) ([]core.TodoItem, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadQuery", q)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadQuery indicates an expected call of ReadQuery.
+func (mr *MockStorageAccessorMockRecorder) ReadQuery(q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadQuery", reflect.TypeOf((*MockStorageAccessor)(nil).ReadQuery), q)
+}
+
+// ReadStream mocks base method.
+func (m *MockStorageAccessor) ReadStream(where func(core.TodoItem) bool, fn func(core.TodoItem) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadStream", where, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadStream indicates an expected call of ReadStream.
+func (mr *MockStorageAccessorMockRecorder) ReadStream(where, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadStream", reflect.TypeOf((*MockStorageAccessor)(nil).ReadStream), where, fn)
+}
+
+// Reindex mocks base method.
+func (m *MockStorageAccessor) Reindex() (core.ReindexReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reindex")
+	ret0, _ := ret[0].(core.ReindexReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reindex indicates an expected call of Reindex.
+func (mr *MockStorageAccessorMockRecorder) Reindex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reindex", reflect.TypeOf((*MockStorageAccessor)(nil).Reindex))
+}
+
+// Reorder mocks base method.
+func (m *MockStorageAccessor) Reorder(ids []core.ItemID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reorder", ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reorder indicates an expected call of Reorder.
+func (mr *MockStorageAccessorMockRecorder) Reorder(ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reorder", reflect.TypeOf((*MockStorageAccessor)(nil).Reorder), ids)
+}
+
+// ReplaceAll mocks base method.
+func (m *MockStorageAccessor) ReplaceAll(todos []core.TodoItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceAll", todos)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceAll indicates an expected call of ReplaceAll.
+func (mr *MockStorageAccessorMockRecorder) ReplaceAll(todos any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceAll", reflect.TypeOf((*MockStorageAccessor)(nil).ReplaceAll), todos)
+}
+
+// Reset mocks base method.
+func (m *MockStorageAccessor) Reset() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockStorageAccessorMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockStorageAccessor)(nil).Reset))
+}
+
+// Restore mocks base method.
+func (m *MockStorageAccessor) Restore(todos []core.TodoItem) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", todos)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockStorageAccessorMockRecorder) Restore(todos any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockStorageAccessor)(nil).Restore), todos)
+}
+
+// Stats mocks base method.
+func (m *MockStorageAccessor) Stats() (core.BackendStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(core.BackendStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStorageAccessorMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStorageAccessor)(nil).Stats))
+}
+
+// Transaction mocks base method.
+func (m *MockStorageAccessor) Transaction(fn func(core.StorageAccessor) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockStorageAccessorMockRecorder) Transaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockStorageAccessor)(nil).Transaction), fn)
+}
+
 // Update mocks base method.
 func (m *MockStorageAccessor) Update(todo core.TodoItem) error {
 	m.ctrl.T.Helper()
@@ -96,3 +435,331 @@ func (mr *MockStorageAccessorMockRecorder) Update(todo any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockStorageAccessor)(nil).Update), todo)
 }
+
+// UpdateCompleted mocks base method.
+func (m *MockStorageAccessor) UpdateCompleted(ids []core.ItemID, completed bool, at time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCompleted", ids, completed, at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCompleted indicates an expected call of UpdateCompleted.
+func (mr *MockStorageAccessorMockRecorder) UpdateCompleted(ids, completed, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompleted", reflect.TypeOf((*MockStorageAccessor)(nil).UpdateCompleted), ids, completed, at)
+}
+
+// UpdateWith mocks base method.
+func (m *MockStorageAccessor) UpdateWith(id core.ItemID, modify func(*core.TodoItem)) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWith", id, modify)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWith indicates an expected call of UpdateWith.
+func (mr *MockStorageAccessorMockRecorder) UpdateWith(id, modify any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWith", reflect.TypeOf((*MockStorageAccessor)(nil).UpdateWith), id, modify)
+}
+
+// Upsert mocks base method.
+func (m *MockStorageAccessor) Upsert(id core.ItemID, modify func(*core.TodoItem)) (core.TodoItem, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", id, modify)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockStorageAccessorMockRecorder) Upsert(id, modify any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockStorageAccessor)(nil).Upsert), id, modify)
+}
+
+// MockListAccessor is a mock of ListAccessor interface.
+type MockListAccessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockListAccessorMockRecorder
+}
+
+// MockListAccessorMockRecorder is the mock recorder for MockListAccessor.
+type MockListAccessorMockRecorder struct {
+	mock *MockListAccessor
+}
+
+// NewMockListAccessor creates a new mock instance.
+func NewMockListAccessor(ctrl *gomock.Controller) *MockListAccessor {
+	mock := &MockListAccessor{ctrl: ctrl}
+	mock.recorder = &MockListAccessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockListAccessor) EXPECT() *MockListAccessorMockRecorder {
+	return m.recorder
+}
+
+// CreateList mocks base method.
+func (m *MockListAccessor) CreateList(arg0 *core.List) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateList", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateList indicates an expected call of CreateList.
+func (mr *MockListAccessorMockRecorder) CreateList(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateList", reflect.TypeOf((*MockListAccessor)(nil).CreateList), arg0)
+}
+
+// DeleteList mocks base method.
+func (m *MockListAccessor) DeleteList(id int, cascade bool) ([]core.ItemID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteList", id, cascade)
+	ret0, _ := ret[0].([]core.ItemID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteList indicates an expected call of DeleteList.
+func (mr *MockListAccessorMockRecorder) DeleteList(id, cascade any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteList", reflect.TypeOf((*MockListAccessor)(nil).DeleteList), id, cascade)
+}
+
+// ReadList mocks base method.
+func (m *MockListAccessor) ReadList(id int) (core.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadList", id)
+	ret0, _ := ret[0].(core.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadList indicates an expected call of ReadList.
+func (mr *MockListAccessorMockRecorder) ReadList(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadList", reflect.TypeOf((*MockListAccessor)(nil).ReadList), id)
+}
+
+// ReadLists mocks base method.
+func (m *MockListAccessor) ReadLists() ([]core.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLists")
+	ret0, _ := ret[0].([]core.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLists indicates an expected call of ReadLists.
+func (mr *MockListAccessorMockRecorder) ReadLists() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLists", reflect.TypeOf((*MockListAccessor)(nil).ReadLists))
+}
+
+// RenameList mocks base method.
+func (m *MockListAccessor) RenameList(id int, name string) (core.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameList", id, name)
+	ret0, _ := ret[0].(core.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameList indicates an expected call of RenameList.
+func (mr *MockListAccessorMockRecorder) RenameList(id, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameList", reflect.TypeOf((*MockListAccessor)(nil).RenameList), id, name)
+}
+
+// MockBlockerAccessor is a mock of BlockerAccessor interface.
+type MockBlockerAccessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockerAccessorMockRecorder
+}
+
+// MockBlockerAccessorMockRecorder is the mock recorder for MockBlockerAccessor.
+type MockBlockerAccessorMockRecorder struct {
+	mock *MockBlockerAccessor
+}
+
+// NewMockBlockerAccessor creates a new mock instance.
+func NewMockBlockerAccessor(ctrl *gomock.Controller) *MockBlockerAccessor {
+	mock := &MockBlockerAccessor{ctrl: ctrl}
+	mock.recorder = &MockBlockerAccessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlockerAccessor) EXPECT() *MockBlockerAccessorMockRecorder {
+	return m.recorder
+}
+
+// AddBlockers mocks base method.
+func (m *MockBlockerAccessor) AddBlockers(id core.ItemID, blockerIDs []core.ItemID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBlockers", id, blockerIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddBlockers indicates an expected call of AddBlockers.
+func (mr *MockBlockerAccessorMockRecorder) AddBlockers(id, blockerIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBlockers", reflect.TypeOf((*MockBlockerAccessor)(nil).AddBlockers), id, blockerIDs)
+}
+
+// ReadBlocked mocks base method.
+func (m *MockBlockerAccessor) ReadBlocked() ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadBlocked")
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadBlocked indicates an expected call of ReadBlocked.
+func (mr *MockBlockerAccessorMockRecorder) ReadBlocked() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBlocked", reflect.TypeOf((*MockBlockerAccessor)(nil).ReadBlocked))
+}
+
+// ReadBlockers mocks base method.
+func (m *MockBlockerAccessor) ReadBlockers(id core.ItemID) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadBlockers", id)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadBlockers indicates an expected call of ReadBlockers.
+func (mr *MockBlockerAccessorMockRecorder) ReadBlockers(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBlockers", reflect.TypeOf((*MockBlockerAccessor)(nil).ReadBlockers), id)
+}
+
+// MockAuditAccessor is a mock of AuditAccessor interface.
+type MockAuditAccessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditAccessorMockRecorder
+}
+
+// MockAuditAccessorMockRecorder is the mock recorder for MockAuditAccessor.
+type MockAuditAccessorMockRecorder struct {
+	mock *MockAuditAccessor
+}
+
+// NewMockAuditAccessor creates a new mock instance.
+func NewMockAuditAccessor(ctrl *gomock.Controller) *MockAuditAccessor {
+	mock := &MockAuditAccessor{ctrl: ctrl}
+	mock.recorder = &MockAuditAccessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditAccessor) EXPECT() *MockAuditAccessorMockRecorder {
+	return m.recorder
+}
+
+// ReadAudit mocks base method.
+func (m *MockAuditAccessor) ReadAudit(itemID core.ItemID) ([]core.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAudit", itemID)
+	ret0, _ := ret[0].([]core.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAudit indicates an expected call of ReadAudit.
+func (mr *MockAuditAccessorMockRecorder) ReadAudit(itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAudit", reflect.TypeOf((*MockAuditAccessor)(nil).ReadAudit), itemID)
+}
+
+// RecordAudit mocks base method.
+func (m *MockAuditAccessor) RecordAudit(entry core.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockAuditAccessorMockRecorder) RecordAudit(entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockAuditAccessor)(nil).RecordAudit), entry)
+}
+
+// MockAttachmentAccessor is a mock of AttachmentAccessor interface.
+type MockAttachmentAccessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentAccessorMockRecorder
+}
+
+// MockAttachmentAccessorMockRecorder is the mock recorder for MockAttachmentAccessor.
+type MockAttachmentAccessorMockRecorder struct {
+	mock *MockAttachmentAccessor
+}
+
+// NewMockAttachmentAccessor creates a new mock instance.
+func NewMockAttachmentAccessor(ctrl *gomock.Controller) *MockAttachmentAccessor {
+	mock := &MockAttachmentAccessor{ctrl: ctrl}
+	mock.recorder = &MockAttachmentAccessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentAccessor) EXPECT() *MockAttachmentAccessorMockRecorder {
+	return m.recorder
+}
+
+// AddAttachment mocks base method.
+func (m *MockAttachmentAccessor) AddAttachment(id core.ItemID, url string, max int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAttachment", id, url, max)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAttachment indicates an expected call of AddAttachment.
+func (mr *MockAttachmentAccessorMockRecorder) AddAttachment(id, url, max any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAttachment", reflect.TypeOf((*MockAttachmentAccessor)(nil).AddAttachment), id, url, max)
+}
+
+// ReadAttachments mocks base method.
+func (m *MockAttachmentAccessor) ReadAttachments(ids []core.ItemID) (map[core.ItemID][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAttachments", ids)
+	ret0, _ := ret[0].(map[core.ItemID][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAttachments indicates an expected call of ReadAttachments.
+func (mr *MockAttachmentAccessorMockRecorder) ReadAttachments(ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAttachments", reflect.TypeOf((*MockAttachmentAccessor)(nil).ReadAttachments), ids)
+}
+
+// RemoveAttachment mocks base method.
+func (m *MockAttachmentAccessor) RemoveAttachment(id core.ItemID, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAttachment", id, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAttachment indicates an expected call of RemoveAttachment.
+func (mr *MockAttachmentAccessorMockRecorder) RemoveAttachment(id, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAttachment", reflect.TypeOf((*MockAttachmentAccessor)(nil).RemoveAttachment), id, url)
+}
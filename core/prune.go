@@ -0,0 +1,32 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultPruneInterval is how often PruneCompleted prunes the completed TodoItems.
+const DefaultPruneInterval = time.Hour
+
+// PruneCompleted deletes the TodoItems completed longer than retention ago with PruneCompletedOlderThan, once right away and then on every interval,
+// until the context is done. Nothing is pruned if the retention is not positive. It blocks, so it's meant to be run in its own goroutine.
+func PruneCompleted(ctx context.Context, c Core, retention, interval time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := c.PruneCompletedOlderThan(retention)
+		if err == nil && n > 0 {
+			log.WithFields(log.Fields{"pruned": n}).Info("CORE: Pruned completed TodoItems.")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DescriptionReplacement is a find-and-replace in the descriptions of the TodoItems; see Core.ReplaceInDescriptions.
+type DescriptionReplacement struct {
+	Find    string
+	Replace string
+	// IgnoreCase finds the text regardless of its case, e.g., "projx" finds "ProjX" as well.
+	IgnoreCase bool
+	// DryRun only tells which TodoItems would be changed, and how, without changing them.
+	DryRun bool
+}
+
+// replacer returns a function that replaces every occurrence of the text to find in a description and normalizes the result with NormalizeDescription,
+// as any description is before it's stored. The pattern to find the text regardless of its case is compiled only once, here.
+func (r DescriptionReplacement) replacer() func(description string) string {
+	replaceAll := func(s string) string {
+		return strings.ReplaceAll(s, r.Find, r.Replace)
+	}
+	if r.IgnoreCase {
+		find := regexp.MustCompile("(?i)" + regexp.QuoteMeta(r.Find))
+		replaceAll = func(s string) string {
+			return find.ReplaceAllLiteralString(s, r.Replace)
+		}
+	}
+	return func(description string) string {
+		return NormalizeDescription(replaceAll(description))
+	}
+}
+
+// ReplaceInDescriptions replaces every occurrence of the text to find in the descriptions of the TodoItems, all in a single transaction,
+// and returns the changed TodoItems as updated, ordered by id. If it's a dry run, nothing is changed and the TodoItems are returned as they would be.
+// The replaced descriptions are normalized, e.g., the spaces left around a removed word are collapsed.
+// A ValidationError is returned if the text to find is empty, or if any of the descriptions would become blank, in which case none is changed.
+func (c *TheCore) ReplaceInDescriptions(r DescriptionReplacement) ([]TodoItem, error) {
+	log.WithFields(log.Fields{"find": r.Find, "replace": r.Replace, "ignore_case": r.IgnoreCase, "dry_run": r.DryRun}).Info("CORE: Replacing in descriptions of TodoItems.")
+	if r.Find == "" {
+		err := ValidationError{Message: "validation failed", Fields: map[string]string{"find": "required"}}
+		log.Warn("CORE: ", err)
+		return nil, err
+	}
+	apply := r.replacer()
+	var changed []TodoItem
+	err := c.accessor.Transaction(func(tx StorageAccessor) error {
+		// NOTE: The events are held back until the transaction commits, as in Batch.
+		txCore := *c
+		txCore.accessor = tx
+		txCore.sinks = nil
+		changed = nil
+		matches := tx.Read(func(todo TodoItem) bool {
+			return apply(todo.Description) != todo.Description
+		})
+		for _, todo := range matches {
+			if r.DryRun {
+				todo.Description = apply(todo.Description)
+			} else {
+				var err error
+				todo, err = txCore.audited().UpdateWith(todo.ID, func(todo *TodoItem) {
+					todo.Description = apply(todo.Description)
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if todo.Description == "" {
+				return ValidationError{Message: "validation failed", Fields: map[string]string{"replace": fmt.Sprintf("would leave the description of TodoItem %d blank", todo.ID)}}
+			}
+			changed = append(changed, todo)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("CORE: ", err)
+		var validationErr ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, validationErr
+		}
+		return nil, wrapStorageError(err)
+	}
+	if !r.DryRun {
+		for _, todo := range changed {
+			c.emitUpdated(todo)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,164 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	core "todolist/core"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// expectReadAmong Expects Read to be called, returning the stored items that meet the condition as the storage accessor would.
+func (e *testEnv) expectReadAmong(stored ...core.TodoItem) *gomock.Call {
+	return e.mockAccessor.EXPECT().
+		Read(gomock.Any()).
+		DoAndReturn(func(where func(core.TodoItem) bool) []core.TodoItem {
+			var todos []core.TodoItem
+			for _, todo := range stored {
+				if where(todo) {
+					todos = append(todos, todo)
+				}
+			}
+			return todos
+		})
+}
+
+// TestReplaceInDescriptions Given items of which two mention the text to find, when ReplaceInDescriptions is called,
+// then only those two are updated in a transaction, returned, and reported to the sink.
+func TestReplaceInDescriptions(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	first := core.TodoItem{ID: 1, Description: "ProjX: write the spec"}
+	second := core.TodoItem{ID: 2, Description: "Buy milk"}
+	third := core.TodoItem{ID: 3, Description: "Ship ProjX, then review ProjX"}
+	e.expectTransaction()
+	e.expectReadAmong(first, second, third)
+	e.expectUpdateWith(&first)
+	e.expectUpdateWith(&third)
+
+	// act
+	got, err := e.core.ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: "ProjY"})
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 2) {
+		assert.Equal(t, "ProjY: write the spec", got[0].Description)
+		assert.Equal(t, "Ship ProjY, then review ProjY", got[1].Description)
+		assert.Equal(t, got, sink.updated)
+	}
+}
+
+// TestReplaceInDescriptionsIgnoreCase Given an item that mentions the text to find in another case, when ReplaceInDescriptions is called ignoring the case,
+// then the text is replaced whatever its case.
+func TestReplaceInDescriptionsIgnoreCase(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	stored := core.TodoItem{ID: 1, Description: "projx and PROJX"}
+	e.expectTransaction()
+	e.expectReadAmong(stored)
+	e.expectUpdateWith(&stored)
+
+	// act
+	got, err := e.core.ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: "ProjY", IgnoreCase: true})
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 1) {
+		assert.Equal(t, "ProjY and ProjY", got[0].Description)
+	}
+}
+
+// TestReplaceInDescriptionsDryRun Given an item that mentions the text to find, when ReplaceInDescriptions is called as a dry run,
+// then the item is previewed as it would be, but not updated nor reported to the sink.
+func TestReplaceInDescriptionsDryRun(t *testing.T) {
+	// arrange
+	e, sink := newTestEnvWithSink(t)
+	stored := core.TodoItem{ID: 1, Description: "ProjX: write the spec"}
+	e.expectTransaction()
+	e.expectReadAmong(stored)
+	e.mockAccessor.EXPECT().UpdateWith(gomock.Any(), gomock.Any()).Times(0)
+
+	// act
+	got, err := e.core.ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: "ProjY", DryRun: true})
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 1) {
+		assert.Equal(t, "ProjY: write the spec", got[0].Description)
+		assert.Equal(t, "ProjX: write the spec", stored.Description)
+		assert.Empty(t, sink.updated)
+	}
+}
+
+// TestReplaceInDescriptionsNoMatch Given no item mentions the text to find, when ReplaceInDescriptions is called, then nothing is updated nor returned.
+func TestReplaceInDescriptionsNoMatch(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.expectTransaction()
+	e.expectReadAmong(core.TodoItem{ID: 1, Description: "Buy milk"})
+	e.mockAccessor.EXPECT().UpdateWith(gomock.Any(), gomock.Any()).Times(0)
+
+	// act
+	got, err := e.core.ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: "ProjY"})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+// TestReplaceInDescriptionsNormalized Given an item that mentions the text to find between two words, when ReplaceInDescriptions replaces it with nothing,
+// then the description is normalized, without the spaces doubled.
+func TestReplaceInDescriptionsNormalized(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	stored := core.TodoItem{ID: 1, Description: "Write the ProjX spec"}
+	e.expectTransaction()
+	e.expectReadAmong(stored)
+	e.expectUpdateWith(&stored)
+
+	// act
+	got, err := e.core.ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX"})
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 1) {
+		assert.Equal(t, "Write the spec", got[0].Description)
+	}
+}
+
+// TestReplaceInDescriptionsBlank Given an item whose whole description is the text to find, when ReplaceInDescriptions replaces it with nothing or only whitespace,
+// then a ValidationError is returned and the transaction is rolled back.
+func TestReplaceInDescriptionsBlank(t *testing.T) {
+	for name, replace := range map[string]string{"nothing": "", "whitespace": " \t "} {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			stored := core.TodoItem{ID: 1, Description: "ProjX"}
+			e.expectTransaction()
+			e.expectReadAmong(stored)
+			e.expectUpdateWith(&stored)
+
+			// act
+			_, err := e.core.ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: replace})
+
+			// assert
+			var validationErr core.ValidationError
+			if assert.True(t, errors.As(err, &validationErr)) {
+				assert.Contains(t, validationErr.Fields, "replace")
+			}
+		})
+	}
+}
+
+// TestReplaceInDescriptionsEmptyFind Given an empty text to find, when ReplaceInDescriptions is called, then a ValidationError is returned without touching the storage.
+func TestReplaceInDescriptionsEmptyFind(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+
+	// act
+	_, err := e.core.ReplaceInDescriptions(core.DescriptionReplacement{Replace: "ProjY"})
+
+	// assert
+	var validationErr core.ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		assert.Equal(t, map[string]string{"find": "required"}, validationErr.Fields)
+	}
+}
@@ -1,13 +1,173 @@
 package core
 
+import (
+	"context"
+	"time"
+)
+
 // StorageAccessor is an interface that defines the functions that the core package will use to interact with the storage layer.
 type StorageAccessor interface {
 	// Create creates a new TodoItem and returns the id of the new TodoItem. The id is also updated in the TodoItem.
-	Create(*TodoItem) (id int, e error)
-	// Read returns a list of TodoItems that satisfy the condition specified by the where function.
+	Create(*TodoItem) (id ItemID, e error)
+	// CreateAll creates the TodoItems at once and fills in their ids and timestamps. Either all of them are created or none is.
+	CreateAll(todos []TodoItem) error
+	// CreateUnique is Create unless there's an incomplete TodoItem with the same description, compared trimmed and case-insensitively,
+	// in which case nothing is created and the TodoItem is filled with the existing one. The check and the creation are done in one transaction,
+	// but nothing in the storage enforces the uniqueness, so the concurrent calls with the same description may still create a duplicate each.
+	CreateUnique(*TodoItem) (created bool, e error)
+	// Read returns a list of TodoItems that satisfy the condition specified by the where function, ordered by id.
 	Read(where func(TodoItem) bool) []TodoItem
+	// ReadAll returns all the TodoItems, ordered by id.
+	ReadAll() ([]TodoItem, error)
+	// ReadStream calls fn with the TodoItems that meet the where condition one at a time, ordered by id, without holding all of them in memory.
+	// It stops at the first error returned by fn and returns it.
+	ReadStream(where func(TodoItem) bool, fn func(TodoItem) error) error
+	// Exists tells whether there's a TodoItem with the id, without reading it.
+	Exists(id ItemID) (bool, error)
+	// ReadByIDs returns the TodoItems whose id is in ids, ordered by id. Ids that don't exist are ignored.
+	ReadByIDs(ids []ItemID) []TodoItem
+	// ReadFiltered returns the TodoItems that meet all the criteria of the filter, ordered by id.
+	ReadFiltered(f ItemFilter) ([]TodoItem, error)
+	// ReadCompletedBetween returns the TodoItems completed within [start, end), ordered by id.
+	ReadCompletedBetween(start, end time.Time) []TodoItem
+	// ReadChangedSince returns the TodoItems created or updated after since, and the ids of the TodoItems deleted after since.
+	ReadChangedSince(since time.Time) (changed []TodoItem, deletedIDs []ItemID, e error)
+	// ReadAfter returns at most limit TodoItems whose id is greater than cursor, ordered by id.
+	ReadAfter(cursor ItemID, limit int) []TodoItem
+	// ReadPage returns at most limit TodoItems ordered by id, skipping the first offset ones, and the total number of TodoItems.
+	ReadPage(offset, limit int) (todos []TodoItem, total int, e error)
+	// ReadQuery returns the TodoItems that meet the filter of the query, in its order, within its window,
+	// and the number of TodoItems that meet the filter regardless of the window. The query is assumed to be valid; see Core.Query.
+	ReadQuery(q Query) (todos []TodoItem, total int, e error)
+	// ReadDescriptionsWithPrefix returns at most limit distinct descriptions that start with the prefix, case-insensitively,
+	// with the most recently created ones first.
+	ReadDescriptionsWithPrefix(prefix string, limit int) []string
+	// Count returns the number of TodoItems that meet the filter, counted by the storage without reading them.
+	Count(f ItemFilter) (int, error)
 	// Update updates a TodoItem with the new values specified in the todo parameter.
 	Update(todo TodoItem) error
+	// UpdateWith reads the TodoItem with the specified id, applies modify to it, and saves the result, all atomically,
+	// so that concurrent updates to the same TodoItem don't overwrite each other. The updated TodoItem is returned.
+	// A TodoItemNotFoundError is returned if there's no such TodoItem.
+	UpdateWith(id ItemID, modify func(*TodoItem)) (TodoItem, error)
+	// Upsert is UpdateWith if there's a TodoItem with the specified id. Otherwise, modify is applied to an empty TodoItem, which is then created with the id,
+	// or with a new one if the id is 0. The check and the creation are done atomically, so concurrent upserts of the same id create it only once.
+	// A ConflictError is returned if the TodoItem with the id is deleted.
+	Upsert(id ItemID, modify func(*TodoItem)) (todo TodoItem, created bool, e error)
+	// UpdateCompleted sets the completed status of the TodoItems whose id is in ids, recording at as the completion time of those that become completed.
+	// Returns the number of TodoItems whose status is changed; ids that don't exist are ignored.
+	UpdateCompleted(ids []ItemID, completed bool, at time.Time) (int, error)
+	// Reorder sets the position of each TodoItem to its index in ids. Either all positions are updated or none is;
+	// a TodoItemNotFoundError is returned if any of the ids doesn't exist.
+	Reorder(ids []ItemID) error
+	// ReplaceAll deletes all the TodoItems, including the deleted ones kept for ReadChangedSince, along with which of them block which and their attachments,
+	// and creates todos with their ids and timestamps.
+	// Either all of them are replaced or none is.
+	ReplaceAll(todos []TodoItem) error
 	// Delete deletes a TodoItem with the specified id.
-	Delete(id int) error
+	Delete(id ItemID) error
+	// DeleteCompleted deletes all the completed TodoItems and returns them as they were before the deletion.
+	DeleteCompleted() ([]TodoItem, error)
+	// DeleteCompletedBefore deletes the TodoItems completed before the time in a single statement and returns the number of deleted TodoItems.
+	// The TodoItems without a completion time are kept.
+	DeleteCompletedBefore(before time.Time) (int, error)
+	// Restore brings back the deleted TodoItems from their snapshots, undeleting the ones still kept as deleted and re-creating the others with new ids.
+	// The restored TodoItems are returned in the same order. Either all of them are restored or none is;
+	// a ConflictError is returned if any of the ids belongs to a TodoItem that is not deleted.
+	Restore(todos []TodoItem) ([]TodoItem, error)
+	// Reset permanently deletes all the TodoItems, including the deleted ones kept for ReadChangedSince, along with which of them block which and their attachments,
+	// and starts the ids over, as if the storage were just created.
+	Reset() error
+	// Reindex recomputes the fields derived from the others of all the TodoItems, including the deleted ones, in a single transaction,
+	// in case they drift out of sync, e.g., after the database is edited by hand. What's fixed is reported.
+	Reindex() (ReindexReport, error)
+	// Stats reports which backend is in use and how it's doing, for debugging.
+	Stats() (BackendStats, error)
+	// Transaction calls fn with a StorageAccessor whose operations are all kept if fn returns nil, or all undone otherwise.
+	// The error of fn is returned as is.
+	Transaction(fn func(StorageAccessor) error) error
+}
+
+// ListAccessor is an interface that defines the functions that the core package will use to store the Lists.
+// The StorageAccessors that support the Lists implement it as well, so that MoveItem can tell whether a List exists.
+type ListAccessor interface {
+	// CreateList creates a new List and fills in its id and timestamps.
+	CreateList(*List) error
+	// ReadList returns the List with the id, or a ListNotFoundError if there's no such List.
+	ReadList(id int) (List, error)
+	// ReadLists returns all the Lists, ordered by id.
+	ReadLists() ([]List, error)
+	// RenameList sets the name of the List with the id and returns the updated List, or a ListNotFoundError if there's no such List.
+	RenameList(id int, name string) (List, error)
+	// DeleteList deletes the List with the id and returns the ids of the TodoItems deleted along with it, or a ListNotFoundError if there's no such List.
+	// If cascade is false, a ConflictError is returned instead if the List has any TodoItem; otherwise its TodoItems are deleted as well.
+	// Either all of them are deleted or none is.
+	DeleteList(id int, cascade bool) (deletedIDs []ItemID, e error)
+}
+
+// BlockerAccessor is an interface that defines the functions that the core package will use to store which TodoItems block which.
+// The StorageAccessors that support the dependencies implement it as well, so that the completion of a blocked TodoItem can be refused.
+type BlockerAccessor interface {
+	// AddBlockers records that the TodoItem with the id is blocked by the TodoItems with the blocker ids, skipping the ones already recorded.
+	// A TodoItemNotFoundError is returned if any of the TodoItems doesn't exist, or a ConflictError if the TodoItem already blocks one of its blockers,
+	// maybe through other TodoItems, which would form a cycle. Either all of them are recorded or none is.
+	AddBlockers(id ItemID, blockerIDs []ItemID) error
+	// ReadBlockers returns the TodoItems that block the TodoItem with the id, ordered by id. The deleted ones are left out.
+	ReadBlockers(id ItemID) ([]TodoItem, error)
+	// ReadBlocked returns the TodoItems that are blocked by at least one incomplete TodoItem, ordered by id. The deleted ones are left out.
+	ReadBlocked() ([]TodoItem, error)
+}
+
+// AttachmentAccessor is an interface that defines the functions that the core package will use to store the URLs attached to the TodoItems.
+type AttachmentAccessor interface {
+	// AddAttachment attaches the URL to the TodoItem with the id; attaching it again does nothing. A TodoItemNotFoundError is returned if there's no such TodoItem,
+	// or a ConflictError if the TodoItem already has max attachments. The count is checked and the URL attached atomically.
+	AddAttachment(id ItemID, url string, max int) error
+	// RemoveAttachment detaches the URL from the TodoItem with the id; detaching one that is not attached does nothing.
+	// A TodoItemNotFoundError is returned if there's no such TodoItem.
+	RemoveAttachment(id ItemID, url string) error
+	// ReadAttachments returns the URLs attached to each of the TodoItems with the ids, in the order they were attached. The TodoItems without any are left out.
+	ReadAttachments(ids []ItemID) (map[ItemID][]string, error)
+}
+
+// AuditAccessor is an interface that defines the functions that the core package will use to keep the audit log of the mutations of the TodoItems.
+// The StorageAccessors that keep one implement it as well, including the ones of their transactions, so that the entries are recorded along with the mutations.
+// The entries are never changed nor removed once recorded, not even by Reset.
+type AuditAccessor interface {
+	// RecordAudit appends the entry to the audit log. The id of the entry is left to the storage.
+	RecordAudit(entry AuditEntry) error
+	// ReadAudit returns the entries of the TodoItem with the id, or of all the TodoItems if the id is 0, ordered by id, i.e., oldest first.
+	ReadAudit(itemID ItemID) ([]AuditEntry, error)
+}
+
+// Reconnector is implemented by the StorageAccessors that can re-establish their connection to the backend, e.g., after the database restarts.
+// TheCore reconnects and retries once if a read fails with a connection error.
+type Reconnector interface {
+	// Reconnect replaces the connection to the backend with a new one.
+	Reconnect() error
+}
+
+// ContextAccessor is implemented by the StorageAccessors whose operations can be cancelled, e.g., when the request they serve times out.
+type ContextAccessor interface {
+	// WithContext returns an accessor to the same storage whose operations are cancelled once ctx is done.
+	WithContext(ctx context.Context) StorageAccessor
+}
+
+// ReindexReport tells how many TodoItems are fixed by Reindex, for each kind of the derived fields.
+type ReindexReport struct {
+	// DescriptionKeys is the number of TodoItems whose key of the description for looking up the duplicates is recomputed.
+	DescriptionKeys int `json:"description_keys"`
+	// CompletedAt is the number of TodoItems whose completion time disagrees with their completed status,
+	// i.e., the completed ones without a completion time, which is taken from their last update, and the incomplete ones with one, which is cleared.
+	CompletedAt int `json:"completed_at"`
+}
+
+// BackendStats is the lightweight statistics of a storage backend.
+type BackendStats struct {
+	// Driver is the name of the backend, e.g., "mysql".
+	Driver string `json:"driver"`
+	// Items is the number of TodoItems stored, not counting the deleted ones.
+	Items int `json:"items"`
+	// OpenConnections is the number of connections to the database, both in use and idle. It's 0 for the backends that are not databases.
+	OpenConnections int `json:"open_connections"`
 }
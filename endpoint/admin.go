@@ -0,0 +1,409 @@
+package endpoint
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todolist/core"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetReadOnlyMode turns the read-only mode on or off at runtime. See ReadOnly.
+//
+// The mode is passed as a JSON body:
+//
+//	{"read_only": true}
+//
+// The response carries the mode in effect:
+//
+//	{"read_only": true}
+func SetReadOnlyMode(writer http.ResponseWriter, request *http.Request) {
+	var body struct {
+		ReadOnly *bool `json:"read_only"`
+	}
+	err := decodeJSON(request, &body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	if body.ReadOnly == nil {
+		writeError(writer, http.StatusBadRequest, errors.New("read_only is required"))
+		return
+	}
+
+	SetReadOnly(*body.ReadOnly)
+	log.WithFields(log.Fields{"read_only": *body.ReadOnly}).Warn("ADMIN: Read-only mode changed.")
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]bool{"read_only": IsReadOnly()})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// exportFlushInterval is the number of TodoItems after which ExportItems flushes the response.
+const exportFlushInterval = 100
+
+// ExportItems responds with all the TodoItems, ordered by id, so that they can be restored with ImportItems.
+//
+//	{"items": [{"id": 1, "description": "...", ...}, ...]}
+//
+// The TodoItems are streamed as they are read, so that exporting many TodoItems doesn't take much memory.
+// NOTE: If reading fails after some TodoItems are sent, the status code can't be changed anymore; the response is cut short instead.
+func ExportItems(writer http.ResponseWriter, request *http.Request) {
+	n := 0
+	begin := func() error {
+		writer.Header().Set("Content-Type", "application/json")
+		_, err := io.WriteString(writer, `{"items": [`)
+		return err
+	}
+	err := coreOf(request).ExportStream(func(todo core.TodoItem) error {
+		var err error
+		if n == 0 {
+			err = begin()
+		} else {
+			_, err = io.WriteString(writer, ",")
+		}
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(writer).Encode(todo); err != nil {
+			return err
+		}
+		n++
+		if n%exportFlushInterval == 0 {
+			_ = http.NewResponseController(writer).Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if n == 0 {
+			writeCoreError(writer, err)
+			return
+		}
+		log.Error("Error streaming the export: ", err)
+		return
+	}
+
+	if n == 0 {
+		err = begin()
+	}
+	if err == nil {
+		_, err = io.WriteString(writer, "]}\n")
+	}
+	if err != nil {
+		log.Error("Error writing response to client")
+	}
+}
+
+// ImportItems replaces all the TodoItems with the ones in the snapshot exported by ExportItems, keeping their ids.
+// Nothing is replaced if any of the TodoItems is invalid.
+//
+// The snapshot is passed as a JSON body, in the same format as the response of ExportItems.
+//
+// The response carries the number of imported TodoItems:
+//
+//	{"imported": 2}
+//
+// If the snapshot doesn't match snapshot.schema.json, the server responds with a 400 status code, telling what's wrong at the path of each offending value:
+//
+//	{"error": "snapshot doesn't match the schema", "fields": {"$.items[0]": "missing properties: 'description'", "$.items[1].completed": "expected boolean, but got string"}}
+func ImportItems(writer http.ResponseWriter, request *http.Request) {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	var instance any
+	err = json.Unmarshal(body, &instance)
+	if err != nil {
+		writeBodyError(writer, jsonBodyError(err))
+		return
+	}
+	err = snapshotSchema.Validate(instance)
+	if err != nil {
+		violations := make(map[string]string)
+		var validationErr *jsonschema.ValidationError
+		if errors.As(err, &validationErr) {
+			collectViolations(validationErr, violations)
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		err = json.NewEncoder(writer).Encode(map[string]any{"error": "snapshot doesn't match the schema", "fields": violations})
+		if err != nil {
+			log.Error("Error encoding response")
+		}
+		return
+	}
+
+	var snapshot core.Snapshot
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	err = decodeJSON(request, &snapshot)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	n, err := coreOf(request).Import(snapshot)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	log.WithFields(log.Fields{"imported": n}).Warn("ADMIN: All TodoItems replaced.")
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]int{"imported": n})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// StorageStatus responds with the backend in use and its statistics, along with the state of WriteBreaker
+// and the number of requests in flight, including this one (see InFlight), for debugging.
+//
+//	{"driver": "mysql", "items": 42, "open_connections": 2, "write_breaker": "closed", "in_flight": 1}
+func StorageStatus(writer http.ResponseWriter, request *http.Request) {
+	stats, err := coreOf(request).StorageStats()
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	status := struct {
+		core.BackendStats
+		WriteBreaker string `json:"write_breaker"`
+		InFlight     int64  `json:"in_flight"`
+	}{stats, WriteBreakerState(), InFlightRequests()}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(status)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+var effectiveConfig map[string]any
+
+// SetEffectiveConfig sets the settings the server is started with, as responded by GetConfig. The secrets have to be redacted already.
+func SetEffectiveConfig(settings map[string]any) {
+	effectiveConfig = settings
+}
+
+// GetConfig responds with the settings the server is started with, for diagnostics; see SetEffectiveConfig.
+// The secrets, e.g., the data source name of the database and the admin token, are redacted.
+//
+//	{"DBDriver": "mysql", "DBDSN": "[REDACTED]", "RequestTimeout": "15s", ...}
+//
+// NOTE: The settings changed at runtime, e.g., the read-only mode, are not reflected.
+func GetConfig(writer http.ResponseWriter, request *http.Request) {
+	settings := effectiveConfig
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(writer).Encode(settings)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+var allowReset bool
+
+// SetAllowReset enables or disables ResetItems and SeedItems. It's disabled unless explicitly enabled, as the reset can't be undone.
+func SetAllowReset(allow bool) {
+	allowReset = allow
+}
+
+//go:embed snapshot.schema.json
+var snapshotSchemaJSON string
+
+// snapshotSchema is the JSON Schema of the snapshots passed to ImportItems.
+// NOTE: The unknown fields are not rejected by the schema, but by decodeJSON in the strict mode, like the other bodies.
+var snapshotSchema = jsonschema.MustCompileString("snapshot.schema.json", snapshotSchemaJSON)
+
+// collectViolations maps the paths of the values that violate the schema, e.g., "$.items[1].completed", to why they do.
+// Only the innermost errors are collected, as the outer ones merely tell that their causes failed; those at the same path are joined.
+func collectViolations(err *jsonschema.ValidationError, violations map[string]string) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			collectViolations(cause, violations)
+		}
+		return
+	}
+	path := jsonPath(err.InstanceLocation)
+	if violations[path] != "" {
+		violations[path] += "; "
+	}
+	violations[path] += err.Message
+}
+
+// jsonPath converts the JSON Pointer of a value, e.g., "/items/1/completed", to its JSON path, e.g., "$.items[1].completed".
+func jsonPath(pointer string) string {
+	path := "$"
+	if pointer == "" {
+		return path
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if _, err := strconv.Atoi(token); err == nil {
+			path += "[" + token + "]"
+		} else {
+			path += "." + token
+		}
+	}
+	return path
+}
+
+// ResetItems permanently deletes all the TodoItems and starts the ids over. It's meant for development and testing only;
+// the server responds with a 403 status code unless it's enabled with SetAllowReset.
+//
+//	{"reset": true}
+func ResetItems(writer http.ResponseWriter, request *http.Request) {
+	if !allowReset {
+		writeError(writer, http.StatusForbidden, errors.New("reset is disabled"))
+		return
+	}
+
+	err := coreOf(request).Reset()
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	log.Warn("ADMIN: All TodoItems reset.")
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]bool{"reset": true})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// ReindexItems repairs the fields of the TodoItems derived from the others, e.g., after the database is edited by hand,
+// and responds with how many TodoItems are fixed for each kind of the fields:
+//
+//	{"description_keys": 1, "completed_at": 2}
+func ReindexItems(writer http.ResponseWriter, request *http.Request) {
+	report, err := coreOf(request).Reindex()
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	log.WithFields(log.Fields{"description_keys": report.DescriptionKeys, "completed_at": report.CompletedAt}).Warn("ADMIN: TodoItems reindexed.")
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(report)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// GetAuditLog responds with the audit log of the mutations of the TodoItems, oldest first, or only of the TodoItem with the id passed as a query parameter named "item_id".
+// The actor of each entry is the one told by ActorHeader.
+//
+//	[{"id": 1, "item_id": 1, "action": "update", "actor": "alice", "diff": {"completed": {"old": false, "new": true}, ...}, "at": "..."}, ...]
+//
+// If the item id is not a positive integer, the server responds with a 400 status code.
+func GetAuditLog(writer http.ResponseWriter, request *http.Request) {
+	itemID := 0
+	if s := request.FormValue("item_id"); s != "" {
+		var err error
+		itemID, err = strconv.Atoi(s)
+		if err != nil || itemID < 1 {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid item id %q", s))
+			return
+		}
+	}
+
+	entries, err := coreOf(request).GetAuditLog(itemID)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(entries)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// MaxSeedCount is the maximum number of TodoItems that SeedItems creates at once.
+const MaxSeedCount = 1000
+
+// DefaultSeedCount is the number of TodoItems that SeedItems creates if the count is not specified.
+const DefaultSeedCount = 20
+
+var (
+	seedVerbs   = []string{"Buy", "Call", "Clean", "Email", "Fix", "Plan", "Read", "Review", "Schedule", "Write"}
+	seedObjects = []string{"the report", "groceries", "the dentist", "the garage", "a birthday gift", "the budget", "the slides", "the backlog", "a blog post", "the car"}
+)
+
+// SeedItems creates random TodoItems for demos and local development, some of which are completed and labeled with colors.
+// Like ResetItems, the server responds with a 403 status code unless it's enabled with SetAllowReset.
+//
+// The number of TodoItems is passed as a query parameter named "count", which is DefaultSeedCount if not passed and at most MaxSeedCount.
+// The same TodoItems are created for the same query parameter "seed"; a seed is picked from the clock if it's not passed.
+// The response carries the seed and the created TodoItems:
+//
+//	{"seed": 42, "items": [{"id": 1, "description": "...", ...}, ...]}
+func SeedItems(writer http.ResponseWriter, request *http.Request) {
+	if !allowReset {
+		writeError(writer, http.StatusForbidden, errors.New("seed is disabled"))
+		return
+	}
+	count := DefaultSeedCount
+	if s := request.FormValue("count"); s != "" {
+		var err error
+		count, err = strconv.Atoi(s)
+		if err != nil || count < 1 || count > MaxSeedCount {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid count %q, expected 1 to %d", s, MaxSeedCount))
+			return
+		}
+	}
+	seed := clock.Now().UnixNano()
+	if s := request.FormValue("seed"); s != "" {
+		var err error
+		seed, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid seed %q", s))
+			return
+		}
+	}
+
+	c := coreOf(request)
+	r := rand.New(rand.NewSource(seed))
+	colors := append([]string{""}, core.Palette...)
+	todos := make([]core.TodoItem, 0, count)
+	for i := 0; i < count; i++ {
+		description := seedVerbs[r.Intn(len(seedVerbs))] + " " + seedObjects[r.Intn(len(seedObjects))]
+		todo, err := c.CreateItem(description, colors[r.Intn(len(colors))])
+		if err == nil && r.Intn(3) == 0 {
+			todo, err = c.UpdateItem(todo.ID, true)
+		}
+		if err != nil {
+			writeCoreError(writer, err)
+			return
+		}
+		todos = append(todos, todo)
+	}
+	log.WithFields(log.Fields{"count": count, "seed": seed}).Warn("ADMIN: TodoItems seeded.")
+
+	writer.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(writer).Encode(struct {
+		Seed  int64           `json:"seed"`
+		Items []core.TodoItem `json:"items"`
+	}{seed, todos})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
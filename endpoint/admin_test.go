@@ -0,0 +1,702 @@
+package endpoint_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"todolist/core"
+	"todolist/endpoint"
+
+	"go.uber.org/mock/gomock"
+)
+
+// TestExportItems Given the ExportItems handler serve at the /admin/export endpoint, when a request is made to the endpoint, then the server should respond with a 200 status code and the snapshot returned by the core.
+func TestExportItems(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/export"
+	e.router.HandleFunc(pattern, endpoint.ExportItems)
+	snapshot := core.Snapshot{Items: []core.TodoItem{
+		{ID: 2, Description: "test2", Completed: false},
+		{ID: 5, Description: "test5", Completed: true},
+	}}
+	e.mockCore.EXPECT().
+		ExportStream(gomock.Any()).
+		DoAndReturn(func(fn func(core.TodoItem) error) error {
+			for _, todo := range snapshot.Items {
+				if err := fn(todo); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := core.Snapshot{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(snapshot, got)
+}
+
+// TestExportItemsMany Given the core streams thousands of items, when a request is made to the /admin/export endpoint, then the response should be a valid snapshot of all the items in order.
+func TestExportItemsMany(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/export"
+	e.router.HandleFunc(pattern, endpoint.ExportItems)
+	const n = 3000
+	e.mockCore.EXPECT().
+		ExportStream(gomock.Any()).
+		DoAndReturn(func(fn func(core.TodoItem) error) error {
+			for i := 1; i <= n; i++ {
+				if err := fn(core.TodoItem{ID: i, Description: fmt.Sprintf("test%d", i)}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	if !e.writer.Flushed {
+		t.Error("expected the response to be flushed while streaming")
+	}
+	got := core.Snapshot{}
+	e.expectUnmarshalWithoutError(&got)
+	if len(got.Items) != n {
+		t.Fatalf("expected %d items, got %d", n, len(got.Items))
+	}
+	for i, todo := range got.Items {
+		if todo.ID != i+1 {
+			t.Fatalf("expected id %d at index %d, got %d", i+1, i, todo.ID)
+		}
+	}
+}
+
+// TestExportItemsThroughMiddlewares Given the /admin/export endpoint behind the middlewares of the server, with a timeout shorter than the export,
+// when a request is made to the endpoint, then the response should still be flushed while streaming and complete with a 200 status code.
+func TestExportItemsThroughMiddlewares(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	e.router.Use(endpoint.Gzip(endpoint.GzipMinSize))
+	e.router.Use(endpoint.PrettyJSON)
+	e.router.Use(endpoint.Timeout(10 * time.Millisecond))
+	e.router.Use(endpoint.ConcurrencyLimit(0))
+	e.router.Use(endpoint.ReadOnly)
+	e.router.Use(endpoint.WriteBreaker)
+	e.router.Use(endpoint.MaxBodyBytes(1<<20, 1<<25))
+	const n = 300
+	e.mockCore.EXPECT().
+		ExportStream(gomock.Any()).
+		DoAndReturn(func(fn func(core.TodoItem) error) error {
+			for i := 1; i <= n; i++ {
+				if err := fn(core.TodoItem{ID: i, Description: fmt.Sprintf("test%d", i)}); err != nil {
+					return err
+				}
+				if i == n/2 {
+					time.Sleep(30 * time.Millisecond)
+				}
+			}
+			return nil
+		})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, endpoint.AdminPathPrefix+"/export", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	if !e.writer.Flushed {
+		t.Error("expected the response to be flushed while streaming")
+	}
+	got := core.Snapshot{}
+	e.expectUnmarshalWithoutError(&got)
+	if len(got.Items) != n {
+		t.Errorf("expected %d items, got %d", n, len(got.Items))
+	}
+}
+
+// TestExportItemsEmpty Given no items, when a request is made to the /admin/export endpoint, then the response should be a snapshot with no items.
+func TestExportItemsEmpty(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/export"
+	e.router.HandleFunc(pattern, endpoint.ExportItems)
+	e.mockCore.EXPECT().
+		ExportStream(gomock.Any()).
+		Return(nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string][]core.TodoItem{"items": {}}
+	got := map[string][]core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestExportItemsError Given the core fails before streaming any item, when a request is made to the /admin/export endpoint, then the server should respond with the status code of the error.
+func TestExportItemsError(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/export"
+	e.router.HandleFunc(pattern, endpoint.ExportItems)
+	e.mockCore.EXPECT().
+		ExportStream(gomock.Any()).
+		Return(core.StorageError{Err: errors.New("database is down")})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusInternalServerError)
+}
+
+// TestImportItems Given the ImportItems handler serve at the /admin/import endpoint, when a request is made to the endpoint with a snapshot, then the snapshot, along with the time spent in seconds,
+// should be passed to the core and the server should respond with the number of imported items.
+func TestImportItems(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/import"
+	e.router.HandleFunc(pattern, endpoint.ImportItems)
+	snapshot := core.Snapshot{Items: []core.TodoItem{
+		{ID: 2, Description: "test2", Completed: false},
+		{ID: 5, Description: "test5", Completed: false, TimeSpent: core.Seconds(90 * time.Second)},
+	}}
+	e.mockCore.EXPECT().
+		Import(snapshot).
+		Return(2, nil)
+
+	// act
+	body := `{"items": [{"id": 2, "description": "test2", "completed": false}, {"id": 5, "description": "test5", "completed": false, "time_spent_seconds": 90}]}`
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(body))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]int{"imported": 2}
+	got := map[string]int{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestImportItemsTruncated Given the ImportItems handler serve at the /admin/import endpoint, when a request is made to the endpoint with a truncated snapshot,
+// then the server should respond with a 400 status code and an error telling that the body ends unexpectedly.
+func TestImportItemsTruncated(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/import"
+	e.router.HandleFunc(pattern, endpoint.ImportItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"items": [{"id": 1, "description": "test1"`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+	want := map[string]string{"error": "malformed JSON: request body ends unexpectedly"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestImportItemsInvalid Given the ImportItems handler serve at the /admin/import endpoint and the core rejects the snapshot, when a request is made to the endpoint, then the server should respond with a 400 status code.
+func TestImportItemsInvalid(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/import"
+	e.router.HandleFunc(pattern, endpoint.ImportItems)
+	e.mockCore.EXPECT().
+		Import(core.Snapshot{Items: []core.TodoItem{{ID: 1, Description: "test1"}, {ID: 1, Description: "test2"}}}).
+		Return(0, core.ValidationError{Message: "id 1 appears more than once"})
+
+	// act
+	body := `{"items": [{"id": 1, "description": "test1", "completed": false}, {"id": 1, "description": "test2", "completed": false}]}`
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(body))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestImportItemsSchema Given the ImportItems handler serve at the /admin/import endpoint, when requests are made to the endpoint with snapshots that don't match the schema,
+// then the server should respond with a 400 status code telling what's wrong at the path of each offending value, without passing the snapshot to the core.
+func TestImportItemsSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{
+			"wrong type",
+			`{"items": [{"id": 1, "description": "test1", "completed": false}, {"id": 2, "description": "test2", "completed": "yes"}]}`,
+			map[string]string{"$.items[1].completed": "expected boolean, but got string"},
+		},
+		{
+			"missing required field",
+			`{"items": [{"id": 1, "completed": false}]}`,
+			map[string]string{"$.items[0]": "missing properties: 'description'"},
+		},
+		{
+			"missing items",
+			`{}`,
+			map[string]string{"$": "missing properties: 'items'"},
+		},
+		{
+			"several violations",
+			`{"items": [{"id": 0, "description": 1, "completed": false}]}`,
+			map[string]string{"$.items[0].id": "must be >= 1 but found 0", "$.items[0].description": "expected string, but got number"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/admin/import"
+			e.router.HandleFunc(pattern, endpoint.ImportItems)
+			e.mockCore.EXPECT().
+				Import(gomock.Any()).
+				Times(0)
+
+			// act
+			request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(tt.body))
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusBadRequest)
+			var got struct {
+				Error  string            `json:"error"`
+				Fields map[string]string `json:"fields"`
+			}
+			e.expectUnmarshalWithoutError(&got)
+			e.expectEqual(tt.want, got.Fields)
+		})
+	}
+}
+
+// TestAdminAuth Given an admin token is set, when requests are made to an administrative endpoint through the AdminAuth middleware, then only the one carrying the token should be served.
+func TestAdminAuth(t *testing.T) {
+	endpoint.SetAdminToken("secret")
+	t.Cleanup(func() { endpoint.SetAdminToken("") })
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong", http.StatusUnauthorized},
+		{"not bearer", "secret", http.StatusUnauthorized},
+		{"right token", "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/admin/export"
+			e.router.HandleFunc(pattern, endpoint.ExportItems)
+			e.router.Use(endpoint.AdminAuth)
+			e.mockCore.EXPECT().
+				ExportStream(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(tt.want)
+		})
+	}
+}
+
+// TestResetItems Given the reset is allowed and the ResetItems handler serve at the /admin/reset endpoint, when a request is made to the endpoint, then the core should be reset.
+func TestResetItems(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAllowReset(true)
+	t.Cleanup(func() { endpoint.SetAllowReset(false) })
+	pattern := "/admin/reset"
+	e.router.HandleFunc(pattern, endpoint.ResetItems)
+	e.mockCore.EXPECT().
+		Reset().
+		Return(nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]bool{"reset": true}
+	got := map[string]bool{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestReindexItems Given the admin token is set and the routes are registered by NewRouter, when a request is made to the /admin/reindex endpoint with the token,
+// then the server should respond with a 200 status code and the report of the core.
+func TestReindexItems(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAdminToken("secret")
+	t.Cleanup(func() { endpoint.SetAdminToken("") })
+	e.router = endpoint.NewRouter("")
+	report := core.ReindexReport{DescriptionKeys: 1, CompletedAt: 2}
+	e.mockCore.EXPECT().
+		Reindex().
+		Return(report, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/admin/reindex", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := core.ReindexReport{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(report, got)
+}
+
+// TestResetItemsDisabled Given the reset is not allowed, when a request is made to the /admin/reset endpoint, then the server should respond with a 403 status code without resetting the core.
+func TestResetItemsDisabled(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/reset"
+	e.router.HandleFunc(pattern, endpoint.ResetItems)
+	e.mockCore.EXPECT().
+		Reset().
+		Times(0)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusForbidden)
+	want := map[string]string{"error": "reset is disabled"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestStorageStatus Given the admin token is set and the routes are registered by NewRouter, when a request is made to the /admin/status endpoint with the token, then the server should respond with the statistics of the storage backend.
+func TestStorageStatus(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAdminToken("secret")
+	t.Cleanup(func() { endpoint.SetAdminToken("") })
+	e.router = endpoint.NewRouter("")
+	stats := core.BackendStats{Driver: "postgres", Items: 7, OpenConnections: 1}
+	e.mockCore.EXPECT().
+		StorageStats().
+		Return(stats, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/admin/status", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := struct {
+		core.BackendStats
+		WriteBreaker string `json:"write_breaker"`
+		InFlight     *int64 `json:"in_flight"`
+	}{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(stats, got.BackendStats)
+	e.expectEqual(endpoint.BreakerClosed, got.WriteBreaker)
+	if got.InFlight == nil {
+		t.Error("expected the number of requests in flight")
+	}
+}
+
+// TestStorageStatusUnauthorized Given the admin token is set and the routes are registered by NewRouter, when a request is made to the /admin/status endpoint without the token, then the server should respond with a 401 status code without asking the core.
+func TestStorageStatusUnauthorized(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAdminToken("secret")
+	t.Cleanup(func() { endpoint.SetAdminToken("") })
+	e.router = endpoint.NewRouter("")
+	e.mockCore.EXPECT().
+		StorageStats().
+		Times(0)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/admin/status", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusUnauthorized)
+}
+
+// TestGetAuditLog Given the admin token is set and the routes are registered by NewRouter, when a request is made to the /admin/audit endpoint with the token and an item id,
+// then the server should respond with a 200 status code and the audit log of the item returned by the core.
+func TestGetAuditLog(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAdminToken("secret")
+	t.Cleanup(func() { endpoint.SetAdminToken("") })
+	e.router = endpoint.NewRouter("")
+	entries := []core.AuditEntry{{ID: 1, ItemID: 2, Action: core.AuditDelete, Actor: "alice", Diff: json.RawMessage(`{"completed":{"old":true,"new":null}}`)}}
+	e.mockCore.EXPECT().
+		GetAuditLog(2).
+		Return(entries, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/admin/audit?item_id=2", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	var got []core.AuditEntry
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(entries, got)
+}
+
+// TestGetAuditLogInvalidItemID Given the GetAuditLog handler serve at the /admin/audit endpoint, when a request is made to the endpoint with an item id that is not a positive integer,
+// then the server should respond with a 400 status code without asking the core.
+func TestGetAuditLogInvalidItemID(t *testing.T) {
+	for _, itemID := range []string{"abc", "0", "-1"} {
+		t.Run(itemID, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/admin/audit"
+			e.router.HandleFunc(pattern, endpoint.GetAuditLog)
+			e.mockCore.EXPECT().
+				GetAuditLog(gomock.Any()).
+				Times(0)
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, pattern+"?item_id="+itemID, nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusBadRequest)
+		})
+	}
+}
+
+// actorCore is a mock core that records the actors it's asked to act as; see endpoint.ActorHeader.
+type actorCore struct {
+	*MockCore
+	actors []string
+}
+
+func (c *actorCore) WithActor(actor string) core.Core {
+	c.actors = append(c.actors, actor)
+	return c.MockCore
+}
+
+// TestActorHeader Given a core that records who makes the mutations, when a TodoItem is deleted with and without the actor header,
+// then the core should act as the actor told by the header, and as itself without the header.
+func TestActorHeader(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	c := &actorCore{MockCore: e.mockCore}
+	endpoint.SetCore(c)
+	e.router = endpoint.NewRouter("")
+	e.mockCore.EXPECT().
+		DeleteItem(1).
+		Return(nil).
+		Times(2)
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, "/todo/1", nil)
+	request.Header.Set(endpoint.ActorHeader, "alice")
+	e.router.ServeHTTP(e.writer, request)
+	anonymous, _ := http.NewRequest(http.MethodDelete, "/todo/1", nil)
+	e.router.ServeHTTP(httptest.NewRecorder(), anonymous)
+
+	// assert
+	e.expectEqual([]string{"alice"}, c.actors)
+}
+
+// TestActorHeaderAttachments Given a core that records who makes the mutations, when a URL is attached to a TodoItem and detached from it with the actor header,
+// then the core should act as the actor for both, so that the audited changes of the attachments tell who made them.
+func TestActorHeaderAttachments(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	c := &actorCore{MockCore: e.mockCore}
+	endpoint.SetCore(c)
+	e.router = endpoint.NewRouter("")
+	e.mockCore.EXPECT().
+		AddAttachment(1, "https://example.com/spec.pdf").
+		Return(core.TodoItem{ID: 1}, nil)
+	e.mockCore.EXPECT().
+		RemoveAttachment(1, "https://example.com/spec.pdf").
+		Return(core.TodoItem{ID: 1}, nil)
+
+	// act
+	add, _ := http.NewRequest(http.MethodPost, "/todo/1/attachments", strings.NewReader(`{"url": "https://example.com/spec.pdf"}`))
+	add.Header.Set(endpoint.ActorHeader, "alice")
+	e.router.ServeHTTP(e.writer, add)
+	remove, _ := http.NewRequest(http.MethodDelete, "/todo/1/attachments?url=https://example.com/spec.pdf", nil)
+	remove.Header.Set(endpoint.ActorHeader, "bob")
+	e.router.ServeHTTP(httptest.NewRecorder(), remove)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual([]string{"alice", "bob"}, c.actors)
+}
+
+// seedItems makes a request to the SeedItems handler with the query, with the core creating the items with increasing ids,
+// and returns the descriptions of the created items.
+func seedItems(t *testing.T, query string) []string {
+	e := newTestEnv(t)
+	pattern := "/admin/seed"
+	e.router.HandleFunc(pattern, endpoint.SeedItems)
+	id := 0
+	e.mockCore.EXPECT().
+		CreateItem(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(description, color string) (core.TodoItem, error) {
+			id++
+			return core.TodoItem{ID: id, Description: description, Color: color}, nil
+		}).
+		AnyTimes()
+	e.mockCore.EXPECT().
+		UpdateItem(gomock.Any(), true).
+		DoAndReturn(func(id core.ItemID, completed bool) (core.TodoItem, error) {
+			return core.TodoItem{ID: id, Completed: completed}, nil
+		}).
+		AnyTimes()
+
+	request, _ := http.NewRequest(http.MethodPost, pattern+query, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := struct {
+		Items []core.TodoItem `json:"items"`
+	}{}
+	e.expectUnmarshalWithoutError(&got)
+	var descriptions []string
+	for _, todo := range got.Items {
+		descriptions = append(descriptions, todo.Description)
+	}
+	return descriptions
+}
+
+// TestSeedItems Given the seed is allowed, when requests are made to the /admin/seed endpoint with a count and the same seed, then the requested number of items should be created with the same descriptions.
+func TestSeedItems(t *testing.T) {
+	// arrange
+	endpoint.SetAllowReset(true)
+	t.Cleanup(func() { endpoint.SetAllowReset(false) })
+
+	// act
+	first := seedItems(t, "?count=15&seed=42")
+	second := seedItems(t, "?count=15&seed=42")
+
+	// assert
+	if len(first) != 15 {
+		t.Fatalf("expected 15 items, got %d", len(first))
+	}
+	if strings.Join(first, "\n") != strings.Join(second, "\n") {
+		t.Errorf("expected the same descriptions for the same seed, got %v and %v", first, second)
+	}
+}
+
+// TestSeedItemsInvalidCount Given the seed is allowed, when a request is made to the /admin/seed endpoint with a count over the maximum, then the server should respond with a 400 status code without creating any item.
+func TestSeedItemsInvalidCount(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAllowReset(true)
+	t.Cleanup(func() { endpoint.SetAllowReset(false) })
+	pattern := "/admin/seed"
+	e.router.HandleFunc(pattern, endpoint.SeedItems)
+	e.mockCore.EXPECT().
+		CreateItem(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern+"?count=1001", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestSeedItemsDisabled Given the seed is not allowed, when a request is made to the /admin/seed endpoint, then the server should respond with a 403 status code without creating any item.
+func TestSeedItemsDisabled(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/admin/seed"
+	e.router.HandleFunc(pattern, endpoint.SeedItems)
+	e.mockCore.EXPECT().
+		CreateItem(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusForbidden)
+}
+
+// TestGetConfig Given the admin token and the effective settings are set and the routes are registered by NewRouter, when a request is made to the /admin/config endpoint with the token,
+// then the server should respond with the settings.
+func TestGetConfig(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAdminToken("secret")
+	settings := map[string]any{"LogLevel": "debug", "AdminToken": "[REDACTED]"}
+	endpoint.SetEffectiveConfig(settings)
+	t.Cleanup(func() {
+		endpoint.SetAdminToken("")
+		endpoint.SetEffectiveConfig(nil)
+	})
+	e.router = endpoint.NewRouter("")
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/admin/config", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := map[string]any{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(settings, got)
+}
+
+// TestGetConfigUnauthorized Given the admin token is set and the routes are registered by NewRouter, when a request is made to the /admin/config endpoint without the token,
+// then the server should respond with a 401 status code.
+func TestGetConfigUnauthorized(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetAdminToken("secret")
+	t.Cleanup(func() { endpoint.SetAdminToken("") })
+	e.router = endpoint.NewRouter("")
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/admin/config", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusUnauthorized)
+}
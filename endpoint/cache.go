@@ -0,0 +1,148 @@
+package endpoint
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"todolist/core"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ItemsCache caches the responses of GetItems, keyed by their query parameters and the media type they are encoded in,
+// so that the repeated reads are served without asking the core.
+//
+// The entries are all invalidated whenever a TodoItem is mutated, as told by the core through the EventSink methods,
+// and after every request that may mutate the TodoItems, i.e., all but GET, HEAD, and OPTIONS ones, which covers the bulk mutations that the core doesn't report.
+// An entry also expires after the TTL, as a backstop for the mutations made neither through the handlers nor reported, e.g., the pruning of the completed TodoItems.
+type ItemsCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+	// generation is increased on every invalidation, so that a response read before an invalidation is not cached after it.
+	generation uint64
+}
+
+// cachedResponse is a response of GetItems as written by the handler.
+type cachedResponse struct {
+	header    http.Header
+	body      []byte
+	fetchedAt time.Time
+}
+
+// NewItemsCache returns an empty cache whose entries expire after the ttl.
+func NewItemsCache(ttl time.Duration) *ItemsCache {
+	return &ItemsCache{ttl: ttl, entries: map[string]cachedResponse{}}
+}
+
+func (c *ItemsCache) ItemCreated(core.TodoItem) {
+	c.Invalidate()
+}
+
+func (c *ItemsCache) ItemUpdated(core.TodoItem) {
+	c.Invalidate()
+}
+
+func (c *ItemsCache) ItemDeleted(core.ItemID) {
+	c.Invalidate()
+}
+
+// Invalidate drops all the entries.
+func (c *ItemsCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
+
+// get returns the entry of the key unless it's missing or expired, along with the current generation.
+func (c *ItemsCache) get(key string) (cachedResponse, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if ok && clock.Now().Sub(entry.fetchedAt) >= c.ttl {
+		delete(c.entries, key)
+		ok = false
+	}
+	return entry, ok, c.generation
+}
+
+// put stores the entry of the key unless the cache is invalidated since the generation.
+func (c *ItemsCache) put(key string, entry cachedResponse, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		c.entries[key] = entry
+	}
+}
+
+var itemsCache *ItemsCache
+
+// SetItemsCache sets the cache of the responses of GetItems; nil, which is the default, turns the caching off.
+// The cache has to be attached to the core as an EventSink as well, so that it's invalidated by the mutations.
+func SetItemsCache(cache *ItemsCache) {
+	itemsCache = cache
+}
+
+// cachedItems serves the requests with the responses cached in the ItemsCache set by SetItemsCache, if any;
+// the other requests are served by next, whose successful responses are cached.
+func cachedItems(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		cache := itemsCache
+		if cache == nil {
+			next(writer, request)
+			return
+		}
+		// NOTE: Encode sorts the query parameters, so that their order doesn't split the entries.
+		// All the Accept headers are part of the key, since the negotiation considers every one of them, not only the first.
+		key := request.URL.Query().Encode() + " " + strings.Join(request.Header.Values("Accept"), ",")
+		entry, ok, generation := cache.get(key)
+		if ok {
+			log.WithFields(log.Fields{"key": key}).Debug("Serving TodoItems from cache")
+			maps.Copy(writer.Header(), entry.header)
+			_, _ = writer.Write(entry.body)
+			return
+		}
+		// Only the headers set by the handler are cached, as the ones set by the middlewares may depend on the request, e.g., CORS.
+		before := writer.Header().Clone()
+		recorder := &recordingResponseWriter{statusResponseWriter: statusResponseWriter{ResponseWriter: writer}}
+		next(recorder, request)
+		if recorder.code != http.StatusOK {
+			return
+		}
+		header := http.Header{}
+		for name, values := range writer.Header() {
+			if !slices.Equal(before[name], values) {
+				header[name] = slices.Clone(values)
+			}
+		}
+		cache.put(key, cachedResponse{header: header, body: recorder.body.Bytes(), fetchedAt: clock.Now()}, generation)
+	}
+}
+
+// recordingResponseWriter records the status code and the body of the response while writing them through.
+type recordingResponseWriter struct {
+	statusResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.statusResponseWriter.Write(p)
+}
+
+// invalidateItemsCache is a middleware that invalidates the ItemsCache set by SetItemsCache, if any, after every request that may mutate the TodoItems.
+func invalidateItemsCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		next.ServeHTTP(writer, request)
+		if cache := itemsCache; cache != nil && !isSafeMethod(request.Method) {
+			cache.Invalidate()
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package endpoint_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"todolist/core"
+	"todolist/endpoint"
+)
+
+// newCachedTestEnv returns a test environment whose routes are registered by NewRouter, with an ItemsCache of the ttl set.
+func newCachedTestEnv(t *testing.T, ttl time.Duration) (*testEnv, *endpoint.ItemsCache) {
+	e := newTestEnv(t)
+	cache := endpoint.NewItemsCache(ttl)
+	endpoint.SetItemsCache(cache)
+	t.Cleanup(func() { endpoint.SetItemsCache(nil) })
+	e.router = endpoint.NewRouter("")
+	return e, cache
+}
+
+// get makes a GET request to the path and returns the response.
+func (e *testEnv) get(path string) *httptest.ResponseRecorder {
+	writer := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, path, nil)
+	e.router.ServeHTTP(writer, request)
+	return writer
+}
+
+// TestItemsCacheHit Given an ItemsCache is set, when the same request is made to the /todo endpoint twice,
+// then the second one should be served from the cache without asking the core, with the same response.
+func TestItemsCacheHit(t *testing.T) {
+	// arrange
+	e, _ := newCachedTestEnv(t, time.Minute)
+	todos := []core.TodoItem{{ID: 1, Description: "test1"}}
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(todos).
+		Times(1)
+
+	// act
+	first := e.get("/todo?completed=false")
+	second := e.get("/todo?completed=false")
+
+	// assert
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("expected status code 200, got %v and %v", first.Code, second.Code)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("expected the same body, got %q and %q", first.Body.String(), second.Body.String())
+	}
+	if got := second.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("expected the content type application/json, got %q", got)
+	}
+}
+
+// TestItemsCacheKeyedByQuery Given an ItemsCache is set, when requests with different query parameters are made to the /todo endpoint,
+// then each of them should be served by the core.
+func TestItemsCacheKeyedByQuery(t *testing.T) {
+	// arrange
+	e, _ := newCachedTestEnv(t, time.Minute)
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(nil)
+	e.mockCore.EXPECT().
+		GetItems(true).
+		Return(nil)
+
+	// act
+	e.get("/todo?completed=false")
+	e.get("/todo?completed=true")
+}
+
+// TestItemsCacheKeyedByAllAccepts Given an ItemsCache is set and a response of the /todo endpoint to a client accepting text is cached, in JSON as the fallback,
+// when the same request is made with a second Accept header asking for XML, then it should be served by the core in XML instead of from the cache.
+func TestItemsCacheKeyedByAllAccepts(t *testing.T) {
+	// arrange
+	e, _ := newCachedTestEnv(t, time.Minute)
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(nil).
+		Times(2)
+	first, _ := http.NewRequest(http.MethodGet, "/todo?completed=false", nil)
+	first.Header.Set("Accept", "text/plain")
+	second, _ := http.NewRequest(http.MethodGet, "/todo?completed=false", nil)
+	second.Header.Add("Accept", "text/plain")
+	second.Header.Add("Accept", "application/xml")
+
+	// act
+	e.router.ServeHTTP(httptest.NewRecorder(), first)
+	writer := httptest.NewRecorder()
+	e.router.ServeHTTP(writer, second)
+
+	// assert
+	if got := writer.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/xml") {
+		t.Errorf("expected the content type application/xml, got %q", got)
+	}
+}
+
+// TestItemsCacheInvalidatedByCreate Given an ItemsCache is set and a response of the /todo endpoint is cached, when a TodoItem is created through the /todo endpoint,
+// then the next request should be served by the core again.
+func TestItemsCacheInvalidatedByCreate(t *testing.T) {
+	// arrange
+	e, _ := newCachedTestEnv(t, time.Minute)
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(nil).
+		Times(2)
+	e.mockCore.EXPECT().
+		CreateItem("test", "").
+		Return(core.TodoItem{ID: 1, Description: "test"}, nil)
+	e.get("/todo?completed=false")
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo", strings.NewReader("description=test"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(httptest.NewRecorder(), request)
+	e.get("/todo?completed=false")
+}
+
+// TestItemsCacheInvalidatedBySink Given an ItemsCache is set and a response of the /todo endpoint is cached, when the cache is told by the core that a TodoItem is created,
+// then the next request should be served by the core again.
+func TestItemsCacheInvalidatedBySink(t *testing.T) {
+	// arrange
+	e, cache := newCachedTestEnv(t, time.Minute)
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(nil).
+		Times(2)
+	e.get("/todo?completed=false")
+
+	// act
+	cache.ItemCreated(core.TodoItem{ID: 1})
+	e.get("/todo?completed=false")
+}
+
+// TestItemsCacheExpired Given an ItemsCache is set and a response of the /todo endpoint is cached, when the TTL elapses,
+// then the next request should be served by the core again.
+func TestItemsCacheExpired(t *testing.T) {
+	// arrange
+	clock := core.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	endpoint.SetClock(clock)
+	t.Cleanup(func() { endpoint.SetClock(core.RealClock{}) })
+	e, _ := newCachedTestEnv(t, time.Minute)
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(nil).
+		Times(2)
+	e.get("/todo?completed=false")
+
+	// act
+	clock.Advance(time.Minute)
+	e.get("/todo?completed=false")
+}
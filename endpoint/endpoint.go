@@ -1,10 +1,22 @@
 package endpoint
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"todolist/core"
 
@@ -18,6 +30,139 @@ func SetCore(c core.Core) {
 	theCore = c
 }
 
+// ActorHeader is the request header telling who makes the request, which is recorded in the audit log as the actor of the mutations.
+// NOTE: It's taken as is, so it's only as trustworthy as the clients, e.g., behind a proxy that authenticates them and sets it.
+const ActorHeader = "X-User"
+
+// actorCore is implemented by the cores that record who makes the mutations; see core.TheCore.WithActor.
+type actorCore interface {
+	WithActor(actor string) core.Core
+}
+
+// contextCore is implemented by the cores whose storage operations can be cancelled; see core.TheCore.WithContext.
+type contextCore interface {
+	WithContext(ctx context.Context) core.Core
+}
+
+// coreOf returns the core to serve the request with, if the core supports them, one whose storage operations are cancelled with the context of the request,
+// e.g., once Timeout gives up on it, and which records the actor told by ActorHeader.
+func coreOf(request *http.Request) core.Core {
+	c := theCore
+	if contextual, ok := c.(contextCore); ok {
+		c = contextual.WithContext(request.Context())
+	}
+	actor := request.Header.Get(ActorHeader)
+	if audited, ok := c.(actorCore); ok && actor != "" {
+		c = audited.WithActor(actor)
+	}
+	return c
+}
+
+var defaultFilter = "all"
+
+// SetDefaultFilter sets which TodoItems GetItems returns if the query parameter "completed" is not passed:
+// "all", "active" (incomplete), or "completed". An error is returned if the filter is none of them.
+func SetDefaultFilter(filter string) error {
+	switch filter {
+	case "all", "active", "completed":
+		defaultFilter = filter
+		return nil
+	default:
+		return fmt.Errorf("unknown default filter %q", filter)
+	}
+}
+
+var (
+	defaultSort       = core.SortByID
+	defaultDescending = false
+)
+
+// SetDefaultSort sets the order of the TodoItems GetItems returns if the query parameter "sort" is not passed:
+// "id" or "position", optionally followed by the direction ":asc" or ":desc", e.g., "position:desc". An error is returned if the order is none of them.
+func SetDefaultSort(sort string) error {
+	order, descending, err := parseSort(sort)
+	if err != nil {
+		return fmt.Errorf("invalid default sort: %w", err)
+	}
+	defaultSort, defaultDescending = order, descending
+	return nil
+}
+
+// parseSort returns the order named by the sort, which is a column, "id" or "position", optionally followed by the direction ":asc" or ":desc",
+// and whether the order is descending.
+func parseSort(sort string) (order core.SortOrder, descending bool, e error) {
+	column, direction, hasDirection := strings.Cut(sort, ":")
+	switch {
+	case !hasDirection, direction == "asc":
+	case direction == "desc":
+		descending = true
+	default:
+		return 0, false, fmt.Errorf("unknown direction %q of sort %q, expected asc or desc", direction, sort)
+	}
+	switch column {
+	case "id":
+		return core.SortByID, descending, nil
+	case "position":
+		return core.SortByPosition, descending, nil
+	default:
+		return 0, false, fmt.Errorf("unknown sort %q", sort)
+	}
+}
+
+var clock core.Clock = core.RealClock{}
+
+// SetClock sets the Clock from which the handlers tell the current time, e.g., what day today is for GetItemsToday.
+func SetClock(c core.Clock) {
+	clock = c
+}
+
+var defaultLocation = time.Local
+
+// SetDefaultTimezone sets the time zone of the clients that don't tell theirs, e.g., when the day rolls over for GetItemsToday, by its IANA name like "Asia/Tokyo".
+// An empty name means the local time zone of the server. An error is returned if the name is unknown.
+func SetDefaultTimezone(name string) error {
+	if name == "" {
+		defaultLocation = time.Local
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q", name)
+	}
+	defaultLocation = loc
+	return nil
+}
+
+// DefaultMassDeleteThreshold is the fraction of all TodoItems above which a deletion requires confirmation unless changed by SetMassDeleteThreshold.
+const DefaultMassDeleteThreshold = 0.5
+
+var massDeleteThreshold = DefaultMassDeleteThreshold
+
+// SetMassDeleteThreshold sets the fraction of all TodoItems, from 0 to 1, above which DeleteCompleted requires confirmation.
+// A threshold of 1 never requires confirmation. An error is returned if the threshold is out of range.
+func SetMassDeleteThreshold(threshold float64) error {
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("mass delete threshold %v is not within [0, 1]", threshold)
+	}
+	massDeleteThreshold = threshold
+	return nil
+}
+
+// DefaultMaxPageSize is the maximum number of TodoItems in a page unless changed by SetMaxPageSize.
+const DefaultMaxPageSize = 500
+
+var maxPageSize = DefaultMaxPageSize
+
+// SetMaxPageSize sets the maximum number of TodoItems in a page of GetItems; a larger limit is clamped to it.
+// An error is returned if the size is not positive.
+func SetMaxPageSize(size int) error {
+	if size <= 0 {
+		return fmt.Errorf("max page size %d is not positive", size)
+	}
+	maxPageSize = size
+	return nil
+}
+
 // Healthz responds with a simple health check message to the client every time it's invoked.
 func Healthz(writer http.ResponseWriter, request *http.Request) {
 	log.Info("API Health is OK")
@@ -28,18 +173,91 @@ func Healthz(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
+// errAttachmentsNotSettable is responded by CreateItem and UpsertItem if the attachments are passed, which are only set through AddAttachment.
+var errAttachmentsNotSettable = errors.New("attachments can't be set here, add them with POST /todo/{id}/attachments")
+
 // CreateItem creates a new TodoItem in the database and returns the newly created item to the client to ensure that the operation was successful.
 //
-// The description of the TodoItem is passed as a form parameter named "description".
+// The description of the TodoItem is passed as a form parameter named "description",
+// and the optional color as one named "color", which is a hex code like "#1e90ff" or one of core.Palette.
+//
+//	{ "description": "string", "color": "string" }
+//
+// The response will be the newly created TodoItem with a 201 status code, and the Location header will be the URL of the TodoItem, e.g., "/todo/1".
+//
+// If the description is blank or the color is invalid, the server responds with a 422 status code, telling why each of the fields is invalid:
+//
+//	{"error": "validation failed", "fields": {"description": "required"}}
+//
+// The attachments are not set here but with AddAttachment once the TodoItem is created; passing them responds with a 400 status code,
+// rather than dropping them silently.
 //
-//	{ "description": "string" }
+// If the operation failed otherwise, the server responds with the status code of the error (see writeCoreError):
 //
-// The response will be the newly created TodoItem.
+//	{"error": "some error message"}
 func CreateItem(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	if request.Form.Has("attachments") {
+		writeError(writer, http.StatusBadRequest, errAttachmentsNotSettable)
+		return
+	}
 	description := request.FormValue("description")
-	todo := theCore.CreateItem(description)
+	color := request.FormValue("color")
+	todo, err := coreOf(request).CreateItem(description, color)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Location", itemLocation(todo.ID))
+	writer.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(writer).Encode(todo)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// UpsertItem updates the TodoItem with the id passed in the JSON body, or creates it with the id if there's no such TodoItem.
+// A TodoItem without an id is always created with a new id. All the fields but the position are set from the body.
+//
+//	{"id": 1, "description": "string", "completed": bool, "color": "string", "list_id": 0}
+//
+// The response will be the TodoItem, with a 201 status code and its URL in the Location header if it's created, or with a 200 status code if it's updated.
+//
+// If any of the fields is invalid, the server responds with a 422 status code, telling why each of them is invalid:
+//
+//	{"error": "validation failed", "fields": {"description": "required"}}
+//
+// Like CreateItem, passing the attachments responds with a 400 status code.
+//
+// If the operation failed otherwise, e.g., the TodoItem with the id is deleted, the server responds with the status code of the error (see writeCoreError):
+//
+//	{"error": "some error message"}
+func UpsertItem(writer http.ResponseWriter, request *http.Request) {
+	var item core.TodoItem
+	err := decodeJSON(request, &item)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	if item.Attachments != nil {
+		writeError(writer, http.StatusBadRequest, errAttachmentsNotSettable)
+		return
+	}
+	todo, created, err := coreOf(request).UpsertItem(item)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
 	writer.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(writer).Encode(todo)
+	if created {
+		writer.Header().Set("Location", itemLocation(todo.ID))
+		writer.WriteHeader(http.StatusCreated)
+	}
+	err = json.NewEncoder(writer).Encode(todo)
 	if err != nil {
 		log.Error("Error encoding response")
 	}
@@ -48,81 +266,1442 @@ func CreateItem(writer http.ResponseWriter, request *http.Request) {
 // UpdateItem updates the completed status of a TodoItem in the database.
 //
 // The completed status is passed as a form parameter named "completed".
+// A TodoItem that is blocked by incomplete TodoItems can't be completed, responding with a 409 status code, unless a form parameter named "force" is true.
 //
-//	{ "completed": bool }
+//	{ "completed": bool, "force": bool }
 //
 // If the operation was successful:
 //
 //	{"updated": true}
 //
-// If the TodoItem was not found in the database:
+// If the operation failed, e.g., the TodoItem was not found in the database, the server responds with the status code of the error (see writeCoreError):
 //
 //	{"updated": false, "error": "some error message"}
 func UpdateItem(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		writeBodyError(writer, err)
+		return
+	}
 	vars := mux.Vars(request)
 	id, _ := strconv.Atoi(vars["id"])
 	completed, _ := strconv.ParseBool(request.FormValue("completed"))
+	force, _ := strconv.ParseBool(request.FormValue("force"))
 
-	_, err := theCore.UpdateItem(id, completed)
+	var err error
+	if force {
+		_, err = coreOf(request).ForceUpdateItem(id, completed)
+	} else {
+		_, err = coreOf(request).UpdateItem(id, completed)
+	}
 
-	var response string
+	response := struct {
+		Updated bool   `json:"updated"`
+		Error   string `json:"error,omitempty"`
+	}{Updated: err == nil}
+	writer.Header().Set("Content-Type", "application/json")
 	if err != nil {
-		response = `{"updated": false, "error": "` + err.Error() + `"}`
-	} else {
-		response = `{"updated": true}`
+		response.Error = err.Error()
+		writer.WriteHeader(statusCodeOf(err))
+	}
+	err = json.NewEncoder(writer).Encode(response)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// SetItemColor sets the color of a TodoItem in the database.
+//
+// The color is passed as a form parameter named "color", which is a hex code like "#1e90ff" or one of core.Palette.
+// An empty color removes the label.
+//
+//	{ "color": "string" }
+//
+// If the operation was successful, the response will be the updated TodoItem.
+//
+// If the operation failed, e.g., the color is invalid, the server responds with the status code of the error (see writeCoreError):
+//
+//	{"error": "some error message"}
+func SetItemColor(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+	color := request.FormValue("color")
+
+	todo, err := coreOf(request).SetItemColor(id, color)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
 	}
+
 	writer.Header().Set("Content-Type", "application/json")
-	_, err = io.WriteString(writer, response)
+	err = json.NewEncoder(writer).Encode(todo)
 	if err != nil {
-		log.Error("Error writing response to client")
+		log.Error("Error encoding response")
 	}
 }
 
-// DeleteItem deletes a TodoItem from the database.
-// If the operation was successful:
+// MoveItem moves a TodoItem to another list in the database.
 //
-//	{"deleted": true}
+// The list is passed as a form parameter named "list_id", which is a non-negative integer; 0 is the default list.
 //
-// If the TodoItem was not found in the database:
+//	{ "list_id": 2 }
 //
-//	{"deleted": false, "error": "some error message"}
-func DeleteItem(writer http.ResponseWriter, request *http.Request) {
+// If the operation was successful, the response will be the updated TodoItem.
+//
+// If the operation failed, e.g., the list id is not a non-negative integer, the server responds with the status code of the error (see writeCoreError):
+//
+//	{"error": "some error message"}
+func MoveItem(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		writeBodyError(writer, err)
+		return
+	}
 	vars := mux.Vars(request)
 	id, _ := strconv.Atoi(vars["id"])
+	listID, err := strconv.Atoi(request.FormValue("list_id"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid list_id %q", request.FormValue("list_id")))
+		return
+	}
 
-	err := theCore.DeleteItem(id)
+	todo, err := coreOf(request).MoveItem(id, listID)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
 
-	var response string
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todo)
 	if err != nil {
-		response = `{"deleted": false, "error": "` + err.Error() + `"}`
-	} else {
-		response = `{"deleted": true}`
+		log.Error("Error encoding response")
+	}
+}
+
+// CloneItem creates an incomplete copy of the TodoItem with the specified id, with " (copy)" appended to the description (see core.CloneItem).
+//
+// The response will be the copy with a 201 status code, and the Location header will be the URL of the copy, e.g., "/todo/2".
+//
+// If the TodoItem was not found in the database, the server responds with a 404 status code:
+//
+//	{"error": "some error message"}
+func CloneItem(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	todo, err := coreOf(request).CloneItem(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
 	}
+
 	writer.Header().Set("Content-Type", "application/json")
-	_, err = io.WriteString(writer, response)
+	writer.Header().Set("Location", itemLocation(todo.ID))
+	writer.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(writer).Encode(todo)
 	if err != nil {
-		log.Error("Error writing response to client")
+		log.Error("Error encoding response")
 	}
 }
 
-// GetItems returns all TodoItems from the database.
-// The completed status of the TodoItems can be filtered by passing a query parameter named "completed".
-// If the query parameter "completed" is not passed, all TodoItems are returned.
-func GetItems(writer http.ResponseWriter, request *http.Request) {
-	completed, unspecified := strconv.ParseBool(request.FormValue("completed"))
+// itemLocation returns the URL of the TodoItem with the id, under the base path.
+func itemLocation(id core.ItemID) string {
+	return basePath + "/todo/" + strconv.Itoa(id)
+}
 
-	var todos []core.TodoItem
-	// If the query parameter "completed" is not passed, all TodoItems are returned.
-	if unspecified != nil {
-		todos = theCore.GetItems(true)
-		todos = append(todos, theCore.GetItems(false)...)
-	} else {
-		todos = theCore.GetItems(completed)
+// ToggleItem flips the completed status of a TodoItem in the database.
+//
+// If the operation was successful, the response will be the updated TodoItem.
+//
+// If the TodoItem was not found in the database, the server responds with a 404 status code:
+//
+//	{"error": "some error message"}
+func ToggleItem(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	todo, err := coreOf(request).ToggleItem(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todo)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// StartItem marks a TodoItem as in progress, recording when the work on it is started; starting it again keeps the time.
+//
+// If the operation was successful, the response will be the updated TodoItem.
+//
+//	{"id": 1, "description": "...", "completed": false, "started_at": "...", ...}
+//
+// If the TodoItem was not found in the database, the server responds with a 404 status code; if it's completed, with a 409 status code:
+//
+//	{"error": "some error message"}
+func StartItem(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	todo, err := coreOf(request).StartItem(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todo)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// StopItem marks a TodoItem as no longer in progress, clearing when the work on it was started.
+//
+// If the operation was successful, the response will be the updated TodoItem.
+//
+// If the TodoItem was not found in the database, the server responds with a 404 status code; if it's completed, with a 409 status code:
+//
+//	{"error": "some error message"}
+func StopItem(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	todo, err := coreOf(request).StopItem(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todo)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// GetTimeSpent responds with the total time worked on a TodoItem in whole seconds, including the time since it's started if it's in progress,
+// as told by running (see StartItem and StopItem).
+//
+//	{"seconds": 5400, "running": true}
+//
+// If the TodoItem was not found in the database, the server responds with a 404 status code:
+//
+//	{"error": "some error message"}
+func GetTimeSpent(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	spent, err := coreOf(request).GetTimeSpent(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]any{"seconds": int64(spent.Total / time.Second), "running": spent.Running})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// AddBlockers records that a TodoItem can't be completed until the other TodoItems are.
+//
+// The ids of the blocking TodoItems are passed as a JSON body:
+//
+//	{"ids": [2, 3]}
+//
+// If the operation was successful, the response will be all the TodoItems that block the TodoItem, including the ones added before.
+//
+//	[{"id": 2, "description": "...", "completed": false, ...}, ...]
+//
+// If any of the TodoItems was not found in the database, none of the blockers is added and the server responds with a 404 status code;
+// if the TodoItem already blocks one of the blockers, maybe through other TodoItems, it responds with a 409 status code:
+//
+//	{"error": "some error message"}
+func AddBlockers(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+	var body struct {
+		IDs []core.ItemID `json:"ids"`
+	}
+	err := decodeJSON(request, &body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	todos, err := coreOf(request).AddBlockers(id, body.IDs)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	if todos == nil {
+		todos = []core.TodoItem{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todos)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// GetBlockedItems responds with the TodoItems that can't be completed yet, as they are blocked by at least one incomplete TodoItem.
+//
+//	[{"id": 1, "description": "...", "completed": false, ...}, ...]
+func GetBlockedItems(writer http.ResponseWriter, request *http.Request) {
+	todos, err := coreOf(request).GetBlockedItems()
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	if todos == nil {
+		todos = []core.TodoItem{}
+	}
+	writeItems(writer, request, todos)
+}
+
+// AddAttachment attaches a URL to a TodoItem, e.g., a link to a related document.
+//
+// The URL is passed as a JSON body, which has to be an absolute http or https URL:
+//
+//	{"url": "https://example.com/spec.pdf"}
+//
+// If the operation was successful, the response will be the TodoItem along with all its attachments. Attaching a URL again does nothing.
+//
+//	{"id": 1, "description": "...", "attachments": ["https://example.com/spec.pdf"], ...}
+//
+// If the URL is malformed, the server responds with a 422 status code; if the TodoItem was not found in the database, with a 404 status code;
+// if the TodoItem already has core.MaxAttachments attachments, with a 409 status code:
+//
+//	{"error": "some error message"}
+func AddAttachment(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+	var body struct {
+		URL string `json:"url"`
+	}
+	err := decodeJSON(request, &body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	todo, err := coreOf(request).AddAttachment(id, body.URL)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todo)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// RemoveAttachment detaches the URL passed as the url query parameter from a TodoItem.
+//
+// If the operation was successful, the response will be the TodoItem along with the rest of its attachments. Detaching a URL that is not attached does nothing.
+//
+//	{"id": 1, "description": "...", "completed": false, ...}
+//
+// If the TodoItem was not found in the database, the server responds with a 404 status code:
+//
+//	{"error": "some error message"}
+func RemoveAttachment(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	todo, err := coreOf(request).RemoveAttachment(id, request.URL.Query().Get("url"))
+	if err != nil {
+		writeCoreError(writer, err)
+		return
 	}
 
 	writer.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(writer).Encode(todos)
+	err = json.NewEncoder(writer).Encode(todo)
 	if err != nil {
 		log.Error("Error encoding response")
 	}
 }
+
+// SetItemsCompleted updates the completed status of multiple TodoItems at once.
+//
+// The ids of the TodoItems and the completed status are passed as a JSON body:
+//
+//	{"ids": [1, 2], "completed": true}
+//
+// The response carries the number of TodoItems whose status is changed. Ids that don't exist are not counted.
+//
+//	{"updated": 2}
+//
+// If more than core.MaxBatchIDs ids are passed, the server responds with a 400 status code.
+func SetItemsCompleted(writer http.ResponseWriter, request *http.Request) {
+	var body struct {
+		IDs       []core.ItemID `json:"ids"`
+		Completed bool          `json:"completed"`
+	}
+	err := decodeJSON(request, &body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	n, err := coreOf(request).SetItemsCompleted(body.IDs, body.Completed)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]int{"updated": n})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// Reorder places the TodoItems in the given order, which can later be listed with GetItems sorted by position.
+//
+// The ids of the TodoItems are passed as a JSON body:
+//
+//	{"ids": [3, 1, 2]}
+//
+// If the operation was successful:
+//
+//	{"reordered": true}
+//
+// If any of the TodoItems was not found in the database, none of them is reordered and the server responds with a 404 status code:
+//
+//	{"error": "some error message"}
+func Reorder(writer http.ResponseWriter, request *http.Request) {
+	var body struct {
+		IDs []core.ItemID `json:"ids"`
+	}
+	err := decodeJSON(request, &body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	err = coreOf(request).Reorder(body.IDs)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_, err = io.WriteString(writer, `{"reordered": true}`)
+	if err != nil {
+		log.Error("Error writing response to client")
+	}
+}
+
+// DeleteItem deletes a TodoItem from the database.
+// If the operation was successful, or the TodoItem had already been deleted, so that the request is safe to retry:
+//
+//	{"deleted": true}
+//
+// If the operation failed, e.g., the database could not be reached, the server responds with the status code of the error (see writeCoreError):
+//
+//	{"deleted": false, "error": "some error message"}
+func DeleteItem(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	err := coreOf(request).DeleteItem(id)
+
+	response := struct {
+		Deleted bool   `json:"deleted"`
+		Error   string `json:"error,omitempty"`
+	}{Deleted: err == nil}
+	writer.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		response.Error = err.Error()
+		writer.WriteHeader(statusCodeOf(err))
+	}
+	err = json.NewEncoder(writer).Encode(response)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// DeleteCompleted deletes all the completed TodoItems and responds with them as they were,
+// so that they can be brought back by passing the response to RestoreItems.
+//
+//	[{"id": 1, "description": "...", "completed": true, ...}, ...]
+//
+// As a safety valve, if more than the fraction set by SetMassDeleteThreshold of all TodoItems would be deleted,
+// the query parameter "confirm" has to be "true"; otherwise nothing is deleted and the server responds with a 409 status code.
+//
+//	{"error": "mass deletion requires confirm"}
+func DeleteCompleted(writer http.ResponseWriter, request *http.Request) {
+	maxFraction := massDeleteThreshold
+	if confirmed, _ := strconv.ParseBool(request.FormValue("confirm")); confirmed {
+		maxFraction = 1
+	}
+
+	todos, err := coreOf(request).DeleteCompleted(maxFraction)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	if todos == nil {
+		// Respond with an empty array instead of null.
+		todos = []core.TodoItem{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todos)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// ReplaceDescriptions replaces the text in the descriptions of all the TodoItems, in a single transaction. The replacement is passed as a JSON body,
+// where "ignore_case" and "dry_run" are optional:
+//
+//	{"find": "ProjX", "replace": "ProjY", "ignore_case": false, "dry_run": false}
+//
+// The response carries the number of TodoItems changed, or that would be changed if it's a dry run, in which case the TodoItems are previewed as well.
+//
+//	{"replaced": 2}
+//	{"replaced": 2, "preview": [{"id": 1, "description": "...", ...}, ...]}
+//
+// If "find" is empty, or any of the descriptions would become blank, nothing is changed and the server responds with a 422 status code.
+func ReplaceDescriptions(writer http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Find       string `json:"find"`
+		Replace    string `json:"replace"`
+		IgnoreCase bool   `json:"ignore_case"`
+		DryRun     bool   `json:"dry_run"`
+	}
+	err := decodeJSON(request, &body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	todos, err := coreOf(request).ReplaceInDescriptions(core.DescriptionReplacement{
+		Find: body.Find, Replace: body.Replace, IgnoreCase: body.IgnoreCase, DryRun: body.DryRun,
+	})
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	response := struct {
+		Replaced int             `json:"replaced"`
+		Preview  []core.TodoItem `json:"preview,omitempty"`
+	}{Replaced: len(todos)}
+	if body.DryRun {
+		response.Preview = todos
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(response)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// RestoreItems brings back the deleted TodoItems, e.g., the ones responded by DeleteCompleted, which are passed as a JSON array.
+// A TodoItem keeps its id if possible and is re-created with a new id otherwise; the response is the restored TodoItems.
+//
+//	[{"id": 1, "description": "...", "completed": true, ...}, ...]
+//
+// If any of the TodoItems is not deleted, nothing is restored and the server responds with a 409 status code.
+func RestoreItems(writer http.ResponseWriter, request *http.Request) {
+	var items []core.TodoItem
+	err := decodeJSON(request, &items)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	todos, err := coreOf(request).RestoreItems(items)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	if todos == nil {
+		// Respond with an empty array instead of null.
+		todos = []core.TodoItem{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todos)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// batchResult is a core.BatchResult as responded by Batch, with the status code and the message of its error.
+type batchResult struct {
+	core.BatchResult
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Batch applies the operations passed as a JSON array in order, and responds with the result of each of them, in the same order.
+//
+//	[{"op": "create", "description": "...", "color": "..."}, {"op": "update", "id": 1, "completed": true}, {"op": "delete", "id": 2}]
+//
+// The status of a result is the status code the operation would have been responded with on its own:
+//
+//	{"results": [{"op": "create", "id": 3, "item": {...}, "status": 201}, {"op": "update", "id": 2, "status": 404, "error": "..."}, ...]}
+//
+// Like DELETE /todo/{id}, deleting a TodoItem that doesn't exist succeeds.
+//
+// The operations are applied in a single transaction unless the query parameter "atomic" is false.
+// If one of them fails in a transaction, none takes effect and the server responds with the status code of its error (see writeCoreError),
+// along with the position of the operation in the batch, starting from 0:
+//
+//	{"error": "some error message", "index": 1}
+//
+// At most core.MaxBatchOps operations can be applied at once; more are rejected with a 400 status code.
+func Batch(writer http.ResponseWriter, request *http.Request) {
+	atomic := true
+	if s := request.URL.Query().Get("atomic"); s != "" {
+		var err error
+		atomic, err = strconv.ParseBool(s)
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid atomic %q", s))
+			return
+		}
+	}
+	var ops []core.BatchOp
+	err := decodeJSON(request, &ops)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+
+	results, err := coreOf(request).Batch(ops, atomic)
+	var batchErr core.BatchError
+	if errors.As(err, &batchErr) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCodeOf(batchErr.Err))
+		err = json.NewEncoder(writer).Encode(map[string]any{"error": batchErr.Error(), "index": batchErr.Index})
+		if err != nil {
+			log.Error("Error encoding response")
+		}
+		return
+	}
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	response := make([]batchResult, len(results))
+	for i, result := range results {
+		response[i] = batchResult{BatchResult: result, Status: http.StatusOK}
+		switch {
+		case result.Err != nil:
+			response[i].Status = statusCodeOf(result.Err)
+			response[i].Error = result.Err.Error()
+		case result.Op == core.BatchCreate:
+			response[i].Status = http.StatusCreated
+		}
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]any{"results": response})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// GetItem returns the TodoItem with the specified id.
+//
+// If the TodoItem was not found in the database, the server responds with a 404 status code:
+//
+//	{"error": "some error message"}
+//
+// Like GetItems, the TodoItem is encoded in XML if the client accepts "application/xml".
+//
+// The update time of the TodoItem is sent as the Last-Modified header. If the client passes it back with the If-Modified-Since header
+// and the TodoItem is not updated since, the server responds with a 304 status code and no body.
+func GetItem(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	todo, err := coreOf(request).GetItem(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	if !todo.UpdatedAt.IsZero() {
+		// NOTE: The HTTP dates are only precise to the second.
+		lastModified := todo.UpdatedAt.UTC().Truncate(time.Second)
+		writer.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		if since, err := http.ParseTime(request.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(since) {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	writeNegotiated(writer, request, todo, xmlTodoItem{TodoItem: todo})
+}
+
+// ItemExists responds to HEAD requests with a 200 status code if the TodoItem with the specified id exists, or a 404 status code otherwise,
+// without reading the TodoItem; there's no body either way.
+//
+// NOTE: Unlike the other HEAD requests, it doesn't mirror GET, so there's no Last-Modified header.
+func ItemExists(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	exists, err := coreOf(request).ExistsItem(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	if !exists {
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// GetItems returns all TodoItems from the database.
+// The completed status of the TodoItems can be filtered by passing a query parameter named "completed".
+// If the query parameter "completed" is not passed, the TodoItems are filtered by the default filter set by SetDefaultFilter,
+// which returns all TodoItems unless changed.
+// The TodoItems are listed in the manual order set by Reorder if the query parameter "sort" is "position", or in the order of their ids if it's "id";
+// either may be followed by ":asc", or by ":desc" for the reverse order. If it's not passed, they are listed in the order set by SetDefaultSort, which is by id unless changed,
+// except when paged through with the cursor, which always goes by id.
+//
+// The TodoItems are encoded in JSON by default, or in XML if the client accepts "application/xml" with the Accept header.
+// In JSON, they are a bare array, unless the client asks for an envelope with the query parameter "envelope=true",
+// or with `Accept: application/json; profile="envelope"` (see EnvelopeProfile); the query parameter takes precedence.
+// The pages below are objects already, so they are never enveloped.
+//
+//	[{"id": 1, ...}, {"id": 2, ...}]
+//	{"data": [{"id": 1, ...}, {"id": 2, ...}], "meta": {"count": 2}}
+//
+// The TodoItems created after a time, exclusively, can be fetched by passing the time in RFC 3339 as a query parameter named "created_after",
+// and the ones created before a time, inclusively, by passing "created_before". They can be combined with each other and with "completed",
+// e.g., "?created_after=2024-01-01T00:00:00Z&created_before=2024-01-08T00:00:00Z" for a week; the TodoItems are then ordered by id unless sorted otherwise.
+// If a time is malformed or "created_after" is later than "created_before", the server responds with a 400 status code.
+//
+// The TodoItems in a list can be fetched by passing the id of the list as a query parameter named "list_id", e.g., "?list_id=2" (see MoveItem).
+// If the list id is malformed, the server responds with a 400 status code.
+//
+// The TodoItems in a status can be fetched by passing a query parameter named "status", which is "todo", "in_progress" (see StartItem), or "completed".
+// If the status is none of them, the server responds with a 400 status code.
+//
+// A specific set of TodoItems can be fetched by passing a comma-separated query parameter named "ids", e.g., "?ids=1,3,5".
+// Ids that don't exist are ignored. If more than core.MaxBatchIDs ids are passed, the server responds with a 400 status code.
+//
+// The TodoItems can also be paged through in the order of their ids by passing the query parameters "after" and "limit", e.g., "?after=20&limit=10".
+// The response is then a page with the cursor to pass as "after" to get the next page; the cursor is empty on the last page.
+// The cursor can't be combined with "sort=position", a descending sort like "sort=id:desc", or "offset".
+//
+//	{"items": [...], "limit": 10, "next_cursor": "30"}
+//
+// Alternatively, the TodoItems can be paged through by passing the query parameter "offset", with an optional "limit", e.g., "?offset=40&limit=20".
+// The response is then a page with the total number of TodoItems, and the Link header (RFC 8288) carries the URLs of the
+// "first", "prev", "next", and "last" pages; "prev" and "next" are omitted on the first and the last page respectively.
+//
+//	{"items": [...], "offset": 40, "limit": 20, "total": 95}
+//	Link: </todo?limit=20&offset=0>; rel="first", </todo?limit=20&offset=20>; rel="prev", </todo?limit=20&offset=60>; rel="next", </todo?limit=20&offset=80>; rel="last"
+//
+// Either kind of page tells its effective limit: core.DefaultPageSize if "limit" is not passed or not positive,
+// and at most the maximum page size set by SetMaxPageSize, to which a larger limit is clamped.
+//
+// The filters, the sort, and either kind of pagination all go through core.Query, so they can be combined freely,
+// e.g., "?completed=false&sort=position&offset=20&limit=10"; the total is then the number of the filtered TodoItems.
+func GetItems(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	if query.Has("ids") {
+		getItemsByIDs(writer, request)
+		return
+	}
+	for _, name := range []string{"offset", "after", "limit", "sort", "created_after", "created_before", "list_id", "status"} {
+		if query.Has(name) {
+			queryItems(writer, request)
+			return
+		}
+	}
+	if defaultSort != core.SortByID || defaultDescending {
+		queryItems(writer, request)
+		return
+	}
+
+	theCore := coreOf(request)
+	var todos []core.TodoItem
+	// If the query parameter "completed" is not passed, the TodoItems are filtered by the default filter.
+	if completed := completedParam(request); completed == nil {
+		todos = theCore.GetItems(true)
+		todos = append(todos, theCore.GetItems(false)...)
+		// Each half is ordered by id but not the whole.
+		slices.SortFunc(todos, func(a, b core.TodoItem) int { return cmp.Compare(a.ID, b.ID) })
+	} else {
+		todos = theCore.GetItems(*completed)
+	}
+
+	writeItems(writer, request, todos)
+}
+
+// GetItemsToday responds with the TodoItems completed today, in the time zone of the client.
+//
+// The time zone is passed either as a query parameter named "utc_offset", e.g., "+08:00",
+// or as an IANA name in the "X-Timezone" header, e.g., "Asia/Taipei". The query parameter takes precedence;
+// the time zone set by SetDefaultTimezone is used if neither is passed.
+//
+//	[{"id": 1, "description": "...", "completed": true, "completed_at": "...", ...}, ...]
+//
+// If the time zone is invalid, the server responds with a 400 status code.
+func GetItemsToday(writer http.ResponseWriter, request *http.Request) {
+	loc, err := clientLocation(request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	todos := coreOf(request).GetItemsCompletedOn(clock.Now().In(loc))
+	if todos == nil {
+		todos = []core.TodoItem{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(todos)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// clientLocation returns the time zone of the client; see GetItemsToday.
+func clientLocation(request *http.Request) (*time.Location, error) {
+	if s := request.FormValue("utc_offset"); s != "" {
+		t, err := time.Parse("-07:00", s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid utc_offset %q, expected +hh:mm or -hh:mm", s)
+		}
+		return t.Location(), nil
+	}
+	if name := request.Header.Get("X-Timezone"); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid X-Timezone %q", name)
+		}
+		return loc, nil
+	}
+	return defaultLocation, nil
+}
+
+// GetChanges returns the TodoItems created or updated, and the ids of the TodoItems deleted, after the time passed as a query parameter named "since" in RFC 3339.
+// If the time is missing or malformed, the server responds with a 400 status code.
+//
+//	{"changed": [...], "deleted": [2, 5]}
+func GetChanges(writer http.ResponseWriter, request *http.Request) {
+	since, err := time.Parse(time.RFC3339, request.FormValue("since"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid since %q, expected RFC 3339", request.FormValue("since")))
+		return
+	}
+
+	changed, deletedIDs, err := coreOf(request).GetChangesSince(since)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	// Respond with empty arrays instead of null.
+	if changed == nil {
+		changed = []core.TodoItem{}
+	}
+	if deletedIDs == nil {
+		deletedIDs = []core.ItemID{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]any{"changed": changed, "deleted": deletedIDs})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// SuggestDescriptions returns the descriptions that start with the prefix passed as a query parameter named "prefix", case-insensitively.
+// At most core.MaxSuggestions distinct descriptions are returned, with the most recently created ones first.
+// If the prefix is empty, the server responds with a 400 status code.
+//
+//	["buy milk", "buy eggs"]
+func SuggestDescriptions(writer http.ResponseWriter, request *http.Request) {
+	prefix := request.FormValue("prefix")
+	if prefix == "" {
+		writeError(writer, http.StatusBadRequest, errors.New("prefix is required"))
+		return
+	}
+
+	descriptions := coreOf(request).SuggestDescriptions(prefix, core.MaxSuggestions)
+	if descriptions == nil {
+		// Respond with an empty array instead of null.
+		descriptions = []string{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(writer).Encode(descriptions)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// markdownEscaper escapes the characters that Markdown would take as formatting, so that the descriptions are rendered literally.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`, "~", `\~`, "!", `\!`,
+)
+
+// ExportMarkdown responds with all the TodoItems as a Markdown checklist, grouped by completion and ordered by id within each group.
+// The groups without TodoItems are left out.
+//
+//	## Active
+//
+//	- [ ] Buy milk
+//
+//	## Completed
+//
+//	- [x] Call mom
+//
+// The descriptions are escaped, so that they are not taken as Markdown formatting.
+func ExportMarkdown(writer http.ResponseWriter, request *http.Request) {
+	todos, err := coreOf(request).GetItemsFiltered(core.ItemFilter{})
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	var active, completed strings.Builder
+	for _, todo := range todos {
+		// NOTE: The descriptions created before the normalization may still span lines, which would break the list.
+		description := markdownEscaper.Replace(strings.Join(strings.Fields(todo.Description), " "))
+		if todo.Completed {
+			fmt.Fprintf(&completed, "- [x] %s\n", description)
+		} else {
+			fmt.Fprintf(&active, "- [ ] %s\n", description)
+		}
+	}
+	var groups []string
+	if active.Len() > 0 {
+		groups = append(groups, "## Active\n\n"+active.String())
+	}
+	if completed.Len() > 0 {
+		groups = append(groups, "## Completed\n\n"+completed.String())
+	}
+
+	writer.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, err = io.WriteString(writer, strings.Join(groups, "\n"))
+	if err != nil {
+		log.Error("Error writing response to client")
+	}
+}
+
+// checklistItem matches a line of a Markdown checklist, capturing the check mark and the description.
+var checklistItem = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+// markdownEscape matches a backslash escape of Markdown, capturing the escaped character.
+var markdownEscape = regexp.MustCompile("\\\\([!-/:-@[-`{-~])")
+
+// parseMarkdownChecklist returns the TodoItems of the checklist lines of the Markdown document, in order, and the number of the other lines that are not blank.
+// The checklist lines without a description are skipped as well. The backslash escapes in the descriptions are undone, so that the export of ExportMarkdown round-trips.
+func parseMarkdownChecklist(document string) (todos []core.TodoItem, skipped int) {
+	for _, line := range strings.Split(document, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		match := checklistItem.FindStringSubmatch(line)
+		if match == nil || strings.TrimSpace(match[2]) == "" {
+			skipped++
+			continue
+		}
+		todos = append(todos, core.TodoItem{
+			Description: markdownEscape.ReplaceAllString(match[2], "$1"),
+			Completed:   match[1] != " ",
+		})
+	}
+	return todos, skipped
+}
+
+// ImportMarkdown creates a TodoItem from each line of the Markdown checklist in the request body, e.g., the one exported by ExportMarkdown.
+// The TodoItems of "- [x]" lines are completed, and the ones of "- [ ]" lines are not. The other lines, e.g., headers and prose, are skipped.
+// Either all the TodoItems are created or none is.
+//
+// The response tells how many checklist lines were parsed into TodoItems and how many lines, not counting the blank ones, were skipped:
+//
+//	{"parsed": 3, "skipped": 2}
+//
+// If the operation failed, the server responds with the status code of the error (see writeCoreError):
+//
+//	{"error": "some error message"}
+func ImportMarkdown(writer http.ResponseWriter, request *http.Request) {
+	document, err := io.ReadAll(request.Body)
+	if err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	todos, skipped := parseMarkdownChecklist(string(document))
+
+	created, err := coreOf(request).CreateItems(todos)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(map[string]int{"parsed": len(created), "skipped": skipped})
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// Summary returns the aggregated statistics of all TodoItems.
+//
+//	{"total": 3, "completed": 1, "active": 2, "completion_rate": 0.333}
+func Summary(writer http.ResponseWriter, request *http.Request) {
+	stats, err := coreOf(request).Summary()
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(stats)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+func getItemsByIDs(writer http.ResponseWriter, request *http.Request) {
+	var ids []core.ItemID
+	for _, s := range strings.Split(request.FormValue("ids"), ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid id %q", s))
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) > core.MaxBatchIDs {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("at most %d ids can be fetched at once", core.MaxBatchIDs))
+		return
+	}
+
+	todos := coreOf(request).GetItemsByIDs(ids)
+	if todos == nil {
+		// Respond with an empty array instead of null.
+		todos = []core.TodoItem{}
+	}
+
+	writeItems(writer, request, todos)
+}
+
+// queryItems responds with the TodoItems of the core.Query built from the query parameters of the request, combining all of the filter, the sort, and the pagination of GetItems.
+func queryItems(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	q := core.Query{Filter: core.ItemFilter{Completed: completedParam(request)}}
+	var err error
+	q.Filter.CreatedAfter, err = timeParam(request, "created_after")
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	q.Filter.CreatedBefore, err = timeParam(request, "created_before")
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+	if query.Has("list_id") {
+		listID, err := strconv.Atoi(query.Get("list_id"))
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid list_id %q", query.Get("list_id")))
+			return
+		}
+		q.Filter.ListID = &listID
+	}
+	if query.Has("status") {
+		status, err := core.ParseItemStatus(query.Get("status"))
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, err)
+			return
+		}
+		q.Filter.Status = &status
+	}
+	switch {
+	case query.Has("sort"):
+		if q.Sort, q.Descending, err = parseSort(query.Get("sort")); err != nil {
+			writeError(writer, http.StatusBadRequest, err)
+			return
+		}
+	case query.Has("after"):
+		// The cursor requires sorting by id in the ascending order, whatever the default.
+		q.Sort = core.SortByID
+	default:
+		q.Sort, q.Descending = defaultSort, defaultDescending
+	}
+	byOffset := query.Has("offset")
+	if byOffset {
+		if q.Offset, err = strconv.Atoi(query.Get("offset")); err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid offset %q", query.Get("offset")))
+			return
+		}
+	}
+	if s := query.Get("after"); s != "" {
+		if q.After, err = strconv.Atoi(s); err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid cursor %q", s))
+			return
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid limit %q", s))
+			return
+		}
+	}
+	paged := byOffset || query.Has("after") || query.Has("limit")
+	if paged && q.Limit <= 0 {
+		q.Limit = core.DefaultPageSize
+	}
+	q.Limit = min(q.Limit, maxPageSize)
+
+	result, err := coreOf(request).Query(q)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	if result.Items == nil {
+		// Respond with an empty array instead of null.
+		result.Items = []core.TodoItem{}
+	}
+
+	switch {
+	case byOffset:
+		p := offsetPage{Items: result.Items, Offset: q.Offset, Limit: q.Limit, Total: result.Total}
+		writer.Header().Set("Link", pageLinks(request.URL, q.Offset, q.Limit, result.Total))
+		writeNegotiated(writer, request, p, p)
+	case paged:
+		p := page{Items: result.Items, Limit: q.Limit}
+		if result.NextCursor != 0 {
+			p.NextCursor = strconv.Itoa(result.NextCursor)
+		}
+		writeNegotiated(writer, request, p, p)
+	default:
+		writeItems(writer, request, result.Items)
+	}
+}
+
+// completedParam returns the completed status passed as the query parameter "completed", or the one of the default filter if it's not passed or malformed.
+// It's nil if the TodoItems are not to be filtered by their completed status.
+func completedParam(request *http.Request) *bool {
+	if completed, err := strconv.ParseBool(request.FormValue("completed")); err == nil {
+		return &completed
+	}
+	switch defaultFilter {
+	case "active":
+		completed := false
+		return &completed
+	case "completed":
+		completed := true
+		return &completed
+	default:
+		return nil
+	}
+}
+
+// timeParam returns the time in RFC 3339 passed as the query parameter of the name, or nil if it's not passed.
+// An error is returned if the time is malformed.
+func timeParam(request *http.Request, name string) (*time.Time, error) {
+	query := request.URL.Query()
+	if !query.Has(name) {
+		return nil, nil
+	}
+	value := query.Get(name)
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, expected RFC 3339", name, value)
+	}
+	return &t, nil
+}
+
+// page is a window of TodoItems obtained with cursor-based pagination.
+type page struct {
+	XMLName    xml.Name        `json:"-" xml:"page"`
+	Items      []core.TodoItem `json:"items" xml:"items>todo"`
+	Limit      int             `json:"limit" xml:"limit"`
+	NextCursor string          `json:"next_cursor" xml:"next_cursor"`
+}
+
+// offsetPage is a window of TodoItems obtained with offset-based pagination.
+type offsetPage struct {
+	XMLName xml.Name        `json:"-" xml:"page"`
+	Items   []core.TodoItem `json:"items" xml:"items>todo"`
+	Offset  int             `json:"offset" xml:"offset"`
+	Limit   int             `json:"limit" xml:"limit"`
+	Total   int             `json:"total" xml:"total"`
+}
+
+// pageLinks returns the value of the Link header that navigates through the pages of total items, limit items each, from the one at offset.
+// The URLs are the one of the request with the offset replaced, so that the base path and the other query parameters are kept.
+// NOTE: The URLs are relative to the host, which may differ from the one the server sees if it's behind a reverse proxy.
+func pageLinks(requestURL *url.URL, offset, limit, total int) string {
+	link := func(offset int, rel string) string {
+		query := requestURL.Query()
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(limit))
+		u := url.URL{Path: requestURL.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", u.String(), rel)
+	}
+
+	lastOffset := 0
+	if total > 0 {
+		lastOffset = (total - 1) / limit * limit
+	}
+	links := []string{link(0, "first")}
+	if offset > 0 {
+		links = append(links, link(max(offset-limit, 0), "prev"))
+	}
+	if offset+limit < total {
+		links = append(links, link(offset+limit, "next"))
+	}
+	links = append(links, link(lastOffset, "last"))
+	return strings.Join(links, ", ")
+}
+
+// xmlTodoItem names the XML element of a single TodoItem.
+type xmlTodoItem struct {
+	XMLName xml.Name `xml:"todo"`
+	core.TodoItem
+}
+
+// xmlTodoList names the XML elements of a list of TodoItems.
+type xmlTodoList struct {
+	XMLName xml.Name        `xml:"todos"`
+	Items   []core.TodoItem `xml:"todo"`
+}
+
+// EnvelopeProfile is the profile of "application/json" in the Accept header with which the client asks for the lists of TodoItems in an itemsEnvelope,
+// i.e., `Accept: application/json; profile="envelope"`.
+const EnvelopeProfile = "envelope"
+
+// itemsEnvelope wraps a list of TodoItems in JSON for the clients that ask for it; see wantsEnvelope.
+//
+//	{"data": [...], "meta": {"count": 2}}
+type itemsEnvelope struct {
+	Data []core.TodoItem `json:"data"`
+	Meta itemsMeta       `json:"meta"`
+}
+
+// itemsMeta describes the list of TodoItems in an itemsEnvelope.
+type itemsMeta struct {
+	Count int `json:"count"`
+}
+
+// writeItems responds with the TodoItems, encoded as the client accepts.
+// In JSON, they are a bare array unless the client asks for an itemsEnvelope; see wantsEnvelope. XML always has the enclosing element.
+func writeItems(writer http.ResponseWriter, request *http.Request, todos []core.TodoItem) {
+	var jsonValue any = todos
+	if wantsEnvelope(request) {
+		if todos == nil {
+			todos = []core.TodoItem{}
+		}
+		jsonValue = itemsEnvelope{Data: todos, Meta: itemsMeta{Count: len(todos)}}
+	}
+	writeNegotiated(writer, request, jsonValue, xmlTodoList{Items: todos})
+}
+
+// wantsEnvelope tells whether the client asks for the lists of TodoItems in an itemsEnvelope, either with the query parameter "envelope=true",
+// or with EnvelopeProfile on "application/json" in the Accept header. A malformed query parameter is taken as false, keeping the bare array.
+func wantsEnvelope(request *http.Request) bool {
+	if envelope, err := strconv.ParseBool(request.URL.Query().Get("envelope")); err == nil {
+		return envelope
+	}
+	for _, accept := range request.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err == nil && mediaType == "application/json" && params["profile"] == EnvelopeProfile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeNegotiated responds with xmlValue encoded in XML if the client accepts "application/xml", or with jsonValue encoded in JSON otherwise.
+// Media types other than JSON and XML are not rejected but fall back to JSON.
+func writeNegotiated(writer http.ResponseWriter, request *http.Request, jsonValue, xmlValue any) {
+	if !acceptsXML(request) {
+		writer.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(writer).Encode(jsonValue)
+		if err != nil {
+			log.Error("Error encoding response")
+		}
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/xml")
+	_, err := io.WriteString(writer, xml.Header)
+	if err == nil {
+		err = xml.NewEncoder(writer).Encode(xmlValue)
+	}
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// acceptsXML tells whether the client prefers XML over JSON, i.e., "application/xml" comes before "application/json" in the Accept header.
+func acceptsXML(request *http.Request) bool {
+	for _, accept := range request.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			switch mediaType {
+			case "application/xml":
+				return true
+			case "application/json":
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// writeError responds with the given status code and a JSON body carrying the error message.
+//
+//	{"error": "some error message"}
+func writeError(writer http.ResponseWriter, code int, err error) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+	e := json.NewEncoder(writer).Encode(map[string]string{"error": err.Error()})
+	if e != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+var strictJSON bool
+
+// SetStrictJSON turns the strict mode of the JSON bodies on or off. In the strict mode, a body with a field that the handler doesn't know,
+// e.g., a typo, is rejected with a 400 status code instead of the field being ignored.
+func SetStrictJSON(on bool) {
+	strictJSON = on
+}
+
+// decodeJSON decodes the JSON body of the request into v, rejecting the unknown fields in the strict mode; see SetStrictJSON.
+// The error is meant to be written with writeBodyError.
+func decodeJSON(request *http.Request, v any) error {
+	decoder := json.NewDecoder(request.Body)
+	if strictJSON {
+		decoder.DisallowUnknownFields()
+	}
+	return jsonBodyError(decoder.Decode(v))
+}
+
+// jsonBodyError translates the error of decoding a JSON body into a message the client can act on, e.g.,
+// "malformed JSON at offset 12" instead of "invalid character '}' looking for beginning of value".
+// The errors other than the ones of the decoding are returned as is, so that writeBodyError still tells them apart.
+func jsonBodyError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is empty, expected JSON")
+	// NOTE: json.Unmarshal tells a truncated body with a SyntaxError rather than io.ErrUnexpectedEOF.
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &syntaxErr) && syntaxErr.Error() == "unexpected end of JSON input":
+		return errors.New("malformed JSON: request body ends unexpectedly")
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Errorf("wrong type of request body: expected %s, got %s", jsonKind(typeErr.Type), typeErr.Value)
+		}
+		return fmt.Errorf("wrong type for field %q: expected %s, got %s", typeErr.Field, jsonKind(typeErr.Type), typeErr.Value)
+	}
+	// NOTE: The unknown fields rejected in the strict mode are only told by the message, e.g., `json: unknown field "complete"`.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Errorf("unknown field %s", field)
+	}
+	return err
+}
+
+// jsonKind names the kind of JSON value that decodes into the Go type t, e.g., "array" for a slice.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Pointer:
+		return jsonKind(t.Elem())
+	}
+	return t.String()
+}
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// writeBodyError responds with the error of reading the request body, with a 413 status code if the body exceeds the limit of MaxBodyBytes,
+// or a 400 status code otherwise.
+func writeBodyError(writer http.ResponseWriter, err error) {
+	if errors.As(err, new(*http.MaxBytesError)) {
+		writeError(writer, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+		return
+	}
+	writeError(writer, http.StatusBadRequest, err)
+}
+
+// writeCoreError responds with the error returned by the core, with the status code decided by statusCodeOf.
+// A core.ValidationError about particular fields also tells why each of them is invalid:
+//
+//	{"error": "validation failed", "fields": {"description": "required", "color": "some error message"}}
+func writeCoreError(writer http.ResponseWriter, err error) {
+	var validationErr core.ValidationError
+	if errors.As(err, &validationErr) && len(validationErr.Fields) > 0 {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCodeOf(err))
+		e := json.NewEncoder(writer).Encode(map[string]any{"error": validationErr.Message, "fields": validationErr.Fields})
+		if e != nil {
+			log.Error("Error encoding response")
+		}
+		return
+	}
+	writeError(writer, statusCodeOf(err), err)
+}
+
+// statusCodeOf maps the errors of the core to the status codes:
+//
+//   - core.TodoItemNotFoundError and core.ListNotFoundError: 404
+//   - core.ValidationError: 422 if it's about particular fields, 400 otherwise
+//   - core.ConflictError: 409
+//   - core.StorageError caused by a timeout, e.g., a query cancelled by the query timeout: 503
+//   - core.StorageError and any other error: 500
+func statusCodeOf(err error) int {
+	var validationErr core.ValidationError
+	switch {
+	case errors.As(err, new(core.TodoItemNotFoundError)), errors.As(err, new(core.ListNotFoundError)):
+		return http.StatusNotFound
+	case errors.As(err, &validationErr):
+		if len(validationErr.Fields) > 0 {
+			return http.StatusUnprocessableEntity
+		}
+		return http.StatusBadRequest
+	case errors.As(err, new(core.ConflictError)):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
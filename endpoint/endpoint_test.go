@@ -1,7 +1,9 @@
 package endpoint_test
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -10,9 +12,10 @@ import (
 	"net/url"
 	"os"
 	"reflect"
-	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"todolist/core"
 	"todolist/endpoint"
@@ -94,7 +97,7 @@ func TestHealthz(t *testing.T) {
 	e.expectEqual(want, got)
 }
 
-// TestCreateItem Give the CreateItem handler serve at the /todo endpoint, when a request is made to the endpoint with a description form parameter, then the server should respond with a 200 status code and a JSON response body describing the newly created TodoItem.
+// TestCreateItem Give the CreateItem handler serve at the /todo endpoint, when a request is made to the endpoint with a description form parameter, then the server should respond with a 201 status code, the URL of the newly created TodoItem in the Location header, and a JSON response body describing it.
 func TestCreateItem(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
@@ -102,8 +105,8 @@ func TestCreateItem(t *testing.T) {
 	e.router.HandleFunc(pattern, endpoint.CreateItem)
 	testDescription := "test"
 	e.mockCore.EXPECT().
-		CreateItem(testDescription).
-		Return(core.TodoItem{ID: 1, Description: testDescription, Completed: false})
+		CreateItem(testDescription, "").
+		Return(core.TodoItem{ID: 1, Description: testDescription, Completed: false}, nil)
 
 	// act
 	params := url.Values{
@@ -114,13 +117,276 @@ func TestCreateItem(t *testing.T) {
 	e.router.ServeHTTP(e.writer, request)
 
 	// assert
-	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectStatusCodeToBe(http.StatusCreated)
+	e.expectEqual("/todo/1", e.writer.Header().Get("Location"))
 	want := core.TodoItem{ID: 1, Description: testDescription, Completed: false}
 	got := core.TodoItem{}
 	e.expectUnmarshalWithoutError(&got)
 	e.expectEqual(want, got)
 }
 
+// TestUpsertItemCreates Given the UpsertItem handler serve at the /todo endpoint and the core creates the TodoItem, when a PUT request is made to the endpoint with the TodoItem,
+// then the server should respond with a 201 status code, the URL of the TodoItem in the Location header, and the TodoItem.
+func TestUpsertItemCreates(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.UpsertItem)
+	todo := core.TodoItem{ID: 5, Description: "test", Completed: true}
+	e.mockCore.EXPECT().
+		UpsertItem(todo).
+		Return(todo, true, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPut, pattern, strings.NewReader(`{"id": 5, "description": "test", "completed": true}`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusCreated)
+	e.expectEqual("/todo/5", e.writer.Header().Get("Location"))
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todo, got)
+}
+
+// TestUpsertItemUpdates Given the UpsertItem handler serve at the /todo endpoint and the core updates the TodoItem, when a PUT request is made to the endpoint with the TodoItem,
+// then the server should respond with a 200 status code and the TodoItem, without the Location header.
+func TestUpsertItemUpdates(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.UpsertItem)
+	todo := core.TodoItem{ID: 1, Description: "test"}
+	e.mockCore.EXPECT().
+		UpsertItem(todo).
+		Return(todo, false, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPut, pattern, strings.NewReader(`{"id": 1, "description": "test"}`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual("", e.writer.Header().Get("Location"))
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todo, got)
+}
+
+// TestUpsertItemMalformed Given the UpsertItem handler serve at the /todo endpoint, when a PUT request is made to the endpoint with a body that is not JSON,
+// then the server should respond with a 400 status code without calling the core.
+func TestUpsertItemMalformed(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.UpsertItem)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPut, pattern, strings.NewReader(`description=test`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestUpsertItemAttachments Given the UpsertItem handler serve at the /todo endpoint, when a PUT request is made to the endpoint with attachments,
+// then the server should respond with a 400 status code without calling the core, rather than dropping them.
+func TestUpsertItemAttachments(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.UpsertItem)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPut, pattern, strings.NewReader(`{"id": 1, "description": "test", "attachments": ["https://example.com"]}`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestCreateItemAttachments Given the CreateItem handler serve at the /todo endpoint, when a request is made to the endpoint with attachments,
+// then the server should respond with a 400 status code without calling the core, rather than dropping them.
+func TestCreateItemAttachments(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.CreateItem)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader("description=test&attachments=https%3A%2F%2Fexample.com"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestCreateItemJSONKeys Give the CreateItem handler serve at the /todo endpoint, when a request is made to the endpoint, then the keys of the TodoItem in the response body should be in lowercase.
+func TestCreateItemJSONKeys(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.CreateItem)
+	e.mockCore.EXPECT().
+		CreateItem("test", "").
+		Return(core.TodoItem{ID: 1, Description: "test", Completed: false}, nil)
+
+	// act
+	params := url.Values{
+		"description": []string{"test"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	want := map[string]json.RawMessage{
+		"id":          []byte(`1`),
+		"description": []byte(`"test"`),
+		"completed":   []byte(`false`),
+		"position":    []byte(`0`),
+		"color":       []byte(`""`),
+		"list_id":     []byte(`0`),
+		"created_at":  []byte(`"0001-01-01T00:00:00Z"`),
+		"updated_at":  []byte(`"0001-01-01T00:00:00Z"`),
+	}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestCreateItemWithColor Given the CreateItem handler serve at the /todo endpoint, when a request is made to the endpoint with a color form parameter, then the color should be passed to the core.
+func TestCreateItemWithColor(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.CreateItem)
+	want := core.TodoItem{ID: 1, Description: "test", Color: "#1e90ff"}
+	e.mockCore.EXPECT().
+		CreateItem("test", "#1e90ff").
+		Return(want, nil)
+
+	// act
+	params := url.Values{
+		"description": []string{"test"},
+		"color":       []string{"#1e90ff"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusCreated)
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestCreateItemInvalidFields Given the CreateItem handler serve at the /todo endpoint and the core rejects several fields, when a request is made to the endpoint,
+// then the server should respond with a 422 status code and tell why each of the fields is invalid.
+func TestCreateItemInvalidFields(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.CreateItem)
+	fields := map[string]string{"description": "required", "color": "invalid color"}
+	e.mockCore.EXPECT().
+		CreateItem("", "teal").
+		Return(core.TodoItem{}, core.ValidationError{Message: "validation failed", Fields: fields})
+
+	// act
+	params := url.Values{
+		"color": []string{"teal"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusUnprocessableEntity)
+	type response struct {
+		Error  string            `json:"error"`
+		Fields map[string]string `json:"fields"`
+	}
+	got := response{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(response{Error: "validation failed", Fields: fields}, got)
+}
+
+// TestSetItemColorInvalid Given the SetItemColor handler serve at the /todo/{id}/color endpoint and the core rejects the color, when a request is made to the endpoint, then the server should respond with a 400 status code.
+func TestSetItemColorInvalid(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/color"
+	e.router.HandleFunc(pattern, endpoint.SetItemColor)
+	e.mockCore.EXPECT().
+		SetItemColor(1, "#12345").
+		Return(core.TodoItem{}, core.ValidationError{Message: "invalid color"})
+
+	// act
+	params := url.Values{
+		"color": []string{"#12345"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/color", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+	want := map[string]string{"error": "invalid color"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestMoveItem Given the MoveItem handler serve at the /todo/{id}/move endpoint and the core moves the item, when a request is made to the endpoint with a list_id form parameter,
+// then the server should respond with a 200 status code and the moved item.
+func TestMoveItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/move"
+	e.router.HandleFunc(pattern, endpoint.MoveItem)
+	moved := core.TodoItem{ID: 1, Description: "test", ListID: 2}
+	e.mockCore.EXPECT().
+		MoveItem(1, 2).
+		Return(moved, nil)
+
+	// act
+	params := url.Values{
+		"list_id": []string{"2"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/move", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(moved, got)
+}
+
+// TestMoveItemMalformedListID Given the MoveItem handler serve at the /todo/{id}/move endpoint, when a request is made to the endpoint with a list_id that's not an integer,
+// then the server should respond with a 400 status code without calling the core.
+func TestMoveItemMalformedListID(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/move"
+	e.router.HandleFunc(pattern, endpoint.MoveItem)
+
+	// act
+	params := url.Values{
+		"list_id": []string{"work"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/move", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
 // TestUpdateItem Given the UpdateItem handler serve at the /todo/{id} endpoint and the core returns without error, when a request is made to the endpoint with a completed form parameter, then the server should respond with a 200 status code and a JSON response body indicating that the update was successful.
 func TestUpdateItem(t *testing.T) {
 	// arrange
@@ -149,7 +415,7 @@ func TestUpdateItem(t *testing.T) {
 	e.expectEqual(want, got)
 }
 
-// TestUpdateItemError Given the UpdateItem handler serve at the /todo/{id} endpoint and the core returns an error, when a request is made to the endpoint with a completed form parameter, then the server should respond with a 200 status code and a JSON response body indicating that the update was not successful.
+// TestUpdateItemError Given the UpdateItem handler serve at the /todo/{id} endpoint and the core returns an error, when a request is made to the endpoint with a completed form parameter, then the server should respond with a 500 status code and a JSON response body indicating that the update was not successful.
 func TestUpdateItemError(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
@@ -168,7 +434,7 @@ func TestUpdateItemError(t *testing.T) {
 	e.router.ServeHTTP(e.writer, request)
 
 	// assert
-	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectStatusCodeToBe(http.StatusInternalServerError)
 	// NOTE: We do not check the error message because it is not guaranteed to be the same as the one returned by the Core.
 	type body struct {
 		Updated bool `json:"updated"`
@@ -179,137 +445,2314 @@ func TestUpdateItemError(t *testing.T) {
 	e.expectEqual(want, got)
 }
 
-// TestGetItem Given the GetItem handler serve at the /todo/{id} endpoint and the core returns without error, when a request is made to the endpoint, then the server should respond with a 200 status code and a JSON response body indicating that the deletion was successful.
-func TestDeleteItem(t *testing.T) {
+// TestUpdateItemBlocked Given the UpdateItem handler serve at the /todo/{id} endpoint and the core refuses to complete a blocked item, when a request is made to complete it,
+// then the server should respond with a 409 status code and a JSON response body indicating that the update was not successful.
+func TestUpdateItemBlocked(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
 	pattern := "/todo/{id}"
-	e.router.HandleFunc(pattern, endpoint.DeleteItem)
-	testID := 1
+	e.router.HandleFunc(pattern, endpoint.UpdateItem)
 	e.mockCore.EXPECT().
-		DeleteItem(testID).
-		Return(nil)
+		UpdateItem(1, true).
+		Return(core.TodoItem{}, core.ConflictError{Message: "TodoItem with id 1 is blocked by the incomplete TodoItems [2]"})
 
 	// act
-	request, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/todo/%d", testID), strings.NewReader(""))
+	params := url.Values{
+		"completed": []string{`true`},
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	e.router.ServeHTTP(e.writer, request)
 
 	// assert
-	e.expectStatusCodeToBe(http.StatusOK)
-	want := map[string]json.RawMessage{"deleted": []byte(`true`)}
-	got := map[string]json.RawMessage{}
+	e.expectStatusCodeToBe(http.StatusConflict)
+	type body struct {
+		Updated bool `json:"updated"`
+	}
+	got := body{}
 	e.expectUnmarshalWithoutError(&got)
-	e.expectEqual(want, got)
+	e.expectEqual(body{Updated: false}, got)
 }
 
-// TestDeleteItemError Given the DeleteItem handler serve at the /todo/{id} endpoint and the core returns an error, when a request is made to the endpoint, then the server should respond with a 200 status code and a JSON response body indicating that the deletion was not successful.
-func TestDeleteItemError(t *testing.T) {
+// TestUpdateItemErrorEscaped Given the UpdateItem handler serve at the /todo/{id} endpoint and the core fails with an error message containing quotes,
+// when a request is made to the endpoint, then the response body should still be valid JSON carrying the message as is.
+func TestUpdateItemErrorEscaped(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
 	pattern := "/todo/{id}"
-	e.router.HandleFunc(pattern, endpoint.DeleteItem)
+	e.router.HandleFunc(pattern, endpoint.UpdateItem)
+	message := `column "completed" is "locked"`
 	e.mockCore.EXPECT().
-		DeleteItem(gomock.Any()).
-		Return(errors.New("test error"))
+		UpdateItem(1, true).
+		Return(core.TodoItem{}, errors.New(message))
 
 	// act
-	request, _ := http.NewRequest(http.MethodDelete, "/todo/1", strings.NewReader(""))
+	params := url.Values{
+		"completed": []string{`true`},
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	e.router.ServeHTTP(e.writer, request)
 
 	// assert
-	e.expectStatusCodeToBe(http.StatusOK)
-	// NOTE: We do not check the error message because it is not guaranteed to be the same as the one returned by the Core.
-	type body struct {
-		Deleted bool `json:"deleted"`
-	}
-	want := body{Deleted: false}
-	got := body{}
+	e.expectStatusCodeToBe(http.StatusInternalServerError)
+	want := map[string]any{"updated": false, "error": message}
+	got := map[string]any{}
 	e.expectUnmarshalWithoutError(&got)
 	e.expectEqual(want, got)
 }
 
-func TestGetItemsCompleted(t *testing.T) {
+// TestUpdateItemForce Given the UpdateItem handler serve at the /todo/{id} endpoint, when a request is made to the endpoint with the force form parameter,
+// then the item should be updated regardless of its blockers and the server should respond with a 200 status code.
+func TestUpdateItemForce(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
-	pattern := "/todo"
-	e.router.HandleFunc(pattern, endpoint.GetItems)
-	todoItems := []core.TodoItem{
-		{ID: 1, Description: "test1", Completed: true},
-		{ID: 3, Description: "test3", Completed: true},
-	}
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.UpdateItem)
 	e.mockCore.EXPECT().
-		GetItems(true).
-		Return(todoItems)
+		UpdateItem(gomock.Any(), gomock.Any()).
+		Times(0)
+	e.mockCore.EXPECT().
+		ForceUpdateItem(1, true).
+		Return(core.TodoItem{ID: 1, Completed: true}, nil)
 
 	// act
-	completed := true
-	request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/todo?completed=%t", completed), strings.NewReader(""))
+	params := url.Values{
+		"completed": []string{`true`},
+		"force":     []string{`true`},
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	e.router.ServeHTTP(e.writer, request)
 
 	// assert
 	e.expectStatusCodeToBe(http.StatusOK)
-	want := todoItems
-	got := []core.TodoItem{}
-	e.expectUnmarshalWithoutError(&got)
-	e.expectEqual(want, got)
 }
 
-func TestGetItemIncomplete(t *testing.T) {
+// TestStartItem Given the routes are registered by NewRouter and the core starts the item, when a request is made to the /todo/{id}/start endpoint,
+// then the server should respond with a 200 status code and the item in progress.
+func TestStartItem(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
-	pattern := "/todo"
-	e.router.HandleFunc(pattern, endpoint.GetItems)
-	todoItems := []core.TodoItem{
-		{ID: 2, Description: "test2", Completed: false},
-		{ID: 4, Description: "test4", Completed: false},
-	}
+	e.router = endpoint.NewRouter("")
+	startedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	todo := core.TodoItem{ID: 1, Description: "test1", StartedAt: &startedAt}
 	e.mockCore.EXPECT().
-		GetItems(false).
-		Return(todoItems)
+		StartItem(1).
+		Return(todo, nil)
 
 	// act
-	completed := false
-	request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/todo?completed=%t", completed), strings.NewReader(""))
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/start", nil)
 	e.router.ServeHTTP(e.writer, request)
 
 	// assert
 	e.expectStatusCodeToBe(http.StatusOK)
-	want := todoItems
-	got := []core.TodoItem{}
+	var got core.TodoItem
 	e.expectUnmarshalWithoutError(&got)
-	e.expectEqual(want, got)
+	e.expectEqual(todo, got)
 }
 
-func TestGetItemsAll(t *testing.T) {
+// TestGetTimeSpent Given the routes are registered by NewRouter and the core returns the time spent on a running item, when a request is made to the /todo/{id}/time endpoint,
+// then the server should respond with a 200 status code and the time spent in whole seconds.
+func TestGetTimeSpent(t *testing.T) {
 	// arrange
 	e := newTestEnv(t)
-	pattern := "/todo"
-	e.router.HandleFunc(pattern, endpoint.GetItems)
-	todoItems := []core.TodoItem{
-		{ID: 1, Description: "test1", Completed: true},
-		{ID: 2, Description: "test2", Completed: false},
-		{ID: 3, Description: "test3", Completed: true},
-		{ID: 4, Description: "test4", Completed: false},
-	}
-	e.mockCore.EXPECT().
-		GetItems(true).
-		Return([]core.TodoItem{todoItems[0], todoItems[2]}).
-		MaxTimes(1)
+	e.router = endpoint.NewRouter("")
 	e.mockCore.EXPECT().
-		GetItems(false).
-		Return([]core.TodoItem{todoItems[1], todoItems[3]}).
-		MaxTimes(1)
+		GetTimeSpent(1).
+		Return(core.TimeSpent{Total: 90*time.Minute + 500*time.Millisecond, Running: true}, nil)
 
 	// act
-	request, _ := http.NewRequest(http.MethodGet, "/todo", strings.NewReader(""))
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1/time", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	var got struct {
+		Seconds int64 `json:"seconds"`
+		Running bool  `json:"running"`
+	}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(int64(5400), got.Seconds)
+	e.expectEqual(true, got.Running)
+}
+
+// TestStopItemCompleted Given the routes are registered by NewRouter and the core refuses to stop the item as it's completed, when a request is made to the /todo/{id}/stop endpoint,
+// then the server should respond with a 409 status code.
+func TestStopItemCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	e.mockCore.EXPECT().
+		StopItem(1).
+		Return(core.TodoItem{}, core.ConflictError{Message: "TodoItem with id 1 is completed"})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/stop", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusConflict)
+}
+
+// TestAddBlockers Given the AddBlockers handler serve at the /todo/{id}/blockers endpoint and the core returns the blockers, when a request is made to the endpoint with the ids,
+// then the server should respond with a 200 status code and all the blockers of the item.
+func TestAddBlockers(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/blockers"
+	e.router.HandleFunc(pattern, endpoint.AddBlockers)
+	blockers := []core.TodoItem{{ID: 2, Description: "blocker 1"}, {ID: 3, Description: "blocker 2"}}
+	e.mockCore.EXPECT().
+		AddBlockers(1, []core.ItemID{3}).
+		Return(blockers, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/blockers", strings.NewReader(`{"ids": [3]}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	var got []core.TodoItem
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(blockers, got)
+}
+
+// TestAddBlockersCycle Given the AddBlockers handler serve at the /todo/{id}/blockers endpoint and the core rejects the blockers as they would form a cycle,
+// when a request is made to the endpoint, then the server should respond with a 409 status code.
+func TestAddBlockersCycle(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/blockers"
+	e.router.HandleFunc(pattern, endpoint.AddBlockers)
+	e.mockCore.EXPECT().
+		AddBlockers(2, []core.ItemID{1}).
+		Return(nil, core.ConflictError{Message: "TodoItem with id 2 already blocks TodoItem with id 1, which would form a cycle"})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/2/blockers", strings.NewReader(`{"ids": [1]}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusConflict)
+}
+
+// TestAddAttachment Given the AddAttachment handler serve at the /todo/{id}/attachments endpoint and the core attaches the URL,
+// when a request is made to the endpoint with the URL, then the server should respond with a 200 status code and the item with its attachments.
+func TestAddAttachment(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/attachments"
+	e.router.HandleFunc(pattern, endpoint.AddAttachment)
+	todo := core.TodoItem{ID: 1, Description: "Test description", Attachments: []string{"https://example.com/spec.pdf"}}
+	e.mockCore.EXPECT().
+		AddAttachment(1, "https://example.com/spec.pdf").
+		Return(todo, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/attachments", strings.NewReader(`{"url": "https://example.com/spec.pdf"}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	var got core.TodoItem
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todo, got)
+}
+
+// TestAddAttachmentInvalid Given the AddAttachment handler serve at the /todo/{id}/attachments endpoint and the core rejects the URL as malformed,
+// when a request is made to the endpoint, then the server should respond with a 422 status code.
+func TestAddAttachmentInvalid(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/attachments"
+	e.router.HandleFunc(pattern, endpoint.AddAttachment)
+	e.mockCore.EXPECT().
+		AddAttachment(1, "ftp://example.com").
+		Return(core.TodoItem{}, core.ValidationError{Message: "invalid url", Fields: map[string]string{"url": "not an http or https URL"}})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/attachments", strings.NewReader(`{"url": "ftp://example.com"}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusUnprocessableEntity)
+}
+
+// TestRemoveAttachment Given the routes are registered by NewRouter and the core detaches the URL, when a DELETE request is made to the /todo/{id}/attachments endpoint
+// with the URL as the query parameter, then the server should respond with a 200 status code and the item with the rest of its attachments.
+func TestRemoveAttachment(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	todo := core.TodoItem{ID: 1, Description: "Test description"}
+	e.mockCore.EXPECT().
+		RemoveAttachment(1, "https://example.com/spec.pdf?v=2").
+		Return(todo, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, "/todo/1/attachments?url="+url.QueryEscape("https://example.com/spec.pdf?v=2"), nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	var got core.TodoItem
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todo, got)
+}
+
+// TestGetBlockedItems Given the routes are registered by NewRouter and the core returns the blocked items, when a request is made to the /todo/blocked endpoint,
+// then the server should respond with a 200 status code and the blocked items, rather than taking "blocked" as an id.
+func TestGetBlockedItems(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	blocked := []core.TodoItem{{ID: 1, Description: "blocked"}}
+	e.mockCore.EXPECT().
+		GetBlockedItems().
+		Return(blocked, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/blocked", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	var got []core.TodoItem
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(blocked, got)
+}
+
+// TestToggleItem Given the ToggleItem handler serve at the /todo/{id}/toggle endpoint and the core returns the toggled item, when a request is made to the endpoint, then the server should respond with a 200 status code and a JSON response body describing the updated TodoItem.
+func TestToggleItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/toggle"
+	e.router.HandleFunc(pattern, endpoint.ToggleItem)
+	testID := 1
+	e.mockCore.EXPECT().
+		ToggleItem(testID).
+		Return(core.TodoItem{ID: testID, Description: "test", Completed: true}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/todo/%d/toggle", testID), strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := core.TodoItem{ID: testID, Description: "test", Completed: true}
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestCloneItem Given the CloneItem handler serve at the /todo/{id}/clone endpoint and the core returns the copy, when a request is made to the endpoint,
+// then the server should respond with a 201 status code, the URL of the copy in the Location header, and a JSON response body describing the copy.
+func TestCloneItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/clone"
+	e.router.HandleFunc(pattern, endpoint.CloneItem)
+	want := core.TodoItem{ID: 2, Description: "test (copy)", Color: "red"}
+	e.mockCore.EXPECT().
+		CloneItem(1).
+		Return(want, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/clone", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusCreated)
+	e.expectEqual("/todo/2", e.writer.Header().Get("Location"))
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestCloneItemNotFound Given the CloneItem handler serve at the /todo/{id}/clone endpoint and the core returns a TodoItemNotFoundError, when a request is made to the endpoint,
+// then the server should respond with a 404 status code.
+func TestCloneItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/clone"
+	e.router.HandleFunc(pattern, endpoint.CloneItem)
+	e.mockCore.EXPECT().
+		CloneItem(1).
+		Return(core.TodoItem{}, core.TodoItemNotFoundError{ID: 1})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/clone", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+}
+
+// TestToggleItemNotFound Given the ToggleItem handler serve at the /todo/{id}/toggle endpoint and the core returns a TodoItemNotFoundError, when a request is made to the endpoint, then the server should respond with a 404 status code and a JSON response body carrying the error.
+func TestToggleItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}/toggle"
+	e.router.HandleFunc(pattern, endpoint.ToggleItem)
+	e.mockCore.EXPECT().
+		ToggleItem(gomock.Any()).
+		Return(core.TodoItem{}, core.TodoItemNotFoundError{ID: 1})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/1/toggle", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	if _, ok := got["error"]; !ok {
+		e.t.Errorf("expected an error in the response body, got %v", got)
+	}
+}
+
+// TestReplaceDescriptions Given the ReplaceDescriptions handler serve at the /todo/replace endpoint, when a replacement is posted,
+// then the core replaces the text and the server responds with the number of TodoItems changed, without a preview.
+func TestReplaceDescriptions(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/replace"
+	e.router.HandleFunc(pattern, endpoint.ReplaceDescriptions)
+	e.mockCore.EXPECT().
+		ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: "ProjY", IgnoreCase: true}).
+		Return([]core.TodoItem{{ID: 1, Description: "ProjY: spec"}, {ID: 3, Description: "Ship ProjY"}}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"find": "ProjX", "replace": "ProjY", "ignore_case": true}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]json.RawMessage{"replaced": []byte(`2`)}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestReplaceDescriptionsDryRun Given the ReplaceDescriptions handler serve at the /todo/replace endpoint, when a dry-run replacement is posted,
+// then the server responds with the number of TodoItems that would be changed and their preview.
+func TestReplaceDescriptionsDryRun(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/replace"
+	e.router.HandleFunc(pattern, endpoint.ReplaceDescriptions)
+	preview := []core.TodoItem{{ID: 1, Description: "ProjY: spec"}}
+	e.mockCore.EXPECT().
+		ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: "ProjY", DryRun: true}).
+		Return(preview, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"find": "ProjX", "replace": "ProjY", "dry_run": true}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	var got struct {
+		Replaced int             `json:"replaced"`
+		Preview  []core.TodoItem `json:"preview"`
+	}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(1, got.Replaced)
+	e.expectEqual(preview, got.Preview)
+}
+
+// TestReplaceDescriptionsNoMatch Given the ReplaceDescriptions handler serve at the /todo/replace endpoint, when the text to find is in no description,
+// then the server responds with zero TodoItems changed.
+func TestReplaceDescriptionsNoMatch(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/replace"
+	e.router.HandleFunc(pattern, endpoint.ReplaceDescriptions)
+	e.mockCore.EXPECT().
+		ReplaceInDescriptions(core.DescriptionReplacement{Find: "ProjX", Replace: "ProjY"}).
+		Return(nil, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"find": "ProjX", "replace": "ProjY"}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]json.RawMessage{"replaced": []byte(`0`)}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestGetItem Given the GetItem handler serve at the /todo/{id} endpoint and the core returns without error, when a request is made to the endpoint, then the server should respond with a 200 status code and a JSON response body indicating that the deletion was successful.
+func TestDeleteItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.DeleteItem)
+	testID := 1
+	e.mockCore.EXPECT().
+		DeleteItem(testID).
+		Return(nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/todo/%d", testID), strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]json.RawMessage{"deleted": []byte(`true`)}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestDeleteItemError Given the DeleteItem handler serve at the /todo/{id} endpoint and the core returns an error, when a request is made to the endpoint, then the server should respond with a 500 status code and a JSON response body indicating that the deletion was not successful.
+func TestDeleteItemError(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.DeleteItem)
+	e.mockCore.EXPECT().
+		DeleteItem(gomock.Any()).
+		Return(errors.New("test error"))
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, "/todo/1", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusInternalServerError)
+	// NOTE: We do not check the error message because it is not guaranteed to be the same as the one returned by the Core.
+	type body struct {
+		Deleted bool `json:"deleted"`
+	}
+	want := body{Deleted: false}
+	got := body{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestDeleteItemErrorEscaped Given the DeleteItem handler serve at the /todo/{id} endpoint and the core fails with an error message containing quotes,
+// when a request is made to the endpoint, then the response body should still be valid JSON carrying the message as is.
+func TestDeleteItemErrorEscaped(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.DeleteItem)
+	message := `row "1" is "locked"`
+	e.mockCore.EXPECT().
+		DeleteItem(1).
+		Return(errors.New(message))
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, "/todo/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusInternalServerError)
+	want := map[string]any{"deleted": false, "error": message}
+	got := map[string]any{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+func TestGetItemsCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	todoItems := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true},
+		{ID: 3, Description: "test3", Completed: true},
+	}
+	e.mockCore.EXPECT().
+		GetItems(true).
+		Return(todoItems)
+
+	// act
+	completed := true
+	request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/todo?completed=%t", completed), strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := todoItems
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+func TestGetItemIncomplete(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	todoItems := []core.TodoItem{
+		{ID: 2, Description: "test2", Completed: false},
+		{ID: 4, Description: "test4", Completed: false},
+	}
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(todoItems)
+
+	// act
+	completed := false
+	request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/todo?completed=%t", completed), strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := todoItems
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+func TestGetItemsAll(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	todoItems := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true},
+		{ID: 2, Description: "test2", Completed: false},
+		{ID: 3, Description: "test3", Completed: true},
+		{ID: 4, Description: "test4", Completed: false},
+	}
+	e.mockCore.EXPECT().
+		GetItems(true).
+		Return([]core.TodoItem{todoItems[0], todoItems[2]}).
+		MaxTimes(1)
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return([]core.TodoItem{todoItems[1], todoItems[3]}).
+		MaxTimes(1)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := todoItems
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestGetItemsEnvelope Given the GetItems handler serve at the /todo endpoint, when the same TodoItems are requested bare, with the envelope query parameter,
+// and with the envelope profile in the Accept header, then the server responds with the bare array, and with the TodoItems in an envelope for the other two.
+func TestGetItemsEnvelope(t *testing.T) {
+	todoItems := []core.TodoItem{{ID: 1, Description: "test1", Completed: true}, {ID: 3, Description: "test3", Completed: true}}
+	tests := []struct {
+		name   string
+		url    string
+		accept string
+		// envelope tells whether the TodoItems are expected in an envelope.
+		envelope bool
+	}{
+		{name: "bare", url: "/todo?completed=true", envelope: false},
+		{name: "query parameter", url: "/todo?completed=true&envelope=true", envelope: true},
+		{name: "accept profile", url: "/todo?completed=true", accept: `application/json; profile="envelope"`, envelope: true},
+		{name: "query parameter over accept profile", url: "/todo?completed=true&envelope=false", accept: `application/json; profile="envelope"`, envelope: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			e.router.HandleFunc("/todo", endpoint.GetItems)
+			e.mockCore.EXPECT().
+				GetItems(true).
+				Return(todoItems)
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+			if tt.envelope {
+				var got struct {
+					Data []core.TodoItem `json:"data"`
+					Meta struct {
+						Count int `json:"count"`
+					} `json:"meta"`
+				}
+				e.expectUnmarshalWithoutError(&got)
+				e.expectEqual(todoItems, got.Data)
+				e.expectEqual(2, got.Meta.Count)
+			} else {
+				got := []core.TodoItem{}
+				e.expectUnmarshalWithoutError(&got)
+				e.expectEqual(todoItems, got)
+			}
+		})
+	}
+}
+
+// TestGetItemsByIDs Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with an ids query parameter, then the server should respond with a 200 status code and the TodoItems returned by the core.
+func TestGetItemsByIDs(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	todoItems := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true},
+		{ID: 3, Description: "test3", Completed: false},
+	}
+	e.mockCore.EXPECT().
+		GetItemsByIDs([]int{1, 3, 5, 3}).
+		Return(todoItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?ids=1,3,5,3", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := todoItems
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestGetItemsByIDsInvalid Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with a malformed ids query parameter, then the server should respond with a 400 status code.
+func TestGetItemsByIDsInvalid(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?ids=1,abc", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestGetItemsByIDsTooMany Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with more than core.MaxBatchIDs ids, then the server should respond with a 400 status code.
+func TestGetItemsByIDsTooMany(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	var ids []string
+	for i := 0; i <= core.MaxBatchIDs; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?ids="+strings.Join(ids, ","), strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestExportMarkdown Given the routes are registered by NewRouter and the core has both active and completed items, when a request is made to the /todo/export.md endpoint,
+// then the server should respond with a Markdown checklist, in which the items are grouped under headers and the completed ones are checked.
+func TestExportMarkdown(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	e.mockCore.EXPECT().
+		GetItemsFiltered(core.ItemFilter{}).
+		Return([]core.TodoItem{
+			{ID: 1, Description: "Buy milk", Completed: false},
+			{ID: 2, Description: "Call mom", Completed: true},
+			{ID: 3, Description: "Walk the dog", Completed: false},
+		}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/export.md", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual("text/markdown; charset=utf-8", e.writer.Header().Get("Content-Type"))
+	want := "## Active\n\n- [ ] Buy milk\n- [ ] Walk the dog\n\n## Completed\n\n- [x] Call mom\n"
+	e.expectEqual(want, e.writer.Body.String())
+}
+
+// TestExportMarkdownEscapes Given the core has an item whose description contains Markdown formatting, when a request is made to the ExportMarkdown handler,
+// then the formatting characters should be escaped and the group without items left out.
+func TestExportMarkdownEscapes(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/export.md"
+	e.router.HandleFunc(pattern, endpoint.ExportMarkdown)
+	e.mockCore.EXPECT().
+		GetItemsFiltered(core.ItemFilter{}).
+		Return([]core.TodoItem{
+			{ID: 1, Description: "Fix *bold* [link](x) in `code`\n# heading", Completed: true},
+		}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := "## Completed\n\n- [x] Fix \\*bold\\* \\[link\\](x) in \\`code\\` \\# heading\n"
+	e.expectEqual(want, e.writer.Body.String())
+}
+
+// TestImportMarkdown Given the routes are registered by NewRouter, when a Markdown document with headers, prose, and checklist items is posted to the /todo/import.md endpoint,
+// then the core should create an item for each checklist line with its completion state, and the server should respond with the numbers of the parsed and the skipped lines.
+func TestImportMarkdown(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	items := []core.TodoItem{
+		{Description: "Buy milk"},
+		{Description: "Call *mom*", Completed: true},
+		{Description: "Walk the dog"},
+	}
+	e.mockCore.EXPECT().
+		CreateItems(items).
+		Return(items, nil)
+	document := "# Weekend\n" +
+		"\n" +
+		"Some things to do before Monday.\n" +
+		"\n" +
+		"- [ ] Buy milk\n" +
+		"- [x] Call \\*mom\\*\n" +
+		"  * [ ] Walk the dog\n" +
+		"- [ ]\n" +
+		"- plain bullet\n"
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo/import.md", strings.NewReader(document))
+	request.Header.Set("Content-Type", "text/markdown")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := map[string]int{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(map[string]int{"parsed": 3, "skipped": 4}, got)
+}
+
+// TestImportMarkdownError Given the core fails to create the items, when a Markdown checklist is posted to the ImportMarkdown handler, then the server should respond with the status code of the error.
+func TestImportMarkdownError(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/import.md"
+	e.router.HandleFunc(pattern, endpoint.ImportMarkdown)
+	e.mockCore.EXPECT().
+		CreateItems(gomock.Any()).
+		Return(nil, core.StorageError{Err: errors.New("some error")})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader("- [ ] Buy milk\n"))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusInternalServerError)
+}
+
+// TestSummary Given the Summary handler serve at the /todo/summary endpoint, when a request is made to the endpoint, then the server should respond with a 200 status code and the statistics returned by the core.
+func TestSummary(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/summary"
+	e.router.HandleFunc(pattern, endpoint.Summary)
+	stats := core.SummaryStats{Total: 4, Completed: 1, Active: 3, CompletionRate: 0.25}
+	e.mockCore.EXPECT().
+		Summary().
+		Return(stats, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]json.RawMessage{
+		"total":           []byte(`4`),
+		"completed":       []byte(`1`),
+		"active":          []byte(`3`),
+		"completion_rate": []byte(`0.25`),
+	}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestGetItemsDefaultFilter Given a default filter is set, when requests are made to the /todo endpoint with and without the completed query parameter, then the default filter should only apply without the parameter.
+func TestGetItemsDefaultFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		query  string
+		want   []bool
+	}{
+		{"all", "", []bool{true, false}},
+		{"all", "?completed=false", []bool{false}},
+		{"active", "", []bool{false}},
+		{"active", "?completed=true", []bool{true}},
+		{"completed", "", []bool{true}},
+		{"completed", "?completed=false", []bool{false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter+tt.query, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			if err := endpoint.SetDefaultFilter(tt.filter); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = endpoint.SetDefaultFilter("all") })
+			pattern := "/todo"
+			e.router.HandleFunc(pattern, endpoint.GetItems)
+			var want []core.TodoItem
+			for i, completed := range tt.want {
+				todo := core.TodoItem{ID: i + 1, Description: "test", Completed: completed}
+				want = append(want, todo)
+				e.mockCore.EXPECT().
+					GetItems(completed).
+					Return([]core.TodoItem{todo})
+			}
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, pattern+tt.query, nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+			got := []core.TodoItem{}
+			e.expectUnmarshalWithoutError(&got)
+			e.expectEqual(want, got)
+		})
+	}
+}
+
+// TestSetDefaultFilterUnknown Given an unknown filter, when SetDefaultFilter is called, then an error should be returned.
+func TestSetDefaultFilterUnknown(t *testing.T) {
+	if err := endpoint.SetDefaultFilter("done"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// TestGetItemsDefaultSort Given the default sort is set to position, when a request is made to the /todo endpoint with and without the sort query parameter,
+// then the default should apply only without it, and not to the paging with the cursor, which requires sorting by id.
+func TestGetItemsDefaultSort(t *testing.T) {
+	tests := []struct {
+		query string
+		want  core.Query
+	}{
+		{"", core.Query{Sort: core.SortByPosition}},
+		{"?completed=false", core.Query{Filter: core.ItemFilter{Completed: new(bool)}, Sort: core.SortByPosition}},
+		{"?sort=id", core.Query{Sort: core.SortByID}},
+		{"?sort=id:asc", core.Query{Sort: core.SortByID}},
+		{"?sort=id:desc", core.Query{Sort: core.SortByID, Descending: true}},
+		{"?after=2&limit=2", core.Query{After: 2, Limit: 2, Sort: core.SortByID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			if err := endpoint.SetDefaultSort("position:asc"); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = endpoint.SetDefaultSort("id:asc") })
+			pattern := "/todo"
+			e.router.HandleFunc(pattern, endpoint.GetItems)
+			e.mockCore.EXPECT().
+				Query(tt.want).
+				Return(core.QueryResult{}, nil)
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, pattern+tt.query, nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+		})
+	}
+}
+
+// TestGetItemsDefaultSortDescending Given the default sort is set to descending ids, when a request is made to the /todo endpoint without the sort query parameter,
+// then the TodoItems should be queried in the descending order, except when paged through with the cursor, which requires the ascending one.
+func TestGetItemsDefaultSortDescending(t *testing.T) {
+	tests := []struct {
+		query string
+		want  core.Query
+	}{
+		{"", core.Query{Sort: core.SortByID, Descending: true}},
+		{"?sort=position", core.Query{Sort: core.SortByPosition}},
+		{"?after=2&limit=2", core.Query{After: 2, Limit: 2, Sort: core.SortByID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			if err := endpoint.SetDefaultSort("id:desc"); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = endpoint.SetDefaultSort("id:asc") })
+			pattern := "/todo"
+			e.router.HandleFunc(pattern, endpoint.GetItems)
+			e.mockCore.EXPECT().
+				Query(tt.want).
+				Return(core.QueryResult{}, nil)
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, pattern+tt.query, nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+		})
+	}
+}
+
+// TestSetDefaultSortInvalid Given a sort that's not one of the columns or has an unknown direction, when SetDefaultSort is called, then an error should be returned.
+func TestSetDefaultSortInvalid(t *testing.T) {
+	for _, sort := range []string{"", "priority:desc", "id:down", "position:up", "created_at"} {
+		if err := endpoint.SetDefaultSort(sort); err == nil {
+			t.Errorf("expected an error for %q", sort)
+		}
+	}
+}
+
+// TestGetItemsAfter Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with the after and limit query parameters, then the server should respond with a 200 status code and a page of TodoItems with the next cursor.
+func TestGetItemsAfter(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	todoItems := []core.TodoItem{
+		{ID: 3, Description: "test3", Completed: true},
+		{ID: 4, Description: "test4", Completed: false},
+	}
+	e.mockCore.EXPECT().
+		Query(core.Query{After: 2, Limit: 2}).
+		Return(core.QueryResult{Items: todoItems, Total: 4, NextCursor: 4}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?after=2&limit=2", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	type body struct {
+		Items      []core.TodoItem `json:"items"`
+		NextCursor string          `json:"next_cursor"`
+	}
+	want := body{Items: todoItems, NextCursor: "4"}
+	got := body{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestGetItemsPageLinks Given the GetItems handler serve at the /todo endpoint and the core has 95 items, when requests are made to the endpoint with the offset query parameter of the first, a middle, and the last page, then the Link header should navigate from the page, without "prev" on the first page and "next" on the last page.
+func TestGetItemsPageLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		want   string
+	}{
+		{
+			"first", 0,
+			`</todo?limit=20&offset=0>; rel="first", </todo?limit=20&offset=20>; rel="next", </todo?limit=20&offset=80>; rel="last"`,
+		},
+		{
+			"middle", 40,
+			`</todo?limit=20&offset=0>; rel="first", </todo?limit=20&offset=20>; rel="prev", </todo?limit=20&offset=60>; rel="next", </todo?limit=20&offset=80>; rel="last"`,
+		},
+		{
+			"last", 80,
+			`</todo?limit=20&offset=0>; rel="first", </todo?limit=20&offset=60>; rel="prev", </todo?limit=20&offset=80>; rel="last"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/todo"
+			e.router.HandleFunc(pattern, endpoint.GetItems)
+			todoItems := []core.TodoItem{{ID: tt.offset + 1, Description: "test"}}
+			e.mockCore.EXPECT().
+				Query(core.Query{Offset: tt.offset, Limit: 20}).
+				Return(core.QueryResult{Items: todoItems, Total: 95}, nil)
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/todo?offset=%d&limit=20", tt.offset), nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+			e.expectEqual(tt.want, e.writer.Header().Get("Link"))
+			type body struct {
+				Items  []core.TodoItem `json:"items"`
+				Offset int             `json:"offset"`
+				Limit  int             `json:"limit"`
+				Total  int             `json:"total"`
+			}
+			want := body{Items: todoItems, Offset: tt.offset, Limit: 20, Total: 95}
+			got := body{}
+			e.expectUnmarshalWithoutError(&got)
+			e.expectEqual(want, got)
+		})
+	}
+}
+
+// TestGetItemsPageLinksKeepPath Given the GetItems handler serve under a base path, when a request is made to the endpoint with the offset query parameter and other query parameters, then the URLs in the Link header should keep the path and the other query parameters.
+func TestGetItemsPageLinksKeepPath(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/api/v1/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	e.mockCore.EXPECT().
+		Query(core.Query{Limit: core.DefaultPageSize}).
+		Return(core.QueryResult{}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/api/v1/todo?offset=0&foo=bar", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := `</api/v1/todo?foo=bar&limit=20&offset=0>; rel="first", </api/v1/todo?foo=bar&limit=20&offset=0>; rel="last"`
+	e.expectEqual(want, e.writer.Header().Get("Link"))
+}
+
+// TestGetItemsPageInvalidOffset Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with a malformed offset, then the server should respond with a 400 status code.
+func TestGetItemsPageInvalidOffset(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?offset=abc", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestGetItemsFiltered Given the GetItems handler serve at the /todo endpoint, when requests are made to the endpoint with the created_after and created_before query parameters, with and without completed, then the parsed criteria should be passed to the core and the filtered items returned.
+func TestGetItemsFiltered(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 0, 7)
+	completed := true
+	tests := []struct {
+		name  string
+		query string
+		want  core.ItemFilter
+	}{
+		{"created after", "created_after=" + url.QueryEscape(after.Format(time.RFC3339)), core.ItemFilter{CreatedAfter: &after}},
+		{"completed and created after", "completed=true&created_after=" + url.QueryEscape(after.Format(time.RFC3339)), core.ItemFilter{Completed: &completed, CreatedAfter: &after}},
+		{"created before", "created_before=" + url.QueryEscape(before.Format(time.RFC3339)), core.ItemFilter{CreatedBefore: &before}},
+		{"created between", "created_after=" + url.QueryEscape(after.Format(time.RFC3339)) + "&created_before=" + url.QueryEscape(before.Format(time.RFC3339)), core.ItemFilter{CreatedAfter: &after, CreatedBefore: &before}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/todo"
+			e.router.HandleFunc(pattern, endpoint.GetItems)
+			todoItems := []core.TodoItem{{ID: 3, Description: "test3", Completed: true}}
+			e.mockCore.EXPECT().
+				Query(gomock.Any()).
+				DoAndReturn(func(q core.Query) (core.QueryResult, error) {
+					e.expectEqual(core.Query{Filter: tt.want}, q)
+					return core.QueryResult{Items: todoItems}, nil
+				})
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, "/todo?"+tt.query, nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+			got := []core.TodoItem{}
+			e.expectUnmarshalWithoutError(&got)
+			e.expectEqual(todoItems, got)
+		})
+	}
+}
+
+// TestGetItemsFilteredInvalidDate Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with a malformed created_after, then the server should respond with a 400 status code.
+func TestGetItemsFilteredInvalidDate(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?completed=true&created_after=yesterday", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestGetItemsFilteredInvertedRange Given the GetItems handler serve at the /todo endpoint and the core rejects the inverted range of creation times,
+// when a request is made to the endpoint with created_after later than created_before, then the server should respond with a 400 status code.
+func TestGetItemsFilteredInvertedRange(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	e.mockCore.EXPECT().
+		Query(gomock.Any()).
+		Return(core.QueryResult{}, core.ValidationError{Message: "inverted range"})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?created_after=2024-01-08T00:00:00Z&created_before=2024-01-01T00:00:00Z", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestGetItemsQueryCombined Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with a filter, a sort, and an offset at once,
+// then all of them should be passed to the core in a single query and the page of the filtered TodoItems returned.
+func TestGetItemsQueryCombined(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	completed := false
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	todoItems := []core.TodoItem{{ID: 5, Description: "test5", Position: 2}}
+	e.mockCore.EXPECT().
+		Query(core.Query{Filter: core.ItemFilter{Completed: &completed, CreatedAfter: &after}, Sort: core.SortByPosition, Offset: 2, Limit: 2}).
+		Return(core.QueryResult{Items: todoItems, Total: 3}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?completed=false&created_after=2024-01-01T00:00:00Z&sort=position&offset=2&limit=2", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	type body struct {
+		Items  []core.TodoItem `json:"items"`
+		Offset int             `json:"offset"`
+		Limit  int             `json:"limit"`
+		Total  int             `json:"total"`
+	}
+	got := body{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(body{Items: todoItems, Offset: 2, Limit: 2, Total: 3}, got)
+}
+
+// TestGetItemsByList Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with the list_id query parameter,
+// then the core should be queried for the items in the list and the server should respond with them.
+func TestGetItemsByList(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	listID := 2
+	todoItems := []core.TodoItem{{ID: 3, Description: "test3", ListID: 2}}
+	e.mockCore.EXPECT().
+		Query(core.Query{Filter: core.ItemFilter{ListID: &listID}}).
+		Return(core.QueryResult{Items: todoItems}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?list_id=2", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todoItems, got)
+}
+
+// TestGetItemsByListMalformed Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with a list_id that's not an integer,
+// then the server should respond with a 400 status code.
+func TestGetItemsByListMalformed(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?list_id=work", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestGetItemsByStatus Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with the status query parameter,
+// then the core should be queried for the items in the status and the server should respond with them.
+func TestGetItemsByStatus(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	status := core.StatusInProgress
+	startedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	todoItems := []core.TodoItem{{ID: 3, Description: "test3", StartedAt: &startedAt}}
+	e.mockCore.EXPECT().
+		Query(core.Query{Filter: core.ItemFilter{Status: &status}}).
+		Return(core.QueryResult{Items: todoItems}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?status=in_progress", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todoItems, got)
+}
+
+// TestGetItemsByStatusUnknown Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with an unknown status,
+// then the server should respond with a 400 status code.
+func TestGetItemsByStatusUnknown(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?status=paused", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestGetItemsAfterLastPage Given the GetItems handler serve at the /todo endpoint and the core returns the last page, when a request is made to the endpoint with the after query parameter, then the next cursor in the response should be empty.
+func TestGetItemsAfterLastPage(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	e.mockCore.EXPECT().
+		Query(core.Query{After: 4, Limit: core.DefaultPageSize}).
+		Return(core.QueryResult{}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?after=4", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]json.RawMessage{"items": []byte(`[]`), "limit": []byte(`20`), "next_cursor": []byte(`""`)}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestGetItemsLimit Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with a limit under, at, or over the maximum page size, or zero,
+// then the core should be queried with the limit clamped to the maximum page size, or the default page size if it's zero, and the page should tell the effective limit.
+func TestGetItemsLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit string
+		want  int
+	}{
+		{"under cap", "100", 100},
+		{"at cap", "500", endpoint.DefaultMaxPageSize},
+		{"over cap", "100000", endpoint.DefaultMaxPageSize},
+		{"zero", "0", core.DefaultPageSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/todo"
+			e.router.HandleFunc(pattern, endpoint.GetItems)
+			e.mockCore.EXPECT().
+				Query(core.Query{Limit: tt.want}).
+				Return(core.QueryResult{}, nil)
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, "/todo?offset=0&limit="+tt.limit, strings.NewReader(""))
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+			got := map[string]json.RawMessage{}
+			e.expectUnmarshalWithoutError(&got)
+			e.expectEqual(json.RawMessage(strconv.Itoa(tt.want)), got["limit"])
+		})
+	}
+}
+
+// TestGetItemsAfterLimitClamped Given the maximum page size is set with SetMaxPageSize, when a request is made to the /todo endpoint with a cursor and a larger limit,
+// then the core should be queried with the maximum page size as the limit, which the page should tell.
+func TestGetItemsAfterLimitClamped(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	if err := endpoint.SetMaxPageSize(10); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = endpoint.SetMaxPageSize(endpoint.DefaultMaxPageSize) })
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	e.mockCore.EXPECT().
+		Query(core.Query{After: 4, Limit: 10}).
+		Return(core.QueryResult{}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?after=4&limit=50", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(json.RawMessage(`10`), got["limit"])
+}
+
+// TestSetMaxPageSizeNotPositive Given a size that is not positive, when it's set with SetMaxPageSize, then an error should be returned.
+func TestSetMaxPageSizeNotPositive(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		if err := endpoint.SetMaxPageSize(size); err == nil {
+			t.Errorf("expected an error for size %d", size)
+		}
+	}
+}
+
+// TestGetItemsAfterInvalid Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with a malformed cursor, then the server should respond with a 400 status code.
+func TestGetItemsAfterInvalid(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?after=abc", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestReorder Given the Reorder handler serve at the /todo/reorder endpoint and the core returns without error, when a request is made to the endpoint with a JSON body of ids, then the server should respond with a 200 status code and a JSON response body indicating that the reorder was successful.
+func TestReorder(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/reorder"
+	e.router.HandleFunc(pattern, endpoint.Reorder)
+	e.mockCore.EXPECT().
+		Reorder([]int{3, 1, 2}).
+		Return(nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"ids": [3, 1, 2]}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]json.RawMessage{"reordered": []byte(`true`)}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestStrictJSON Given the SetItemsCompleted handler serve at the /todo/status endpoint, when requests are made to the endpoint with and without unknown fields, then the unknown fields should only be rejected in the strict mode, with the field named in the error.
+func TestStrictJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		strict   bool
+		body     string
+		wantCode int
+	}{
+		{"known fields in strict mode", true, `{"ids": [1, 2], "completed": true}`, http.StatusOK},
+		{"unknown field in strict mode", true, `{"ids": [1, 2], "complete": true}`, http.StatusBadRequest},
+		{"unknown field in lenient mode", false, `{"ids": [1, 2], "complete": true}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			endpoint.SetStrictJSON(tt.strict)
+			t.Cleanup(func() { endpoint.SetStrictJSON(false) })
+			pattern := "/todo/status"
+			e.router.HandleFunc(pattern, endpoint.SetItemsCompleted)
+			if tt.wantCode == http.StatusOK {
+				e.mockCore.EXPECT().
+					SetItemsCompleted([]int{1, 2}, gomock.Any()).
+					Return(2, nil)
+			}
+
+			// act
+			request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(tt.body))
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(tt.wantCode)
+			if tt.wantCode == http.StatusBadRequest {
+				got := map[string]string{}
+				e.expectUnmarshalWithoutError(&got)
+				if !strings.Contains(got["error"], `"complete"`) {
+					t.Errorf("expected the error to name the unknown field, got %q", got["error"])
+				}
+			}
+		})
+	}
+}
+
+// TestMalformedJSON Given the SetItemsCompleted handler serve at the /todo/status endpoint, when requests are made to the endpoint with malformed JSON bodies,
+// then the server should respond with a 400 status code and an error telling what's wrong with the body.
+func TestMalformedJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"syntax error", `{"ids": [1, 2}`, "malformed JSON at offset 14"},
+		{"truncated", `{"ids": [1, 2]`, "malformed JSON: request body ends unexpectedly"},
+		{"empty", ``, "request body is empty, expected JSON"},
+		{"wrong type for field", `{"ids": "1,2"}`, `wrong type for field "ids": expected array, got string`},
+		{"wrong type for boolean field", `{"ids": [1], "completed": "yes"}`, `wrong type for field "completed": expected boolean, got string`},
+		{"wrong type of body", `[1, 2]`, "wrong type of request body: expected object, got array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/todo/status"
+			e.router.HandleFunc(pattern, endpoint.SetItemsCompleted)
+
+			// act
+			request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(tt.body))
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusBadRequest)
+			want := map[string]string{"error": tt.wantErr}
+			got := map[string]string{}
+			e.expectUnmarshalWithoutError(&got)
+			e.expectEqual(want, got)
+		})
+	}
+}
+
+// TestReorderNotFound Given the Reorder handler serve at the /todo/reorder endpoint and the core returns a TodoItemNotFoundError, when a request is made to the endpoint, then the server should respond with a 404 status code.
+func TestReorderNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/reorder"
+	e.router.HandleFunc(pattern, endpoint.Reorder)
+	e.mockCore.EXPECT().
+		Reorder(gomock.Any()).
+		Return(core.TodoItemNotFoundError{ID: 4})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"ids": [4]}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+}
+
+// TestReorderMalformed Given the Reorder handler serve at the /todo/reorder endpoint, when a request is made to the endpoint with a malformed JSON body, then the server should respond with a 400 status code.
+func TestReorderMalformed(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/reorder"
+	e.router.HandleFunc(pattern, endpoint.Reorder)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"ids": [3, 1`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestGetItemsSortByPosition Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint with the sort query parameter set to position, then the TodoItems should be queried and listed in the order of their positions.
+func TestGetItemsSortByPosition(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	todoItems := []core.TodoItem{
+		{ID: 2, Description: "test2", Completed: false, Position: 0},
+		{ID: 3, Description: "test3", Completed: false, Position: 1},
+		{ID: 1, Description: "test1", Completed: false, Position: 2},
+	}
+	completed := false
+	e.mockCore.EXPECT().
+		Query(core.Query{Filter: core.ItemFilter{Completed: &completed}, Sort: core.SortByPosition}).
+		Return(core.QueryResult{Items: todoItems, Total: 3}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?completed=false&sort=position", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := []core.TodoItem{
+		{ID: 2, Description: "test2", Completed: false, Position: 0},
+		{ID: 3, Description: "test3", Completed: false, Position: 1},
+		{ID: 1, Description: "test1", Completed: false, Position: 2},
+	}
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestSuggestDescriptions Given the SuggestDescriptions handler serve at the /todo/suggest endpoint, when a request is made to the endpoint with a prefix, then the server should respond with a 200 status code and the descriptions returned by the core.
+func TestSuggestDescriptions(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/suggest"
+	e.router.HandleFunc(pattern, endpoint.SuggestDescriptions)
+	descriptions := []string{"buy milk", "buy eggs"}
+	e.mockCore.EXPECT().
+		SuggestDescriptions("buy", core.MaxSuggestions).
+		Return(descriptions)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/suggest?prefix=buy", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := []string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(descriptions, got)
+}
+
+// TestSuggestDescriptionsEmptyPrefix Given the SuggestDescriptions handler serve at the /todo/suggest endpoint, when a request is made to the endpoint without a prefix, then the server should respond with a 400 status code.
+func TestSuggestDescriptionsEmptyPrefix(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/suggest"
+	e.router.HandleFunc(pattern, endpoint.SuggestDescriptions)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/suggest?prefix=", strings.NewReader(""))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestSetItemsCompleted Given the SetItemsCompleted handler serve at the /todo/status endpoint, when a request is made to the endpoint with a JSON body of ids and a completed status, then the server should respond with a 200 status code and the number of updated TodoItems.
+func TestSetItemsCompleted(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/status"
+	e.router.HandleFunc(pattern, endpoint.SetItemsCompleted)
+	e.mockCore.EXPECT().
+		SetItemsCompleted([]int{1, 2, 5}, true).
+		Return(2, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"ids": [1, 2, 5], "completed": true}`))
+	request.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]json.RawMessage{"updated": []byte(`2`)}
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestGetItem Given the GetItem handler serve at the /todo/{id} endpoint, when a request is made to the endpoint without an Accept header, then the server should respond with a 200 status code and the TodoItem encoded in JSON.
+func TestGetItem(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetItem)
+	todo := core.TodoItem{ID: 1, Description: "test1", Completed: true}
+	e.mockCore.EXPECT().
+		GetItem(1).
+		Return(todo, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todo, got)
+}
+
+// TestGetItemCompletedAt Given the GetItem handler serve at the /todo/{id} endpoint and the TodoItem is completed, when a request is made to the endpoint,
+// then the completion time should be in the response, while it's omitted for an incomplete TodoItem (see TestCreateItemJSONKeys).
+func TestGetItemCompletedAt(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetItem)
+	completedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	e.mockCore.EXPECT().
+		GetItem(1).
+		Return(core.TodoItem{ID: 1, Description: "test1", Completed: true, CompletedAt: &completedAt}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := map[string]json.RawMessage{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(json.RawMessage(`"2024-01-01T12:00:00Z"`), got["completed_at"])
+}
+
+// TestGetItemLastModified Given the GetItem handler serve at the /todo/{id} endpoint, when a request is made to the endpoint and then again with the Last-Modified of the response as If-Modified-Since, then the first response should be 200 with the update time as Last-Modified and the second should be 304 without a body.
+func TestGetItemLastModified(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetItem)
+	todo := core.TodoItem{ID: 1, Description: "test1", UpdatedAt: time.Date(2024, 1, 1, 9, 30, 15, 500, time.UTC)}
+	e.mockCore.EXPECT().
+		GetItem(1).
+		Return(todo, nil).
+		Times(2)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+	e.expectStatusCodeToBe(http.StatusOK)
+	lastModified := e.writer.Header().Get("Last-Modified")
+	e.expectEqual("Mon, 01 Jan 2024 09:30:15 GMT", lastModified)
+	e.writer = httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodGet, "/todo/1", nil)
+	request.Header.Set("If-Modified-Since", lastModified)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotModified)
+	if e.writer.Body.Len() != 0 {
+		t.Errorf("expected no body, got %q", e.writer.Body.String())
+	}
+}
+
+// TestGetItemModifiedSince Given the GetItem handler serve at the /todo/{id} endpoint and the item is updated after the If-Modified-Since, when a request is made to the endpoint, then the server should respond with a 200 status code and the item.
+func TestGetItemModifiedSince(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetItem)
+	todo := core.TodoItem{ID: 1, Description: "test1", UpdatedAt: time.Date(2024, 1, 1, 9, 30, 16, 0, time.UTC)}
+	e.mockCore.EXPECT().
+		GetItem(1).
+		Return(todo, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1", nil)
+	request.Header.Set("If-Modified-Since", "Mon, 01 Jan 2024 09:30:15 GMT")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todo, got)
+}
+
+// TestGetItemNotFound Given the GetItem handler serve at the /todo/{id} endpoint and the core returns a TodoItemNotFoundError, when a request is made to the endpoint, then the server should respond with a 404 status code.
+func TestGetItemNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetItem)
+	e.mockCore.EXPECT().
+		GetItem(1).
+		Return(core.TodoItem{}, core.TodoItemNotFoundError{ID: 1})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+}
+
+// TestGetItemXML Given the GetItem handler serve at the /todo/{id} endpoint, when a request is made to the endpoint accepting XML, then the server should respond with a 200 status code and the TodoItem encoded in XML.
+func TestGetItemXML(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetItem)
+	e.mockCore.EXPECT().
+		GetItem(1).
+		Return(core.TodoItem{ID: 1, Description: "test1", Completed: true}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1", nil)
+	request.Header.Set("Accept", "application/xml")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual("application/xml", e.writer.Header().Get("Content-Type"))
+	want := xml.Header + "<todo><id>1</id><description>test1</description><completed>true</completed><position>0</position>" +
+		"<created_at>0001-01-01T00:00:00Z</created_at><updated_at>0001-01-01T00:00:00Z</updated_at></todo>"
+	e.expectEqual(want, e.writer.Body.String())
+}
+
+// TestGetItemsXML Given the GetItems handler serve at the /todo endpoint, when requests are made to the endpoint accepting JSON and XML, then the server should respond with the same TodoItems encoded in each format.
+func TestGetItemsXML(t *testing.T) {
+	// arrange
+	todoItems := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true},
+		{ID: 3, Description: "test3", Completed: true},
+	}
+	fetch := func(accept string) *httptest.ResponseRecorder {
+		e := newTestEnv(t)
+		e.router.HandleFunc("/todo", endpoint.GetItems)
+		e.mockCore.EXPECT().
+			GetItems(true).
+			Return(todoItems)
+		request, _ := http.NewRequest(http.MethodGet, "/todo?completed=true", nil)
+		request.Header.Set("Accept", accept)
+		e.router.ServeHTTP(e.writer, request)
+		return e.writer
+	}
+
+	// act
+	jsonWriter := fetch("application/json")
+	xmlWriter := fetch("application/xml")
+
+	// assert
+	gotJSON := []core.TodoItem{}
+	if err := json.Unmarshal(jsonWriter.Body.Bytes(), &gotJSON); err != nil {
+		t.Fatalf("error unmarshalling JSON response body: %v", err)
+	}
+	gotXML := struct {
+		Items []core.TodoItem `xml:"todo"`
+	}{}
+	if err := xml.Unmarshal(xmlWriter.Body.Bytes(), &gotXML); err != nil {
+		t.Fatalf("error unmarshalling XML response body: %v", err)
+	}
+	if !reflect.DeepEqual(todoItems, gotJSON) {
+		t.Errorf("expected %v, got %v", todoItems, gotJSON)
+	}
+	if !reflect.DeepEqual(todoItems, gotXML.Items) {
+		t.Errorf("expected %v, got %v", todoItems, gotXML.Items)
+	}
+	if !strings.Contains(xmlWriter.Body.String(), "<todos><todo>") {
+		t.Errorf("expected the TodoItems to be enclosed in <todos>, got %v", xmlWriter.Body.String())
+	}
+}
+
+// TestGetItemsUnsupportedAccept Given the GetItems handler serve at the /todo endpoint, when a request is made to the endpoint accepting an unsupported media type, then the server should fall back to JSON with a 200 status code.
+func TestGetItemsUnsupportedAccept(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	todoItems := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true},
+	}
+	e.mockCore.EXPECT().
+		GetItems(true).
+		Return(todoItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo?completed=true", nil)
+	request.Header.Set("Accept", "text/csv")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual("application/json", e.writer.Header().Get("Content-Type"))
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(todoItems, got)
+}
+
+// TestDeleteCompletedThenRestore Given the DeleteCompleted and RestoreItems handlers registered by NewRouter, when the completed items are cleared and the response is passed to the restore endpoint, then the same items should be restored.
+func TestDeleteCompletedThenRestore(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	items := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true},
+		{ID: 3, Description: "test3", Completed: true},
+	}
+	e.mockCore.EXPECT().
+		DeleteCompleted(1.0).
+		Return(items, nil)
+	e.mockCore.EXPECT().
+		RestoreItems(items).
+		Return(items, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, "/todo/completed?confirm=true", nil)
+	e.router.ServeHTTP(e.writer, request)
+	deleted := e.writer.Body.String()
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.writer = httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodPost, "/todo/restore", strings.NewReader(deleted))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := []core.TodoItem{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(items, got)
+}
+
+// TestDeleteCompletedUnderThreshold Given the DeleteCompleted handler serve at the /todo/completed endpoint, when a request is made to the endpoint without confirmation,
+// then the items should be deleted by the core, limited to the mass delete threshold.
+func TestDeleteCompletedUnderThreshold(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/completed"
+	e.router.HandleFunc(pattern, endpoint.DeleteCompleted)
+	items := []core.TodoItem{{ID: 1, Description: "test1", Completed: true}}
+	e.mockCore.EXPECT().
+		DeleteCompleted(endpoint.DefaultMassDeleteThreshold).
+		Return(items, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, pattern, nil)
 	e.router.ServeHTTP(e.writer, request)
 
 	// assert
 	e.expectStatusCodeToBe(http.StatusOK)
-	want := todoItems
 	got := []core.TodoItem{}
 	e.expectUnmarshalWithoutError(&got)
-	// NOTE: Sort the slices before comparing them because the order of the items is not guaranteed.
-	sort.Slice(got, func(i, j int) bool { return got[i].ID < got[j].ID })
+	e.expectEqual(items, got)
+}
+
+// TestDeleteCompletedOverThreshold Given the DeleteCompleted handler serve at the /todo/completed endpoint and the completed items are more than the mass delete threshold,
+// when a request is made to the endpoint without confirmation, then the server should respond with a 409 status code as the core refuses the deletion.
+func TestDeleteCompletedOverThreshold(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/completed"
+	e.router.HandleFunc(pattern, endpoint.DeleteCompleted)
+	e.mockCore.EXPECT().
+		DeleteCompleted(endpoint.DefaultMassDeleteThreshold).
+		Return(nil, core.ConflictError{Message: "mass deletion requires confirm"})
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusConflict)
+	want := map[string]string{"error": "mass deletion requires confirm"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestSetMassDeleteThresholdOutOfRange Given a threshold not within 0 and 1, when it's set with SetMassDeleteThreshold, then an error should be returned.
+func TestSetMassDeleteThresholdOutOfRange(t *testing.T) {
+	for _, threshold := range []float64{-0.1, 1.1} {
+		if err := endpoint.SetMassDeleteThreshold(threshold); err == nil {
+			t.Errorf("expected an error for threshold %v", threshold)
+		}
+	}
+}
+
+// TestGetItemsToday Given a fake clock at an instant where the days differ across time zones, when a request is made to the /todo/today endpoint with a time zone, then the core should be asked for the items completed on the day in that time zone.
+func TestGetItemsToday(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database is not available: ", err)
+	}
+	endpoint.SetClock(core.NewFakeClock(time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { endpoint.SetClock(core.RealClock{}) })
+	tests := []struct {
+		name     string
+		query    string
+		timezone string
+		wantDay  time.Time
+	}{
+		{"offset ahead of UTC", "?utc_offset=%2B08:00", "", time.Date(2024, 1, 2, 0, 0, 0, 0, time.FixedZone("", 8*60*60))},
+		{"header behind UTC", "", "America/New_York", time.Date(2024, 1, 1, 0, 0, 0, 0, newYork)},
+		{"offset over header", "?utc_offset=%2B08:00", "America/New_York", time.Date(2024, 1, 2, 0, 0, 0, 0, time.FixedZone("", 8*60*60))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/todo/today"
+			e.router.HandleFunc(pattern, endpoint.GetItemsToday)
+			completedAt := time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC)
+			items := []core.TodoItem{{ID: 1, Description: "test1", Completed: true, CompletedAt: &completedAt}}
+			e.mockCore.EXPECT().
+				GetItemsCompletedOn(gomock.Any()).
+				DoAndReturn(func(date time.Time) []core.TodoItem {
+					gotDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+					if !gotDay.Equal(tt.wantDay) {
+						t.Errorf("expected the day starting at %v, got %v", tt.wantDay, gotDay)
+					}
+					return items
+				})
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, pattern+tt.query, nil)
+			if tt.timezone != "" {
+				request.Header.Set("X-Timezone", tt.timezone)
+			}
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+			got := []core.TodoItem{}
+			e.expectUnmarshalWithoutError(&got)
+			if len(got) != 1 || got[0].ID != 1 {
+				t.Errorf("expected the item with id 1, got %v", got)
+			}
+		})
+	}
+}
+
+// TestGetItemsTodayInvalidTimezone Given an invalid time zone, when a request is made to the /todo/today endpoint, then the server should respond with a 400 status code.
+func TestGetItemsTodayInvalidTimezone(t *testing.T) {
+	for _, tt := range []struct{ query, timezone string }{
+		{"?utc_offset=8", ""},
+		{"", "Mars/Olympus_Mons"},
+	} {
+		// arrange
+		e := newTestEnv(t)
+		pattern := "/todo/today"
+		e.router.HandleFunc(pattern, endpoint.GetItemsToday)
+
+		// act
+		request, _ := http.NewRequest(http.MethodGet, pattern+tt.query, nil)
+		if tt.timezone != "" {
+			request.Header.Set("X-Timezone", tt.timezone)
+		}
+		e.router.ServeHTTP(e.writer, request)
+
+		// assert
+		e.expectStatusCodeToBe(http.StatusBadRequest)
+	}
+}
+
+// TestGetItemsTodayDefaultTimezone Given the default time zone is Asia/Tokyo and it's already the next day there, when a request is made to the GetItemsToday handler
+// without a time zone, then the day should roll over in Asia/Tokyo rather than in UTC, unless the client tells its own time zone.
+func TestGetItemsTodayDefaultTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("time zone database is not available: ", err)
+	}
+	err = endpoint.SetDefaultTimezone("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { endpoint.SetDefaultTimezone("") })
+	// 05:00 on January 2 in Asia/Tokyo.
+	endpoint.SetClock(core.NewFakeClock(time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)))
+	t.Cleanup(func() { endpoint.SetClock(core.RealClock{}) })
+	tests := []struct {
+		name     string
+		timezone string
+		wantDay  time.Time
+	}{
+		{"default", "", time.Date(2024, 1, 2, 0, 0, 0, 0, tokyo)},
+		{"header over default", "UTC", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/todo/today"
+			e.router.HandleFunc(pattern, endpoint.GetItemsToday)
+			e.mockCore.EXPECT().
+				GetItemsCompletedOn(gomock.Any()).
+				DoAndReturn(func(date time.Time) []core.TodoItem {
+					gotDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+					if !gotDay.Equal(tt.wantDay) {
+						t.Errorf("expected the day starting at %v, got %v", tt.wantDay, gotDay)
+					}
+					return nil
+				})
+
+			// act
+			request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+			if tt.timezone != "" {
+				request.Header.Set("X-Timezone", tt.timezone)
+			}
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusOK)
+		})
+	}
+}
+
+// TestSetDefaultTimezoneUnknown Given an unknown time zone, when SetDefaultTimezone is called with it, then an error should be returned.
+func TestSetDefaultTimezoneUnknown(t *testing.T) {
+	// act
+	err := endpoint.SetDefaultTimezone("Mars/Olympus_Mons")
+
+	// assert
+	if err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
+
+// TestRestoreItemsConflict Given the RestoreItems handler serve at the /todo/restore endpoint and the core finds an item not deleted, when a request is made to the endpoint, then the server should respond with a 409 status code.
+func TestRestoreItemsConflict(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/restore"
+	e.router.HandleFunc(pattern, endpoint.RestoreItems)
+	e.mockCore.EXPECT().
+		RestoreItems([]core.TodoItem{{ID: 1, Description: "test1"}}).
+		Return(nil, core.ConflictError{Message: "TodoItem with id 1 is not deleted"})
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`[{"id": 1, "description": "test1"}]`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusConflict)
+}
+
+// TestGetChanges Given the GetChanges handler serve at the /todo/changes endpoint, when a request is made to the endpoint with a since query parameter, then the server should respond with a 200 status code and the changed TodoItems and deleted ids returned by the core.
+func TestGetChanges(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/changes"
+	e.router.HandleFunc(pattern, endpoint.GetChanges)
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	changed := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true, UpdatedAt: since.Add(time.Hour)},
+	}
+	e.mockCore.EXPECT().
+		GetChangesSince(since).
+		Return(changed, []int{2}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/changes?since="+url.QueryEscape(since.Format(time.RFC3339)), nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	type body struct {
+		Changed []core.TodoItem `json:"changed"`
+		Deleted []int           `json:"deleted"`
+	}
+	want := body{Changed: changed, Deleted: []int{2}}
+	got := body{}
+	e.expectUnmarshalWithoutError(&got)
 	e.expectEqual(want, got)
 }
+
+// TestGetChangesInvalidSince Given the GetChanges handler serve at the /todo/changes endpoint, when a request is made to the endpoint with a malformed since query parameter, then the server should respond with a 400 status code.
+func TestGetChangesInvalidSince(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/changes"
+	e.router.HandleFunc(pattern, endpoint.GetChanges)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/changes?since=yesterday", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestCoreErrorStatusCodes Given the handlers serve at their endpoints and the core returns one of its errors, when a request is made to the endpoints, then the server should respond with the status code of the error.
+func TestCoreErrorStatusCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", core.TodoItemNotFoundError{ID: 1}, http.StatusNotFound},
+		{"validation", core.ValidationError{Message: "invalid"}, http.StatusBadRequest},
+		{"conflict", core.ConflictError{Message: "conflict"}, http.StatusConflict},
+		{"storage", core.StorageError{Err: errors.New("test error")}, http.StatusInternalServerError},
+		{"storage timeout", core.StorageError{Err: fmt.Errorf("query: %w", context.DeadlineExceeded)}, http.StatusServiceUnavailable},
+		{"other", errors.New("test error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/toggle", func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			e.router.HandleFunc("/todo/{id}/toggle", endpoint.ToggleItem)
+			e.mockCore.EXPECT().
+				ToggleItem(gomock.Any()).
+				Return(core.TodoItem{}, tt.err)
+
+			// act
+			request, _ := http.NewRequest(http.MethodPost, "/todo/1/toggle", strings.NewReader(""))
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(tt.want)
+		})
+		t.Run(tt.name+"/delete", func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			e.router.HandleFunc("/todo/{id}", endpoint.DeleteItem)
+			e.mockCore.EXPECT().
+				DeleteItem(gomock.Any()).
+				Return(tt.err)
+
+			// act
+			request, _ := http.NewRequest(http.MethodDelete, "/todo/1", strings.NewReader(""))
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(tt.want)
+		})
+	}
+}
+
+// TestBatch Given the Batch handler serve at the /todo/batch endpoint and the core applies the operations atomically, when a request is made to the endpoint with the operations,
+// then the server should respond with the result of each operation, along with the status code of it.
+func TestBatch(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/batch"
+	e.router.HandleFunc(pattern, endpoint.Batch)
+	completed := true
+	ops := []core.BatchOp{
+		{Op: core.BatchCreate, Description: "test1"},
+		{Op: core.BatchUpdate, ID: 1, Completed: &completed},
+		{Op: core.BatchDelete, ID: 2},
+	}
+	e.mockCore.EXPECT().
+		Batch(ops, true).
+		Return([]core.BatchResult{
+			{Op: core.BatchCreate, ID: 3, Item: &core.TodoItem{ID: 3, Description: "test1"}},
+			{Op: core.BatchUpdate, ID: 1, Item: &core.TodoItem{ID: 1, Description: "test2", Completed: true}},
+			{Op: core.BatchDelete, ID: 2},
+		}, nil)
+
+	// act
+	body := `[{"op": "create", "description": "test1"}, {"op": "update", "id": 1, "completed": true}, {"op": "delete", "id": 2}]`
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(body))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	type result struct {
+		Op     string         `json:"op"`
+		ID     int            `json:"id"`
+		Item   *core.TodoItem `json:"item"`
+		Status int            `json:"status"`
+		Error  string         `json:"error"`
+	}
+	got := map[string][]result{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(map[string][]result{"results": {
+		{Op: "create", ID: 3, Item: &core.TodoItem{ID: 3, Description: "test1"}, Status: http.StatusCreated},
+		{Op: "update", ID: 1, Item: &core.TodoItem{ID: 1, Description: "test2", Completed: true}, Status: http.StatusOK},
+		{Op: "delete", ID: 2, Status: http.StatusOK},
+	}}, got)
+}
+
+// TestBatchNotAtomic Given the Batch handler serve at the /todo/batch endpoint and one of the operations fails, when a request is made to the endpoint with the atomic query parameter false,
+// then the server should respond with a 200 status code and the status code and error of the failed operation in its result.
+func TestBatchNotAtomic(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/batch"
+	e.router.HandleFunc(pattern, endpoint.Batch)
+	e.mockCore.EXPECT().
+		Batch([]core.BatchOp{{Op: core.BatchDelete, ID: 2}}, false).
+		Return([]core.BatchResult{{Op: core.BatchDelete, ID: 2, Err: errors.New("some error")}}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern+"?atomic=false", strings.NewReader(`[{"op": "delete", "id": 2}]`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := map[string][]map[string]any{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(map[string][]map[string]any{"results": {
+		{"op": "delete", "id": float64(2), "status": float64(http.StatusInternalServerError), "error": "some error"},
+	}}, got)
+}
+
+// TestBatchAtomicFailure Given the Batch handler serve at the /todo/batch endpoint and an operation fails in the middle of an atomic batch, when a request is made to the endpoint,
+// then the server should respond with the status code of the error and the position of the failed operation.
+func TestBatchAtomicFailure(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/batch"
+	e.router.HandleFunc(pattern, endpoint.Batch)
+	e.mockCore.EXPECT().
+		Batch(gomock.Any(), true).
+		Return(nil, core.BatchError{Index: 1, Op: core.BatchDelete, Err: core.TodoItemNotFoundError{ID: 2}})
+
+	// act
+	body := `[{"op": "create", "description": "test1"}, {"op": "delete", "id": 2}, {"op": "create", "description": "test2"}]`
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(body))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+	got := map[string]any{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(float64(1), got["index"])
+}
+
+// TestBatchMalformedAtomic Given the Batch handler serve at the /todo/batch endpoint, when a request is made to the endpoint with a non-boolean atomic query parameter,
+// then the server should respond with a 400 status code without calling the core.
+func TestBatchMalformedAtomic(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/batch"
+	e.router.HandleFunc(pattern, endpoint.Batch)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern+"?atomic=maybe", strings.NewReader(`[]`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusBadRequest)
+}
+
+// TestItemExists Given the routes are registered by NewRouter, when a HEAD request is made to the /todo/{id} endpoint of an existing and a missing TodoItem,
+// then the server should respond with a 200 and a 404 status code respectively, both without a body, and the TodoItem should not be read.
+func TestItemExists(t *testing.T) {
+	tests := map[string]struct {
+		exists bool
+		want   int
+	}{
+		"existing": {true, http.StatusOK},
+		"missing":  {false, http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			e.router = endpoint.NewRouter("")
+			e.mockCore.EXPECT().
+				ExistsItem(1).
+				Return(tt.exists, nil)
+			e.mockCore.EXPECT().
+				GetItem(gomock.Any()).
+				Times(0)
+
+			// act
+			request, _ := http.NewRequest(http.MethodHead, "/todo/1", nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(tt.want)
+			e.expectEqual(0, e.writer.Body.Len())
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"todolist/core"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+var theListCore core.ListCore
+
+func SetListCore(c core.ListCore) {
+	theListCore = c
+}
+
+// listLocation returns the URL of the List with the id, under the base path.
+func listLocation(id int) string {
+	return basePath + "/list/" + strconv.Itoa(id)
+}
+
+// writeLists responds with the Lists, or a single List, encoded in JSON.
+func writeLists(writer http.ResponseWriter, lists any) {
+	writer.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(writer).Encode(lists)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// CreateList creates a new List in the database.
+//
+// The name of the List is passed as a form parameter named "name".
+//
+//	{ "name": "string" }
+//
+// The response will be the newly created List with a 201 status code, and the Location header will be the URL of the List, e.g., "/list/1".
+//
+// If the name is blank, the server responds with a 422 status code:
+//
+//	{"error": "validation failed", "fields": {"name": "required"}}
+func CreateList(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	list, err := theListCore.CreateList(request.FormValue("name"))
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	writer.Header().Set("Location", listLocation(list.ID))
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(writer).Encode(list)
+	if err != nil {
+		log.Error("Error encoding response")
+	}
+}
+
+// GetLists returns all the Lists, ordered by id.
+//
+//	[{"id": 1, "name": "...", "created_at": "...", "updated_at": "..."}, ...]
+//
+// The TodoItems of a List are fetched with GetItems by passing its id as the query parameter "list_id".
+func GetLists(writer http.ResponseWriter, request *http.Request) {
+	lists, err := theListCore.GetLists()
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	if lists == nil {
+		// Respond with an empty array instead of null.
+		lists = []core.List{}
+	}
+	writeLists(writer, lists)
+}
+
+// GetList returns the List with the specified id.
+//
+// If the List was not found in the database, the server responds with a 404 status code:
+//
+//	{"error": "some error message"}
+func GetList(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	list, err := theListCore.GetList(id)
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	writeLists(writer, list)
+}
+
+// RenameList sets the name of the List with the specified id.
+//
+// The name is passed as a form parameter named "name".
+//
+//	{ "name": "string" }
+//
+// If the operation was successful, the response will be the updated List.
+//
+// If the operation failed, e.g., the List was not found in the database, the server responds with the status code of the error (see writeCoreError):
+//
+//	{"error": "some error message"}
+func RenameList(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		writeBodyError(writer, err)
+		return
+	}
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	list, err := theListCore.RenameList(id, request.FormValue("name"))
+	if err != nil {
+		writeCoreError(writer, err)
+		return
+	}
+	writeLists(writer, list)
+}
+
+// DeleteList deletes the List with the specified id.
+// If the List still has TodoItems, they are either deleted along with it or the deletion is refused with a 409 status code,
+// depending on the core.ListDeletePolicy.
+// If the operation was successful:
+//
+//	{"deleted": true}
+//
+// If the operation failed, e.g., the List was not found in the database, the server responds with the status code of the error (see writeCoreError):
+//
+//	{"deleted": false, "error": "some error message"}
+func DeleteList(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	id, _ := strconv.Atoi(vars["id"])
+
+	err := theListCore.DeleteList(id)
+	writer.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		writer.WriteHeader(statusCodeOf(err))
+		err = json.NewEncoder(writer).Encode(map[string]any{"deleted": false, "error": err.Error()})
+		if err != nil {
+			log.Error("Error encoding response")
+		}
+		return
+	}
+	_, err = io.WriteString(writer, `{"deleted": true}`)
+	if err != nil {
+		log.Error("Error writing response to client")
+	}
+}
@@ -0,0 +1,184 @@
+package endpoint_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"todolist/core"
+	"todolist/endpoint"
+)
+
+// newListTestEnv is newTestEnv with a mock ListCore set as well.
+func newListTestEnv(t *testing.T) (*testEnv, *MockListCore) {
+	e := newTestEnv(t)
+	mockListCore := NewMockListCore(e.ctrl)
+	endpoint.SetListCore(mockListCore)
+	return e, mockListCore
+}
+
+// TestCreateList Given the CreateList handler serve at the /list endpoint and the core creates the list, when a request is made to the endpoint with a name form parameter,
+// then the server should respond with a 201 status code, the URL of the list in the Location header, and the list.
+func TestCreateList(t *testing.T) {
+	// arrange
+	e, mockListCore := newListTestEnv(t)
+	pattern := "/list"
+	e.router.HandleFunc(pattern, endpoint.CreateList)
+	list := core.List{ID: 3, Name: "Groceries"}
+	mockListCore.EXPECT().
+		CreateList("Groceries").
+		Return(list, nil)
+
+	// act
+	params := url.Values{
+		"name": []string{"Groceries"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusCreated)
+	e.expectEqual("/list/3", e.writer.Header().Get("Location"))
+	got := core.List{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(list, got)
+}
+
+// TestCreateListBlankName Given the CreateList handler serve at the /list endpoint and the core rejects the name, when a request is made to the endpoint,
+// then the server should respond with a 422 status code.
+func TestCreateListBlankName(t *testing.T) {
+	// arrange
+	e, mockListCore := newListTestEnv(t)
+	pattern := "/list"
+	e.router.HandleFunc(pattern, endpoint.CreateList)
+	mockListCore.EXPECT().
+		CreateList("").
+		Return(core.List{}, core.ValidationError{Message: "validation failed", Fields: map[string]string{"name": "required"}})
+
+	// act
+	params := url.Values{
+		"name": []string{""},
+	}
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusUnprocessableEntity)
+}
+
+// TestGetLists Given the GetLists handler serve at the /list endpoint and the core has no list, when a request is made to the endpoint,
+// then the server should respond with an empty array instead of null.
+func TestGetLists(t *testing.T) {
+	// arrange
+	e, mockListCore := newListTestEnv(t)
+	pattern := "/list"
+	e.router.HandleFunc(pattern, endpoint.GetLists)
+	mockListCore.EXPECT().
+		GetLists().
+		Return(nil, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual("[]\n", e.writer.Body.String())
+}
+
+// TestGetListNotFound Given the GetList handler serve at the /list/{id} endpoint and the core fails to find the list, when a request is made to the endpoint,
+// then the server should respond with a 404 status code.
+func TestGetListNotFound(t *testing.T) {
+	// arrange
+	e, mockListCore := newListTestEnv(t)
+	pattern := "/list/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetList)
+	mockListCore.EXPECT().
+		GetList(1).
+		Return(core.List{}, core.ListNotFoundError{ID: 1})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/list/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+}
+
+// TestRenameList Given the RenameList handler serve at the /list/{id} endpoint and the core renames the list, when a request is made to the endpoint with a name form parameter,
+// then the server should respond with the renamed list.
+func TestRenameList(t *testing.T) {
+	// arrange
+	e, mockListCore := newListTestEnv(t)
+	pattern := "/list/{id}"
+	e.router.HandleFunc(pattern, endpoint.RenameList)
+	list := core.List{ID: 1, Name: "Shopping"}
+	mockListCore.EXPECT().
+		RenameList(1, "Shopping").
+		Return(list, nil)
+
+	// act
+	params := url.Values{
+		"name": []string{"Shopping"},
+	}
+	request, _ := http.NewRequest(http.MethodPost, "/list/1", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := core.List{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(list, got)
+}
+
+// TestDeleteList Given the DeleteList handler serve at the /list/{id} endpoint and the core deletes the list, when a request is made to the endpoint,
+// then the server should respond that the list is deleted.
+func TestDeleteList(t *testing.T) {
+	// arrange
+	e, mockListCore := newListTestEnv(t)
+	pattern := "/list/{id}"
+	e.router.HandleFunc(pattern, endpoint.DeleteList)
+	mockListCore.EXPECT().
+		DeleteList(1).
+		Return(nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, "/list/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	got := map[string]bool{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(map[string]bool{"deleted": true}, got)
+}
+
+// TestDeleteListBlocked Given the DeleteList handler serve at the /list/{id} endpoint and the core refuses to delete a list with items, when a request is made to the endpoint,
+// then the server should respond with a 409 status code and the reason.
+func TestDeleteListBlocked(t *testing.T) {
+	// arrange
+	e, mockListCore := newListTestEnv(t)
+	pattern := "/list/{id}"
+	e.router.HandleFunc(pattern, endpoint.DeleteList)
+	mockListCore.EXPECT().
+		DeleteList(1).
+		Return(core.ConflictError{Message: "List with id 1 still has 2 TodoItems"})
+
+	// act
+	request, _ := http.NewRequest(http.MethodDelete, "/list/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusConflict)
+	type response struct {
+		Deleted bool   `json:"deleted"`
+		Error   string `json:"error"`
+	}
+	got := response{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(response{Deleted: false, Error: "List with id 1 still has 2 TodoItems"}, got)
+}
@@ -0,0 +1,848 @@
+package endpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/cors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Timeout returns a middleware that responds with a 503 status code if the handler doesn't finish within the timeout.
+// The context of the request is canceled once the timeout elapses, so that the handler can stop its work early;
+// the queries of the core are cancelled with it, see coreOf.
+//
+//	{"error": "request timed out"}
+//
+// NOTE: ExportItems is left out, as the response is buffered until the handler finishes, which would defeat the streaming;
+// an export takes as long as the TodoItems take to be read, each query being bounded by the storage instead.
+func Timeout(timeout time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		timeoutHandler := http.TimeoutHandler(next, timeout, `{"error": "request timed out"}`)
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if isStreaming(request) {
+				next.ServeHTTP(writer, request)
+				return
+			}
+			// NOTE: The timeout message is written directly to this writer, so the content type has to be set beforehand.
+			// Headers set by the handler still take precedence if it finishes in time.
+			writer.Header().Set("Content-Type", "application/json")
+			timeoutHandler.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// The media types of the request bodies read by the handlers; see Consumes.
+const (
+	FormMediaType      = "application/x-www-form-urlencoded"
+	MultipartMediaType = "multipart/form-data"
+	JSONMediaType      = "application/json"
+	MarkdownMediaType  = "text/markdown"
+)
+
+// Consumes returns a middleware that responds with a 415 status code to the requests whose body is declared by the Content-Type header
+// to be none of the media types, telling the accepted ones in the Accept header, instead of the handler misreading the body.
+// A request without the header is taken as the first of the media types, for the clients that omit it,
+// e.g., the body of a form is parsed as form-encoded, which net/http wouldn't do by itself.
+//
+//	{"error": "unsupported media type \"text/plain\", expected one of application/json"}
+func Consumes(mediaTypes ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			contentType := request.Header.Get("Content-Type")
+			if contentType == "" {
+				request.Header.Set("Content-Type", mediaTypes[0])
+				next.ServeHTTP(writer, request)
+				return
+			}
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || !slices.Contains(mediaTypes, mediaType) {
+				writer.Header().Set("Accept", strings.Join(mediaTypes, ", "))
+				writeError(writer, http.StatusUnsupportedMediaType,
+					fmt.Errorf("unsupported media type %q, expected one of %s", contentType, strings.Join(mediaTypes, ", ")))
+				return
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// AdminPathPrefix is the prefix of the paths of the administrative endpoints, e.g., SetReadOnlyMode, under the base path. See NewRouter.
+const AdminPathPrefix = "/admin"
+
+var readOnly atomic.Bool
+
+// SetReadOnly turns the read-only mode on or off. See ReadOnly.
+func SetReadOnly(on bool) {
+	readOnly.Store(on)
+}
+
+// IsReadOnly reports whether the read-only mode is on.
+func IsReadOnly() bool {
+	return readOnly.Load()
+}
+
+// ReadOnly is a middleware that responds with a 503 status code to the requests that may mutate the TodoItems while the read-only mode is on,
+// i.e., all but GET, HEAD, and OPTIONS requests.
+//
+//	{"error": "read-only mode"}
+//
+// NOTE: SetReadOnlyMode is always served, so that the read-only mode can be turned off again; the other administrative endpoints that mutate,
+// e.g., ImportItems and ResetItems, are rejected like the rest.
+func ReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if readOnly.Load() && !isSafeMethod(request.Method) && !isReadOnlyToggle(request) {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			_, err := io.WriteString(writer, `{"error": "read-only mode"}`)
+			if err != nil {
+				log.Error("Error writing response to client")
+			}
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// isStreaming tells whether the request is to ExportItems, which streams the response; see Timeout.
+func isStreaming(request *http.Request) bool {
+	return request.URL.Path == basePath+AdminPathPrefix+"/export"
+}
+
+// isReadOnlyToggle tells whether the request is to SetReadOnlyMode, which ReadOnly and WriteBreaker never reject.
+func isReadOnlyToggle(request *http.Request) bool {
+	return request.URL.Path == basePath+AdminPathPrefix+"/readonly"
+}
+
+// The states of the write breaker; see WriteBreaker.
+const (
+	// BreakerClosed lets the writes through.
+	BreakerClosed = "closed"
+	// BreakerOpen rejects the writes until the cooldown elapses.
+	BreakerOpen = "open"
+	// BreakerHalfOpen lets a single write through as a probe, whose outcome closes or reopens the breaker.
+	BreakerHalfOpen = "half-open"
+)
+
+// writeBreaker is the state of WriteBreaker. The zero value never opens.
+type writeBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	// failures is the number of consecutive failed writes while closed.
+	failures int
+	open     bool
+	openedAt time.Time
+	// probing tells whether the probe of the half-open breaker is in flight.
+	probing bool
+}
+
+var breaker writeBreaker
+
+// SetWriteBreaker sets after how many consecutive failed writes WriteBreaker opens, and how long it stays open before letting a probe through.
+// The breaker is reset to closed. It never opens if the threshold is not positive.
+func SetWriteBreaker(threshold int, cooldown time.Duration) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	breaker.threshold = threshold
+	breaker.cooldown = cooldown
+	breaker.failures = 0
+	breaker.open = false
+	breaker.probing = false
+}
+
+// WriteBreakerState returns the state of WriteBreaker: BreakerClosed, BreakerOpen, or BreakerHalfOpen.
+func WriteBreakerState() string {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	return breaker.state()
+}
+
+// state returns the state of the breaker; the caller must hold the lock.
+func (b *writeBreaker) state() string {
+	switch {
+	case !b.open:
+		return BreakerClosed
+	case clock.Now().Sub(b.openedAt) < b.cooldown:
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+// allow tells whether the write can go through, marking it as the probe if the breaker is half-open.
+// If not, it also returns how long until the breaker lets a write through again, at least a second.
+func (b *writeBreaker) allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state() {
+	case BreakerClosed:
+		return true, 0
+	case BreakerHalfOpen:
+		if b.probing {
+			return false, time.Second
+		}
+		b.probing = true
+		return true, 0
+	default:
+		return false, max(b.openedAt.Add(b.cooldown).Sub(clock.Now()), time.Second)
+	}
+}
+
+// record updates the breaker with the outcome of a write that was let through.
+func (b *writeBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := b.probing
+	b.probing = false
+	switch {
+	case !failed:
+		if b.open {
+			log.Warn("Write breaker closed, the probe succeeded")
+		}
+		b.failures = 0
+		b.open = false
+	case probe:
+		log.Warn("Write breaker reopened, the probe failed")
+		b.openedAt = clock.Now()
+	case b.threshold > 0:
+		b.failures++
+		if b.failures >= b.threshold && !b.open {
+			log.WithFields(log.Fields{"failures": b.failures, "cooldown": b.cooldown}).Warn("Write breaker opened, the writes are suspended")
+			b.open = true
+			b.openedAt = clock.Now()
+		}
+	}
+}
+
+// WriteBreaker is a middleware that suspends the writes, i.e., all but GET, HEAD, and OPTIONS requests, after they fail repeatedly,
+// e.g., when the disk of the database is full, so that the reads are still served in the meantime.
+// A write fails if it's responded with a 5xx status code. After the number of consecutive failures set by SetWriteBreaker, the breaker opens and the writes
+// are rejected with a 503 status code for the cooldown. Then the breaker is half-open: a single write is let through as a probe,
+// which closes the breaker if it succeeds or opens it again for another cooldown if it fails.
+//
+//	{"error": "writes are suspended after repeated failures"}
+//
+// NOTE: Like ReadOnly, SetReadOnlyMode is always served, so that the writes can be turned off for good while the breaker is open.
+func WriteBreaker(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if isSafeMethod(request.Method) || isReadOnlyToggle(request) {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if ok, retryAfter := breaker.allow(); !ok {
+			writer.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			writeError(writer, http.StatusServiceUnavailable, errors.New("writes are suspended after repeated failures"))
+			return
+		}
+		statusWriter := &statusResponseWriter{ResponseWriter: writer}
+		defer func() {
+			breaker.record(statusWriter.code >= http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(statusWriter, request)
+	})
+}
+
+// statusResponseWriter records the status code of the response.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	// code is the status code written by the handler; zero if nothing is written yet.
+	code int
+}
+
+func (w *statusResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusResponseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying ResponseWriter, so that http.ResponseController can reach it.
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+var adminToken string
+
+// SetAdminToken sets the token that the requests to the administrative endpoints have to carry. See AdminAuth.
+func SetAdminToken(token string) {
+	adminToken = token
+}
+
+// AdminAuth is a middleware that responds with a 401 status code to the requests that don't carry the admin token set by SetAdminToken
+// as a bearer token, i.e., with the "Authorization: Bearer <token>" header. No token is required if the admin token is empty.
+//
+//	{"error": "unauthorized"}
+func AdminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		// NOTE: Compared in constant time, so that the token can't be guessed from the response time.
+		if adminToken != "" && (!ok || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1) {
+			log.WithFields(log.Fields{"client_ip": ClientIP(request), "path": request.URL.Path}).Warn("ADMIN: Unauthorized request.")
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusUnauthorized)
+			_, err := io.WriteString(writer, `{"error": "unauthorized"}`)
+			if err != nil {
+				log.Error("Error writing response to client")
+			}
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+var trustedProxies []netip.Prefix
+
+// SetTrustedProxies sets the reverse proxies whose X-Forwarded-For and X-Real-IP headers ClientIP believes, as addresses or CIDRs, e.g., "10.0.0.0/8".
+// An error is returned if any of them is malformed, in which case the trusted proxies are left unchanged.
+func SetTrustedProxies(proxies []string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(proxy)
+			if addrErr != nil {
+				return fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	trustedProxies = prefixes
+	return nil
+}
+
+func isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that made the request.
+//
+// It's the address of the immediate peer, unless the peer is one of the proxies set by SetTrustedProxies. The X-Forwarded-For header is then
+// walked from the nearest hop back, and the first address that is not a trusted proxy is the client; the X-Real-IP header is used if there's
+// no X-Forwarded-For. The headers of the untrusted peers are ignored, since anyone can send them.
+func ClientIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(peer.Unmap()) {
+		return host
+	}
+
+	client := peer.Unmap()
+	if forwarded := request.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// NOTE: A malformed hop can't be told apart from a spoofed one, so the nearest well-formed hop is the best guess.
+				break
+			}
+			client = hop.Unmap()
+			if !isTrustedProxy(client) {
+				break
+			}
+		}
+		return client.String()
+	}
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(request.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.Unmap().String()
+	}
+	return client.String()
+}
+
+// DefaultMaxBodyBytes is the default limit of MaxBodyBytes on the request bodies.
+const DefaultMaxBodyBytes = 1 << 20
+
+// DefaultMaxImportBytes is the default limit of MaxBodyBytes on the request bodies of ImportItems, which carry all the TodoItems.
+const DefaultMaxImportBytes = 32 << 20
+
+// MaxBodyBytes returns a middleware that limits the size of the request bodies to limit bytes, or importLimit bytes for ImportItems,
+// so that a client can't exhaust the memory with a huge body. It responds with a 413 status code if the body declares a larger size;
+// otherwise the body is cut off at the limit, and the handlers reading it respond with a 413 status code as well.
+//
+//	{"error": "request body too large"}
+func MaxBodyBytes(limit, importLimit int64) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			n := limit
+			if request.URL.Path == basePath+AdminPathPrefix+"/import" {
+				n = importLimit
+			}
+			if request.ContentLength > n {
+				writeError(writer, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+				return
+			}
+			request.Body = http.MaxBytesReader(writer, request.Body, n)
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// ConcurrencyLimit returns a middleware that allows each client, told apart by ClientIP, at most max requests in flight at once,
+// so that a single client can't exhaust the connections to the database. A request beyond that is rejected with a 429 status code.
+// There's no limit if max is not positive.
+//
+//	{"error": "too many concurrent requests"}
+//
+// NOTE: A client is forgotten as soon as it has no request in flight, so the idle clients don't pile up.
+func ConcurrencyLimit(max int) mux.MiddlewareFunc {
+	var mu sync.Mutex
+	inFlight := map[string]int{}
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			client := ClientIP(request)
+			mu.Lock()
+			if inFlight[client] >= max {
+				mu.Unlock()
+				log.WithFields(log.Fields{"client_ip": client, "max": max}).Warn("Too many concurrent requests")
+				writeError(writer, http.StatusTooManyRequests, errors.New("too many concurrent requests"))
+				return
+			}
+			inFlight[client]++
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				defer mu.Unlock()
+				if inFlight[client]--; inFlight[client] == 0 {
+					delete(inFlight, client)
+				}
+			}()
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+var inFlight atomic.Int64
+
+// InFlightRequests returns the number of requests being served through InFlight, including the one asking.
+func InFlightRequests() int64 {
+	return inFlight.Load()
+}
+
+// InFlight is a middleware that counts the requests being served, as reported by InFlightRequests, so that Drain can tell how many are left.
+// It should wrap all the other middlewares, so that the requests rejected by them are counted as well.
+func InFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// Drain shuts the server down gracefully, refusing new requests and waiting for the ones in flight to finish, logging how many are left every second.
+// If they don't finish within the timeout, the server is closed, cutting them off, and the error of the shutdown is returned.
+// It waits as long as it takes if the timeout is not positive.
+func Drain(server *http.Server, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Shutdown(ctx)
+	}()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				log.WithFields(log.Fields{"in_flight": InFlightRequests(), "timeout": timeout}).Warn("Requests not drained in time, closing the server")
+				return errors.Join(err, server.Close())
+			}
+			log.Info("All requests drained")
+			return nil
+		case <-ticker.C:
+			log.WithFields(log.Fields{"in_flight": InFlightRequests()}).Info("Draining requests")
+		}
+	}
+}
+
+// Run serves the server with serve, e.g., Serve, until the ctx is done, and then drains it with Drain.
+// It returns only once the requests in flight are drained or cut off, so that the caller can release what they depend on, e.g., the database, afterwards.
+// The error of serve is returned if it fails before the ctx is done, and the one of Drain otherwise.
+func Run(ctx context.Context, server *http.Server, serve func() error, drainTimeout time.Duration) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+	select {
+	case err := <-served:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+	log.Info("Shutting down Todolist API server")
+	err := Drain(server, drainTimeout)
+	// NOTE: serve returns http.ErrServerClosed as soon as the shutdown begins, so it's only waited for to not leak it.
+	<-served
+	return err
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// Head is a middleware that serves the HEAD requests with the handlers of GET, responding with the same headers but no body.
+// The Content-Length is set to the size of the body that GET would respond with, as the body is discarded.
+func Head(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodHead {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		headWriter := &headResponseWriter{ResponseWriter: writer}
+		next.ServeHTTP(headWriter, request)
+		if headWriter.Header().Get("Content-Length") == "" {
+			headWriter.Header().Set("Content-Length", strconv.Itoa(headWriter.length))
+		}
+		if headWriter.code == 0 {
+			headWriter.code = http.StatusOK
+		}
+		writer.WriteHeader(headWriter.code)
+	})
+}
+
+// headResponseWriter discards the body but counts its size, and holds back the status code until the size is known.
+type headResponseWriter struct {
+	http.ResponseWriter
+	code   int
+	length int
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *headResponseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.length += len(p)
+	return len(p), nil
+}
+
+// GzipMinSize is the default size in bytes below which the responses are not compressed by Gzip, as the overhead outweighs the saving.
+const GzipMinSize = 1024
+
+// Gzip returns a middleware that compresses the responses with gzip if the client accepts it, i.e., "gzip" is in the Accept-Encoding header.
+// Responses smaller than minSize bytes, responses that already have a Content-Encoding,
+// and responses of content types that are already compressed, e.g., images, are sent as-is.
+//
+// NOTE: The response is buffered until minSize bytes are written, or the handler flushes it.
+func Gzip(minSize int) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Add("Vary", "Accept-Encoding")
+			// NOTE: There's no body to compress in the response to HEAD, and the Content-Length set by Head is of the uncompressed body.
+			if !acceptsGzip(request) || request.Method == http.MethodHead {
+				next.ServeHTTP(writer, request)
+				return
+			}
+			gzipWriter := &gzipResponseWriter{ResponseWriter: writer, minSize: minSize}
+			defer gzipWriter.close()
+			next.ServeHTTP(gzipWriter, request)
+		})
+	}
+}
+
+// acceptsGzip tells whether "gzip" is in the Accept-Encoding header with a non-zero quality value.
+func acceptsGzip(request *http.Request) bool {
+	for _, accept := range request.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(accept, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(params), "q="), 64)
+			return err != nil || q > 0
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the response until it's large enough to be worth compressing, then compresses the rest of it on the fly.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+	// code is the status code written by the handler; zero if not written yet.
+	code int
+	buf  []byte
+	// started tells whether the header is sent, after which the response is either compressed by gz or passed through if gz is nil.
+	started bool
+	gz      *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.started || w.code != 0 {
+		return
+	}
+	w.code = code
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		if w.compressible() {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) < w.minSize {
+				return len(p), nil
+			}
+			if err := w.start(true); err != nil {
+				return 0, err
+			}
+			return len(p), nil
+		}
+		if err := w.start(false); err != nil {
+			return 0, err
+		}
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends the buffered response to the client, so that the streaming handlers are not held back by the buffering.
+func (w *gzipResponseWriter) Flush() {
+	if !w.started {
+		_ = w.start(w.compressible())
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter, so that http.ResponseController can reach it.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressible tells whether the response is worth compressing by its status code and headers.
+func (w *gzipResponseWriter) compressible() bool {
+	if w.code == http.StatusNoContent || w.code == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	return !isCompressedContentType(header.Get("Content-Type"))
+}
+
+// start sends the header and the buffered response, compressed if compress is true.
+func (w *gzipResponseWriter) start(compress bool) error {
+	w.started = true
+	header := w.Header()
+	if compress {
+		if header.Get("Content-Type") == "" {
+			// NOTE: Otherwise the content type is sniffed from the compressed bytes.
+			header.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close sends what is left in the buffer uncompressed, as it's smaller than minSize, or finishes the compression.
+func (w *gzipResponseWriter) close() {
+	if !w.started {
+		if err := w.start(false); err != nil {
+			log.Error("Error writing response to client")
+		}
+		return
+	}
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			log.Error("Error writing response to client")
+		}
+	}
+}
+
+// isCompressedContentType tells whether the content type is compressed by itself, so compressing it again is a waste.
+func isCompressedContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/gzip", "application/x-gzip", "application/zip", "application/zstd",
+		"application/x-bzip2", "application/x-7z-compressed", "application/x-rar-compressed":
+		return true
+	}
+	for _, prefix := range []string{"image/", "video/", "audio/", "font/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return mediaType != "image/svg+xml"
+		}
+	}
+	return false
+}
+
+var prettyJSON atomic.Bool
+
+// SetPrettyJSON turns the indentation of all the JSON responses on or off. See PrettyJSON.
+func SetPrettyJSON(on bool) {
+	prettyJSON.Store(on)
+}
+
+// PrettyJSON is a middleware that indents the JSON responses, which are compact otherwise, if the query parameter "pretty" is true,
+// or if it's not passed and the indentation is turned on by SetPrettyJSON.
+// The responses of the other content types, and those that are not valid JSON, are sent as-is.
+//
+// NOTE: The response is buffered until the handler finishes, or flushes it, in which case the rest of it is sent as-is.
+func PrettyJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		pretty, err := strconv.ParseBool(request.URL.Query().Get("pretty"))
+		if err != nil {
+			pretty = prettyJSON.Load()
+		}
+		if !pretty || request.Method == http.MethodHead {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		prettyWriter := &prettyResponseWriter{ResponseWriter: writer}
+		defer prettyWriter.close()
+		next.ServeHTTP(prettyWriter, request)
+	})
+}
+
+// prettyResponseWriter buffers the response, so that it can be indented as a whole once it's complete.
+type prettyResponseWriter struct {
+	http.ResponseWriter
+	// code is the status code written by the handler; zero if not written yet.
+	code int
+	buf  bytes.Buffer
+	// started tells whether the header is sent, after which the response is passed through.
+	started bool
+}
+
+func (w *prettyResponseWriter) WriteHeader(code int) {
+	if w.started || w.code != 0 {
+		return
+	}
+	w.code = code
+}
+
+func (w *prettyResponseWriter) Write(p []byte) (int, error) {
+	if w.started {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.buf.Write(p)
+}
+
+// Flush sends the buffered response to the client as-is, so that the streaming handlers are not held back by the buffering.
+func (w *prettyResponseWriter) Flush() {
+	if !w.started {
+		_ = w.start(w.buf.Bytes())
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter, so that http.ResponseController can reach it.
+func (w *prettyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start sends the header and the body.
+func (w *prettyResponseWriter) start(body []byte) error {
+	w.started = true
+	w.Header().Del("Content-Length")
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
+
+// close sends the buffered response, indented if it's JSON.
+func (w *prettyResponseWriter) close() {
+	if w.started {
+		return
+	}
+	body := w.buf.Bytes()
+	if mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type")); err == nil && mediaType == "application/json" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err == nil {
+			body = indented.Bytes()
+		}
+	}
+	if err := w.start(body); err != nil {
+		log.Error("Error writing response to client")
+	}
+}
+
+// CORS returns a middleware that lets the frontends served from other origins call the API.
+// The preflight requests, i.e., OPTIONS requests asking whether a method is allowed, are answered by the middleware itself without reaching the handler,
+// telling the browser to cache the answer for maxAge seconds. The browser decides how long to cache it if maxAge is 0.
+//
+// NOTE: Unlike the other middlewares, it wraps the router instead of being used by it, since the router doesn't serve OPTIONS requests.
+func CORS(maxAge int) func(http.Handler) http.Handler {
+	return cors.New(cors.Options{
+		// NOTE: "OPTIONS" is not included in comparison with the blog post since it's not necessary.
+		// See https://stackoverflow.com/questions/66926518/should-access-control-allow-methods-include-options.
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		// So that the browsers let the frontend read the pagination links and the URLs of the created TodoItems.
+		ExposedHeaders: []string{"Link", "Location"},
+		MaxAge:         maxAge,
+	}).Handler
+}
@@ -0,0 +1,974 @@
+package endpoint_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"todolist/core"
+	"todolist/endpoint"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/mock/gomock"
+)
+
+// TestTimeout Given a handler that takes longer than the timeout, when a request is made to it through the Timeout middleware, then the server should respond with a 503 status code and a JSON error.
+func TestTimeout(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/slow"
+	e.router.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-request.Context().Done():
+		}
+	})
+	e.router.Use(endpoint.Timeout(10 * time.Millisecond))
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusServiceUnavailable)
+	want := map[string]string{"error": "request timed out"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// contextCore is a mock core that records the context its storage operations are cancelled with; see core.TheCore.WithContext.
+type contextCore struct {
+	*MockCore
+	ctx context.Context
+}
+
+func (c *contextCore) WithContext(ctx context.Context) core.Core {
+	c.ctx = ctx
+	return c.MockCore
+}
+
+// TestTimeoutCancelsCore Given a core whose storage operations can be cancelled, when a request takes longer than the timeout,
+// then the operation of the core should be cancelled with the context of the request, instead of running on after the 503 response.
+func TestTimeoutCancelsCore(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	c := &contextCore{MockCore: e.mockCore}
+	endpoint.SetCore(c)
+	e.router = endpoint.NewRouter("")
+	e.router.Use(endpoint.Timeout(10 * time.Millisecond))
+	cancelled := make(chan error, 1)
+	e.mockCore.EXPECT().
+		GetItem(1).
+		DoAndReturn(func(int) (core.TodoItem, error) {
+			var err error
+			select {
+			case <-c.ctx.Done():
+				err = c.ctx.Err()
+			case <-time.After(time.Second):
+			}
+			cancelled <- err
+			return core.TodoItem{}, err
+		})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusServiceUnavailable)
+	// NOTE: The handler may still be running after the 503 response is written.
+	if err := <-cancelled; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the operation to be cancelled with %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+// TestTimeoutNotExceeded Given a handler that finishes within the timeout, when a request is made to it through the Timeout middleware, then the response of the handler should be passed through.
+func TestTimeoutNotExceeded(t *testing.T) {
+	// arrange
+	router := mux.NewRouter()
+	pattern := "/healthz"
+	router.HandleFunc(pattern, endpoint.Healthz)
+	router.Use(endpoint.Timeout(time.Second))
+	writer := httptest.NewRecorder()
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	router.ServeHTTP(writer, request)
+
+	// assert
+	if writer.Code != http.StatusOK {
+		t.Errorf("expected status code %v, got %v", http.StatusOK, writer.Code)
+	}
+}
+
+// TestReadOnlyAllowsReads Given the read-only mode is on, when a GET request is made through the ReadOnly middleware, then the response of the handler should be passed through.
+func TestReadOnlyAllowsReads(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetReadOnly(true)
+	t.Cleanup(func() { endpoint.SetReadOnly(false) })
+	pattern := "/healthz"
+	e.router.HandleFunc(pattern, endpoint.Healthz).Methods("GET")
+	e.router.Use(endpoint.ReadOnly)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+}
+
+// TestReadOnlyBlocksWrites Given the read-only mode is on, when a POST request is made through the ReadOnly middleware, then the server should respond with a 503 status code and a JSON error without calling the handler.
+func TestReadOnlyBlocksWrites(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetReadOnly(true)
+	t.Cleanup(func() { endpoint.SetReadOnly(false) })
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.CreateItem).Methods("POST")
+	e.router.Use(endpoint.ReadOnly)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader("description=some+task"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusServiceUnavailable)
+	want := map[string]string{"error": "read-only mode"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestReadOnlyBlocksAdminWrites Given the read-only mode is on, when a POST request is made to the ImportItems handler through the ReadOnly middleware,
+// then the server should respond with a 503 status code without importing anything, as only SetReadOnlyMode is exempted.
+func TestReadOnlyBlocksAdminWrites(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetReadOnly(true)
+	t.Cleanup(func() { endpoint.SetReadOnly(false) })
+	pattern := endpoint.AdminPathPrefix + "/import"
+	e.router.HandleFunc(pattern, endpoint.ImportItems).Methods("POST")
+	e.router.Use(endpoint.ReadOnly)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"items": []}`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusServiceUnavailable)
+}
+
+// TestSetReadOnlyMode Given the read-only mode is on, when a request is made to the SetReadOnlyMode handler through the ReadOnly middleware to turn it off, then the mode should be turned off.
+func TestSetReadOnlyMode(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetReadOnly(true)
+	t.Cleanup(func() { endpoint.SetReadOnly(false) })
+	pattern := endpoint.AdminPathPrefix + "/readonly"
+	e.router.HandleFunc(pattern, endpoint.SetReadOnlyMode).Methods("POST")
+	e.router.Use(endpoint.ReadOnly)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"read_only": false}`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	want := map[string]bool{"read_only": false}
+	got := map[string]bool{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+	if endpoint.IsReadOnly() {
+		t.Error("expected the read-only mode to be off")
+	}
+}
+
+// TestGzip Given a response larger than the minimum size, when a request accepting gzip is made through the Gzip middleware, then the response should be compressed and decompress to the JSON of the handler.
+func TestGzip(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.GetItems)
+	e.router.Use(endpoint.Gzip(endpoint.GzipMinSize))
+	todoItems := []core.TodoItem{}
+	for i := 1; i <= 50; i++ {
+		todoItems = append(todoItems, core.TodoItem{ID: i, Description: fmt.Sprintf("test%d", i)})
+	}
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(todoItems)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern+"?completed=false", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	if got := e.writer.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected Content-Encoding gzip, got %q", got)
+	}
+	reader, err := gzip.NewReader(e.writer.Body)
+	if err != nil {
+		t.Fatalf("error creating gzip reader: %v", err)
+	}
+	got := []core.TodoItem{}
+	if err := json.NewDecoder(reader).Decode(&got); err != nil {
+		t.Fatalf("error decoding decompressed body: %v", err)
+	}
+	e.expectEqual(todoItems, got)
+}
+
+// TestGzipSmallBody Given a response smaller than the minimum size, when a request accepting gzip is made through the Gzip middleware, then the response should be sent uncompressed.
+func TestGzipSmallBody(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/healthz"
+	e.router.HandleFunc(pattern, endpoint.Healthz)
+	e.router.Use(endpoint.Gzip(endpoint.GzipMinSize))
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	if got := e.writer.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no Content-Encoding, got %q", got)
+	}
+	got := map[string]bool{}
+	e.expectUnmarshalWithoutError(&got)
+}
+
+// TestGzipAlreadyCompressed Given a handler responding with an already compressed content type, when a request accepting gzip is made through the Gzip middleware, then the response should be sent as-is.
+func TestGzipAlreadyCompressed(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/image"
+	body := bytes.Repeat([]byte{0xff}, 2*endpoint.GzipMinSize)
+	e.router.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "image/png")
+		_, _ = writer.Write(body)
+	})
+	e.router.Use(endpoint.Gzip(endpoint.GzipMinSize))
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	if got := e.writer.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no Content-Encoding, got %q", got)
+	}
+	e.expectEqual(body, e.writer.Body.Bytes())
+}
+
+// TestMaxBodyBytes Given a body larger than the limit, when a request declaring its size is made through the MaxBodyBytes middleware, then the server should respond with a 413 status code and a JSON error without calling the core.
+func TestMaxBodyBytes(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo"
+	e.router.HandleFunc(pattern, endpoint.CreateItem).Methods("POST")
+	e.router.Use(endpoint.MaxBodyBytes(16, 1024))
+	e.mockCore.EXPECT().
+		CreateItem(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader("description="+strings.Repeat("a", 16)))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusRequestEntityTooLarge)
+	want := map[string]string{"error": "request body too large"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestMaxBodyBytesUndeclared Given a body larger than the limit, when a request not declaring its size is made through the MaxBodyBytes middleware, then the handler should respond with a 413 status code and a JSON error without calling the core.
+func TestMaxBodyBytesUndeclared(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/reorder"
+	e.router.HandleFunc(pattern, endpoint.Reorder).Methods("POST")
+	e.router.Use(endpoint.MaxBodyBytes(16, 1024))
+	e.mockCore.EXPECT().
+		Reorder(gomock.Any()).
+		Times(0)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"ids": [1, 2, 3, 4, 5, 6, 7, 8]}`))
+	request.ContentLength = -1
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusRequestEntityTooLarge)
+	want := map[string]string{"error": "request body too large"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
+
+// TestMaxBodyBytesImport Given a body larger than the limit but not the import limit, when a request is made to the import endpoint through the MaxBodyBytes middleware, then the body should be passed to the handler.
+func TestMaxBodyBytesImport(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := endpoint.AdminPathPrefix + "/import"
+	e.router.HandleFunc(pattern, endpoint.ImportItems).Methods("POST")
+	e.router.Use(endpoint.MaxBodyBytes(16, 1024))
+	snapshot := core.Snapshot{Items: []core.TodoItem{{ID: 1, Description: "test1"}}}
+	e.mockCore.EXPECT().
+		Import(snapshot).
+		Return(1, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{"items": [{"id": 1, "description": "test1"}]}`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+}
+
+// TestClientIP Given some trusted proxies, when ClientIP is called with requests from direct clients, through the trusted proxies, or with spoofed headers from untrusted peers,
+// then the forwarding headers should only be believed if the immediate peer is a trusted proxy.
+func TestClientIP(t *testing.T) {
+	if err := endpoint.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = endpoint.SetTrustedProxies(nil) })
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		want         string
+	}{
+		{"direct", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"direct IPv6", "[2001:db8::1]:5000", nil, "", "2001:db8::1"},
+		{"spoofed forwarded for", "203.0.113.7:5000", []string{"198.51.100.1"}, "", "203.0.113.7"},
+		{"spoofed real IP", "203.0.113.7:5000", nil, "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"trusted proxy by address", "192.168.1.1:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:5000", []string{"198.51.100.1, 10.4.5.6", "10.7.8.9"}, "", "198.51.100.1"},
+		{"spoofed hop behind trusted proxy", "10.1.2.3:5000", []string{"1.2.3.4, 198.51.100.1"}, "", "198.51.100.1"},
+		{"malformed hop", "10.1.2.3:5000", []string{"not an address, 10.4.5.6"}, "", "10.4.5.6"},
+		{"real IP from trusted proxy", "10.1.2.3:5000", nil, "198.51.100.1", "198.51.100.1"},
+		{"trusted proxy without headers", "10.1.2.3:5000", nil, "", "10.1.2.3"},
+		{"untrusted neighbour", "192.168.1.2:5000", []string{"198.51.100.1"}, "", "192.168.1.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			request := httptest.NewRequest(http.MethodGet, "/todo", nil)
+			request.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				request.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			// act
+			got := endpoint.ClientIP(request)
+
+			// assert
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestSetTrustedProxiesMalformed Given a malformed proxy, when SetTrustedProxies is called, then an error should be returned.
+func TestSetTrustedProxiesMalformed(t *testing.T) {
+	// act
+	err := endpoint.SetTrustedProxies([]string{"10.0.0.0/8", "proxy.local"})
+
+	// assert
+	if err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
+
+// TestPrettyJSON Given the GetItem handler served through the PrettyJSON middleware, when a request is made with the pretty query parameter true, then the JSON response should be indented.
+func TestPrettyJSON(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/todo/{id}"
+	e.router.HandleFunc(pattern, endpoint.GetItem)
+	e.router.Use(endpoint.PrettyJSON)
+	e.mockCore.EXPECT().
+		GetItem(1).
+		Return(core.TodoItem{}, core.TodoItemNotFoundError{ID: 1})
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo/1?pretty=true", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+	e.expectEqual("{\n  \"error\": \"TodoItem with id 1 not found\"\n}\n", e.writer.Body.String())
+}
+
+// TestPrettyJSONDefault Given the Healthz handler served through the PrettyJSON middleware, when a request is made without the pretty query parameter, then the JSON response should be sent as-is.
+func TestPrettyJSONDefault(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	pattern := "/healthz"
+	e.router.HandleFunc(pattern, endpoint.Healthz)
+	e.router.Use(endpoint.PrettyJSON)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual(`{"alive": true}`, e.writer.Body.String())
+}
+
+// TestPrettyJSONTurnedOn Given the indentation is turned on by SetPrettyJSON, when a request is made through the PrettyJSON middleware without the pretty query parameter, then the JSON response should be indented,
+// unless the parameter is false.
+func TestPrettyJSONTurnedOn(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	endpoint.SetPrettyJSON(true)
+	t.Cleanup(func() { endpoint.SetPrettyJSON(false) })
+	pattern := "/healthz"
+	e.router.HandleFunc(pattern, endpoint.Healthz)
+	e.router.Use(endpoint.PrettyJSON)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, pattern, nil)
+	e.router.ServeHTTP(e.writer, request)
+	compact := httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodGet, pattern+"?pretty=false", nil)
+	e.router.ServeHTTP(compact, request)
+
+	// assert
+	e.expectEqual("{\n  \"alive\": true\n}", e.writer.Body.String())
+	e.expectEqual(`{"alive": true}`, compact.Body.String())
+}
+
+// TestConcurrencyLimit Given a handler served through the ConcurrencyLimit middleware with a limit of N, when N+1 requests are made from the same address at once,
+// then the extra one should be responded with a 429 status code while the others are served, and another address should still be served.
+func TestConcurrencyLimit(t *testing.T) {
+	// arrange
+	const max = 3
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router := mux.NewRouter()
+	router.HandleFunc("/block", func(writer http.ResponseWriter, request *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+	router.HandleFunc("/healthz", endpoint.Healthz)
+	router.Use(endpoint.ConcurrencyLimit(max))
+	newRequest := func(path, remoteAddr string) *http.Request {
+		request, _ := http.NewRequest(http.MethodGet, path, nil)
+		request.RemoteAddr = remoteAddr
+		return request
+	}
+
+	// act
+	writers := make([]*httptest.ResponseRecorder, max)
+	var wg sync.WaitGroup
+	for i := range writers {
+		writers[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(writer *httptest.ResponseRecorder) {
+			defer wg.Done()
+			router.ServeHTTP(writer, newRequest("/block", "192.0.2.1:1234"))
+		}(writers[i])
+	}
+	for i := 0; i < max; i++ {
+		<-started
+	}
+	rejected := httptest.NewRecorder()
+	router.ServeHTTP(rejected, newRequest("/healthz", "192.0.2.1:5678"))
+	other := httptest.NewRecorder()
+	router.ServeHTTP(other, newRequest("/healthz", "192.0.2.2:1234"))
+	close(release)
+	wg.Wait()
+
+	// assert
+	if rejected.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status code %d, got %d", http.StatusTooManyRequests, rejected.Code)
+	}
+	for _, writer := range append(writers, other) {
+		if writer.Code != http.StatusOK {
+			t.Errorf("expected status code %d, got %d", http.StatusOK, writer.Code)
+		}
+	}
+}
+
+// TestConcurrencyLimitReleased Given a handler served through the ConcurrencyLimit middleware with a limit of 1, when requests are made from the same address one after another,
+// then all of them should be served, as the slot is released once the handler returns.
+func TestConcurrencyLimitReleased(t *testing.T) {
+	// arrange
+	router := mux.NewRouter()
+	router.HandleFunc("/healthz", endpoint.Healthz)
+	router.Use(endpoint.ConcurrencyLimit(1))
+
+	for i := 0; i < 3; i++ {
+		// act
+		writer := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+		request.RemoteAddr = "192.0.2.1:1234"
+		router.ServeHTTP(writer, request)
+
+		// assert
+		if writer.Code != http.StatusOK {
+			t.Errorf("expected status code %d, got %d", http.StatusOK, writer.Code)
+		}
+	}
+}
+
+// newBreakerRouter returns a router served through the WriteBreaker middleware with a threshold of 2 and a cooldown of a minute,
+// along with the fake clock of the breaker and a flag telling whether the writes fail.
+func newBreakerRouter(t *testing.T) (*mux.Router, *core.FakeClock, *bool) {
+	clock := core.NewFakeClock(time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC))
+	endpoint.SetClock(clock)
+	endpoint.SetWriteBreaker(2, time.Minute)
+	t.Cleanup(func() {
+		endpoint.SetClock(core.RealClock{})
+		endpoint.SetWriteBreaker(0, 0)
+	})
+	failing := false
+	router := mux.NewRouter()
+	router.HandleFunc("/todo", func(writer http.ResponseWriter, request *http.Request) {
+		if failing {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	})
+	router.Use(endpoint.WriteBreaker)
+	return router, clock, &failing
+}
+
+// serveBreaker makes a request with the method to the router and returns its status code.
+func serveBreaker(router *mux.Router, method string) int {
+	writer := httptest.NewRecorder()
+	request, _ := http.NewRequest(method, "/todo", nil)
+	router.ServeHTTP(writer, request)
+	return writer.Code
+}
+
+// TestWriteBreakerOpen Given the WriteBreaker middleware with a threshold of 2, when 2 writes fail in a row,
+// then the breaker should open, rejecting the next write with a 503 status code and a Retry-After header without serving it, while the reads are still served.
+func TestWriteBreakerOpen(t *testing.T) {
+	// arrange
+	router, _, failing := newBreakerRouter(t)
+	*failing = true
+
+	// act
+	serveBreaker(router, http.MethodPost)
+	if state := endpoint.WriteBreakerState(); state != endpoint.BreakerClosed {
+		t.Errorf("expected state %q after a failure, got %q", endpoint.BreakerClosed, state)
+	}
+	serveBreaker(router, http.MethodPost)
+	*failing = false
+	writer := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodPost, "/todo", nil)
+	router.ServeHTTP(writer, request)
+
+	// assert
+	if state := endpoint.WriteBreakerState(); state != endpoint.BreakerOpen {
+		t.Errorf("expected state %q, got %q", endpoint.BreakerOpen, state)
+	}
+	if writer.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status code %d, got %d", http.StatusServiceUnavailable, writer.Code)
+	}
+	if got := writer.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("expected Retry-After %q, got %q", "60", got)
+	}
+	if code := serveBreaker(router, http.MethodGet); code != http.StatusOK {
+		t.Errorf("expected status code %d for a read, got %d", http.StatusOK, code)
+	}
+}
+
+// TestWriteBreakerOpenAdmin Given the WriteBreaker middleware is open, when writes are made to the administrative endpoints,
+// then the import should be rejected with a 503 status code like the other writes, while the read-only mode can still be turned on.
+func TestWriteBreakerOpenAdmin(t *testing.T) {
+	// arrange
+	router, _, failing := newBreakerRouter(t)
+	served := map[string]bool{}
+	for _, path := range []string{endpoint.AdminPathPrefix + "/import", endpoint.AdminPathPrefix + "/readonly"} {
+		router.HandleFunc(path, func(writer http.ResponseWriter, request *http.Request) {
+			served[request.URL.Path] = true
+		})
+	}
+	*failing = true
+	serveBreaker(router, http.MethodPost)
+	serveBreaker(router, http.MethodPost)
+
+	// act
+	importWriter := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodPost, endpoint.AdminPathPrefix+"/import", nil)
+	router.ServeHTTP(importWriter, request)
+	readOnlyWriter := httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodPost, endpoint.AdminPathPrefix+"/readonly", nil)
+	router.ServeHTTP(readOnlyWriter, request)
+
+	// assert
+	if importWriter.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status code %d for the import, got %d", http.StatusServiceUnavailable, importWriter.Code)
+	}
+	if served[endpoint.AdminPathPrefix+"/import"] {
+		t.Error("expected the import not to be served")
+	}
+	if !served[endpoint.AdminPathPrefix+"/readonly"] {
+		t.Error("expected the read-only mode to be served")
+	}
+}
+
+// TestWriteBreakerSuccessResets Given the WriteBreaker middleware with a threshold of 2, when a write fails, then succeeds, and then fails again,
+// then the breaker should stay closed, as the failures are not consecutive.
+func TestWriteBreakerSuccessResets(t *testing.T) {
+	// arrange
+	router, _, failing := newBreakerRouter(t)
+
+	// act
+	*failing = true
+	serveBreaker(router, http.MethodPost)
+	*failing = false
+	serveBreaker(router, http.MethodPost)
+	*failing = true
+	serveBreaker(router, http.MethodPost)
+
+	// assert
+	if state := endpoint.WriteBreakerState(); state != endpoint.BreakerClosed {
+		t.Errorf("expected state %q, got %q", endpoint.BreakerClosed, state)
+	}
+}
+
+// TestWriteBreakerHalfOpenSucceeds Given the WriteBreaker middleware is open, when the cooldown elapses and a write succeeds,
+// then the breaker should be half-open before the write and closed after it, serving the later writes.
+func TestWriteBreakerHalfOpenSucceeds(t *testing.T) {
+	// arrange
+	router, clock, failing := newBreakerRouter(t)
+	*failing = true
+	serveBreaker(router, http.MethodPost)
+	serveBreaker(router, http.MethodPost)
+	*failing = false
+
+	// act
+	clock.Advance(time.Minute)
+	if state := endpoint.WriteBreakerState(); state != endpoint.BreakerHalfOpen {
+		t.Errorf("expected state %q after the cooldown, got %q", endpoint.BreakerHalfOpen, state)
+	}
+	probe := serveBreaker(router, http.MethodPut)
+
+	// assert
+	if probe != http.StatusOK {
+		t.Errorf("expected status code %d for the probe, got %d", http.StatusOK, probe)
+	}
+	if state := endpoint.WriteBreakerState(); state != endpoint.BreakerClosed {
+		t.Errorf("expected state %q, got %q", endpoint.BreakerClosed, state)
+	}
+	if code := serveBreaker(router, http.MethodDelete); code != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, code)
+	}
+}
+
+// TestWriteBreakerHalfOpenFails Given the WriteBreaker middleware is open, when the cooldown elapses and a write fails,
+// then the breaker should open again for another cooldown, rejecting the next write.
+func TestWriteBreakerHalfOpenFails(t *testing.T) {
+	// arrange
+	router, clock, failing := newBreakerRouter(t)
+	*failing = true
+	serveBreaker(router, http.MethodPost)
+	serveBreaker(router, http.MethodPost)
+
+	// act
+	clock.Advance(time.Minute)
+	probe := serveBreaker(router, http.MethodPost)
+	*failing = false
+	clock.Advance(time.Second)
+	code := serveBreaker(router, http.MethodPost)
+
+	// assert
+	if probe != http.StatusInternalServerError {
+		t.Errorf("expected status code %d for the probe, got %d", http.StatusInternalServerError, probe)
+	}
+	if state := endpoint.WriteBreakerState(); state != endpoint.BreakerOpen {
+		t.Errorf("expected state %q, got %q", endpoint.BreakerOpen, state)
+	}
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("expected status code %d, got %d", http.StatusServiceUnavailable, code)
+	}
+}
+
+// TestCORSPreflight Given a router wrapped by the CORS middleware with a max age, when a preflight request is made from another origin,
+// then it should be answered by the middleware with the max age, without reaching the router.
+func TestCORSPreflight(t *testing.T) {
+	// arrange
+	served := false
+	router := mux.NewRouter()
+	router.HandleFunc("/todo", func(writer http.ResponseWriter, request *http.Request) {
+		served = true
+	})
+	handler := endpoint.CORS(600)(router)
+
+	// act
+	writer := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodOptions, "/todo", nil)
+	request.Header.Set("Origin", "http://frontend.example")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	handler.ServeHTTP(writer, request)
+
+	// assert
+	if writer.Code != http.StatusNoContent {
+		t.Errorf("expected status code %d, got %d", http.StatusNoContent, writer.Code)
+	}
+	if got := writer.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("expected Access-Control-Max-Age %q, got %q", "600", got)
+	}
+	if got := writer.Header().Get("Access-Control-Allow-Methods"); got != http.MethodPost {
+		t.Errorf("expected Access-Control-Allow-Methods %q, got %q", http.MethodPost, got)
+	}
+	if served {
+		t.Error("expected the preflight request not to reach the router")
+	}
+}
+
+// TestCORSPreflightDefault Given a router wrapped by the CORS middleware with a max age of 0, when a preflight request is made,
+// then the max age should be left to the browser.
+func TestCORSPreflightDefault(t *testing.T) {
+	// arrange
+	handler := endpoint.CORS(0)(mux.NewRouter())
+
+	// act
+	writer := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodOptions, "/todo", nil)
+	request.Header.Set("Origin", "http://frontend.example")
+	request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	handler.ServeHTTP(writer, request)
+
+	// assert
+	if got := writer.Header().Values("Access-Control-Max-Age"); len(got) != 0 {
+		t.Errorf("expected no Access-Control-Max-Age, got %q", got)
+	}
+}
+
+// TestConsumes Given a handler accepting JSON bodies behind the Consumes middleware, when requests are made to it with a JSON, a wrong, a malformed, or no Content-Type header,
+// then the JSON and the unlabeled ones should be served, the latter taken as JSON, while the others are rejected with a 415 status code telling the accepted media types.
+func TestConsumes(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		want        int
+	}{
+		"json":      {"application/json; charset=utf-8", http.StatusOK},
+		"wrong":     {"text/plain", http.StatusUnsupportedMediaType},
+		"malformed": {"application/", http.StatusUnsupportedMediaType},
+		"missing":   {"", http.StatusOK},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			pattern := "/json"
+			var served string
+			e.router.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
+				served = request.Header.Get("Content-Type")
+			})
+			e.router.Use(endpoint.Consumes(endpoint.JSONMediaType))
+
+			// act
+			request, _ := http.NewRequest(http.MethodPost, pattern, strings.NewReader(`{}`))
+			if tt.contentType != "" {
+				request.Header.Set("Content-Type", tt.contentType)
+			}
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(tt.want)
+			if tt.want == http.StatusOK {
+				e.expectEqual(true, strings.HasPrefix(served, endpoint.JSONMediaType))
+			} else {
+				e.expectEqual("", served)
+				e.expectEqual(endpoint.JSONMediaType, e.writer.Header().Get("Accept"))
+			}
+		})
+	}
+}
+
+// TestCreateItemContentType Given the routes are registered by NewRouter, when a form is posted to the /todo endpoint without a Content-Type header, and then with a JSON one,
+// then the form should be taken as form-encoded and the TodoItem created, while the JSON one is rejected with a 415 status code without reaching the core.
+func TestCreateItemContentType(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	e.mockCore.EXPECT().
+		CreateItem("test", "").
+		Return(core.TodoItem{ID: 1, Description: "test"}, nil).
+		Times(1)
+	body := url.Values{"description": []string{"test"}}.Encode()
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/todo", strings.NewReader(body))
+	e.router.ServeHTTP(e.writer, request)
+	jsonWriter := httptest.NewRecorder()
+	jsonRequest, _ := http.NewRequest(http.MethodPost, "/todo", strings.NewReader(`{"description": "test"}`))
+	jsonRequest.Header.Set("Content-Type", "application/json")
+	e.router.ServeHTTP(jsonWriter, jsonRequest)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusCreated)
+	e.expectEqual(http.StatusUnsupportedMediaType, jsonWriter.Code)
+}
+
+// TestInFlight Given a handler served through the InFlight middleware, when a request is being served and after it's served,
+// then the count of the requests in flight should be one more during the request and back after it.
+func TestInFlight(t *testing.T) {
+	// arrange
+	before := endpoint.InFlightRequests()
+	var during int64
+	handler := endpoint.InFlight(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		during = endpoint.InFlightRequests()
+	}))
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	// assert
+	if during != before+1 {
+		t.Errorf("expected %d requests in flight during the request, got %d", before+1, during)
+	}
+	if after := endpoint.InFlightRequests(); after != before {
+		t.Errorf("expected %d requests in flight after the request, got %d", before, after)
+	}
+}
+
+// TestDrain Given a server with a request that doesn't finish, when Drain is called with a short timeout,
+// then an error should be returned once the timeout elapses and the request should be cut off.
+func TestDrain(t *testing.T) {
+	// arrange
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewUnstartedServer(endpoint.InFlight(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		close(started)
+		<-release
+	})))
+	server.Start()
+	clientErr := make(chan error, 1)
+	go func() {
+		response, err := http.Get(server.URL)
+		if err == nil {
+			response.Body.Close()
+		}
+		clientErr <- err
+	}()
+	<-started
+
+	// act
+	err := endpoint.Drain(server.Config, 50*time.Millisecond)
+
+	// assert
+	if err == nil {
+		t.Error("expected an error as the request is not drained in time")
+	}
+	if err := <-clientErr; err == nil {
+		t.Error("expected the request to be cut off")
+	}
+}
+
+// TestDrainIdle Given a server without requests in flight, when Drain is called, then it should return without error.
+func TestDrainIdle(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(endpoint.InFlight(http.HandlerFunc(endpoint.Healthz)))
+
+	// act
+	err := endpoint.Drain(server.Config, time.Second)
+
+	// assert
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestRun Given a server run by Run with a request in flight, when the context is cancelled,
+// then Run should not return until the request finishes, and the request should be served in full.
+func TestRun(t *testing.T) {
+	// arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := &http.Server{Handler: endpoint.InFlight(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		close(started)
+		<-release
+		writer.WriteHeader(http.StatusOK)
+	}))}
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	go func() {
+		ran <- endpoint.Run(ctx, server, func() error { return server.Serve(listener) }, time.Minute)
+	}()
+	clientCode := make(chan int, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			clientCode <- 0
+			return
+		}
+		response.Body.Close()
+		clientCode <- response.StatusCode
+	}()
+	<-started
+
+	// act
+	cancel()
+
+	// assert
+	select {
+	case err := <-ran:
+		t.Fatalf("expected Run to wait for the request in flight, but it returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-ran; err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if code := <-clientCode; code != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, code)
+	}
+}
+
+// TestRunServeError Given a server that fails to serve, when Run is called, then the error of serving should be returned without waiting for the context.
+func TestRunServeError(t *testing.T) {
+	// arrange
+	serveErr := errors.New("address already in use")
+
+	// act
+	err := endpoint.Run(context.Background(), &http.Server{}, func() error { return serveErr }, time.Minute)
+
+	// assert
+	if !errors.Is(err, serveErr) {
+		t.Errorf("expected %v, got %v", serveErr, err)
+	}
+}
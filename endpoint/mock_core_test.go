@@ -11,6 +11,7 @@ package endpoint_test
 
 import (
 	reflect "reflect"
+	time "time"
 
 	core "todolist/core"
 
@@ -40,22 +41,113 @@ func (m *MockCore) EXPECT() *MockCoreMockRecorder {
 	return m.recorder
 }
 
+// AddAttachment mocks base method.
+func (m *MockCore) AddAttachment(id core.ItemID, url string) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAttachment", id, url)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAttachment indicates an expected call of AddAttachment.
+func (mr *MockCoreMockRecorder) AddAttachment(id, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAttachment", reflect.TypeOf((*MockCore)(nil).AddAttachment), id, url)
+}
+
+// AddBlockers mocks base method.
+func (m *MockCore) AddBlockers(id core.ItemID, blockerIDs []core.ItemID) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBlockers", id, blockerIDs)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBlockers indicates an expected call of AddBlockers.
+func (mr *MockCoreMockRecorder) AddBlockers(id, blockerIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBlockers", reflect.TypeOf((*MockCore)(nil).AddBlockers), id, blockerIDs)
+}
+
+// Batch mocks base method.
+func (m *MockCore) Batch(ops []core.BatchOp, atomic bool) ([]core.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Batch", ops, atomic)
+	ret0, _ := ret[0].([]core.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch.
+func (mr *MockCoreMockRecorder) Batch(ops, atomic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockCore)(nil).Batch), ops, atomic)
+}
+
+// CloneItem mocks base method.
+func (m *MockCore) CloneItem(id core.ItemID) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloneItem", id)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloneItem indicates an expected call of CloneItem.
+func (mr *MockCoreMockRecorder) CloneItem(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloneItem", reflect.TypeOf((*MockCore)(nil).CloneItem), id)
+}
+
 // CreateItem mocks base method.
-func (m *MockCore) CreateItem(description string) core.TodoItem {
+func (m *MockCore) CreateItem(description, color string) (core.TodoItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateItem", description)
+	ret := m.ctrl.Call(m, "CreateItem", description, color)
 	ret0, _ := ret[0].(core.TodoItem)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateItem indicates an expected call of CreateItem.
-func (mr *MockCoreMockRecorder) CreateItem(description any) *gomock.Call {
+func (mr *MockCoreMockRecorder) CreateItem(description, color any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockCore)(nil).CreateItem), description, color)
+}
+
+// CreateItems mocks base method.
+func (m *MockCore) CreateItems(items []core.TodoItem) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateItems", items)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateItems indicates an expected call of CreateItems.
+func (mr *MockCoreMockRecorder) CreateItems(items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItems", reflect.TypeOf((*MockCore)(nil).CreateItems), items)
+}
+
+// DeleteCompleted mocks base method.
+func (m *MockCore) DeleteCompleted(maxFraction float64) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompleted", maxFraction)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCompleted indicates an expected call of DeleteCompleted.
+func (mr *MockCoreMockRecorder) DeleteCompleted(maxFraction any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockCore)(nil).CreateItem), description)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompleted", reflect.TypeOf((*MockCore)(nil).DeleteCompleted), maxFraction)
 }
 
 // DeleteItem mocks base method.
-func (m *MockCore) DeleteItem(id int) error {
+func (m *MockCore) DeleteItem(id core.ItemID) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteItem", id)
 	ret0, _ := ret[0].(error)
@@ -68,6 +160,126 @@ func (mr *MockCoreMockRecorder) DeleteItem(id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockCore)(nil).DeleteItem), id)
 }
 
+// ExistsItem mocks base method.
+func (m *MockCore) ExistsItem(id core.ItemID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistsItem", id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistsItem indicates an expected call of ExistsItem.
+func (mr *MockCoreMockRecorder) ExistsItem(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsItem", reflect.TypeOf((*MockCore)(nil).ExistsItem), id)
+}
+
+// Export mocks base method.
+func (m *MockCore) Export() (core.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export")
+	ret0, _ := ret[0].(core.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockCoreMockRecorder) Export() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockCore)(nil).Export))
+}
+
+// ExportStream mocks base method.
+func (m *MockCore) ExportStream(fn func(core.TodoItem) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportStream", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportStream indicates an expected call of ExportStream.
+func (mr *MockCoreMockRecorder) ExportStream(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportStream", reflect.TypeOf((*MockCore)(nil).ExportStream), fn)
+}
+
+// ForceUpdateItem mocks base method.
+func (m *MockCore) ForceUpdateItem(id core.ItemID, completed bool) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceUpdateItem", id, completed)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForceUpdateItem indicates an expected call of ForceUpdateItem.
+func (mr *MockCoreMockRecorder) ForceUpdateItem(id, completed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceUpdateItem", reflect.TypeOf((*MockCore)(nil).ForceUpdateItem), id, completed)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockCore) GetAuditLog(itemID core.ItemID) ([]core.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", itemID)
+	ret0, _ := ret[0].([]core.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockCoreMockRecorder) GetAuditLog(itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockCore)(nil).GetAuditLog), itemID)
+}
+
+// GetBlockedItems mocks base method.
+func (m *MockCore) GetBlockedItems() ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockedItems")
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockedItems indicates an expected call of GetBlockedItems.
+func (mr *MockCoreMockRecorder) GetBlockedItems() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockedItems", reflect.TypeOf((*MockCore)(nil).GetBlockedItems))
+}
+
+// GetChangesSince mocks base method.
+func (m *MockCore) GetChangesSince(since time.Time) ([]core.TodoItem, []core.ItemID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangesSince", since)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].([]core.ItemID)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetChangesSince indicates an expected call of GetChangesSince.
+func (mr *MockCoreMockRecorder) GetChangesSince(since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangesSince", reflect.TypeOf((*MockCore)(nil).GetChangesSince), since)
+}
+
+// GetItem mocks base method.
+func (m *MockCore) GetItem(id core.ItemID) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", id)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockCoreMockRecorder) GetItem(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockCore)(nil).GetItem), id)
+}
+
 // GetItems mocks base method.
 func (m *MockCore) GetItems(completed bool) []core.TodoItem {
 	m.ctrl.T.Helper()
@@ -82,8 +294,393 @@ func (mr *MockCoreMockRecorder) GetItems(completed any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItems", reflect.TypeOf((*MockCore)(nil).GetItems), completed)
 }
 
+// GetItemsAfter mocks base method.
+func (m *MockCore) GetItemsAfter(cursor core.ItemID, limit int) ([]core.TodoItem, core.ItemID) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsAfter", cursor, limit)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(core.ItemID)
+	return ret0, ret1
+}
+
+// GetItemsAfter indicates an expected call of GetItemsAfter.
+func (mr *MockCoreMockRecorder) GetItemsAfter(cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsAfter", reflect.TypeOf((*MockCore)(nil).GetItemsAfter), cursor, limit)
+}
+
+// GetItemsByIDs mocks base method.
+func (m *MockCore) GetItemsByIDs(ids []core.ItemID) []core.TodoItem {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsByIDs", ids)
+	ret0, _ := ret[0].([]core.TodoItem)
+	return ret0
+}
+
+// GetItemsByIDs indicates an expected call of GetItemsByIDs.
+func (mr *MockCoreMockRecorder) GetItemsByIDs(ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByIDs", reflect.TypeOf((*MockCore)(nil).GetItemsByIDs), ids)
+}
+
+// GetItemsByStatus mocks base method.
+func (m *MockCore) GetItemsByStatus(status core.ItemStatus) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsByStatus", status)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemsByStatus indicates an expected call of GetItemsByStatus.
+func (mr *MockCoreMockRecorder) GetItemsByStatus(status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByStatus", reflect.TypeOf((*MockCore)(nil).GetItemsByStatus), status)
+}
+
+// GetItemsCompletedBetween mocks base method.
+func (m *MockCore) GetItemsCompletedBetween(start, end time.Time) []core.TodoItem {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsCompletedBetween", start, end)
+	ret0, _ := ret[0].([]core.TodoItem)
+	return ret0
+}
+
+// GetItemsCompletedBetween indicates an expected call of GetItemsCompletedBetween.
+func (mr *MockCoreMockRecorder) GetItemsCompletedBetween(start, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsCompletedBetween", reflect.TypeOf((*MockCore)(nil).GetItemsCompletedBetween), start, end)
+}
+
+// GetItemsCompletedOn mocks base method.
+func (m *MockCore) GetItemsCompletedOn(date time.Time) []core.TodoItem {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsCompletedOn", date)
+	ret0, _ := ret[0].([]core.TodoItem)
+	return ret0
+}
+
+// GetItemsCompletedOn indicates an expected call of GetItemsCompletedOn.
+func (mr *MockCoreMockRecorder) GetItemsCompletedOn(date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsCompletedOn", reflect.TypeOf((*MockCore)(nil).GetItemsCompletedOn), date)
+}
+
+// GetItemsFiltered mocks base method.
+func (m *MockCore) GetItemsFiltered(f core.ItemFilter) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsFiltered", f)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItemsFiltered indicates an expected call of GetItemsFiltered.
+func (mr *MockCoreMockRecorder) GetItemsFiltered(f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsFiltered", reflect.TypeOf((*MockCore)(nil).GetItemsFiltered), f)
+}
+
+// GetItemsPage mocks base method.
+func (m *MockCore) GetItemsPage(offset, limit int) ([]core.TodoItem, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItemsPage", offset, limit)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetItemsPage indicates an expected call of GetItemsPage.
+func (mr *MockCoreMockRecorder) GetItemsPage(offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsPage", reflect.TypeOf((*MockCore)(nil).GetItemsPage), offset, limit)
+}
+
+// GetTimeSpent mocks base method.
+func (m *MockCore) GetTimeSpent(id core.ItemID) (core.TimeSpent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeSpent", id)
+	ret0, _ := ret[0].(core.TimeSpent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeSpent indicates an expected call of GetTimeSpent.
+func (mr *MockCoreMockRecorder) GetTimeSpent(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeSpent", reflect.TypeOf((*MockCore)(nil).GetTimeSpent), id)
+}
+
+// Import mocks base method.
+func (m *MockCore) Import(snapshot core.Snapshot) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", snapshot)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockCoreMockRecorder) Import(snapshot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockCore)(nil).Import), snapshot)
+}
+
+// MoveItem mocks base method.
+func (m *MockCore) MoveItem(id core.ItemID, listID int) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveItem", id, listID)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveItem indicates an expected call of MoveItem.
+func (mr *MockCoreMockRecorder) MoveItem(id, listID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveItem", reflect.TypeOf((*MockCore)(nil).MoveItem), id, listID)
+}
+
+// PruneCompletedOlderThan mocks base method.
+func (m *MockCore) PruneCompletedOlderThan(d time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneCompletedOlderThan", d)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneCompletedOlderThan indicates an expected call of PruneCompletedOlderThan.
+func (mr *MockCoreMockRecorder) PruneCompletedOlderThan(d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneCompletedOlderThan", reflect.TypeOf((*MockCore)(nil).PruneCompletedOlderThan), d)
+}
+
+// Query mocks base method.
+func (m *MockCore) Query(q core.Query) (core.QueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", q)
+	ret0, _ := ret[0].(core.QueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockCoreMockRecorder) Query(q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockCore)(nil).Query), q)
+}
+
+// Reindex mocks base method.
+func (m *MockCore) Reindex() (core.ReindexReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reindex")
+	ret0, _ := ret[0].(core.ReindexReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reindex indicates an expected call of Reindex.
+func (mr *MockCoreMockRecorder) Reindex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reindex", reflect.TypeOf((*MockCore)(nil).Reindex))
+}
+
+// RemoveAttachment mocks base method.
+func (m *MockCore) RemoveAttachment(id core.ItemID, url string) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAttachment", id, url)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveAttachment indicates an expected call of RemoveAttachment.
+func (mr *MockCoreMockRecorder) RemoveAttachment(id, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAttachment", reflect.TypeOf((*MockCore)(nil).RemoveAttachment), id, url)
+}
+
+// Reorder mocks base method.
+func (m *MockCore) Reorder(ids []core.ItemID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reorder", ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reorder indicates an expected call of Reorder.
+func (mr *MockCoreMockRecorder) Reorder(ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reorder", reflect.TypeOf((*MockCore)(nil).Reorder), ids)
+}
+
+// ReplaceInDescriptions mocks base method.
+func (m *MockCore) ReplaceInDescriptions(r core.DescriptionReplacement) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceInDescriptions", r)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceInDescriptions indicates an expected call of ReplaceInDescriptions.
+func (mr *MockCoreMockRecorder) ReplaceInDescriptions(r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceInDescriptions", reflect.TypeOf((*MockCore)(nil).ReplaceInDescriptions), r)
+}
+
+// Reset mocks base method.
+func (m *MockCore) Reset() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockCoreMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockCore)(nil).Reset))
+}
+
+// RestoreItems mocks base method.
+func (m *MockCore) RestoreItems(items []core.TodoItem) ([]core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreItems", items)
+	ret0, _ := ret[0].([]core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreItems indicates an expected call of RestoreItems.
+func (mr *MockCoreMockRecorder) RestoreItems(items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItems", reflect.TypeOf((*MockCore)(nil).RestoreItems), items)
+}
+
+// SetItemColor mocks base method.
+func (m *MockCore) SetItemColor(id core.ItemID, color string) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetItemColor", id, color)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetItemColor indicates an expected call of SetItemColor.
+func (mr *MockCoreMockRecorder) SetItemColor(id, color any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetItemColor", reflect.TypeOf((*MockCore)(nil).SetItemColor), id, color)
+}
+
+// SetItemsCompleted mocks base method.
+func (m *MockCore) SetItemsCompleted(ids []core.ItemID, completed bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetItemsCompleted", ids, completed)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetItemsCompleted indicates an expected call of SetItemsCompleted.
+func (mr *MockCoreMockRecorder) SetItemsCompleted(ids, completed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetItemsCompleted", reflect.TypeOf((*MockCore)(nil).SetItemsCompleted), ids, completed)
+}
+
+// StartItem mocks base method.
+func (m *MockCore) StartItem(id core.ItemID) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartItem", id)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartItem indicates an expected call of StartItem.
+func (mr *MockCoreMockRecorder) StartItem(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartItem", reflect.TypeOf((*MockCore)(nil).StartItem), id)
+}
+
+// StopItem mocks base method.
+func (m *MockCore) StopItem(id core.ItemID) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopItem", id)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopItem indicates an expected call of StopItem.
+func (mr *MockCoreMockRecorder) StopItem(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopItem", reflect.TypeOf((*MockCore)(nil).StopItem), id)
+}
+
+// StorageStats mocks base method.
+func (m *MockCore) StorageStats() (core.BackendStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorageStats")
+	ret0, _ := ret[0].(core.BackendStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StorageStats indicates an expected call of StorageStats.
+func (mr *MockCoreMockRecorder) StorageStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageStats", reflect.TypeOf((*MockCore)(nil).StorageStats))
+}
+
+// SuggestDescriptions mocks base method.
+func (m *MockCore) SuggestDescriptions(prefix string, limit int) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestDescriptions", prefix, limit)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// SuggestDescriptions indicates an expected call of SuggestDescriptions.
+func (mr *MockCoreMockRecorder) SuggestDescriptions(prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestDescriptions", reflect.TypeOf((*MockCore)(nil).SuggestDescriptions), prefix, limit)
+}
+
+// Summary mocks base method.
+func (m *MockCore) Summary() (core.SummaryStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary")
+	ret0, _ := ret[0].(core.SummaryStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Summary indicates an expected call of Summary.
+func (mr *MockCoreMockRecorder) Summary() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockCore)(nil).Summary))
+}
+
+// ToggleItem mocks base method.
+func (m *MockCore) ToggleItem(id core.ItemID) (core.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToggleItem", id)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ToggleItem indicates an expected call of ToggleItem.
+func (mr *MockCoreMockRecorder) ToggleItem(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleItem", reflect.TypeOf((*MockCore)(nil).ToggleItem), id)
+}
+
 // UpdateItem mocks base method.
-func (m *MockCore) UpdateItem(id int, completed bool) (core.TodoItem, error) {
+func (m *MockCore) UpdateItem(id core.ItemID, completed bool) (core.TodoItem, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateItem", id, completed)
 	ret0, _ := ret[0].(core.TodoItem)
@@ -96,3 +693,116 @@ func (mr *MockCoreMockRecorder) UpdateItem(id, completed any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockCore)(nil).UpdateItem), id, completed)
 }
+
+// UpsertItem mocks base method.
+func (m *MockCore) UpsertItem(item core.TodoItem) (core.TodoItem, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertItem", item)
+	ret0, _ := ret[0].(core.TodoItem)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpsertItem indicates an expected call of UpsertItem.
+func (mr *MockCoreMockRecorder) UpsertItem(item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertItem", reflect.TypeOf((*MockCore)(nil).UpsertItem), item)
+}
+
+// MockListCore is a mock of ListCore interface.
+type MockListCore struct {
+	ctrl     *gomock.Controller
+	recorder *MockListCoreMockRecorder
+}
+
+// MockListCoreMockRecorder is the mock recorder for MockListCore.
+type MockListCoreMockRecorder struct {
+	mock *MockListCore
+}
+
+// NewMockListCore creates a new mock instance.
+func NewMockListCore(ctrl *gomock.Controller) *MockListCore {
+	mock := &MockListCore{ctrl: ctrl}
+	mock.recorder = &MockListCoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockListCore) EXPECT() *MockListCoreMockRecorder {
+	return m.recorder
+}
+
+// CreateList mocks base method.
+func (m *MockListCore) CreateList(name string) (core.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateList", name)
+	ret0, _ := ret[0].(core.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateList indicates an expected call of CreateList.
+func (mr *MockListCoreMockRecorder) CreateList(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateList", reflect.TypeOf((*MockListCore)(nil).CreateList), name)
+}
+
+// DeleteList mocks base method.
+func (m *MockListCore) DeleteList(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteList", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteList indicates an expected call of DeleteList.
+func (mr *MockListCoreMockRecorder) DeleteList(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteList", reflect.TypeOf((*MockListCore)(nil).DeleteList), id)
+}
+
+// GetList mocks base method.
+func (m *MockListCore) GetList(id int) (core.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", id)
+	ret0, _ := ret[0].(core.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockListCoreMockRecorder) GetList(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockListCore)(nil).GetList), id)
+}
+
+// GetLists mocks base method.
+func (m *MockListCore) GetLists() ([]core.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLists")
+	ret0, _ := ret[0].([]core.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLists indicates an expected call of GetLists.
+func (mr *MockListCoreMockRecorder) GetLists() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLists", reflect.TypeOf((*MockListCore)(nil).GetLists))
+}
+
+// RenameList mocks base method.
+func (m *MockListCore) RenameList(id int, name string) (core.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameList", id, name)
+	ret0, _ := ret[0].(core.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameList indicates an expected call of RenameList.
+func (mr *MockListCoreMockRecorder) RenameList(id, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameList", reflect.TypeOf((*MockListCore)(nil).RenameList), id, name)
+}
@@ -0,0 +1,131 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// basePath is the prefix of the paths of all the endpoints, set by NewRouter.
+var basePath string
+
+// NewRouter returns a router with all the endpoints registered under the base path, e.g., "/api/v1".
+// An empty base path serves the endpoints at the root.
+//
+// NOTE: The middlewares are left to the caller, as they depend on the settings of the application; Head and the invalidation of the ItemsCache are the only exceptions.
+func NewRouter(base string) *mux.Router {
+	basePath = base
+	router := mux.NewRouter()
+	api := router
+	if base != "" {
+		api = router.PathPrefix(base).Subrouter()
+	}
+	// The handlers reading the request bodies only accept the media types they can read.
+	formBody := consumes(FormMediaType, MultipartMediaType)
+	jsonBody := consumes(JSONMediaType)
+	markdownBody := consumes(MarkdownMediaType, "text/plain")
+	// NOTE: The endpoint are not entirely the same as the blog post.
+	api.HandleFunc("/healthz", Healthz).Methods("GET", "HEAD")
+	api.Handle("/todo", formBody(CreateItem)).Methods("POST")
+	api.HandleFunc("/todo", cachedItems(GetItems)).Methods("GET", "HEAD")
+	api.Handle("/todo", jsonBody(UpsertItem)).Methods("PUT")
+	api.HandleFunc("/todo/summary", Summary).Methods("GET", "HEAD")
+	api.HandleFunc("/todo/suggest", SuggestDescriptions).Methods("GET", "HEAD")
+	api.HandleFunc("/todo/changes", GetChanges).Methods("GET", "HEAD")
+	api.HandleFunc("/todo/today", GetItemsToday).Methods("GET", "HEAD")
+	api.HandleFunc("/todo/blocked", GetBlockedItems).Methods("GET", "HEAD")
+	api.HandleFunc("/todo/export.md", ExportMarkdown).Methods("GET", "HEAD")
+	api.HandleFunc("/todo/{id}", GetItem).Methods("GET")
+	api.HandleFunc("/todo/{id}", ItemExists).Methods("HEAD")
+	// NOTE: Must be registered before "/todo/{id}", otherwise "reorder", "status", "restore", "replace", "batch", "import.md", and "completed" are taken as ids.
+	api.Handle("/todo/reorder", jsonBody(Reorder)).Methods("POST")
+	api.Handle("/todo/status", jsonBody(SetItemsCompleted)).Methods("POST")
+	api.Handle("/todo/restore", jsonBody(RestoreItems)).Methods("POST")
+	api.Handle("/todo/replace", jsonBody(ReplaceDescriptions)).Methods("POST")
+	api.Handle("/todo/batch", jsonBody(Batch)).Methods("POST")
+	api.Handle("/todo/import.md", markdownBody(ImportMarkdown)).Methods("POST")
+	api.HandleFunc("/todo/completed", DeleteCompleted).Methods("DELETE")
+	api.Handle("/todo/{id}", formBody(UpdateItem)).Methods("POST")
+	api.HandleFunc("/todo/{id}/toggle", ToggleItem).Methods("POST")
+	api.HandleFunc("/todo/{id}/start", StartItem).Methods("POST")
+	api.HandleFunc("/todo/{id}/stop", StopItem).Methods("POST")
+	api.HandleFunc("/todo/{id}/time", GetTimeSpent).Methods("GET", "HEAD")
+	api.Handle("/todo/{id}/color", formBody(SetItemColor)).Methods("POST")
+	api.Handle("/todo/{id}/move", formBody(MoveItem)).Methods("POST")
+	api.HandleFunc("/todo/{id}/clone", CloneItem).Methods("POST")
+	api.Handle("/todo/{id}/blockers", jsonBody(AddBlockers)).Methods("POST")
+	api.Handle("/todo/{id}/attachments", jsonBody(AddAttachment)).Methods("POST")
+	api.HandleFunc("/todo/{id}/attachments", RemoveAttachment).Methods("DELETE")
+	api.HandleFunc("/todo/{id}", DeleteItem).Methods("DELETE")
+	api.Handle("/list", formBody(CreateList)).Methods("POST")
+	api.HandleFunc("/list", GetLists).Methods("GET", "HEAD")
+	api.HandleFunc("/list/{id}", GetList).Methods("GET", "HEAD")
+	api.Handle("/list/{id}", formBody(RenameList)).Methods("POST")
+	api.HandleFunc("/list/{id}", DeleteList).Methods("DELETE")
+	admin := api.PathPrefix(AdminPathPrefix).Subrouter()
+	admin.Handle("/readonly", jsonBody(SetReadOnlyMode)).Methods("POST")
+	admin.HandleFunc("/export", ExportItems).Methods("GET")
+	admin.Handle("/import", jsonBody(ImportItems)).Methods("POST")
+	admin.HandleFunc("/reset", ResetItems).Methods("POST")
+	admin.Handle("/seed", formBody(SeedItems)).Methods("POST")
+	admin.HandleFunc("/reindex", ReindexItems).Methods("POST")
+	admin.HandleFunc("/audit", GetAuditLog).Methods("GET", "HEAD")
+	admin.HandleFunc("/status", StorageStatus).Methods("GET", "HEAD")
+	admin.HandleFunc("/config", GetConfig).Methods("GET", "HEAD")
+	admin.Use(AdminAuth)
+	// NOTE: Unlike the other middlewares, Head is required by the routes above, which serve HEAD with the handlers of GET.
+	router.Use(Head)
+	// NOTE: Required by the cached GetItems above, so that the cache is never stale after a mutation through the handlers.
+	router.Use(invalidateItemsCache)
+	// NOTE: mux loses the method mismatch of the routes in the subrouters once a later route shares their prefix, responding with "not found" instead,
+	// so both cases are told apart by the same handler.
+	router.NotFoundHandler = unmatched(router)
+	router.MethodNotAllowedHandler = router.NotFoundHandler
+	return router
+}
+
+// consumes returns a function that wraps a handler with Consumes(mediaTypes...).
+func consumes(mediaTypes ...string) func(http.HandlerFunc) http.Handler {
+	middleware := Consumes(mediaTypes...)
+	return func(handler http.HandlerFunc) http.Handler {
+		return middleware(handler)
+	}
+}
+
+// unmatched returns a handler for the requests that match no endpoint of the router.
+// If the path matches an endpoint but the method doesn't, it responds with a 405 status code and lists the methods that the path is served with in the Allow header.
+//
+//	{"error": "method PUT not allowed"}
+//
+// Otherwise, it responds with a 404 status code.
+//
+//	{"error": "path /foo not found"}
+func unmatched(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var allowed []string
+		_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			var match mux.RouteMatch
+			if route.Match(request, &match) || !errors.Is(match.MatchErr, mux.ErrMethodMismatch) {
+				return nil
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				// The subrouters have no methods of their own.
+				return nil
+			}
+			allowed = append(allowed, methods...)
+			return nil
+		})
+		if len(allowed) == 0 {
+			writeError(writer, http.StatusNotFound, fmt.Errorf("path %s not found", request.URL.Path))
+			return
+		}
+		slices.Sort(allowed)
+		writer.Header().Set("Allow", strings.Join(slices.Compact(allowed), ", "))
+		writeError(writer, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", request.Method))
+	})
+}
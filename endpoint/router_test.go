@@ -0,0 +1,165 @@
+package endpoint_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"todolist/core"
+	"todolist/endpoint"
+)
+
+// TestNewRouterWithBasePath Given a router constructed with a base path, when a request is made to an endpoint under the base path, then the endpoint should be served.
+func TestNewRouterWithBasePath(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("/api/v1")
+	t.Cleanup(func() { endpoint.NewRouter("") })
+	e.mockCore.EXPECT().
+		GetItems(false).
+		Return(nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/api/v1/todo?completed=false", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+}
+
+// TestNewRouterWithBasePathLocation Given a router constructed with a base path, when a TodoItem is created, then the Location header should point at it under the base path.
+func TestNewRouterWithBasePathLocation(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("/api/v1")
+	t.Cleanup(func() { endpoint.NewRouter("") })
+	e.mockCore.EXPECT().
+		CreateItem("test", "").
+		Return(core.TodoItem{ID: 7, Description: "test"}, nil)
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/api/v1/todo", strings.NewReader("description=test"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusCreated)
+	e.expectEqual("/api/v1/todo/7", e.writer.Header().Get("Location"))
+}
+
+// TestNewRouterWithBasePathOutside Given a router constructed with a base path, when a request is made to an endpoint outside the base path, then the server should respond with a 404 status code.
+func TestNewRouterWithBasePathOutside(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("/api/v1")
+	t.Cleanup(func() { endpoint.NewRouter("") })
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/todo", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+}
+
+// TestNewRouterWithBasePathReadOnly Given a router constructed with a base path and the read-only mode is on, when a request is made to turn it off through the ReadOnly middleware, then the administrative endpoint should still be served.
+func TestNewRouterWithBasePathReadOnly(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("/api/v1")
+	t.Cleanup(func() { endpoint.NewRouter("") })
+	e.router.Use(endpoint.ReadOnly)
+	endpoint.SetReadOnly(true)
+	t.Cleanup(func() { endpoint.SetReadOnly(false) })
+
+	// act
+	request, _ := http.NewRequest(http.MethodPost, "/api/v1"+endpoint.AdminPathPrefix+"/readonly", strings.NewReader(`{"read_only": false}`))
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+}
+
+// TestNewRouterHead Given a router constructed by NewRouter, when a HEAD request is made to /todo, then the server should respond with a 200 status code, the Content-Length of the body of GET, and no body.
+func TestNewRouterHead(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+	todoItems := []core.TodoItem{
+		{ID: 1, Description: "test1", Completed: true},
+		{ID: 2, Description: "test2", Completed: false},
+	}
+	e.mockCore.EXPECT().
+		GetItems(true).
+		Return(todoItems).
+		Times(2)
+	getWriter := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, "/todo?completed=true", nil)
+	e.router.ServeHTTP(getWriter, request)
+
+	// act
+	request, _ = http.NewRequest(http.MethodHead, "/todo?completed=true", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusOK)
+	e.expectEqual(strconv.Itoa(getWriter.Body.Len()), e.writer.Header().Get("Content-Length"))
+	e.expectEqual(getWriter.Header().Get("Content-Type"), e.writer.Header().Get("Content-Type"))
+	if e.writer.Body.Len() != 0 {
+		t.Errorf("expected no body, got %q", e.writer.Body.String())
+	}
+}
+
+// TestNewRouterMethodNotAllowed Given a router constructed by NewRouter, when a request is made to an endpoint with a method it's not served with, then the server should respond with a 405 status code, a JSON error, and the methods it's served with in the Allow header.
+func TestNewRouterMethodNotAllowed(t *testing.T) {
+	tests := []struct {
+		base, path, method string
+		wantAllow          string
+	}{
+		{"", "/todo", http.MethodPatch, "GET, HEAD, POST, PUT"},
+		{"", "/todo/1", http.MethodPut, "DELETE, GET, HEAD, POST"},
+		{"/api/v1", "/api/v1/todo/1/toggle", http.MethodGet, "POST"},
+		{"/api/v1", "/api/v1/todo/reorder", http.MethodPut, "DELETE, GET, HEAD, POST"},
+		{"", "/admin/export", http.MethodPost, "GET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			// arrange
+			e := newTestEnv(t)
+			e.router = endpoint.NewRouter(tt.base)
+			t.Cleanup(func() { endpoint.NewRouter("") })
+
+			// act
+			request, _ := http.NewRequest(tt.method, tt.path, nil)
+			e.router.ServeHTTP(e.writer, request)
+
+			// assert
+			e.expectStatusCodeToBe(http.StatusMethodNotAllowed)
+			e.expectEqual(tt.wantAllow, e.writer.Header().Get("Allow"))
+			want := map[string]string{"error": "method " + tt.method + " not allowed"}
+			got := map[string]string{}
+			e.expectUnmarshalWithoutError(&got)
+			e.expectEqual(want, got)
+		})
+	}
+}
+
+// TestNewRouterNotFound Given a router constructed by NewRouter, when a request is made to a path that matches no endpoint, then the server should respond with a 404 status code and a JSON error.
+func TestNewRouterNotFound(t *testing.T) {
+	// arrange
+	e := newTestEnv(t)
+	e.router = endpoint.NewRouter("")
+
+	// act
+	request, _ := http.NewRequest(http.MethodGet, "/nothing/here", nil)
+	e.router.ServeHTTP(e.writer, request)
+
+	// assert
+	e.expectStatusCodeToBe(http.StatusNotFound)
+	want := map[string]string{"error": "path /nothing/here not found"}
+	got := map[string]string{}
+	e.expectUnmarshalWithoutError(&got)
+	e.expectEqual(want, got)
+}
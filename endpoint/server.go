@@ -0,0 +1,38 @@
+package endpoint
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MinTLSVersion is the oldest version of TLS accepted when serving HTTPS.
+const MinTLSVersion = tls.VersionTLS12
+
+// NewServer returns the server of the handler listening on the addr. It's set up to serve HTTPS with the certificate and its key if both files are given,
+// refusing the versions of TLS older than MinTLSVersion, and plain HTTP otherwise. Serve it with Serve.
+// The key pair is loaded here, so that an error is returned at startup if it can't be, instead of once the server is serving.
+func NewServer(addr string, handler http.Handler, certFile, keyFile string) (*http.Server, error) {
+	server := &http.Server{Addr: addr, Handler: handler}
+	if certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS certificate or key: %w", err)
+		}
+		server.TLSConfig = &tls.Config{MinVersion: MinTLSVersion, Certificates: []tls.Certificate{cert}}
+	}
+	return server, nil
+}
+
+// Serve serves HTTPS with the certificate loaded by NewServer if the server is set up so, and plain HTTP otherwise, until the server is shut down.
+func Serve(server *http.Server) error {
+	if server.TLSConfig != nil {
+		log.WithFields(log.Fields{"addr": server.Addr}).Info("Serving HTTPS")
+		// The certificate is already in the TLSConfig.
+		return server.ListenAndServeTLS("", "")
+	}
+	log.WithFields(log.Fields{"addr": server.Addr}).Info("Serving HTTP")
+	return server.ListenAndServe()
+}
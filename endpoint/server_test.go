@@ -0,0 +1,110 @@
+package endpoint_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"todolist/endpoint"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeKeyPair writes a self-signed certificate for localhost and its key to a temporary directory, and returns the paths of the files.
+func writeKeyPair(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// TestNewServerTLS Given both a certificate and its key, when NewServer is called, then the server is set up to serve HTTPS with the key pair and TLS 1.2 at least.
+func TestNewServerTLS(t *testing.T) {
+	// arrange
+	handler := http.NotFoundHandler()
+	certFile, keyFile := writeKeyPair(t)
+
+	// act
+	server, err := endpoint.NewServer(":8443", handler, certFile, keyFile)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, ":8443", server.Addr)
+		if assert.NotNil(t, server.TLSConfig) {
+			assert.Equal(t, uint16(tls.VersionTLS12), server.TLSConfig.MinVersion)
+			assert.Len(t, server.TLSConfig.Certificates, 1)
+		}
+	}
+}
+
+// TestNewServerInvalidKeyPair Given a certificate that doesn't exist or a key that doesn't match, when NewServer is called,
+// then an error is returned, so that the server fails at startup rather than once serving.
+func TestNewServerInvalidKeyPair(t *testing.T) {
+	// arrange
+	certFile, keyFile := writeKeyPair(t)
+	otherCertFile, _ := writeKeyPair(t)
+	tests := map[string][2]string{
+		"missing certificate": {filepath.Join(t.TempDir(), "missing.pem"), keyFile},
+		"mismatched key":      {otherCertFile, keyFile},
+		"swapped files":       {keyFile, certFile},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			_, err := endpoint.NewServer(":8443", http.NotFoundHandler(), files[0], files[1])
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestNewServerPlain Given no certificate nor key, when NewServer is called, then the server is set up to serve plain HTTP.
+func TestNewServerPlain(t *testing.T) {
+	// arrange
+	handler := http.NotFoundHandler()
+
+	// act
+	server, err := endpoint.NewServer(":8000", handler, "", "")
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Nil(t, server.TLSConfig)
+	}
+}
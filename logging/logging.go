@@ -0,0 +1,54 @@
+// Package logging sets up the logger of the application.
+package logging
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Configure sets the level and the format of the standard logger.
+//
+// The level is one of "debug", "info", "warn", or "error", and the format is either "text" or "json".
+// Empty values fall back to "info" and "text". The caller of each log entry is reported only at the debug level, as it's noisy otherwise.
+func Configure(level, format string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	formatter, err := parseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	log.SetLevel(lvl)
+	log.SetFormatter(formatter)
+	log.SetReportCaller(lvl == log.DebugLevel)
+	return nil
+}
+
+func parseLevel(level string) (log.Level, error) {
+	switch level {
+	case "debug":
+		return log.DebugLevel, nil
+	case "", "info":
+		return log.InfoLevel, nil
+	case "warn":
+		return log.WarnLevel, nil
+	case "error":
+		return log.ErrorLevel, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+func parseFormat(format string) (log.Formatter, error) {
+	switch format {
+	case "", "text":
+		return &log.TextFormatter{}, nil
+	case "json":
+		return &log.JSONFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
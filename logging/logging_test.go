@@ -0,0 +1,48 @@
+package logging_test
+
+import (
+	"testing"
+
+	"todolist/logging"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfigure Given a level and a format, when Configure is called, then the standard logger should be set up accordingly.
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name            string
+		level           string
+		format          string
+		wantLevel       log.Level
+		wantFormatter   log.Formatter
+		wantReportCalls bool
+	}{
+		{"defaults", "", "", log.InfoLevel, &log.TextFormatter{}, false},
+		{"debug text", "debug", "text", log.DebugLevel, &log.TextFormatter{}, true},
+		{"info json", "info", "json", log.InfoLevel, &log.JSONFormatter{}, false},
+		{"warn", "warn", "", log.WarnLevel, &log.TextFormatter{}, false},
+		{"error json", "error", "json", log.ErrorLevel, &log.JSONFormatter{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			err := logging.Configure(tt.level, tt.format)
+
+			// assert
+			if assert.NoError(t, err) {
+				logger := log.StandardLogger()
+				assert.Equal(t, tt.wantLevel, logger.GetLevel())
+				assert.IsType(t, tt.wantFormatter, logger.Formatter)
+				assert.Equal(t, tt.wantReportCalls, logger.ReportCaller)
+			}
+		})
+	}
+}
+
+// TestConfigureUnknown Given an unknown level or format, when Configure is called, then an error is returned.
+func TestConfigureUnknown(t *testing.T) {
+	assert.Error(t, logging.Configure("verbose", "text"))
+	assert.Error(t, logging.Configure("info", "xml"))
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"todolist/core"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AttachmentModel records the URL attached to the TodoItemModel with the ItemID.
+// NOTE: Like the BlockerModels, the records are kept when the TodoItemModel is deleted, since the deletion may be undone with Restore.
+type AttachmentModel struct {
+	ID        int         `gorm:"primaryKey"`
+	ItemID    core.ItemID `gorm:"index"`
+	URL       string
+	CreatedAt time.Time
+}
+
+func (dba *DatabaseAccessor) AddAttachment(id core.ItemID, url string, max int) error {
+	log.WithFields(log.Fields{"id": id, "url": url}).Info("DB: Adding AttachmentModel.")
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&TodoItemModel{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return core.TodoItemNotFoundError{ID: id}
+		}
+		var urls []string
+		if err := tx.Model(&AttachmentModel{}).Where("item_id = ?", id).Pluck("url", &urls).Error; err != nil {
+			return err
+		}
+		if slices.Contains(urls, url) {
+			return nil
+		}
+		if len(urls) >= max {
+			return core.ConflictError{Message: fmt.Sprintf("TodoItem with id %d already has %d attachments", id, len(urls))}
+		}
+		return tx.Create(&AttachmentModel{ItemID: id, URL: url}).Error
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	return nil
+}
+
+func (dba *DatabaseAccessor) RemoveAttachment(id core.ItemID, url string) error {
+	log.WithFields(log.Fields{"id": id, "url": url}).Info("DB: Removing AttachmentModel.")
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&TodoItemModel{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return core.TodoItemNotFoundError{ID: id}
+		}
+		return tx.Where("item_id = ? AND url = ?", id, url).Delete(&AttachmentModel{}).Error
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	return nil
+}
+
+func (dba *DatabaseAccessor) ReadAttachments(ids []core.ItemID) (map[core.ItemID][]string, error) {
+	log.WithFields(log.Fields{"ids": ids}).Info("DB: Reading AttachmentModels.")
+	attachments := map[core.ItemID][]string{}
+	if len(ids) == 0 {
+		return attachments, nil
+	}
+	var attachmentModels []AttachmentModel
+	if err := dba.conn().Where("item_id IN ?", ids).Order("id").Find(&attachmentModels).Error; err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+	for _, attachmentModel := range attachmentModels {
+		attachments[attachmentModel.ItemID] = append(attachments[attachmentModel.ItemID], attachmentModel.URL)
+	}
+	return attachments, nil
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"testing"
+
+	"todolist/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAddAttachment Given some todo items in the database, when AddAttachment is called with a URL attached twice and RemoveAttachment with one of the URLs,
+// then ReadAttachments should return the rest of the distinct URLs of each item in the order they were attached, leaving out the items without any.
+func TestAddAttachment(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	for i := 0; i < 3; i++ {
+		dba.Create(&core.TodoItem{Description: "Test description"})
+	}
+
+	// act
+	errs := []error{
+		dba.AddAttachment(1, "https://example.com/b", core.MaxAttachments),
+		dba.AddAttachment(1, "https://example.com/a", core.MaxAttachments),
+		dba.AddAttachment(1, "https://example.com/b", core.MaxAttachments),
+		dba.AddAttachment(2, "https://example.com/c", core.MaxAttachments),
+		dba.AddAttachment(2, "https://example.com/d", core.MaxAttachments),
+		dba.RemoveAttachment(2, "https://example.com/c"),
+	}
+
+	// assert
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	got, err := dba.ReadAttachments([]core.ItemID{1, 2, 3})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[core.ItemID][]string{
+			1: {"https://example.com/b", "https://example.com/a"},
+			2: {"https://example.com/d"},
+		}, got)
+	}
+}
+
+// TestAddAttachmentMax Given a todo item with the maximum number of attachments, when AddAttachment is called with a new URL and with an attached one,
+// then a ConflictError should be returned for the former and nothing for the latter.
+func TestAddAttachmentMax(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description"})
+	dba.AddAttachment(1, "https://example.com/a", 2)
+	dba.AddAttachment(1, "https://example.com/b", 2)
+
+	// act
+	errNew := dba.AddAttachment(1, "https://example.com/c", 2)
+	errAttached := dba.AddAttachment(1, "https://example.com/a", 2)
+
+	// assert
+	assert.ErrorAs(t, errNew, new(core.ConflictError))
+	assert.NoError(t, errAttached)
+	got, err := dba.ReadAttachments([]core.ItemID{1})
+	if assert.NoError(t, err) {
+		assert.Len(t, got[1], 2)
+	}
+}
+
+// TestAddAttachmentNotFound Given an empty database, when AddAttachment and RemoveAttachment are called, then a TodoItemNotFoundError should be returned by both.
+func TestAddAttachmentNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	errAdd := dba.AddAttachment(1, "https://example.com/a", core.MaxAttachments)
+	errRemove := dba.RemoveAttachment(1, "https://example.com/a")
+
+	// assert
+	assert.Equal(t, core.TodoItemNotFoundError{ID: 1}, errAdd)
+	assert.Equal(t, core.TodoItemNotFoundError{ID: 1}, errRemove)
+}
+
+// TestResetDeletesAttachments Given a todo item with an attachment, when Reset is called and the item is created again,
+// then the new item should have no attachment.
+func TestResetDeletesAttachments(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description"})
+	dba.AddAttachment(1, "https://example.com/a", core.MaxAttachments)
+
+	// act
+	err := dba.Reset()
+
+	// assert
+	assert.NoError(t, err)
+	dba.Create(&core.TodoItem{Description: "Test description"})
+	got, err := dba.ReadAttachments([]core.ItemID{1})
+	if assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
+
+// TestReplaceAllDeletesAttachments Given a todo item with an attachment, when ReplaceAll is called with an item of the same id,
+// then the item should have no attachment.
+func TestReplaceAllDeletesAttachments(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description"})
+	dba.AddAttachment(1, "https://example.com/a", core.MaxAttachments)
+
+	// act
+	err := dba.ReplaceAll([]core.TodoItem{{ID: 1, Description: "Replaced"}})
+
+	// assert
+	assert.NoError(t, err)
+	got, err := dba.ReadAttachments([]core.ItemID{1})
+	if assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	"todolist/core"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditLogModel is an entry of the audit log of the mutations of the TodoItemModels. The entries are only ever inserted.
+type AuditLogModel struct {
+	ID     int         `gorm:"primaryKey"`
+	ItemID core.ItemID `gorm:"index"`
+	Action string
+	Actor  string
+	// Diff is the JSON of core.AuditEntry.Diff.
+	Diff string
+	At   time.Time
+}
+
+func (m AuditLogModel) toAuditEntry() core.AuditEntry {
+	return core.AuditEntry{
+		ID:     m.ID,
+		ItemID: m.ItemID,
+		Action: core.AuditAction(m.Action),
+		Actor:  m.Actor,
+		Diff:   json.RawMessage(m.Diff),
+		At:     m.At,
+	}
+}
+
+func (dba *DatabaseAccessor) RecordAudit(entry core.AuditEntry) error {
+	log.WithFields(log.Fields{"item_id": entry.ItemID, "action": entry.Action, "actor": entry.Actor}).Info("DB: Adding AuditLogModel to database.")
+	auditModel := AuditLogModel{ItemID: entry.ItemID, Action: string(entry.Action), Actor: entry.Actor, Diff: string(entry.Diff), At: entry.At.UTC()}
+	if err := dba.conn().Create(&auditModel).Error; err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	return nil
+}
+
+func (dba *DatabaseAccessor) ReadAudit(itemID core.ItemID) ([]core.AuditEntry, error) {
+	log.WithFields(log.Fields{"item_id": itemID}).Info("DB: Reading AuditLogModels from database.")
+	db := dba.conn().Order("id")
+	if itemID != 0 {
+		db = db.Where("item_id = ?", itemID)
+	}
+	var auditModels []AuditLogModel
+	if err := db.Find(&auditModels).Error; err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+	entries := make([]core.AuditEntry, 0, len(auditModels))
+	for _, auditModel := range auditModels {
+		entries = append(entries, auditModel.toAuditEntry())
+	}
+	return entries, nil
+}
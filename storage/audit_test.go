@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"todolist/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fieldChange is a field of the diff of an audit entry.
+type fieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// TestAuditedMutations Given two todo items in the database, the first of which is incomplete and the second completed, when each mutation is made through a core acting as alice,
+// then exactly one audit entry should be recorded for each affected item, telling the action, the actor, and the old and new values of the changed field.
+func TestAuditedMutations(t *testing.T) {
+	tests := map[string]struct {
+		mutate func(c core.Core) error
+		itemID core.ItemID
+		action core.AuditAction
+		field  string
+		old    any
+		new    any
+	}{
+		"create": {
+			mutate: func(c core.Core) error { _, err := c.CreateItem("Buy milk", ""); return err },
+			itemID: 3, action: core.AuditCreate, field: "description", old: nil, new: "Buy milk",
+		},
+		"clone": {
+			mutate: func(c core.Core) error { _, err := c.CloneItem(1); return err },
+			itemID: 3, action: core.AuditCreate, field: "description", old: nil, new: "Test description 1" + core.CopySuffix,
+		},
+		"update": {
+			mutate: func(c core.Core) error { _, err := c.UpdateItem(1, true); return err },
+			itemID: 1, action: core.AuditUpdate, field: "completed", old: false, new: true,
+		},
+		"toggle": {
+			mutate: func(c core.Core) error { _, err := c.ToggleItem(2); return err },
+			itemID: 2, action: core.AuditUpdate, field: "completed", old: true, new: false,
+		},
+		"color": {
+			mutate: func(c core.Core) error { _, err := c.SetItemColor(1, "red"); return err },
+			itemID: 1, action: core.AuditUpdate, field: "color", old: "", new: "red",
+		},
+		"upsert": {
+			mutate: func(c core.Core) error {
+				_, _, err := c.UpsertItem(core.TodoItem{ID: 1, Description: "Renamed"})
+				return err
+			},
+			itemID: 1, action: core.AuditUpdate, field: "description", old: "Test description 1", new: "Renamed",
+		},
+		"set completed": {
+			mutate: func(c core.Core) error { _, err := c.SetItemsCompleted([]core.ItemID{1, 2}, true); return err },
+			itemID: 1, action: core.AuditUpdate, field: "completed", old: false, new: true,
+		},
+		"delete": {
+			mutate: func(c core.Core) error { return c.DeleteItem(2) },
+			itemID: 2, action: core.AuditDelete, field: "description", old: "Test description 2", new: nil,
+		},
+		"delete completed": {
+			mutate: func(c core.Core) error { _, err := c.DeleteCompleted(1); return err },
+			itemID: 2, action: core.AuditDelete, field: "completed", old: true, new: nil,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			dba := DatabaseAccessor{}
+			initTestDb(&dba)
+			defer closeTestDb(&dba)
+			dba.Create(&core.TodoItem{Description: "Test description 1"})
+			dba.Create(&core.TodoItem{Description: "Test description 2"})
+			dba.UpdateCompleted([]core.ItemID{2}, true, time.Now())
+			c := core.NewCore(&dba).WithActor("alice")
+
+			// act
+			err := tt.mutate(c)
+
+			// assert
+			if !assert.NoError(t, err) {
+				return
+			}
+			entries, err := dba.ReadAudit(0)
+			if assert.NoError(t, err) && assert.Len(t, entries, 1) {
+				entry := entries[0]
+				assert.Equal(t, tt.itemID, entry.ItemID)
+				assert.Equal(t, tt.action, entry.Action)
+				assert.Equal(t, "alice", entry.Actor)
+				assert.False(t, entry.At.IsZero())
+				var diff map[string]fieldChange
+				if assert.NoError(t, json.Unmarshal(entry.Diff, &diff)) {
+					assert.Equal(t, fieldChange{Old: tt.old, New: tt.new}, diff[tt.field])
+					assert.NotContains(t, diff, "updated_at")
+				}
+			}
+		})
+	}
+}
+
+// TestAuditedMutationFails Given a todo item in the database, when a mutation fails, then no audit entry should be recorded;
+// and when the audit log can't be written, then the mutation should be undone along with it.
+func TestAuditedMutationFails(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	c := core.NewCore(&dba)
+
+	// act
+	_, updateErr := c.UpdateItem(9, true)
+	dba.db.Migrator().DropTable(&AuditLogModel{})
+	_, createErr := c.CreateItem("Buy milk", "")
+
+	// assert
+	assert.ErrorAs(t, updateErr, new(core.TodoItemNotFoundError))
+	assert.Error(t, createErr)
+	todos, _ := dba.ReadAll()
+	assert.Equal(t, []core.ItemID{1}, idsOf(todos))
+}
+
+// TestReadAudit Given audit entries of two todo items, when ReadAudit is called with the id of one of them, then only its entries should be returned, oldest first.
+func TestReadAudit(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	for _, entry := range []core.AuditEntry{
+		{ItemID: 1, Action: core.AuditCreate, Diff: json.RawMessage(`{}`)},
+		{ItemID: 2, Action: core.AuditCreate, Diff: json.RawMessage(`{}`)},
+		{ItemID: 1, Action: core.AuditDelete, Diff: json.RawMessage(`{}`)},
+	} {
+		dba.RecordAudit(entry)
+	}
+
+	// act
+	got, err := dba.ReadAudit(1)
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 2) {
+		assert.Equal(t, core.AuditCreate, got[0].Action)
+		assert.Equal(t, core.AuditDelete, got[1].Action)
+	}
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"todolist/core"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlockerModel records that the TodoItemModel with the ItemID is blocked by the one with the BlockerID.
+// NOTE: The records are kept when either TodoItemModel is deleted, since the deletion is soft and may be undone with Restore;
+// the reads leave out the deleted TodoItemModels instead.
+type BlockerModel struct {
+	ItemID    core.ItemID `gorm:"primaryKey;autoIncrement:false"`
+	BlockerID core.ItemID `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
+
+func (dba *DatabaseAccessor) AddBlockers(id core.ItemID, blockerIDs []core.ItemID) error {
+	log.WithFields(log.Fields{"id": id, "blocker_ids": blockerIDs}).Info("DB: Adding BlockerModels.")
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		ids := append([]core.ItemID{id}, blockerIDs...)
+		var existing []core.ItemID
+		if err := tx.Model(&TodoItemModel{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if !slices.Contains(existing, id) {
+				return core.TodoItemNotFoundError{ID: id}
+			}
+		}
+		// All the new records start from the TodoItemModel, so a cycle is formed only if it already blocks, maybe indirectly, one of its new blockers.
+		for _, blockerID := range blockerIDs {
+			blocks, err := isBlockedBy(tx, blockerID, id)
+			if err != nil {
+				return err
+			}
+			if blocks {
+				return core.ConflictError{Message: fmt.Sprintf("TodoItem with id %d already blocks TodoItem with id %d, which would form a cycle", id, blockerID)}
+			}
+		}
+		blockers := make([]BlockerModel, 0, len(blockerIDs))
+		for _, blockerID := range blockerIDs {
+			blockers = append(blockers, BlockerModel{ItemID: id, BlockerID: blockerID})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&blockers).Error
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	return nil
+}
+
+// isBlockedBy tells whether the TodoItemModel with the id is blocked by the one with the blocker id, either directly or through other TodoItemModels.
+func isBlockedBy(db *gorm.DB, id, blockerID core.ItemID) (bool, error) {
+	visited := map[core.ItemID]bool{id: true}
+	frontier := []core.ItemID{id}
+	for len(frontier) > 0 {
+		var next []core.ItemID
+		if err := db.Model(&BlockerModel{}).Where("item_id IN ?", frontier).Pluck("blocker_id", &next).Error; err != nil {
+			return false, err
+		}
+		frontier = frontier[:0]
+		for _, id := range next {
+			if id == blockerID {
+				return true, nil
+			}
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return false, nil
+}
+
+func (dba *DatabaseAccessor) ReadBlockers(id core.ItemID) ([]core.TodoItem, error) {
+	log.WithFields(log.Fields{"id": id}).Info("DB: Reading blockers of TodoItemModel.")
+	db := dba.conn()
+	blockerIDs := db.Model(&BlockerModel{}).Select("blocker_id").Where("item_id = ?", id)
+	var todoModels []TodoItemModel
+	if err := db.Where("id IN (?)", blockerIDs).Order("id").Find(&todoModels).Error; err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+
+	var todoItems []core.TodoItem
+	for _, todoModel := range todoModels {
+		todoItems = append(todoItems, todoModel.toTodoItem())
+	}
+	return todoItems, nil
+}
+
+func (dba *DatabaseAccessor) ReadBlocked() ([]core.TodoItem, error) {
+	log.Info("DB: Reading blocked TodoItemModels.")
+	db := dba.conn()
+	blockedIDs := db.Model(&BlockerModel{}).
+		Select("blocker_models.item_id").
+		Joins("JOIN todo_item_models ON todo_item_models.id = blocker_models.blocker_id").
+		Where("todo_item_models.completed = ? AND todo_item_models.deleted_at IS NULL", false)
+	var todoModels []TodoItemModel
+	if err := db.Where("id IN (?)", blockedIDs).Order("id").Find(&todoModels).Error; err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+
+	var todoItems []core.TodoItem
+	for _, todoModel := range todoModels {
+		todoItems = append(todoItems, todoModel.toTodoItem())
+	}
+	return todoItems, nil
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"todolist/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockerIDs returns the ids of the todo items.
+func idsOf(todos []core.TodoItem) []core.ItemID {
+	var ids []core.ItemID
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	return ids
+}
+
+// TestAddBlockers Given some todo items in the database, when AddBlockers is called twice with overlapping blockers,
+// then all the distinct blockers should be recorded and read back by ReadBlockers in the order of their ids.
+func TestAddBlockers(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	for i := 0; i < 4; i++ {
+		dba.Create(&core.TodoItem{Description: "Test description"})
+	}
+
+	// act
+	err1 := dba.AddBlockers(1, []core.ItemID{3, 2})
+	err2 := dba.AddBlockers(1, []core.ItemID{2, 4})
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	got, err := dba.ReadBlockers(1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{2, 3, 4}, idsOf(got))
+	}
+}
+
+// TestAddBlockersNotFound Given some todo items in the database, when AddBlockers is called with a blocker that doesn't exist,
+// then a TodoItemNotFoundError should be returned and none of the blockers should be recorded.
+func TestAddBlockersNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	dba.Create(&core.TodoItem{Description: "Test description 2"})
+
+	// act
+	err := dba.AddBlockers(1, []core.ItemID{2, 3})
+
+	// assert
+	assert.Equal(t, core.TodoItemNotFoundError{ID: 3}, err)
+	var count int64
+	dba.db.Model(&BlockerModel{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+// TestAddBlockersCycle Given item 1 is blocked by item 2, which is blocked by item 3, when AddBlockers is called to block item 3 by item 1 or item 2 by item 1,
+// then a ConflictError should be returned for both, as they would form a cycle, and nothing should be recorded.
+func TestAddBlockersCycle(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	for i := 0; i < 3; i++ {
+		dba.Create(&core.TodoItem{Description: "Test description"})
+	}
+	dba.AddBlockers(1, []core.ItemID{2})
+	dba.AddBlockers(2, []core.ItemID{3})
+
+	// act
+	indirectErr := dba.AddBlockers(3, []core.ItemID{1})
+	directErr := dba.AddBlockers(2, []core.ItemID{1})
+
+	// assert
+	assert.ErrorAs(t, indirectErr, new(core.ConflictError))
+	assert.ErrorAs(t, directErr, new(core.ConflictError))
+	var count int64
+	dba.db.Model(&BlockerModel{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+// TestReadBlocked Given item 1 is blocked by an incomplete item, item 3 by a completed one, and item 5 by a deleted one, when ReadBlocked is called,
+// then only item 1 should be returned.
+func TestReadBlocked(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	for i := 0; i < 6; i++ {
+		dba.Create(&core.TodoItem{Description: "Test description"})
+	}
+	dba.UpdateCompleted([]core.ItemID{4}, true, time.Now())
+	dba.AddBlockers(1, []core.ItemID{2})
+	dba.AddBlockers(3, []core.ItemID{4})
+	dba.AddBlockers(5, []core.ItemID{6})
+	dba.Delete(6)
+
+	// act
+	got, err := dba.ReadBlocked()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{1}, idsOf(got))
+	}
+}
+
+// TestResetBlockers Given an item blocked by another, when Reset is called, then the blockers should be removed as well,
+// so that the items created afterwards with the same ids are not blocked.
+func TestResetBlockers(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	dba.Create(&core.TodoItem{Description: "Test description 2"})
+	dba.AddBlockers(1, []core.ItemID{2})
+
+	// act
+	err := dba.Reset()
+
+	// assert
+	if assert.NoError(t, err) {
+		dba.Create(&core.TodoItem{Description: "Test description 3"})
+		dba.Create(&core.TodoItem{Description: "Test description 4"})
+		got, err := dba.ReadBlocked()
+		if assert.NoError(t, err) {
+			assert.Empty(t, got)
+		}
+	}
+}
+
+// TestReplaceAllBlockers Given an item blocked by another, when ReplaceAll is called with incomplete items of the same ids,
+// then the blockers should be removed as well, so that the imported items are not blocked.
+func TestReplaceAllBlockers(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	dba.Create(&core.TodoItem{Description: "Test description 2"})
+	dba.AddBlockers(1, []core.ItemID{2})
+
+	// act
+	err := dba.ReplaceAll([]core.TodoItem{{ID: 1, Description: "Imported 1"}, {ID: 2, Description: "Imported 2"}})
+
+	// assert
+	if assert.NoError(t, err) {
+		got, err := dba.ReadBlocked()
+		if assert.NoError(t, err) {
+			assert.Empty(t, got)
+		}
+	}
+}
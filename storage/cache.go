@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"todolist/core"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CachedAccessor is a StorageAccessor that keeps the TodoItems read from the primary one in memory for a while,
+// so that reads keep being served, though maybe stale, when the primary one is unreachable.
+// Read and ReadAll are served from the cache, which is refreshed with ReadAll of the primary once it's older than the TTL;
+// if the refresh fails, the stale TodoItems are served instead, unless there's nothing cached yet.
+// The other reads go to the primary one as is, and every write expires the cache, so that the TodoItems written are read back;
+// the TodoItems cached before the write are still served if the primary one fails afterwards, though they miss the write.
+// NOTE: Only the StorageAccessor of the primary one is exposed, so the optional accessors, such as ListAccessor, are hidden.
+type CachedAccessor struct {
+	core.StorageAccessor
+	ttl time.Duration
+	// now tells the current time, which is replaced in tests.
+	now func() time.Time
+
+	mu        sync.Mutex
+	items     []core.TodoItem
+	fetchedAt time.Time
+	cached    bool
+	// expired tells that the cached TodoItems are older than a write, so they are only served if the primary one fails.
+	expired bool
+}
+
+// NewCachedAccessor returns a CachedAccessor in front of primary, whose TodoItems are read again after they're cached for ttl.
+func NewCachedAccessor(primary core.StorageAccessor, ttl time.Duration) *CachedAccessor {
+	return &CachedAccessor{StorageAccessor: primary, ttl: ttl, now: time.Now}
+}
+
+func (ca *CachedAccessor) Read(where func(core.TodoItem) bool) []core.TodoItem {
+	items, err := ca.ReadAll()
+	if err != nil {
+		return nil
+	}
+	var todoItems []core.TodoItem
+	for _, item := range items {
+		if where(item) {
+			todoItems = append(todoItems, item)
+		}
+	}
+	return todoItems
+}
+
+func (ca *CachedAccessor) ReadAll() ([]core.TodoItem, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if ca.cached && !ca.expired && ca.now().Sub(ca.fetchedAt) < ca.ttl {
+		return cloneItems(ca.items), nil
+	}
+	items, err := ca.StorageAccessor.ReadAll()
+	if err != nil {
+		if !ca.cached {
+			return nil, err
+		}
+		log.WithFields(log.Fields{"cached_at": ca.fetchedAt}).Warn("CACHE: Serving stale TodoItems as the primary storage failed: ", err)
+		return cloneItems(ca.items), nil
+	}
+	ca.items, ca.fetchedAt, ca.cached, ca.expired = items, ca.now(), true, false
+	return cloneItems(items), nil
+}
+
+// invalidate expires the cached TodoItems, so that the next read goes to the primary one. They are kept as the fallback if it fails.
+func (ca *CachedAccessor) invalidate() {
+	ca.mu.Lock()
+	ca.expired = true
+	ca.mu.Unlock()
+}
+
+// cloneItems returns a copy of items, so that the callers can't modify the cached ones.
+func cloneItems(items []core.TodoItem) []core.TodoItem {
+	if items == nil {
+		return nil
+	}
+	return append([]core.TodoItem(nil), items...)
+}
+
+func (ca *CachedAccessor) Create(todo *core.TodoItem) (core.ItemID, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Create(todo)
+}
+
+func (ca *CachedAccessor) CreateAll(todos []core.TodoItem) error {
+	defer ca.invalidate()
+	return ca.StorageAccessor.CreateAll(todos)
+}
+
+func (ca *CachedAccessor) CreateUnique(todo *core.TodoItem) (bool, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.CreateUnique(todo)
+}
+
+func (ca *CachedAccessor) Update(todo core.TodoItem) error {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Update(todo)
+}
+
+func (ca *CachedAccessor) UpdateWith(id core.ItemID, modify func(*core.TodoItem)) (core.TodoItem, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.UpdateWith(id, modify)
+}
+
+func (ca *CachedAccessor) Upsert(id core.ItemID, modify func(*core.TodoItem)) (core.TodoItem, bool, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Upsert(id, modify)
+}
+
+func (ca *CachedAccessor) UpdateCompleted(ids []core.ItemID, completed bool, at time.Time) (int, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.UpdateCompleted(ids, completed, at)
+}
+
+func (ca *CachedAccessor) Reorder(ids []core.ItemID) error {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Reorder(ids)
+}
+
+func (ca *CachedAccessor) ReplaceAll(todos []core.TodoItem) error {
+	defer ca.invalidate()
+	return ca.StorageAccessor.ReplaceAll(todos)
+}
+
+func (ca *CachedAccessor) Delete(id core.ItemID) error {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Delete(id)
+}
+
+func (ca *CachedAccessor) DeleteCompleted() ([]core.TodoItem, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.DeleteCompleted()
+}
+
+func (ca *CachedAccessor) DeleteCompletedBefore(before time.Time) (int, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.DeleteCompletedBefore(before)
+}
+
+func (ca *CachedAccessor) Restore(todos []core.TodoItem) ([]core.TodoItem, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Restore(todos)
+}
+
+func (ca *CachedAccessor) Reset() error {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Reset()
+}
+
+func (ca *CachedAccessor) Reindex() (core.ReindexReport, error) {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Reindex()
+}
+
+// Transaction calls fn with the StorageAccessor of the transaction of the primary one, which is not cached, and expires the cache once it's done.
+func (ca *CachedAccessor) Transaction(fn func(core.StorageAccessor) error) error {
+	defer ca.invalidate()
+	return ca.StorageAccessor.Transaction(fn)
+}
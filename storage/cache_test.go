@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"todolist/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyAccessor is a DatabaseAccessor whose ReadAll fails with err, if any, and counts how many times it's called.
+type flakyAccessor struct {
+	*DatabaseAccessor
+	err   error
+	reads int
+}
+
+func (fa *flakyAccessor) ReadAll() ([]core.TodoItem, error) {
+	fa.reads++
+	if fa.err != nil {
+		return nil, fa.err
+	}
+	return fa.DatabaseAccessor.ReadAll()
+}
+
+// newTestCache returns a CachedAccessor with a TTL of a minute in front of a flakyAccessor, along with the pointer to its current time.
+func newTestCache(dba *DatabaseAccessor) (*CachedAccessor, *flakyAccessor, *time.Time) {
+	primary := &flakyAccessor{DatabaseAccessor: dba}
+	cache := NewCachedAccessor(primary, time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	return cache, primary, &now
+}
+
+// TestCachedAccessorHit Given the todo items are read through the cache, when they are read again within the TTL,
+// then the cached items should be returned without reading the primary storage again.
+func TestCachedAccessorHit(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	dba.Create(&core.TodoItem{Description: "Test description 2"})
+	cache, primary, now := newTestCache(&dba)
+	cache.ReadAll()
+	dba.Delete(2)
+	*now = now.Add(59 * time.Second)
+
+	// act
+	got := cache.Read(func(core.TodoItem) bool { return true })
+
+	// assert
+	assert.Equal(t, []core.ItemID{1, 2}, idsOf(got))
+	assert.Equal(t, 1, primary.reads)
+}
+
+// TestCachedAccessorExpiry Given the todo items are read through the cache, when they are read again after the TTL,
+// then the primary storage should be read again and the changes made to it since be returned.
+func TestCachedAccessorExpiry(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	dba.Create(&core.TodoItem{Description: "Test description 2"})
+	cache, primary, now := newTestCache(&dba)
+	cache.ReadAll()
+	dba.Delete(2)
+	*now = now.Add(time.Minute)
+
+	// act
+	got, err := cache.ReadAll()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{1}, idsOf(got))
+	}
+	assert.Equal(t, 2, primary.reads)
+}
+
+// TestCachedAccessorStaleOnError Given the todo items are read through the cache, when the primary storage fails after the TTL,
+// then the stale items should be returned, while the error should be returned as is if nothing is cached yet.
+func TestCachedAccessorStaleOnError(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	cache, primary, now := newTestCache(&dba)
+	cold, coldPrimary, _ := newTestCache(&dba)
+	cache.ReadAll()
+	primaryErr := errors.New("connection refused")
+	primary.err = primaryErr
+	coldPrimary.err = primaryErr
+	*now = now.Add(time.Hour)
+
+	// act
+	got, err := cache.ReadAll()
+	_, coldErr := cold.ReadAll()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{1}, idsOf(got))
+	}
+	assert.Equal(t, primaryErr, coldErr)
+}
+
+// TestCachedAccessorWriteInvalidates Given the todo items are read through the cache, when an item is written through the cache,
+// then the next read should go to the primary storage, even within the TTL, and return the written item.
+func TestCachedAccessorWriteInvalidates(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	cache, primary, _ := newTestCache(&dba)
+	cache.ReadAll()
+
+	// act
+	_, err := cache.Create(&core.TodoItem{Description: "Test description 2"})
+
+	// assert
+	if assert.NoError(t, err) {
+		got, _ := cache.ReadAll()
+		assert.Equal(t, []core.ItemID{1, 2}, idsOf(got))
+		assert.Equal(t, 2, primary.reads)
+	}
+}
+
+// TestCachedAccessorStaleAfterWrite Given the todo items are read through the cache, when an item is written through the cache
+// and then the primary storage fails, then the items cached before the write should still be returned instead of the error.
+func TestCachedAccessorStaleAfterWrite(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	cache, primary, _ := newTestCache(&dba)
+	cache.ReadAll()
+	_, err := cache.Create(&core.TodoItem{Description: "Test description 2"})
+	assert.NoError(t, err)
+	primary.err = errors.New("connection refused")
+
+	// act
+	got, err := cache.ReadAll()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{1}, idsOf(got))
+	}
+	assert.Equal(t, 2, primary.reads)
+}
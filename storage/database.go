@@ -1,76 +1,526 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"todolist/core"
 
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DatabaseAccessor struct {
+	// mu guards db, which is replaced by Reconnect.
+	mu sync.RWMutex
 	db *gorm.DB
+	// dialect and config are the ones the database is initialized with, kept so that Reconnect can open it again.
+	dialect gorm.Dialector
+	config  gorm.Config
+	// queryTimeout is the time.Duration set by SetQueryTimeout.
+	queryTimeout atomic.Int64
+	// origin and ctx are set on the accessors returned by WithContext, which run their statements on the connection of origin with ctx.
+	origin *DatabaseAccessor
+	ctx    context.Context
 }
 
 type TodoItemModel struct {
-	ID          int `gorm:"primary_key"`
+	ID          core.ItemID `gorm:"primary_key"`
 	Description string
 	Completed   bool
+	Position    int
+	Color       string
+	ListID      int `gorm:"index"`
+	CompletedAt *time.Time
+	StartedAt   *time.Time
+	// TimeSpent is the TimeSpent of the TodoItem in whole seconds.
+	TimeSpent int64
+	CreatedAt time.Time
+	UpdatedAt time.Time `gorm:"index"`
+	// DeletedAt makes the deletion soft, i.e., the deleted items are kept as tombstones so that GetChangesSince can report them.
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	// DescriptionKey is the description trimmed and in lowercase, so that CreateUnique can look up the duplicates with the index.
+	// It's kept in sync with the description by BeforeSave.
+	DescriptionKey string `gorm:"index"`
 }
 
-// InitDb initializes the database connection and creates the TodoItemModel table.
-func (dba *DatabaseAccessor) InitDb(dialect gorm.Dialector, config *gorm.Config) {
-	var err error
-	dba.db, err = gorm.Open(dialect, config)
+// BeforeSave is a GORM hook that derives the description key from the description whenever the model is saved.
+func (m *TodoItemModel) BeforeSave(*gorm.DB) error {
+	m.DescriptionKey = descriptionKey(m.Description)
+	return nil
+}
+
+// descriptionKey normalizes the description so that the ones differing only in case or surrounding spaces are taken as the same.
+func descriptionKey(description string) string {
+	return strings.ToLower(strings.TrimSpace(description))
+}
+
+func (m TodoItemModel) toTodoItem() core.TodoItem {
+	return core.TodoItem{
+		ID:          m.ID,
+		Description: m.Description,
+		Completed:   m.Completed,
+		Position:    m.Position,
+		Color:       m.Color,
+		ListID:      m.ListID,
+		CompletedAt: m.CompletedAt,
+		StartedAt:   m.StartedAt,
+		TimeSpent:   core.Seconds(time.Duration(m.TimeSpent) * time.Second),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// escapeLike escapes the wildcard characters of LIKE in s, so that it's matched literally.
+// NOTE: The escape character is "!" instead of a backslash, which MySQL treats differently from the other databases in string literals.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// utc returns the time in UTC, or nil if t is nil.
+// NOTE: Times are stored in UTC so that they can be compared regardless of the time zone they were created in.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// seconds returns the duration in whole seconds, rounded, as stored in the database.
+func seconds(d time.Duration) int64 {
+	return int64(d.Round(time.Second) / time.Second)
+}
+
+// openDb opens the database connection. It's a variable so that the tests can simulate an unavailable database.
+var openDb = gorm.Open
+
+// InitDb initializes the database connection and migrates the database with Migrate.
+func (dba *DatabaseAccessor) InitDb(dialect gorm.Dialector, config *gorm.Config) error {
+	if config.NowFunc == nil {
+		// The timestamps GORM fills in are stored in UTC as well.
+		config.NowFunc = func() time.Time { return time.Now().UTC() }
+	}
+	// NOTE: A copy is kept since GORM fills the config it's opened with, e.g., with the connection pool.
+	dba.dialect, dba.config = dialect, *config
+	db, err := openDb(dialect, config)
+	if err != nil {
+		return err
+	}
+	err = dba.registerQueryTimeout(db)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	err = dba.db.Debug().AutoMigrate(&TodoItemModel{})
+	err = Migrate(db.Debug())
 	if err != nil {
-		panic(err)
+		return err
+	}
+	dba.mu.Lock()
+	dba.db = db
+	dba.mu.Unlock()
+	return nil
+}
+
+// Reconnect opens the database again with the settings of InitDb and replaces the connection with the new one, closing the old one.
+// It's meant for recovering from a dropped connection, e.g., after the database restarts; the database is migrated again in case it's a new one.
+// The old connection is kept if the database can't be opened.
+func (dba *DatabaseAccessor) Reconnect() error {
+	if dba.origin != nil {
+		return dba.origin.Reconnect()
+	}
+	log.Warn("DB: Reconnecting to database.")
+	if dba.dialect == nil {
+		return errors.New("database is not initialized")
+	}
+	config := dba.config
+	db, err := openDb(dba.dialect, &config)
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	err = dba.registerQueryTimeout(db)
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	err = Migrate(db)
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	dba.mu.Lock()
+	old := dba.db
+	dba.db = db
+	dba.mu.Unlock()
+	if old != nil {
+		if sqlDB, err := old.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	return nil
+}
+
+// conn returns the current connection to the database.
+func (dba *DatabaseAccessor) conn() *gorm.DB {
+	if dba.origin != nil {
+		return dba.origin.conn().WithContext(dba.ctx)
+	}
+	dba.mu.RLock()
+	defer dba.mu.RUnlock()
+	return dba.db
+}
+
+// InitDbWithRetry is InitDb but retries up to attempts times if the database is not reachable, e.g., when it's still starting up.
+// The wait before each retry starts at backoff and is doubled every time. The error of the last attempt is returned if all attempts fail,
+// or the error of the context if it's done before that.
+func (dba *DatabaseAccessor) InitDbWithRetry(ctx context.Context, dialect gorm.Dialector, config *gorm.Config, attempts int, backoff time.Duration) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = dba.InitDb(dialect, config)
+		if err == nil || attempt >= attempts {
+			return err
+		}
+		log.WithFields(log.Fields{"attempt": attempt, "backoff": backoff}).Warn("DB: Failed to initialize database, retrying. ", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
 	}
 }
 
 // CloseDb closes the database connection.
 func (dba *DatabaseAccessor) CloseDb() {
 	// NOTE: Starting from GORM v2, the db.Close() method is not available as it supports connection pooling.
+	dba.mu.Lock()
 	dba.db = nil
+	dba.mu.Unlock()
 }
 
-func (dba *DatabaseAccessor) Create(todo *core.TodoItem) (id int, e error) {
+func (dba *DatabaseAccessor) Create(todo *core.TodoItem) (id core.ItemID, e error) {
 	log.WithFields(log.Fields{"description": todo.Description}).Info("DB: Adding new TodoItemModel to database.")
 
-	result := dba.db.Create(&TodoItemModel{Description: todo.Description, Completed: false})
+	// The id is left to the database unless the core assigns one.
+	todoModel := TodoItemModel{ID: todo.ID, Description: todo.Description, Completed: false, Color: todo.Color, ListID: todo.ListID}
+	result := dba.conn().Create(&todoModel)
 	if result.Error != nil {
 		log.Warn("DB: ", result.Error)
 		return 0, result.Error
 	}
 
-	// We access it from the database to get the Id.
-	var todoModel TodoItemModel
-	dba.db.Last(&todoModel)
+	// The id and the timestamps are filled in by the database.
 	todo.ID = todoModel.ID
+	todo.CreatedAt = todoModel.CreatedAt
+	todo.UpdatedAt = todoModel.UpdatedAt
 	return todoModel.ID, nil
 }
 
+func (dba *DatabaseAccessor) CreateAll(todos []core.TodoItem) error {
+	log.WithFields(log.Fields{"count": len(todos)}).Info("DB: Adding new TodoItemModels to database.")
+	if len(todos) == 0 {
+		return nil
+	}
+	todoModels := make([]TodoItemModel, 0, len(todos))
+	for _, todo := range todos {
+		todoModels = append(todoModels, TodoItemModel{
+			ID:          todo.ID,
+			Description: todo.Description,
+			Completed:   todo.Completed,
+			Color:       todo.Color,
+			ListID:      todo.ListID,
+			CompletedAt: utc(todo.CompletedAt),
+			StartedAt:   utc(todo.StartedAt),
+			TimeSpent:   seconds(time.Duration(todo.TimeSpent)),
+		})
+	}
+	// NOTE: The TodoItemModels are inserted by a single statement, which is atomic by itself.
+	if err := dba.conn().Create(&todoModels).Error; err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	for i, todoModel := range todoModels {
+		todos[i].ID = todoModel.ID
+		todos[i].CreatedAt = todoModel.CreatedAt
+		todos[i].UpdatedAt = todoModel.UpdatedAt
+	}
+	return nil
+}
+
+func (dba *DatabaseAccessor) CreateUnique(todo *core.TodoItem) (created bool, e error) {
+	log.WithFields(log.Fields{"description": todo.Description}).Info("DB: Adding new TodoItemModel to database unless a duplicate exists.")
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		// NOTE: The index on description_key is not unique, since the duplicates are allowed among the completed TodoItems and when CreateUnique is not used;
+		// a transaction that doesn't see the TodoItem created by a concurrent one, not committed yet, creates a duplicate.
+		var existing TodoItemModel
+		result := tx.Where("description_key = ? AND completed = ?", descriptionKey(todo.Description), false).Order("id").Limit(1).Find(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			*todo = existing.toTodoItem()
+			return nil
+		}
+
+		todoModel := TodoItemModel{ID: todo.ID, Description: todo.Description, Completed: false, Color: todo.Color, ListID: todo.ListID}
+		if err := tx.Create(&todoModel).Error; err != nil {
+			return err
+		}
+		todo.ID = todoModel.ID
+		todo.CreatedAt = todoModel.CreatedAt
+		todo.UpdatedAt = todoModel.UpdatedAt
+		created = true
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return false, err
+	}
+	return created, nil
+}
+
 func (dba *DatabaseAccessor) Read(where func(core.TodoItem) bool) []core.TodoItem {
 	log.Info("DB: Reading all TodoItemModels from database.")
 	// TODO: Reading all items may not be efficient.
 	var todoModels []TodoItemModel
-	dba.db.Find(&todoModels)
+	dba.conn().Order("id").Find(&todoModels)
 
 	log.Info("DB: Filtering TodoItemModels.")
 	var todoItems []core.TodoItem
 	for _, todoModel := range todoModels {
-		if item := (core.TodoItem{ID: todoModel.ID, Description: todoModel.Description, Completed: todoModel.Completed}); where(item) {
+		if item := todoModel.toTodoItem(); where(item) {
 			todoItems = append(todoItems, item)
 		}
 	}
 	return todoItems
 }
 
+func (dba *DatabaseAccessor) ReadAll() ([]core.TodoItem, error) {
+	log.Info("DB: Reading all TodoItemModels from database.")
+	var todoModels []TodoItemModel
+	result := dba.conn().Order("id").Find(&todoModels)
+	if result.Error != nil {
+		log.Warn("DB: ", result.Error)
+		return nil, result.Error
+	}
+
+	var todoItems []core.TodoItem
+	for _, todoModel := range todoModels {
+		todoItems = append(todoItems, todoModel.toTodoItem())
+	}
+	return todoItems, nil
+}
+
+func (dba *DatabaseAccessor) ReadStream(where func(core.TodoItem) bool, fn func(core.TodoItem) error) error {
+	log.Info("DB: Streaming TodoItemModels from database.")
+	db := dba.conn()
+	rows, err := db.Model(&TodoItemModel{}).Order("id").Rows()
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var todoModel TodoItemModel
+		if err := db.ScanRows(rows, &todoModel); err != nil {
+			log.Warn("DB: ", err)
+			return err
+		}
+		if item := todoModel.toTodoItem(); where(item) {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+	}
+	return rows.Err()
+}
+
+func (dba *DatabaseAccessor) Exists(id core.ItemID) (bool, error) {
+	log.WithFields(log.Fields{"id": id}).Info("DB: Checking existence of TodoItemModel in database.")
+	var found []int
+	result := dba.conn().Model(&TodoItemModel{}).Select("1").Where("id = ?", id).Limit(1).Find(&found)
+	if result.Error != nil {
+		log.Warn("DB: ", result.Error)
+		return false, result.Error
+	}
+	return len(found) > 0, nil
+}
+
+func (dba *DatabaseAccessor) ReadByIDs(ids []core.ItemID) []core.TodoItem {
+	log.WithFields(log.Fields{"ids": ids}).Info("DB: Reading TodoItemModels by ids from database.")
+	var todoModels []TodoItemModel
+	dba.conn().Where("id IN ?", ids).Order("id").Find(&todoModels)
+
+	var todoItems []core.TodoItem
+	for _, todoModel := range todoModels {
+		todoItems = append(todoItems, todoModel.toTodoItem())
+	}
+	return todoItems
+}
+
+func (dba *DatabaseAccessor) ReadFiltered(f core.ItemFilter) ([]core.TodoItem, error) {
+	log.WithFields(log.Fields{"completed": f.Completed, "created_after": f.CreatedAfter, "created_before": f.CreatedBefore, "list_id": f.ListID, "status": f.Status}).Info("DB: Reading filtered TodoItemModels from database.")
+	todos, _, err := dba.readQuery(core.Query{Filter: f}, false)
+	return todos, err
+}
+
+func (dba *DatabaseAccessor) ReadCompletedBetween(start, end time.Time) []core.TodoItem {
+	log.WithFields(log.Fields{"start": start, "end": end}).Info("DB: Reading TodoItemModels completed between from database.")
+	var todoModels []TodoItemModel
+	dba.conn().Where("completed_at >= ? AND completed_at < ?", start.UTC(), end.UTC()).Order("id").Find(&todoModels)
+
+	var todoItems []core.TodoItem
+	for _, todoModel := range todoModels {
+		todoItems = append(todoItems, todoModel.toTodoItem())
+	}
+	return todoItems
+}
+
+func (dba *DatabaseAccessor) ReadChangedSince(since time.Time) (changed []core.TodoItem, deletedIDs []core.ItemID, e error) {
+	log.WithFields(log.Fields{"since": since}).Info("DB: Reading TodoItemModels changed since from database.")
+	var todoModels []TodoItemModel
+	result := dba.conn().Where("updated_at > ?", since.UTC()).Order("id").Find(&todoModels)
+	if result.Error != nil {
+		log.Warn("DB: ", result.Error)
+		return nil, nil, result.Error
+	}
+	for _, todoModel := range todoModels {
+		changed = append(changed, todoModel.toTodoItem())
+	}
+
+	result = dba.conn().Unscoped().Model(&TodoItemModel{}).Where("deleted_at > ?", since.UTC()).Order("id").Pluck("id", &deletedIDs)
+	if result.Error != nil {
+		log.Warn("DB: ", result.Error)
+		return nil, nil, result.Error
+	}
+	return changed, deletedIDs, nil
+}
+
+func (dba *DatabaseAccessor) ReadAfter(cursor core.ItemID, limit int) []core.TodoItem {
+	log.WithFields(log.Fields{"cursor": cursor, "limit": limit}).Info("DB: Reading TodoItemModels after cursor from database.")
+	todos, _, _ := dba.readQuery(core.Query{After: cursor, Limit: limit}, false)
+	return todos
+}
+
+func (dba *DatabaseAccessor) ReadPage(offset, limit int) (todos []core.TodoItem, total int, e error) {
+	log.WithFields(log.Fields{"offset": offset, "limit": limit}).Info("DB: Reading a page of TodoItemModels from database.")
+	return dba.readQuery(core.Query{Offset: offset, Limit: limit}, true)
+}
+
+func (dba *DatabaseAccessor) ReadQuery(q core.Query) (todos []core.TodoItem, total int, e error) {
+	log.WithFields(log.Fields{"filter": q.Filter, "sort": q.Sort, "descending": q.Descending, "offset": q.Offset, "after": q.After, "limit": q.Limit}).Info("DB: Querying TodoItemModels from database.")
+	return dba.readQuery(q, true)
+}
+
+// readQuery builds and runs the statements of the query, which all the reads with criteria, an order, or a window go through.
+// The total is only counted if withTotal is true; it's 0 otherwise.
+func (dba *DatabaseAccessor) readQuery(q core.Query, withTotal bool) (todos []core.TodoItem, total int, e error) {
+	var todoModels []TodoItemModel
+	var count int64
+	read := func(tx *gorm.DB) error {
+		if withTotal {
+			if err := filtered(tx, q.Filter).Count(&count).Error; err != nil {
+				return err
+			}
+		}
+		window := filtered(tx, q.Filter)
+		if q.After > 0 {
+			window = window.Where("id > ?", q.After)
+		}
+		columns := []string{"id"}
+		if q.Sort == core.SortByPosition {
+			columns = []string{"position", "id"}
+		}
+		for _, column := range columns {
+			window = window.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: q.Descending})
+		}
+		if q.Offset > 0 {
+			window = window.Offset(q.Offset)
+		}
+		if q.Limit > 0 {
+			window = window.Limit(q.Limit)
+		}
+		return window.Find(&todoModels).Error
+	}
+	var err error
+	if withTotal {
+		// NOTE: The count and the window are read in the same transaction, so that they are consistent with each other.
+		err = dba.conn().Transaction(read)
+	} else {
+		err = read(dba.conn())
+	}
+	if err != nil {
+		log.Warn("DB: ", err)
+		return nil, 0, err
+	}
+
+	for _, todoModel := range todoModels {
+		todos = append(todos, todoModel.toTodoItem())
+	}
+	return todos, int(count), nil
+}
+
+// filtered returns the statement of the TodoItemModels that meet all the criteria of the filter.
+func filtered(db *gorm.DB, f core.ItemFilter) *gorm.DB {
+	query := db.Model(&TodoItemModel{})
+	if f.Completed != nil {
+		query = query.Where("completed = ?", *f.Completed)
+	}
+	if f.CreatedAfter != nil {
+		query = query.Where("created_at > ?", f.CreatedAfter.UTC())
+	}
+	if f.CreatedBefore != nil {
+		query = query.Where("created_at <= ?", f.CreatedBefore.UTC())
+	}
+	if f.ListID != nil {
+		query = query.Where("list_id = ?", *f.ListID)
+	}
+	if f.Status != nil {
+		switch *f.Status {
+		case core.StatusTodo:
+			query = query.Where("completed = ? AND started_at IS NULL", false)
+		case core.StatusInProgress:
+			query = query.Where("completed = ? AND started_at IS NOT NULL", false)
+		case core.StatusCompleted:
+			query = query.Where("completed = ?", true)
+		}
+	}
+	return query
+}
+
+func (dba *DatabaseAccessor) ReadDescriptionsWithPrefix(prefix string, limit int) []string {
+	log.WithFields(log.Fields{"prefix": prefix, "limit": limit}).Info("DB: Reading descriptions with prefix from database.")
+	var descriptions []string
+	// NOTE: The auto-incremented id tells which item is created more recently.
+	dba.conn().Model(&TodoItemModel{}).
+		Where("LOWER(description) LIKE ? ESCAPE '!'", escapeLike(strings.ToLower(prefix))+"%").
+		Group("description").
+		Order("MAX(id) DESC").
+		Limit(limit).
+		Pluck("description", &descriptions)
+	return descriptions
+}
+
+func (dba *DatabaseAccessor) Count(f core.ItemFilter) (int, error) {
+	log.Info("DB: Counting TodoItemModels in database.")
+	var count int64
+	if err := filtered(dba.conn(), f).Count(&count).Error; err != nil {
+		log.Warn("DB: ", err)
+		return 0, err
+	}
+	return int(count), nil
+}
+
 func (dba *DatabaseAccessor) Update(todo core.TodoItem) error {
 	var todoModel TodoItemModel
-	result := dba.db.First(&todoModel, todo.ID)
+	result := dba.conn().First(&todoModel, todo.ID)
 	if result.Error != nil {
 		log.Warn("DB: ", result.Error)
 		return result.Error
@@ -79,19 +529,437 @@ func (dba *DatabaseAccessor) Update(todo core.TodoItem) error {
 	log.WithFields(log.Fields{"id": todo.ID}).Info("DB: Updating TodoItemModel.")
 	todoModel.Description = todo.Description
 	todoModel.Completed = todo.Completed
-	dba.db.Save(&todoModel)
+	todoModel.Position = todo.Position
+	todoModel.Color = todo.Color
+	todoModel.ListID = todo.ListID
+	todoModel.CompletedAt = utc(todo.CompletedAt)
+	todoModel.StartedAt = utc(todo.StartedAt)
+	todoModel.TimeSpent = seconds(time.Duration(todo.TimeSpent))
+	dba.conn().Save(&todoModel)
+	return nil
+}
+
+func (dba *DatabaseAccessor) UpdateWith(id core.ItemID, modify func(*core.TodoItem)) (core.TodoItem, error) {
+	log.WithFields(log.Fields{"id": id}).Info("DB: Updating TodoItemModel in a transaction.")
+	var todo core.TodoItem
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		var todoModel TodoItemModel
+		// NOTE: The row is locked until the transaction ends, so that concurrent updates are serialized.
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&todoModel, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return core.TodoItemNotFoundError{ID: id}
+		}
+
+		todo = todoModel.toTodoItem()
+		modify(&todo)
+		todoModel.Description = todo.Description
+		todoModel.Completed = todo.Completed
+		todoModel.Position = todo.Position
+		todoModel.Color = todo.Color
+		todoModel.ListID = todo.ListID
+		todoModel.CompletedAt = utc(todo.CompletedAt)
+		todoModel.StartedAt = utc(todo.StartedAt)
+		todoModel.TimeSpent = seconds(time.Duration(todo.TimeSpent))
+		if err := tx.Save(&todoModel).Error; err != nil {
+			return err
+		}
+		// The update time is bumped on save.
+		todo.UpdatedAt = todoModel.UpdatedAt
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return core.TodoItem{}, err
+	}
+	return todo, nil
+}
+
+func (dba *DatabaseAccessor) Upsert(id core.ItemID, modify func(*core.TodoItem)) (todo core.TodoItem, created bool, e error) {
+	log.WithFields(log.Fields{"id": id}).Info("DB: Upserting TodoItemModel in a transaction.")
+	todo, created, err := dba.upsert(id, modify)
+	// NOTE: Locking a row that doesn't exist yet doesn't keep a concurrent Upsert of the same id from creating it first, in which case the insert fails
+	// with a duplicate key, whose error differs between the drivers. So a failed creation is retried once, as an update, if the row exists by now.
+	if err != nil && created && id != 0 {
+		var count int64
+		if dba.conn().Unscoped().Model(&TodoItemModel{}).Where("id = ?", id).Count(&count).Error == nil && count > 0 {
+			log.WithFields(log.Fields{"id": id}).Info("DB: Retrying the upsert of TodoItemModel created concurrently.")
+			todo, created, err = dba.upsert(id, modify)
+		}
+	}
+	if err != nil {
+		log.Warn("DB: ", err)
+		return core.TodoItem{}, false, err
+	}
+	return todo, created, nil
+}
+
+// upsert is a single attempt of Upsert. Whether it tried to create the TodoItemModel is returned even if it fails.
+func (dba *DatabaseAccessor) upsert(id core.ItemID, modify func(*core.TodoItem)) (todo core.TodoItem, created bool, e error) {
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		var todoModel TodoItemModel
+		if id != 0 {
+			// NOTE: The deleted rows are looked up as well, since their ids can't be reused.
+			result := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&todoModel, id)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 && todoModel.DeletedAt.Valid {
+				return core.ConflictError{Message: fmt.Sprintf("TodoItem with id %d is deleted", id)}
+			}
+			created = result.RowsAffected == 0
+		} else {
+			created = true
+		}
+
+		if created {
+			todo = core.TodoItem{ID: id}
+		} else {
+			todo = todoModel.toTodoItem()
+		}
+		modify(&todo)
+		todoModel.ID = id
+		todoModel.Description = todo.Description
+		todoModel.Completed = todo.Completed
+		todoModel.Position = todo.Position
+		todoModel.Color = todo.Color
+		todoModel.ListID = todo.ListID
+		todoModel.CompletedAt = utc(todo.CompletedAt)
+		todoModel.StartedAt = utc(todo.StartedAt)
+		todoModel.TimeSpent = seconds(time.Duration(todo.TimeSpent))
+		save := tx.Save
+		if created {
+			save = tx.Create
+		}
+		if err := save(&todoModel).Error; err != nil {
+			return err
+		}
+		todo.ID = todoModel.ID
+		todo.CreatedAt = todoModel.CreatedAt
+		todo.UpdatedAt = todoModel.UpdatedAt
+		return nil
+	})
+	return todo, created, err
+}
+
+func (dba *DatabaseAccessor) UpdateCompleted(ids []core.ItemID, completed bool, at time.Time) (int, error) {
+	log.WithFields(log.Fields{"ids": ids, "completed": completed}).Info("DB: Updating completed status of TodoItemModels.")
+	var completedAt *time.Time
+	if completed {
+		completedAt = utc(&at)
+	}
+	var n int64
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		if !completed {
+			// The started items that are reopened resume the work, as in core.setCompleted, which is computed in Go for it's not portable in SQL.
+			var startedModels []TodoItemModel
+			if err := tx.Where("id IN ? AND completed = ? AND started_at IS NOT NULL", ids, true).Find(&startedModels).Error; err != nil {
+				return err
+			}
+			for _, todoModel := range startedModels {
+				spent := todoModel.toTodoItem().TimeSpentAt(at)
+				err := tx.Model(&TodoItemModel{ID: todoModel.ID}).Updates(map[string]any{"time_spent": seconds(spent), "started_at": utc(&at)}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// NOTE: Only the items whose status changes are updated, so that the completion time of the already completed items is kept.
+		result := tx.Model(&TodoItemModel{}).
+			Where("id IN ? AND completed <> ?", ids, completed).
+			Updates(map[string]any{"completed": completed, "completed_at": completedAt})
+		n = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (dba *DatabaseAccessor) Reorder(ids []core.ItemID) error {
+	log.WithFields(log.Fields{"ids": ids}).Info("DB: Reordering TodoItemModels.")
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		// NOTE: The ids are checked up front rather than by the affected rows of the updates, since MySQL doesn't count a row whose position is unchanged.
+		var existing []core.ItemID
+		if err := tx.Model(&TodoItemModel{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if !slices.Contains(existing, id) {
+				return core.TodoItemNotFoundError{ID: id}
+			}
+		}
+		for position, id := range ids {
+			if err := tx.Model(&TodoItemModel{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	return nil
+}
+
+func (dba *DatabaseAccessor) ReplaceAll(todos []core.TodoItem) error {
+	log.WithFields(log.Fields{"count": len(todos)}).Info("DB: Replacing all TodoItemModels.")
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		// NOTE: Unscoped, so that the tombstones are deleted as well; they may collide with the imported ids otherwise.
+		if err := tx.Unscoped().Where("1 = 1").Delete(&TodoItemModel{}).Error; err != nil {
+			return err
+		}
+		// The blockers and the attachments of the replaced TodoItemModels would be taken as the ones of the imported TodoItemModels with the same ids otherwise.
+		if err := tx.Where("1 = 1").Delete(&BlockerModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&AttachmentModel{}).Error; err != nil {
+			return err
+		}
+		if len(todos) == 0 {
+			return nil
+		}
+		todoModels := make([]TodoItemModel, 0, len(todos))
+		for _, todo := range todos {
+			// The timestamps are kept as they are, since GORM only fills in the zero ones.
+			todoModels = append(todoModels, TodoItemModel{
+				ID:          todo.ID,
+				Description: todo.Description,
+				Completed:   todo.Completed,
+				Position:    todo.Position,
+				Color:       todo.Color,
+				ListID:      todo.ListID,
+				CompletedAt: utc(todo.CompletedAt),
+				StartedAt:   utc(todo.StartedAt),
+				TimeSpent:   seconds(time.Duration(todo.TimeSpent)),
+				CreatedAt:   todo.CreatedAt.UTC(),
+				UpdatedAt:   todo.UpdatedAt.UTC(),
+			})
+		}
+		if err := tx.Create(&todoModels).Error; err != nil {
+			return err
+		}
+		if tx.Dialector.Name() == "postgres" {
+			// NOTE: PostgreSQL doesn't advance the sequence of the ids on explicit inserts, so the next created item would collide.
+			return tx.Exec("SELECT setval(pg_get_serial_sequence('todo_item_models', 'id'), (SELECT MAX(id) FROM todo_item_models))").Error
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	return nil
+}
+
+func (dba *DatabaseAccessor) Transaction(fn func(core.StorageAccessor) error) error {
+	log.Info("DB: Beginning a transaction.")
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		// NOTE: The transactions of the methods called on the accessor of the transaction become nested ones, i.e., savepoints.
+		return fn(&DatabaseAccessor{db: tx})
+	})
+	if err != nil {
+		log.Warn("DB: Transaction rolled back. ", err)
+		return err
+	}
+	return nil
+}
+
+func (dba *DatabaseAccessor) Reset() error {
+	log.Warn("DB: Resetting TodoItemModels.")
+	db := dba.conn()
+	var err error
+	switch db.Dialector.Name() {
+	case "mysql":
+		// NOTE: TRUNCATE commits implicitly on MySQL, so it can't be part of a transaction anyway.
+		err = db.Exec("TRUNCATE TABLE todo_item_models").Error
+		if err == nil {
+			err = db.Exec("TRUNCATE TABLE blocker_models").Error
+		}
+		if err == nil {
+			err = db.Exec("TRUNCATE TABLE attachment_models").Error
+		}
+	case "postgres":
+		err = db.Exec("TRUNCATE TABLE todo_item_models, blocker_models, attachment_models RESTART IDENTITY").Error
+	default:
+		// SQLite has no TRUNCATE; the ids start over once the table is dropped from the autoincrement counters.
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Where("1 = 1").Delete(&TodoItemModel{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("1 = 1").Delete(&BlockerModel{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("1 = 1").Delete(&AttachmentModel{}).Error; err != nil {
+				return err
+			}
+			return tx.Exec("DELETE FROM sqlite_sequence WHERE name = ?", "todo_item_models").Error
+		})
+	}
+	if err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
 	return nil
 }
 
-func (dba *DatabaseAccessor) Delete(id int) error {
+func (dba *DatabaseAccessor) Reindex() (core.ReindexReport, error) {
+	log.Warn("DB: Reindexing TodoItemModels.")
+	var report core.ReindexReport
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		// NOTE: The keys are recomputed in Go, as LOWER and TRIM of the databases don't agree with descriptionKey on non-ASCII text.
+		var todoModels []TodoItemModel
+		if err := tx.Unscoped().Select("id", "description", "description_key").Order("id").Find(&todoModels).Error; err != nil {
+			return err
+		}
+		for _, todoModel := range todoModels {
+			key := descriptionKey(todoModel.Description)
+			if key == todoModel.DescriptionKey {
+				continue
+			}
+			// UpdateColumn skips the hooks and leaves the update time alone, as the TodoItem is not changed.
+			if err := tx.Unscoped().Model(&TodoItemModel{ID: todoModel.ID}).UpdateColumn("description_key", key).Error; err != nil {
+				return err
+			}
+			report.DescriptionKeys++
+		}
+
+		completed := tx.Unscoped().Model(&TodoItemModel{}).Where("completed = ? AND completed_at IS NULL", true).UpdateColumn("completed_at", gorm.Expr("updated_at"))
+		if completed.Error != nil {
+			return completed.Error
+		}
+		incomplete := tx.Unscoped().Model(&TodoItemModel{}).Where("completed = ? AND completed_at IS NOT NULL", false).UpdateColumn("completed_at", nil)
+		if incomplete.Error != nil {
+			return incomplete.Error
+		}
+		report.CompletedAt = int(completed.RowsAffected + incomplete.RowsAffected)
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return core.ReindexReport{}, err
+	}
+	return report, nil
+}
+
+func (dba *DatabaseAccessor) Stats() (core.BackendStats, error) {
+	db := dba.conn()
+	var count int64
+	if err := db.Model(&TodoItemModel{}).Count(&count).Error; err != nil {
+		log.Warn("DB: ", err)
+		return core.BackendStats{}, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Warn("DB: ", err)
+		return core.BackendStats{}, err
+	}
+	return core.BackendStats{
+		Driver:          db.Dialector.Name(),
+		Items:           int(count),
+		OpenConnections: sqlDB.Stats().OpenConnections,
+	}, nil
+}
+
+func (dba *DatabaseAccessor) Delete(id core.ItemID) error {
 	var todoModel TodoItemModel
-	result := dba.db.First(&todoModel, id)
+	result := dba.conn().Limit(1).Find(&todoModel, id)
 	if result.Error != nil {
 		log.Warn("DB: ", result.Error)
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		err := core.TodoItemNotFoundError{ID: id}
+		log.Warn("DB: ", err)
+		return err
+	}
 
 	log.WithFields(log.Fields{"id": id}).Info("DB: Deleting TodoItemModel.")
-	dba.db.Delete(&todoModel)
+	result = dba.conn().Delete(&todoModel)
+	if result.Error != nil {
+		log.Warn("DB: ", result.Error)
+		return result.Error
+	}
 	return nil
 }
+
+func (dba *DatabaseAccessor) DeleteCompleted() ([]core.TodoItem, error) {
+	log.Info("DB: Deleting completed TodoItemModels.")
+	var todoModels []TodoItemModel
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("completed = ?", true).Order("id").Find(&todoModels).Error; err != nil {
+			return err
+		}
+		if len(todoModels) == 0 {
+			return nil
+		}
+		return tx.Delete(&todoModels).Error
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+
+	var todoItems []core.TodoItem
+	for _, todoModel := range todoModels {
+		todoItems = append(todoItems, todoModel.toTodoItem())
+	}
+	return todoItems, nil
+}
+
+func (dba *DatabaseAccessor) DeleteCompletedBefore(before time.Time) (int, error) {
+	log.WithFields(log.Fields{"before": before}).Info("DB: Deleting TodoItemModels completed before.")
+	// NOTE: The deletion is soft, so that GetChangesSince reports the pruned items as well.
+	result := dba.conn().Where("completed = ? AND completed_at < ?", true, before.UTC()).Delete(&TodoItemModel{})
+	if result.Error != nil {
+		log.Warn("DB: ", result.Error)
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (dba *DatabaseAccessor) Restore(todos []core.TodoItem) ([]core.TodoItem, error) {
+	log.WithFields(log.Fields{"count": len(todos)}).Info("DB: Restoring TodoItemModels.")
+	var restored []core.TodoItem
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		for _, todo := range todos {
+			var todoModel TodoItemModel
+			// NOTE: Unscoped, so that the deleted items kept as tombstones are found as well.
+			result := tx.Unscoped().Limit(1).Find(&todoModel, todo.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 && !todoModel.DeletedAt.Valid {
+				return core.ConflictError{Message: fmt.Sprintf("TodoItem with id %d is not deleted", todo.ID)}
+			}
+			if result.RowsAffected == 0 {
+				// The item is gone for good, so it's re-created with a new id.
+				todoModel = TodoItemModel{CreatedAt: todo.CreatedAt.UTC()}
+			}
+			todoModel.Description = todo.Description
+			todoModel.Completed = todo.Completed
+			todoModel.Position = todo.Position
+			todoModel.Color = todo.Color
+			todoModel.ListID = todo.ListID
+			todoModel.CompletedAt = utc(todo.CompletedAt)
+			todoModel.StartedAt = utc(todo.StartedAt)
+			todoModel.TimeSpent = seconds(time.Duration(todo.TimeSpent))
+			todoModel.DeletedAt = gorm.DeletedAt{}
+			if err := tx.Unscoped().Save(&todoModel).Error; err != nil {
+				return err
+			}
+			restored = append(restored, todoModel.toTodoItem())
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+	return restored, nil
+}
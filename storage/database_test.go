@@ -1,9 +1,17 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"todolist/core"
 
@@ -23,15 +31,38 @@ func TestMain(m *testing.M) {
 
 func initTestDb(dba *DatabaseAccessor) {
 	// NOTE: Using the in-memory SQLite database for testing purposes.
-	dba.InitDb(sqlite.Open("file::memory:"), &gorm.Config{
+	err := dba.InitDb(sqlite.Open("file::memory:"), &gorm.Config{
 		Logger: logger.Discard,
 	})
+	if err != nil {
+		panic(err)
+	}
 }
 
 func closeTestDb(dba *DatabaseAccessor) {
 	dba.CloseDb()
 }
 
+// withoutTimestamps clears the timestamps filled in by the database, so that the models can be compared with the expected ones.
+// The description keys, which are derived from the descriptions, are cleared as well.
+func withoutTimestamps(todoModels []TodoItemModel) []TodoItemModel {
+	for i := range todoModels {
+		todoModels[i].CreatedAt = time.Time{}
+		todoModels[i].UpdatedAt = time.Time{}
+		todoModels[i].DescriptionKey = ""
+	}
+	return todoModels
+}
+
+// itemsWithoutTimestamps is withoutTimestamps for TodoItems.
+func itemsWithoutTimestamps(todos []core.TodoItem) []core.TodoItem {
+	for i := range todos {
+		todos[i].CreatedAt = time.Time{}
+		todos[i].UpdatedAt = time.Time{}
+	}
+	return todos
+}
+
 // TestCreate Given a todo item, when Create is called, then the todo item should be created in the database and the id should be set and returned.
 func TestCreate(t *testing.T) {
 	// arrange
@@ -51,7 +82,134 @@ func TestCreate(t *testing.T) {
 		}
 		todosInDb := []TodoItemModel{}
 		dba.db.Find(&todosInDb)
-		assert.Equal(t, want, todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestCreateAll Given some todo items, one of which is completed, when CreateAll is called, then all of them should be created in the database in order and their ids set.
+func TestCreateAll(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	todos := []core.TodoItem{
+		{Description: "Test description 1"},
+		{Description: "Test description 2", Completed: true, CompletedAt: &completedAt},
+	}
+
+	// act
+	err := dba.CreateAll(todos)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, todos[0].ID)
+		assert.Equal(t, 2, todos[1].ID)
+		assert.False(t, todos[0].CreatedAt.IsZero())
+		want := []TodoItemModel{
+			{ID: 1, Description: "Test description 1"},
+			{ID: 2, Description: "Test description 2", Completed: true, CompletedAt: &completedAt},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Order("id").Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestCreateWithID Given a todo item whose id is assigned by the core, when Create is called, then the todo item should be created with the id.
+func TestCreateWithID(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	todo := core.TodoItem{ID: 42, Description: "Test description", Completed: false}
+	id, err := dba.Create(&todo)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 42, id)
+		want := []TodoItemModel{
+			{ID: 42, Description: todo.Description, Completed: todo.Completed},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestUpdateWithColor Given a todo item in the database, when UpdateWith is called to set its color, then the color should be stored.
+func TestUpdateWithColor(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	todo := core.TodoItem{Description: "Test description", Color: "red"}
+	dba.Create(&todo)
+
+	// act
+	_, err := dba.UpdateWith(todo.ID, func(todo *core.TodoItem) { todo.Color = "#00ff00" })
+
+	// assert
+	if assert.NoError(t, err) {
+		want := []TodoItemModel{
+			{ID: todo.ID, Description: todo.Description, Color: "#00ff00"},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestCreateUnique Given an incomplete todo item in the database, when CreateUnique is called with the same description in a different case and with surrounding spaces, then nothing should be created and the existing todo item should be returned.
+func TestCreateUnique(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Buy milk", Completed: true},
+		{ID: 2, Description: "Buy milk", Completed: false},
+	})
+
+	// act
+	todo := core.TodoItem{Description: "  buy MILK "}
+	created, err := dba.CreateUnique(&todo)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, 2, todo.ID)
+		assert.Equal(t, "Buy milk", todo.Description)
+		var count int64
+		dba.db.Model(&TodoItemModel{}).Count(&count)
+		assert.Equal(t, int64(2), count)
+	}
+}
+
+// TestCreateUniqueOnlyCompleted Given only a completed todo item with the same description in the database, when CreateUnique is called, then a new todo item should be created.
+func TestCreateUniqueOnlyCompleted(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Buy milk", Completed: true})
+
+	// act
+	todo := core.TodoItem{Description: "Buy milk"}
+	created, err := dba.CreateUnique(&todo)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.True(t, created)
+		want := []TodoItemModel{
+			{ID: 1, Description: "Buy milk", Completed: true},
+			{ID: todo.ID, Description: "Buy milk", Completed: false},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Order("id").Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
 	}
 }
 
@@ -73,40 +231,90 @@ func TestRead(t *testing.T) {
 
 	// assert
 	if assert.Len(t, got, 1) {
-		assert.Equal(t, want, got[0])
+		assert.Equal(t, want, itemsWithoutTimestamps(got)[0])
 	}
 }
 
-// TestUpdate Given some todo items in the database, when Update is called with the id of a todo item, then the todo item should be updated.
-func TestUpdate(t *testing.T) {
+// TestReadOrder Given a thousand todo items inserted in a shuffled order of ids, when Read is called repeatedly,
+// then the items should be returned in the order of their ids every time.
+func TestReadOrder(t *testing.T) {
 	// arrange
 	dba := DatabaseAccessor{}
 	initTestDb(&dba)
 	defer closeTestDb(&dba)
-	targetID := 2
-	dba.db.Create(&[]TodoItemModel{
-		{ID: 1, Description: "Test description 1", Completed: false},
-		{ID: targetID, Description: "Test description 2", Completed: true},
-	})
+	todoModels := make([]TodoItemModel, 1000)
+	for i, id := range rand.Perm(len(todoModels)) {
+		todoModels[i] = TodoItemModel{ID: id + 1, Description: fmt.Sprint("Test description ", id+1), Completed: id%3 == 0}
+	}
+	dba.db.CreateInBatches(&todoModels, 100)
+
+	for i := 0; i < 3; i++ {
+		// act
+		got := dba.Read(func(item core.TodoItem) bool { return !item.Completed })
+
+		// assert
+		ids := idsOf(got)
+		assert.Len(t, ids, 666)
+		assert.True(t, slices.IsSorted(ids), "read %d is not ordered by id", i)
+	}
+}
+
+// TestReadStream Given thousands of todo items in the database, one of which is deleted, when ReadStream is called with a condition, then the callback should be called once for each item that meets the condition, in the order of ids.
+func TestReadStream(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	const n = 3000
+	todoModels := make([]TodoItemModel, 0, n)
+	for i := 1; i <= n; i++ {
+		todoModels = append(todoModels, TodoItemModel{ID: i, Description: fmt.Sprintf("Test description %d", i), Completed: i%2 == 0})
+	}
+	dba.db.CreateInBatches(todoModels, 500)
+	dba.Delete(2)
 
 	// act
-	updatedTodo := core.TodoItem{ID: targetID, Description: "Updated description", Completed: false}
-	err := dba.Update(updatedTodo)
+	var ids []int
+	err := dba.ReadStream(func(todo core.TodoItem) bool { return todo.Completed }, func(todo core.TodoItem) error {
+		ids = append(ids, todo.ID)
+		return nil
+	})
 
 	// assert
 	if assert.NoError(t, err) {
-		want := []TodoItemModel{
-			{ID: 1, Description: "Test description 1", Completed: false},
-			{ID: targetID, Description: updatedTodo.Description, Completed: updatedTodo.Completed},
+		if assert.Len(t, ids, n/2-1, "only the completed items that are not deleted should be streamed") {
+			assert.Equal(t, 4, ids[0])
+			assert.Equal(t, n, ids[len(ids)-1])
 		}
-		todosInDb := []TodoItemModel{}
-		dba.db.Find(&todosInDb)
-		assert.Equal(t, want, todosInDb)
 	}
 }
 
-// TestUpdateNotFound Given some todo items in the database, when Update is called with an id that does not exist, then an error should be returned.
-func TestUpdateNotFound(t *testing.T) {
+// TestReadStreamStop Given some todo items in the database, when ReadStream is called with a callback that fails, then the streaming should stop with the error.
+func TestReadStreamStop(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1"},
+		{ID: 2, Description: "Test description 2"},
+	})
+	stop := errors.New("stop")
+
+	// act
+	calls := 0
+	err := dba.ReadStream(func(core.TodoItem) bool { return true }, func(core.TodoItem) error {
+		calls++
+		return stop
+	})
+
+	// assert
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+}
+
+// TestReadByIDs Given some todo items in the database, when ReadByIDs is called with existing and missing ids, then only the existing todo items should be returned.
+func TestReadByIDs(t *testing.T) {
 	// arrange
 	dba := DatabaseAccessor{}
 	initTestDb(&dba)
@@ -114,56 +322,1345 @@ func TestUpdateNotFound(t *testing.T) {
 	dba.db.Create(&[]TodoItemModel{
 		{ID: 1, Description: "Test description 1", Completed: false},
 		{ID: 2, Description: "Test description 2", Completed: true},
+		{ID: 3, Description: "Test description 3", Completed: false},
 	})
 
 	// act
-	nonExistentTodo := core.TodoItem{ID: 3, Description: "Updated description", Completed: false}
-	err := dba.Update(nonExistentTodo)
+	got := dba.ReadByIDs([]int{1, 3, 5})
 
 	// assert
-	assert.Error(t, err)
+	want := []core.TodoItem{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 3, Description: "Test description 3", Completed: false},
+	}
+	assert.ElementsMatch(t, want, itemsWithoutTimestamps(got))
 }
 
-// TestDelete Given some todo items in the database, when Delete is called with the id of a todo item, then the todo item should be deleted.
-func TestDelete(t *testing.T) {
+// TestReadFiltered Given some todo items created at different times in the database, when ReadFiltered is called with combinations of criteria, then only the todo items meeting all of them should be returned.
+func TestReadFiltered(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: true, CreatedAt: base},
+		{ID: 2, Description: "Test description 2", Completed: false, CreatedAt: base},
+		{ID: 3, Description: "Test description 3", Completed: true, CreatedAt: base.Add(48 * time.Hour)},
+		{ID: 4, Description: "Test description 4", Completed: false, CreatedAt: base.Add(48 * time.Hour)},
+	})
+	completed := true
+	after := base.Add(24 * time.Hour)
+	later := base.Add(48 * time.Hour)
+	tests := []struct {
+		name string
+		f    core.ItemFilter
+		want []core.ItemID
+	}{
+		{"none", core.ItemFilter{}, []core.ItemID{1, 2, 3, 4}},
+		{"completed", core.ItemFilter{Completed: &completed}, []core.ItemID{1, 3}},
+		{"created after", core.ItemFilter{CreatedAfter: &after}, []core.ItemID{3, 4}},
+		{"completed and created after", core.ItemFilter{Completed: &completed, CreatedAfter: &after}, []core.ItemID{3}},
+		{"created before, inclusively", core.ItemFilter{CreatedBefore: &base}, []core.ItemID{1, 2}},
+		{"created between", core.ItemFilter{CreatedAfter: &after, CreatedBefore: &later}, []core.ItemID{3, 4}},
+		{"created between, exclusively after", core.ItemFilter{CreatedAfter: &base, CreatedBefore: &after}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := dba.ReadFiltered(tt.f)
+
+			// assert
+			if assert.NoError(t, err) {
+				var ids []core.ItemID
+				for _, todo := range got {
+					ids = append(ids, todo.ID)
+				}
+				assert.Equal(t, tt.want, ids)
+			}
+		})
+	}
+}
+
+// TestReadFilteredByList Given some todo items in the default list, when one of them is moved to another list with UpdateWith, then ReadFiltered should tell the todo items of each list apart.
+func TestReadFilteredByList(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1"},
+		{ID: 2, Description: "Test description 2"},
+	})
+	defaultList, otherList := 0, 2
+
+	// act
+	_, err := dba.UpdateWith(2, func(todo *core.TodoItem) { todo.ListID = otherList })
+
+	// assert
+	if !assert.NoError(t, err) {
+		return
+	}
+	for listID, want := range map[int]core.ItemID{defaultList: 1, otherList: 2} {
+		got, err := dba.ReadFiltered(core.ItemFilter{ListID: &listID})
+		if assert.NoError(t, err) && assert.Len(t, got, 1) {
+			assert.Equal(t, want, got[0].ID)
+			assert.Equal(t, listID, got[0].ListID)
+		}
+	}
+}
+
+// TestReadFilteredByStatus Given some todo items in the database, when some of them are started with UpdateWith and one of the started ones is completed,
+// then ReadFiltered should tell the todo items of each status apart, taking the completed one as completed though it was started.
+func TestReadFilteredByStatus(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1"},
+		{ID: 2, Description: "Test description 2"},
+		{ID: 3, Description: "Test description 3"},
+	})
+	startedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	// act
+	_, err1 := dba.UpdateWith(2, func(todo *core.TodoItem) { todo.StartedAt = &startedAt })
+	_, err2 := dba.UpdateWith(3, func(todo *core.TodoItem) { todo.StartedAt = &startedAt })
+	_, err3 := dba.UpdateCompleted([]core.ItemID{3}, true, startedAt.Add(time.Hour))
+
+	// assert
+	if !assert.NoError(t, errors.Join(err1, err2, err3)) {
+		return
+	}
+	for status, want := range map[core.ItemStatus]core.ItemID{core.StatusTodo: 1, core.StatusInProgress: 2, core.StatusCompleted: 3} {
+		got, err := dba.ReadFiltered(core.ItemFilter{Status: &status})
+		if assert.NoError(t, err) && assert.Len(t, got, 1) {
+			assert.Equal(t, want, got[0].ID)
+			assert.Equal(t, status, got[0].Status())
+		}
+	}
+	got := dba.ReadByIDs([]core.ItemID{2})
+	if assert.Len(t, got, 1) && assert.NotNil(t, got[0].StartedAt) {
+		assert.True(t, startedAt.Equal(*got[0].StartedAt))
+	}
+}
+
+// TestTimeSpent Given a started todo item with some time spent in the database, when it's completed and reopened with UpdateCompleted an hour later,
+// then the time spent should be kept in whole seconds, with the time until the completion added, and the work resumed from the reopening.
+func TestTimeSpent(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description"})
+	startedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	_, err := dba.UpdateWith(1, func(todo *core.TodoItem) {
+		todo.StartedAt = &startedAt
+		todo.TimeSpent = core.Seconds(90*time.Second + 400*time.Millisecond)
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// act
+	_, errCompleted := dba.UpdateCompleted([]core.ItemID{1}, true, startedAt.Add(10*time.Minute))
+	_, errReopened := dba.UpdateCompleted([]core.ItemID{1}, false, startedAt.Add(70*time.Minute))
+
+	// assert
+	assert.NoError(t, errCompleted)
+	assert.NoError(t, errReopened)
+	got := dba.ReadByIDs([]core.ItemID{1})
+	if assert.Len(t, got, 1) && assert.NotNil(t, got[0].StartedAt) {
+		assert.Equal(t, core.Seconds(90*time.Second+10*time.Minute), got[0].TimeSpent)
+		assert.True(t, startedAt.Add(70*time.Minute).Equal(*got[0].StartedAt))
+		assert.Equal(t, core.StatusInProgress, got[0].Status())
+	}
+}
+
+// TestReadCompletedBetween Given some todo items completed at different times in the database, when ReadCompletedBetween is called with a range, then only the todo items completed within the range should be returned.
+func TestReadCompletedBetween(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	at := func(day int) *time.Time {
+		t := time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: true, CompletedAt: at(1)},
+		{ID: 2, Description: "Test description 2", Completed: true, CompletedAt: at(3)},
+		{ID: 3, Description: "Test description 3", Completed: true, CompletedAt: at(8)},
+		{ID: 4, Description: "Test description 4", Completed: false},
+	})
+
+	// act
+	got := dba.ReadCompletedBetween(*at(1), *at(8))
+
+	// assert
+	var ids []int
+	for _, item := range got {
+		ids = append(ids, item.ID)
+	}
+	assert.ElementsMatch(t, []int{1, 2}, ids)
+}
+
+// TestReadAfter Given some todo items in the database, when ReadAfter is called with a cursor and a limit, then at most limit todo items with a greater id should be returned in order.
+func TestReadAfter(t *testing.T) {
 	// arrange
 	dba := DatabaseAccessor{}
 	initTestDb(&dba)
 	defer closeTestDb(&dba)
 	dba.db.Create(&[]TodoItemModel{
+		{ID: 4, Description: "Test description 4", Completed: false},
 		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 3, Description: "Test description 3", Completed: true},
 		{ID: 2, Description: "Test description 2", Completed: true},
 	})
 
 	// act
-	err := dba.Delete(1)
+	got := dba.ReadAfter(1, 2)
+
+	// assert
+	want := []core.TodoItem{
+		{ID: 2, Description: "Test description 2", Completed: true},
+		{ID: 3, Description: "Test description 3", Completed: true},
+	}
+	assert.Equal(t, want, itemsWithoutTimestamps(got))
+}
+
+// TestReadPage Given some todo items in the database, when ReadPage is called with an offset and a limit, then the todo items in the window ordered by id and the total number of todo items should be returned.
+func TestReadPage(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	for i := 1; i <= 5; i++ {
+		dba.db.Create(&TodoItemModel{ID: i, Description: fmt.Sprintf("Task %d", i)})
+	}
+
+	// act
+	got, total, err := dba.ReadPage(3, 2)
 
 	// assert
 	if assert.NoError(t, err) {
-		want := []TodoItemModel{
-			{ID: 2, Description: "Test description 2", Completed: true},
+		want := []core.TodoItem{
+			{ID: 4, Description: "Task 4"},
+			{ID: 5, Description: "Task 5"},
 		}
-		todosInDb := []TodoItemModel{}
-		dba.db.Find(&todosInDb)
-		assert.Equal(t, want, todosInDb)
+		assert.Equal(t, want, itemsWithoutTimestamps(got))
+		assert.Equal(t, 5, total)
 	}
 }
 
-// TestDeleteNotFound Given some todo items in the database, when Delete is called with an id that does not exist, then an error should be returned.
-func TestDeleteNotFound(t *testing.T) {
+// TestReadQuery Given todo items with different completed statuses, creation times, and positions in the database,
+// when ReadQuery is called with a filter, a sort, and a window at once, then the window of the filtered todo items in the order should be returned
+// with the number of all the filtered ones.
+func TestReadQuery(t *testing.T) {
 	// arrange
 	dba := DatabaseAccessor{}
 	initTestDb(&dba)
 	defer closeTestDb(&dba)
-	items := []TodoItemModel{
-		{ID: 1, Description: "Test description 1", Completed: false},
-		{ID: 2, Description: "Test description 2", Completed: true},
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false, Position: 4, CreatedAt: base},
+		{ID: 2, Description: "Test description 2", Completed: false, Position: 3, CreatedAt: base.Add(24 * time.Hour)},
+		{ID: 3, Description: "Test description 3", Completed: true, Position: 2, CreatedAt: base.Add(24 * time.Hour)},
+		{ID: 4, Description: "Test description 4", Completed: false, Position: 1, CreatedAt: base.Add(48 * time.Hour)},
+		{ID: 5, Description: "Test description 5", Completed: false, Position: 1, CreatedAt: base.Add(72 * time.Hour)},
+	})
+	active := false
+	tests := []struct {
+		name      string
+		q         core.Query
+		wantIDs   []core.ItemID
+		wantTotal int
+	}{
+		{"filter, sort, and offset", core.Query{Filter: core.ItemFilter{Completed: &active, CreatedAfter: &base}, Sort: core.SortByPosition, Offset: 1, Limit: 2}, []core.ItemID{5, 2}, 3},
+		{"filter and cursor", core.Query{Filter: core.ItemFilter{Completed: &active}, After: 1, Limit: 2}, []core.ItemID{2, 4}, 4},
+		{"no window", core.Query{Sort: core.SortByPosition}, []core.ItemID{4, 5, 3, 2, 1}, 5},
+		{"descending ids", core.Query{Filter: core.ItemFilter{Completed: &active}, Descending: true, Limit: 3}, []core.ItemID{5, 4, 2}, 4},
+		{"descending positions", core.Query{Sort: core.SortByPosition, Descending: true, Offset: 1}, []core.ItemID{2, 3, 5, 4}, 5},
 	}
-	dba.db.Create(&items)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, total, err := dba.ReadQuery(tt.q)
+
+			// assert
+			if assert.NoError(t, err) {
+				var ids []core.ItemID
+				for _, todo := range got {
+					ids = append(ids, todo.ID)
+				}
+				assert.Equal(t, tt.wantIDs, ids)
+				assert.Equal(t, tt.wantTotal, total)
+			}
+		})
+	}
+}
+
+// TestReadDescriptionsWithPrefix Given some todo items in the database, when ReadDescriptionsWithPrefix is called with a prefix, then the distinct descriptions starting with the prefix case-insensitively should be returned with the most recent first.
+func TestReadDescriptionsWithPrefix(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Buy milk"},
+		{ID: 2, Description: "Walk the dog"},
+		{ID: 3, Description: "buy eggs"},
+		{ID: 4, Description: "Buy milk"},
+		{ID: 5, Description: "Call mom to buy"},
+	})
 
 	// act
-	err := dba.Delete(3)
+	got := dba.ReadDescriptionsWithPrefix("BUY", 10)
 
 	// assert
-	assert.Error(t, err)
+	assert.Equal(t, []string{"Buy milk", "buy eggs"}, got)
+}
+
+// TestReadDescriptionsWithPrefixLimit Given more matching todo items in the database than the limit, when ReadDescriptionsWithPrefix is called, then only limit descriptions should be returned.
+func TestReadDescriptionsWithPrefixLimit(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	for i := 1; i <= 5; i++ {
+		dba.db.Create(&TodoItemModel{ID: i, Description: fmt.Sprintf("Task %d", i)})
+	}
+
+	// act
+	got := dba.ReadDescriptionsWithPrefix("task", 3)
+
+	// assert
+	assert.Equal(t, []string{"Task 5", "Task 4", "Task 3"}, got)
+}
+
+// TestReadDescriptionsWithPrefixWildcard Given some todo items in the database, when ReadDescriptionsWithPrefix is called with a prefix containing a wildcard character, then the wildcard should be matched literally.
+func TestReadDescriptionsWithPrefixWildcard(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "100% done"},
+		{ID: 2, Description: "1000 things"},
+	})
+
+	// act
+	got := dba.ReadDescriptionsWithPrefix("100%", 10)
+
+	// assert
+	assert.Equal(t, []string{"100% done"}, got)
+}
+
+// TestCount Given some todo items in the database, one of them deleted, when Count is called with a filter, then the number of the remaining todo items meeting the filter should be returned.
+func TestCount(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Buy milk", Completed: false},
+		{ID: 2, Description: "Buy eggs", Completed: true},
+		{ID: 3, Description: "Call mom", Completed: false},
+		{ID: 4, Description: "Buy bread", Completed: false},
+	})
+	dba.db.Delete(&TodoItemModel{}, 4)
+	completed := false
+
+	// act
+	got, err := dba.Count(core.ItemFilter{Completed: &completed})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, got)
+	}
+}
+
+// TestCountEmpty Given no todo items in the database, when Count is called without a filter, then zero should be returned.
+func TestCountEmpty(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	got, err := dba.Count(core.ItemFilter{})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Zero(t, got)
+	}
+}
+
+// TestUpdate Given some todo items in the database, when Update is called with the id of a todo item, then the todo item should be updated.
+func TestUpdate(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	targetID := 2
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: targetID, Description: "Test description 2", Completed: true},
+	})
+
+	// act
+	updatedTodo := core.TodoItem{ID: targetID, Description: "Updated description", Completed: false}
+	err := dba.Update(updatedTodo)
+
+	// assert
+	if assert.NoError(t, err) {
+		want := []TodoItemModel{
+			{ID: 1, Description: "Test description 1", Completed: false},
+			{ID: targetID, Description: updatedTodo.Description, Completed: updatedTodo.Completed},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestUpdateNotFound Given some todo items in the database, when Update is called with an id that does not exist, then an error should be returned.
+func TestUpdateNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 2, Description: "Test description 2", Completed: true},
+	})
+
+	// act
+	nonExistentTodo := core.TodoItem{ID: 3, Description: "Updated description", Completed: false}
+	err := dba.Update(nonExistentTodo)
+
+	// assert
+	assert.Error(t, err)
+}
+
+// TestUpdateWith Given some todo items in the database, when UpdateWith is called with the id of a todo item, then the modification should be saved and the updated todo item returned.
+func TestUpdateWith(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 2, Description: "Test description 2", Completed: false},
+	})
+
+	// act
+	got, err := dba.UpdateWith(2, func(todo *core.TodoItem) {
+		todo.Completed = true
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.TodoItem{ID: 2, Description: "Test description 2", Completed: true}, itemsWithoutTimestamps([]core.TodoItem{got})[0])
+		want := []TodoItemModel{
+			{ID: 1, Description: "Test description 1", Completed: false},
+			{ID: 2, Description: "Test description 2", Completed: true},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestUpdateWithNotFound Given some todo items in the database, when UpdateWith is called with an id that does not exist, then a TodoItemNotFoundError should be returned.
+func TestUpdateWithNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1", Completed: false})
+
+	// act
+	_, err := dba.UpdateWith(3, func(*core.TodoItem) {})
+
+	// assert
+	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+}
+
+// TestUpdateWithConcurrent Given a todo item in the database, when UpdateWith is called concurrently to toggle it an even number of times, then no toggle should be lost and the todo item should end up incomplete.
+func TestUpdateWithConcurrent(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	// NOTE: Each connection to an in-memory SQLite database has its own database, so all goroutines have to share the same one.
+	sqlDb, _ := dba.db.DB()
+	sqlDb.SetMaxOpenConns(1)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1", Completed: false})
+	toggles := 0
+	var mu sync.Mutex
+
+	// act
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := dba.UpdateWith(1, func(todo *core.TodoItem) {
+				mu.Lock()
+				toggles++
+				mu.Unlock()
+				todo.Completed = !todo.Completed
+				// Give the other goroutines a chance to interleave.
+				time.Sleep(time.Millisecond)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, 20, toggles)
+	var todoInDb TodoItemModel
+	dba.db.First(&todoInDb, 1)
+	assert.False(t, todoInDb.Completed)
+}
+
+// TestUpsertCreates Given no todo item with the id in the database, when Upsert is called with the id, then the modified todo item should be created with the id.
+func TestUpsertCreates(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	todo, created, err := dba.Upsert(5, func(todo *core.TodoItem) {
+		todo.Description = "Test description"
+		todo.Color = "red"
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.True(t, created)
+		assert.Equal(t, 5, todo.ID)
+		assert.False(t, todo.CreatedAt.IsZero())
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, []TodoItemModel{{ID: 5, Description: "Test description", Color: "red"}}, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestUpsertCreatesWithNewID Given some todo item in the database, when Upsert is called with an id of 0, then the modified todo item should be created with a new id.
+func TestUpsertCreatesWithNewID(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1"})
+
+	// act
+	todo, created, err := dba.Upsert(0, func(todo *core.TodoItem) {
+		todo.Description = "Test description 2"
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.True(t, created)
+		assert.Equal(t, 2, todo.ID)
+	}
+}
+
+// TestUpsertUpdates Given a todo item in the database, when Upsert is called with its id, then the todo item should be modified in place and not be created again.
+func TestUpsertUpdates(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1", Position: 3})
+
+	// act
+	todo, created, err := dba.Upsert(1, func(todo *core.TodoItem) {
+		todo.Description = "Test description 2"
+		todo.Completed = true
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, "Test description 2", todo.Description)
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, []TodoItemModel{{ID: 1, Description: "Test description 2", Completed: true, Position: 3}}, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestUpsertDeleted Given a deleted todo item in the database, when Upsert is called with its id, then a ConflictError should be returned and nothing should be created.
+func TestUpsertDeleted(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description"})
+	dba.db.Delete(&TodoItemModel{}, 1)
+
+	// act
+	_, _, err := dba.Upsert(1, func(todo *core.TodoItem) {
+		todo.Description = "Test description"
+	})
+
+	// assert
+	assert.IsType(t, core.ConflictError{}, err)
+	var count int64
+	dba.db.Model(&TodoItemModel{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+// TestUpsertConcurrent Given no todo item with the id in the database, when Upsert is called concurrently with the id, then the todo item should be created only once
+// and updated by the other calls.
+func TestUpsertConcurrent(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	// NOTE: Each connection to an in-memory SQLite database has its own database, so all goroutines have to share the same one.
+	sqlDb, _ := dba.db.DB()
+	sqlDb.SetMaxOpenConns(1)
+	creations := 0
+	var mu sync.Mutex
+
+	// act
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, created, err := dba.Upsert(5, func(todo *core.TodoItem) {
+				todo.Description = "Test description"
+				// Give the other goroutines a chance to interleave.
+				time.Sleep(time.Millisecond)
+			})
+			if assert.NoError(t, err) && created {
+				mu.Lock()
+				creations++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, 1, creations)
+	var count int64
+	dba.db.Model(&TodoItemModel{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestUpsertCreatedConcurrently Given a database shared by several connections, when another connection creates the id while Upsert is creating it too,
+// then Upsert should update the TodoItem created by the other connection instead of failing with a duplicate key.
+func TestUpsertCreatedConcurrently(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	// NOTE: Unlike the in-memory database, a file is shared by all the connections, so that the other connection can commit while Upsert's transaction is open.
+	err := dba.InitDb(sqlite.Open(filepath.Join(t.TempDir(), "todolist.db")+"?_journal_mode=WAL"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestDb(&dba)
+	sqlDb, _ := dba.db.DB()
+	sqlDb.SetMaxOpenConns(2)
+	raced := false
+
+	// act
+	todo, created, err := dba.Upsert(5, func(todo *core.TodoItem) {
+		if !raced {
+			raced = true
+			// The other connection wins the race after Upsert found no TodoItemModel with the id.
+			if err := dba.db.Create(&TodoItemModel{ID: 5, Description: "Created concurrently", Color: "red"}).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		todo.Description = "Test description"
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, "Test description", todo.Description)
+		assert.Equal(t, "red", todo.Color)
+		var todosInDb []TodoItemModel
+		dba.db.Find(&todosInDb)
+		if assert.Len(t, todosInDb, 1) {
+			assert.Equal(t, "Test description", todosInDb[0].Description)
+		}
+	}
+}
+
+// TestUpdateCompleted Given some todo items in the database, when UpdateCompleted is called with existing and missing ids, then the status of the existing todo items should be updated and the number of changed todo items returned.
+func TestUpdateCompleted(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 2, Description: "Test description 2", Completed: true, CompletedAt: &earlier},
+		{ID: 3, Description: "Test description 3", Completed: false},
+	})
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// act
+	n, err := dba.UpdateCompleted([]int{1, 2, 4}, true, now)
+
+	// assert: only item 1 changes; item 2 is already completed and item 4 doesn't exist
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, n)
+		todosInDb := []TodoItemModel{}
+		dba.db.Order("id").Find(&todosInDb)
+		assert.True(t, todosInDb[0].Completed)
+		assert.True(t, now.Equal(*todosInDb[0].CompletedAt))
+		assert.True(t, earlier.Equal(*todosInDb[1].CompletedAt), "completion time of an already completed item should be kept")
+		assert.False(t, todosInDb[2].Completed)
+	}
+}
+
+// TestUpdateCompletedIncomplete Given some completed todo items in the database, when UpdateCompleted is called to mark them incomplete, then their completion time should be cleared.
+func TestUpdateCompletedIncomplete(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: true, CompletedAt: &earlier},
+		{ID: 2, Description: "Test description 2", Completed: true, CompletedAt: &earlier},
+	})
+
+	// act
+	n, err := dba.UpdateCompleted([]int{1, 2}, false, time.Now())
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, n)
+		want := []TodoItemModel{
+			{ID: 1, Description: "Test description 1", Completed: false},
+			{ID: 2, Description: "Test description 2", Completed: false},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestReorder Given some todo items in the database, when Reorder is called with their ids, then the position of each todo item should be its index in the ids.
+func TestReorder(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 2, Description: "Test description 2", Completed: true},
+		{ID: 3, Description: "Test description 3", Completed: false},
+	})
+
+	// act
+	err := dba.Reorder([]int{3, 1, 2})
+
+	// assert
+	if assert.NoError(t, err) {
+		want := []TodoItemModel{
+			{ID: 1, Description: "Test description 1", Completed: false, Position: 1},
+			{ID: 2, Description: "Test description 2", Completed: true, Position: 2},
+			{ID: 3, Description: "Test description 3", Completed: false, Position: 0},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestReorderNotFound Given some todo items in the database, when Reorder is called with an id that does not exist, then an error should be returned and no position should be changed.
+func TestReorderNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	items := []TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false, Position: 0},
+		{ID: 2, Description: "Test description 2", Completed: true, Position: 1},
+	}
+	dba.db.Create(&items)
+
+	// act
+	err := dba.Reorder([]int{2, 1, 3})
+
+	// assert
+	assert.IsType(t, core.TodoItemNotFoundError{}, err)
+	todosInDb := []TodoItemModel{}
+	dba.db.Find(&todosInDb)
+	assert.Equal(t, items, todosInDb)
+}
+
+// TestReorderUnchanged Given some todo items in the database already in order, when Reorder is called with the same order,
+// then no error should be returned, even though no position changes.
+func TestReorderUnchanged(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	items := []TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false, Position: 0},
+		{ID: 2, Description: "Test description 2", Completed: true, Position: 1},
+	}
+	dba.db.Create(&items)
+
+	// act
+	err := dba.Reorder([]int{1, 2})
+
+	// assert
+	if assert.NoError(t, err) {
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, withoutTimestamps(items), withoutTimestamps(todosInDb))
+	}
+}
+
+// TestExists Given some todo items in the database, one of which is deleted, when Exists is called, then only the ids of the items not deleted should exist.
+func TestExists(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1"},
+		{ID: 2, Description: "Test description 2"},
+	})
+	dba.Delete(2)
+
+	for id, want := range map[core.ItemID]bool{1: true, 2: false, 3: false} {
+		// act
+		got, err := dba.Exists(id)
+
+		// assert
+		if assert.NoError(t, err) {
+			assert.Equal(t, want, got, "id %d", id)
+		}
+	}
+}
+
+// TestReindex Given todo items whose description key and completion time are corrupted by hand, one of which is deleted,
+// when Reindex is called, then the derived fields should be repaired and the number of fixed items reported.
+func TestReindex(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	completedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: " Buy Milk "},
+		{ID: 2, Description: "Call mom", Completed: true, CompletedAt: &completedAt},
+		{ID: 3, Description: "Walk the dog", Completed: true, CompletedAt: &completedAt},
+		{ID: 4, Description: "Fix the car", CompletedAt: &completedAt},
+	})
+	dba.Delete(4)
+	dba.db.Model(&TodoItemModel{ID: 1}).UpdateColumn("description_key", "stale")
+	dba.db.Model(&TodoItemModel{ID: 3}).UpdateColumn("completed_at", nil)
+
+	// act
+	got, err := dba.Reindex()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, core.ReindexReport{DescriptionKeys: 1, CompletedAt: 2}, got)
+		var todoModels []TodoItemModel
+		dba.db.Unscoped().Order("id").Find(&todoModels)
+		assert.Equal(t, "buy milk", todoModels[0].DescriptionKey)
+		assert.Equal(t, completedAt, *todoModels[1].CompletedAt)
+		if assert.NotNil(t, todoModels[2].CompletedAt) {
+			assert.Equal(t, todoModels[2].UpdatedAt, *todoModels[2].CompletedAt)
+		}
+		assert.Nil(t, todoModels[3].CompletedAt)
+	}
+	again, err := dba.Reindex()
+	if assert.NoError(t, err) {
+		assert.Zero(t, again)
+	}
+}
+
+// TestDelete Given some todo items in the database, when Delete is called with the id of a todo item, then the todo item should be deleted.
+func TestDelete(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 2, Description: "Test description 2", Completed: true},
+	})
+
+	// act
+	err := dba.Delete(1)
+
+	// assert
+	if assert.NoError(t, err) {
+		want := []TodoItemModel{
+			{ID: 2, Description: "Test description 2", Completed: true},
+		}
+		todosInDb := []TodoItemModel{}
+		dba.db.Find(&todosInDb)
+		assert.Equal(t, want, withoutTimestamps(todosInDb))
+	}
+}
+
+// TestDeleteNotFound Given some todo items in the database, when Delete is called with an id that does not exist, then a TodoItemNotFoundError should be returned.
+func TestDeleteNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	items := []TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: false},
+		{ID: 2, Description: "Test description 2", Completed: true},
+	}
+	dba.db.Create(&items)
+
+	// act
+	err := dba.Delete(3)
+
+	// assert
+	assert.ErrorIs(t, err, core.TodoItemNotFoundError{ID: 3})
+}
+
+// TestReplaceAllRoundTrip Given the todo items read by ReadAll and the database is changed afterward, when ReplaceAll is called with the items, then the database should hold exactly the items again, with their ids and timestamps.
+func TestReplaceAllRoundTrip(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 3, Description: "Test description 3", Completed: false, Position: 1},
+		{ID: 7, Description: "Test description 7", Completed: true, CompletedAt: &completedAt},
+	})
+	exported, err := dba.ReadAll()
+	if !assert.NoError(t, err) {
+		return
+	}
+	dba.Delete(3)
+	dba.Create(&core.TodoItem{Description: "Test description 8"})
+
+	// act
+	err = dba.ReplaceAll(exported)
+
+	// assert
+	if assert.NoError(t, err) {
+		got, err := dba.ReadAll()
+		if assert.NoError(t, err) {
+			assert.Equal(t, exported, got)
+		}
+		var count int64
+		dba.db.Unscoped().Model(&TodoItemModel{}).Count(&count)
+		assert.Equal(t, int64(2), count, "tombstones should be removed")
+	}
+}
+
+// TestReset Given some todo items in the database, one of which is deleted, when Reset is called, then the database should be empty and the ids should start over.
+func TestReset(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	dba.Create(&core.TodoItem{Description: "Test description 2"})
+	dba.Delete(1)
+
+	// act
+	err := dba.Reset()
+
+	// assert
+	if assert.NoError(t, err) {
+		var count int64
+		dba.db.Unscoped().Model(&TodoItemModel{}).Count(&count)
+		assert.Equal(t, int64(0), count, "tombstones should be removed as well")
+		id, err := dba.Create(&core.TodoItem{Description: "Test description 3"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, id)
+		}
+	}
+}
+
+// TestStats Given some todo items in the database, one of which is deleted, when Stats is called, then the driver should be reported along with the number of the remaining items.
+func TestStats(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.Create(&core.TodoItem{Description: "Test description 1"})
+	dba.Create(&core.TodoItem{Description: "Test description 2"})
+	dba.Create(&core.TodoItem{Description: "Test description 3"})
+	dba.Delete(2)
+
+	// act
+	got, err := dba.Stats()
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sqlite", got.Driver)
+		assert.Equal(t, 2, got.Items)
+		assert.GreaterOrEqual(t, got.OpenConnections, 1)
+	}
+}
+
+// TestReplaceAllEmpty Given some todo items in the database, when ReplaceAll is called with no items, then the database should be empty.
+func TestReplaceAllEmpty(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1"})
+
+	// act
+	err := dba.ReplaceAll(nil)
+
+	// assert
+	if assert.NoError(t, err) {
+		got, err := dba.ReadAll()
+		if assert.NoError(t, err) {
+			assert.Empty(t, got)
+		}
+	}
+}
+
+// TestDeleteCompletedBefore Given todo items completed before and after a time, an incomplete one, and a completed one without a completion time, when DeleteCompletedBefore is called with the time, then only the ones completed before it should be deleted.
+func TestDeleteCompletedBefore(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := before.Add(-time.Second)
+	recent := before.Add(time.Second)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: true, CompletedAt: &old},
+		{ID: 2, Description: "Test description 2", Completed: true, CompletedAt: &recent},
+		{ID: 3, Description: "Test description 3", Completed: false},
+		{ID: 4, Description: "Test description 4", Completed: true},
+		{ID: 5, Description: "Test description 5", Completed: true, CompletedAt: &old},
+	})
+
+	// act
+	got, err := dba.DeleteCompletedBefore(before)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, got)
+		var ids []int
+		dba.db.Model(&TodoItemModel{}).Order("id").Pluck("id", &ids)
+		assert.Equal(t, []int{2, 3, 4}, ids)
+	}
+}
+
+// TestDeleteCompletedThenRestore Given some todo items in the database, when DeleteCompleted is called and then Restore is called with the deleted items, then the completed items should be deleted and restored with their ids.
+func TestDeleteCompletedThenRestore(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	completedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: true, CompletedAt: &completedAt},
+		{ID: 2, Description: "Test description 2", Completed: false},
+		{ID: 3, Description: "Test description 3", Completed: true, CompletedAt: &completedAt},
+	})
+
+	// act
+	deleted, err := dba.DeleteCompleted()
+	if !assert.NoError(t, err) {
+		return
+	}
+	remaining := dba.Read(func(core.TodoItem) bool { return true })
+	restored, err := dba.Restore(deleted)
+
+	// assert
+	if assert.NoError(t, err) {
+		wantDeleted := []core.TodoItem{
+			{ID: 1, Description: "Test description 1", Completed: true, CompletedAt: &completedAt},
+			{ID: 3, Description: "Test description 3", Completed: true, CompletedAt: &completedAt},
+		}
+		assert.Equal(t, wantDeleted, itemsWithoutTimestamps(deleted))
+		assert.Equal(t, []core.TodoItem{{ID: 2, Description: "Test description 2"}}, itemsWithoutTimestamps(remaining))
+		assert.Equal(t, wantDeleted, itemsWithoutTimestamps(restored))
+		got, _ := dba.ReadAll()
+		assert.Len(t, got, 3)
+	}
+}
+
+// TestRestoreHardDeleted Given a todo item deleted permanently from the database, when Restore is called with the item, then the item should be re-created with a new id.
+func TestRestoreHardDeleted(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 5, Description: "Test description 5", Completed: true})
+	dba.db.Unscoped().Delete(&TodoItemModel{}, 5)
+
+	// act
+	restored, err := dba.Restore([]core.TodoItem{{ID: 5, Description: "Test description 5", Completed: true}})
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, restored, 1) {
+		assert.NotZero(t, restored[0].ID)
+		assert.Equal(t, "Test description 5", restored[0].Description)
+		assert.True(t, restored[0].Completed)
+	}
+}
+
+// TestRestoreNotDeleted Given some todo items in the database, when Restore is called with a deleted item and one that is not deleted, then a ConflictError should be returned and nothing should be restored.
+func TestRestoreNotDeleted(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", Completed: true},
+		{ID: 2, Description: "Test description 2", Completed: false},
+	})
+	dba.Delete(1)
+
+	// act
+	_, err := dba.Restore([]core.TodoItem{
+		{ID: 1, Description: "Test description 1", Completed: true},
+		{ID: 2, Description: "Test description 2", Completed: false},
+	})
+
+	// assert
+	assert.IsType(t, core.ConflictError{}, err)
+	got, _ := dba.ReadAll()
+	assert.Equal(t, []core.TodoItem{{ID: 2, Description: "Test description 2"}}, itemsWithoutTimestamps(got))
+}
+
+// TestInitDbWithRetry Given the database is unreachable for the first two attempts, when InitDbWithRetry is called with three attempts, then the database should be initialized without error.
+func TestInitDbWithRetry(t *testing.T) {
+	// arrange
+	failures := 2
+	attempts := 0
+	openDb = func(dialector gorm.Dialector, opts ...gorm.Option) (*gorm.DB, error) {
+		attempts++
+		if attempts <= failures {
+			return nil, errors.New("connection refused")
+		}
+		return gorm.Open(dialector, opts...)
+	}
+	defer func() { openDb = gorm.Open }()
+	dba := DatabaseAccessor{}
+
+	// act
+	err := dba.InitDbWithRetry(context.Background(), sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard}, 3, time.Millisecond)
+	defer closeTestDb(&dba)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, attempts)
+		assert.NotNil(t, dba.db)
+	}
+}
+
+// TestInitDbWithRetryGiveUp Given the database is always unreachable, when InitDbWithRetry is called, then the error should be returned after all attempts.
+func TestInitDbWithRetryGiveUp(t *testing.T) {
+	// arrange
+	attempts := 0
+	openDb = func(gorm.Dialector, ...gorm.Option) (*gorm.DB, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	}
+	defer func() { openDb = gorm.Open }()
+	dba := DatabaseAccessor{}
+
+	// act
+	err := dba.InitDbWithRetry(context.Background(), sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard}, 3, time.Millisecond)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+// TestReconnect Given the connection to the database is dropped, when Reconnect is called, then the queries should work again with a new connection.
+func TestReconnect(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	sqlDB, _ := dba.db.DB()
+	_ = sqlDB.Close()
+	_, err := dba.ReadAll()
+	assert.Error(t, err, "the connection should have been dropped")
+
+	// act
+	err = dba.Reconnect()
+
+	// assert
+	if assert.NoError(t, err) {
+		todo := core.TodoItem{Description: "Test description"}
+		_, err = dba.Create(&todo)
+		assert.NoError(t, err)
+		got, err := dba.ReadAll()
+		if assert.NoError(t, err) {
+			assert.Equal(t, []core.TodoItem{{ID: todo.ID, Description: "Test description"}}, itemsWithoutTimestamps(got))
+		}
+	}
+}
+
+// TestReconnectFails Given the database can't be opened again, when Reconnect is called, then the error should be returned and the old connection should be kept.
+func TestReconnectFails(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	openDb = func(gorm.Dialector, ...gorm.Option) (*gorm.DB, error) {
+		return nil, errors.New("connection refused")
+	}
+	defer func() { openDb = gorm.Open }()
+
+	// act
+	err := dba.Reconnect()
+
+	// assert
+	assert.Error(t, err)
+	_, err = dba.ReadAll()
+	assert.NoError(t, err)
+}
+
+// TestReadChangedSince Given todo items created, updated, and deleted before and after a point in time, when ReadChangedSince is called with that time, then only the todo items created or updated after it should be returned as changed and only the ones deleted after it as deleted.
+func TestReadChangedSince(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	old := time.Now().UTC().Add(-time.Hour)
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", CreatedAt: old, UpdatedAt: old},
+		{ID: 2, Description: "Test description 2", CreatedAt: old, UpdatedAt: old},
+		{ID: 3, Description: "Test description 3", CreatedAt: old, UpdatedAt: old},
+		{ID: 4, Description: "Test description 4", CreatedAt: old, UpdatedAt: old, DeletedAt: gorm.DeletedAt{Time: old, Valid: true}},
+	})
+	since := time.Now().UTC().Add(-time.Minute)
+
+	// act: create one, update one, and delete one after since
+	created := core.TodoItem{Description: "Test description 5"}
+	_, err := dba.Create(&created)
+	assert.NoError(t, err)
+	_, err = dba.UpdateWith(2, func(todo *core.TodoItem) { todo.Completed = true })
+	assert.NoError(t, err)
+	assert.NoError(t, dba.Delete(3))
+	changed, deletedIDs, err := dba.ReadChangedSince(since)
+
+	// assert
+	if assert.NoError(t, err) {
+		var changedIDs []int
+		for _, todo := range changed {
+			changedIDs = append(changedIDs, todo.ID)
+		}
+		assert.Equal(t, []int{2, created.ID}, changedIDs)
+		assert.Equal(t, []int{3}, deletedIDs)
+	}
+}
+
+// TestTransaction Given some todo items created in a transaction, when the function returns no error, then the todo items should be committed.
+func TestTransaction(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	err := dba.Transaction(func(tx core.StorageAccessor) error {
+		_, err := tx.Create(&core.TodoItem{Description: "Test description 1"})
+		if err != nil {
+			return err
+		}
+		_, err = tx.Create(&core.TodoItem{Description: "Test description 2"})
+		return err
+	})
+
+	// assert
+	if assert.NoError(t, err) {
+		var count int64
+		dba.db.Model(&TodoItemModel{}).Count(&count)
+		assert.Equal(t, int64(2), count)
+	}
+}
+
+// TestTransactionRollback Given a todo item created in a transaction, when the function returns an error, then the error should be returned and the todo item should be rolled back.
+func TestTransactionRollback(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	stop := errors.New("stop")
+
+	// act
+	err := dba.Transaction(func(tx core.StorageAccessor) error {
+		_, err := tx.Create(&core.TodoItem{Description: "Test description"})
+		if err != nil {
+			return err
+		}
+		return stop
+	})
+
+	// assert
+	assert.ErrorIs(t, err, stop)
+	var count int64
+	dba.db.Model(&TodoItemModel{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+// TestBatchRollback Given a todo item, when an atomic batch that updates it and then deletes a missing one is applied by the core,
+// then none of the operations should take effect.
+func TestBatchRollback(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description"})
+	theCore := core.NewCore(&dba)
+	completed := true
+
+	// act
+	_, err := theCore.Batch([]core.BatchOp{
+		{Op: core.BatchCreate, Description: "Another description"},
+		{Op: core.BatchUpdate, ID: 1, Completed: &completed},
+		{Op: core.BatchUpdate, ID: 3, Completed: &completed},
+	}, true)
+
+	// assert
+	assert.ErrorAs(t, err, new(core.BatchError))
+	todosInDb := []TodoItemModel{}
+	dba.db.Order("id").Find(&todosInDb)
+	assert.Equal(t, []TodoItemModel{{ID: 1, Description: "Test description"}}, withoutTimestamps(todosInDb))
+}
+
+// TestDeleteCompletedOverMaxFraction Given more completed todo items than the max fraction of all, when they are deleted by the core,
+// then a ConflictError should be returned and none of them should be deleted.
+func TestDeleteCompletedOverMaxFraction(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1", Completed: true})
+	dba.db.Create(&TodoItemModel{ID: 2, Description: "Test description 2", Completed: true})
+	dba.db.Create(&TodoItemModel{ID: 3, Description: "Test description 3"})
+	theCore := core.NewCore(&dba)
+
+	// act
+	_, err := theCore.DeleteCompleted(0.5)
+
+	// assert
+	assert.ErrorAs(t, err, new(core.ConflictError))
+	var count int64
+	dba.db.Model(&TodoItemModel{}).Count(&count)
+	assert.Equal(t, int64(3), count)
 }
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"todolist/core"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Config describes which storage backend to use and how to connect to it.
+type Config struct {
+	// Driver is the name of the storage backend, one of "mysql", "postgres", or "sqlite".
+	Driver string
+	// DSN is the data source name passed to the driver.
+	DSN string
+	// ConnectAttempts is the number of times to try connecting to the backend before giving up. At least one attempt is made.
+	ConnectAttempts int
+	// ConnectBackoff is the wait before the first retry; it's doubled on every retry.
+	ConnectBackoff time.Duration
+	// QueryTimeout is the maximum duration of each statement sent to the backend. There's no limit if it's 0.
+	QueryTimeout time.Duration
+}
+
+// NewAccessor returns the StorageAccessor of the backend specified by the config.
+// An error is returned if the driver is unknown or the backend is still unreachable after all connection attempts.
+func NewAccessor(cfg Config) (core.StorageAccessor, error) {
+	dialect, err := dialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	accessor := &DatabaseAccessor{}
+	err = accessor.InitDbWithRetry(context.Background(), dialect, &gorm.Config{}, cfg.ConnectAttempts, cfg.ConnectBackoff)
+	if err != nil {
+		return nil, err
+	}
+	accessor.SetQueryTimeout(cfg.QueryTimeout)
+	return accessor, nil
+}
+
+// dialector returns the GORM dialector of the driver specified by the config.
+func dialector(cfg Config) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "mysql":
+		return mysql.Open(cfg.DSN), nil
+	case "postgres":
+		return postgres.Open(cfg.DSN), nil
+	case "sqlite":
+		return sqlite.Open(cfg.DSN), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+)
+
+// TestDialector Given each of the known drivers, when dialector is called, then the dialector of the driver is returned.
+func TestDialector(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   any
+	}{
+		{"mysql", &mysql.Dialector{}},
+		{"postgres", &postgres.Dialector{}},
+		{"sqlite", &sqlite.Dialector{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			// act
+			got, err := dialector(Config{Driver: tt.driver, DSN: "dsn"})
+
+			// assert
+			if assert.NoError(t, err) {
+				assert.IsType(t, tt.want, got)
+			}
+		})
+	}
+}
+
+// TestNewAccessorSqlite Given the sqlite driver, when NewAccessor is called, then a DatabaseAccessor is returned.
+func TestNewAccessorSqlite(t *testing.T) {
+	// act
+	got, err := NewAccessor(Config{Driver: "sqlite", DSN: "file::memory:"})
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.IsType(t, &DatabaseAccessor{}, got)
+		got.(*DatabaseAccessor).CloseDb()
+	}
+}
+
+// TestNewAccessorUnknownDriver Given an unknown driver, when NewAccessor is called, then an error is returned.
+func TestNewAccessorUnknownDriver(t *testing.T) {
+	// act
+	_, err := NewAccessor(Config{Driver: "unknown"})
+
+	// assert
+	assert.Error(t, err)
+}
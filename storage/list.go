@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"todolist/core"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ListModel is the stored core.List. The TodoItemModels refer to it with their ListID.
+// NOTE: The deletion is hard, unlike the one of the TodoItemModels, since the changes of the Lists are not reported.
+type ListModel struct {
+	ID        int `gorm:"primary_key"`
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (m ListModel) toList() core.List {
+	return core.List{
+		ID:        m.ID,
+		Name:      m.Name,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func (dba *DatabaseAccessor) CreateList(list *core.List) error {
+	log.WithFields(log.Fields{"name": list.Name}).Info("DB: Adding new ListModel to database.")
+	listModel := ListModel{Name: list.Name}
+	if err := dba.conn().Create(&listModel).Error; err != nil {
+		log.Warn("DB: ", err)
+		return err
+	}
+	*list = listModel.toList()
+	return nil
+}
+
+// readList finds the ListModel with the id, or returns a core.ListNotFoundError if there's no such ListModel.
+func readList(db *gorm.DB, id int) (ListModel, error) {
+	var listModel ListModel
+	result := db.Limit(1).Find(&listModel, id)
+	if result.Error != nil {
+		return ListModel{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ListModel{}, core.ListNotFoundError{ID: id}
+	}
+	return listModel, nil
+}
+
+func (dba *DatabaseAccessor) ReadList(id int) (core.List, error) {
+	log.WithFields(log.Fields{"id": id}).Info("DB: Reading ListModel from database.")
+	listModel, err := readList(dba.conn(), id)
+	if err != nil {
+		log.Warn("DB: ", err)
+		return core.List{}, err
+	}
+	return listModel.toList(), nil
+}
+
+func (dba *DatabaseAccessor) ReadLists() ([]core.List, error) {
+	log.Info("DB: Reading ListModels from database.")
+	var listModels []ListModel
+	if err := dba.conn().Order("id").Find(&listModels).Error; err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+	lists := make([]core.List, 0, len(listModels))
+	for _, listModel := range listModels {
+		lists = append(lists, listModel.toList())
+	}
+	return lists, nil
+}
+
+func (dba *DatabaseAccessor) RenameList(id int, name string) (core.List, error) {
+	log.WithFields(log.Fields{"id": id, "name": name}).Info("DB: Renaming ListModel.")
+	var list core.List
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		listModel, err := readList(tx, id)
+		if err != nil {
+			return err
+		}
+		listModel.Name = name
+		if err := tx.Save(&listModel).Error; err != nil {
+			return err
+		}
+		list = listModel.toList()
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return core.List{}, err
+	}
+	return list, nil
+}
+
+func (dba *DatabaseAccessor) DeleteList(id int, cascade bool) ([]core.ItemID, error) {
+	log.WithFields(log.Fields{"id": id, "cascade": cascade}).Info("DB: Deleting ListModel.")
+	var deletedIDs []core.ItemID
+	err := dba.conn().Transaction(func(tx *gorm.DB) error {
+		listModel, err := readList(tx, id)
+		if err != nil {
+			return err
+		}
+		var itemIDs []core.ItemID
+		if err := tx.Model(&TodoItemModel{}).Where("list_id = ?", id).Order("id").Pluck("id", &itemIDs).Error; err != nil {
+			return err
+		}
+		if len(itemIDs) > 0 {
+			if !cascade {
+				return core.ConflictError{Message: fmt.Sprintf("List with id %d still has %d TodoItems", id, len(itemIDs))}
+			}
+			// NOTE: The deletion of the TodoItemModels is soft as usual, so that GetChangesSince reports them.
+			if err := tx.Where("list_id = ?", id).Delete(&TodoItemModel{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&listModel).Error; err != nil {
+			return err
+		}
+		deletedIDs = itemIDs
+		return nil
+	})
+	if err != nil {
+		log.Warn("DB: ", err)
+		return nil, err
+	}
+	return deletedIDs, nil
+}
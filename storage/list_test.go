@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"testing"
+
+	"todolist/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateList Given a list, when CreateList is called, then the list should be created in the database with its id and timestamps filled in, and be read back by ReadList.
+func TestCreateList(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	list := core.List{Name: "Groceries"}
+
+	// act
+	err := dba.CreateList(&list)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, list.ID)
+		assert.False(t, list.CreatedAt.IsZero())
+		got, err := dba.ReadList(list.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, list.Name, got.Name)
+		}
+	}
+}
+
+// TestReadListNotFound Given no list in the database, when ReadList is called, then a ListNotFoundError should be returned.
+func TestReadListNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	_, err := dba.ReadList(1)
+
+	// assert
+	assert.Equal(t, core.ListNotFoundError{ID: 1}, err)
+}
+
+// TestReadLists Given some lists in the database, when ReadLists is called, then all of them should be returned in the order of their ids.
+func TestReadLists(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&[]ListModel{
+		{ID: 2, Name: "Work"},
+		{ID: 1, Name: "Groceries"},
+	})
+
+	// act
+	got, err := dba.ReadLists()
+
+	// assert
+	if assert.NoError(t, err) && assert.Len(t, got, 2) {
+		assert.Equal(t, "Groceries", got[0].Name)
+		assert.Equal(t, "Work", got[1].Name)
+	}
+}
+
+// TestRenameList Given a list in the database, when RenameList is called, then the name should be updated in the database and the updated list returned.
+func TestRenameList(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&ListModel{ID: 1, Name: "Groceries"})
+
+	// act
+	got, err := dba.RenameList(1, "Shopping")
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Shopping", got.Name)
+		var listModel ListModel
+		dba.db.First(&listModel, 1)
+		assert.Equal(t, "Shopping", listModel.Name)
+	}
+}
+
+// TestRenameListNotFound Given no list in the database, when RenameList is called, then a ListNotFoundError should be returned.
+func TestRenameListNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	_, err := dba.RenameList(1, "Shopping")
+
+	// assert
+	assert.Equal(t, core.ListNotFoundError{ID: 1}, err)
+}
+
+// TestDeleteListEmpty Given a list without todo items, when DeleteList is called without cascading, then the list should be deleted.
+func TestDeleteListEmpty(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&ListModel{ID: 1, Name: "Groceries"})
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1"})
+
+	// act
+	deletedIDs, err := dba.DeleteList(1, false)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Empty(t, deletedIDs)
+		_, err := dba.ReadList(1)
+		assert.IsType(t, core.ListNotFoundError{}, err)
+	}
+}
+
+// TestDeleteListBlocked Given a list with a todo item, when DeleteList is called without cascading, then a ConflictError should be returned and both the list and the todo item should be kept.
+func TestDeleteListBlocked(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&ListModel{ID: 1, Name: "Groceries"})
+	dba.db.Create(&TodoItemModel{ID: 1, Description: "Test description 1", ListID: 1})
+
+	// act
+	_, err := dba.DeleteList(1, false)
+
+	// assert
+	assert.IsType(t, core.ConflictError{}, err)
+	_, err = dba.ReadList(1)
+	assert.NoError(t, err)
+	var count int64
+	dba.db.Model(&TodoItemModel{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestDeleteListCascade Given a list with some todo items and a todo item in the default list, when DeleteList is called with cascading,
+// then the list and its todo items should be deleted, their ids returned, and the other todo item kept.
+func TestDeleteListCascade(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.db.Create(&ListModel{ID: 1, Name: "Groceries"})
+	dba.db.Create(&[]TodoItemModel{
+		{ID: 1, Description: "Test description 1", ListID: 1},
+		{ID: 2, Description: "Test description 2"},
+		{ID: 3, Description: "Test description 3", ListID: 1},
+	})
+
+	// act
+	deletedIDs, err := dba.DeleteList(1, true)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []core.ItemID{1, 3}, deletedIDs)
+		_, err := dba.ReadList(1)
+		assert.IsType(t, core.ListNotFoundError{}, err)
+		var ids []core.ItemID
+		dba.db.Model(&TodoItemModel{}).Pluck("id", &ids)
+		assert.Equal(t, []core.ItemID{2}, ids)
+	}
+}
+
+// TestDeleteListNotFound Given no list in the database, when DeleteList is called, then a ListNotFoundError should be returned.
+func TestDeleteListNotFound(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+
+	// act
+	_, err := dba.DeleteList(1, true)
+
+	// assert
+	assert.Equal(t, core.ListNotFoundError{ID: 1}, err)
+}
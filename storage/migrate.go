@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// migration is a named change to the schema, or the data, of the database.
+type migration struct {
+	name string
+	up   func(tx *gorm.DB) error
+}
+
+// migrations are applied in order by Migrate; the version of a migration is its index plus one.
+// NOTE: Append new migrations to the end and never change the applied ones, otherwise the versions recorded in the databases become wrong.
+var migrations = []migration{
+	{
+		name: "create todo_item_models",
+		up: func(tx *gorm.DB) error {
+			// NOTE: The table used to be created by AutoMigrate, so the databases created before the migrations existed are brought up to date here as well.
+			return tx.AutoMigrate(&TodoItemModel{})
+		},
+	},
+	{
+		name: "fill in description_key",
+		up: func(tx *gorm.DB) error {
+			// The keys are not unique, since duplicates are allowed among the completed items and when CreateUnique is not used.
+			return tx.Unscoped().Model(&TodoItemModel{}).
+				Where("description_key = ? AND description <> ?", "", "").
+				UpdateColumn("description_key", gorm.Expr("LOWER(TRIM(description))")).Error
+		},
+	},
+	{
+		name: "add color",
+		up: func(tx *gorm.DB) error {
+			// The fresh databases already have the column, since the first migration creates the table from the current model.
+			if tx.Migrator().HasColumn(&TodoItemModel{}, "Color") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&TodoItemModel{}, "Color")
+		},
+	},
+	{
+		name: "add list_id",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&TodoItemModel{}, "ListID") {
+				return nil
+			}
+			if err := tx.Migrator().AddColumn(&TodoItemModel{}, "ListID"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&TodoItemModel{}, "ListID")
+		},
+	},
+	{
+		name: "create list_models",
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&ListModel{})
+		},
+	},
+	{
+		name: "create blocker_models",
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&BlockerModel{})
+		},
+	},
+	{
+		name: "create audit_log_models",
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&AuditLogModel{})
+		},
+	},
+	{
+		name: "create attachment_models",
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&AttachmentModel{})
+		},
+	},
+	{
+		name: "add started_at",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&TodoItemModel{}, "StartedAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&TodoItemModel{}, "StartedAt")
+		},
+	},
+	{
+		name: "add time_spent",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&TodoItemModel{}, "TimeSpent") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&TodoItemModel{}, "TimeSpent")
+		},
+	},
+}
+
+// schemaMigration records an applied migration.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate applies the migrations that are not applied to the database yet, in order, and records them in the schema_migrations table.
+// Each migration is applied in a transaction along with its record, so a failed migration is retried by the next Migrate.
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(&schemaMigration{})
+	if err != nil {
+		return err
+	}
+	var applied []int
+	err = db.Model(&schemaMigration{}).Pluck("version", &applied).Error
+	if err != nil {
+		return err
+	}
+	isApplied := make(map[int]bool)
+	for _, version := range applied {
+		isApplied[version] = true
+	}
+
+	for i, m := range migrations {
+		version := i + 1
+		if isApplied[version] {
+			continue
+		}
+		log.WithFields(log.Fields{"version": version, "name": m.name}).Info("DB: Applying migration.")
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: version, Name: m.name, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			log.Warn("DB: ", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDb(t *testing.T) *gorm.DB {
+	// NOTE: Using the in-memory SQLite database for testing purposes.
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// TestMigrateTwice Given some migrations, when Migrate is called twice, then each migration should be applied exactly once and recorded in the version table.
+func TestMigrateTwice(t *testing.T) {
+	// arrange
+	db := openTestDb(t)
+	applied := map[string]int{}
+	countingMigration := func(name string) migration {
+		return migration{name: name, up: func(*gorm.DB) error {
+			applied[name]++
+			return nil
+		}}
+	}
+	original := migrations
+	migrations = []migration{countingMigration("first"), countingMigration("second")}
+	t.Cleanup(func() { migrations = original })
+
+	// act
+	err1 := Migrate(db)
+	err2 := Migrate(db)
+
+	// assert
+	if assert.NoError(t, err1) && assert.NoError(t, err2) {
+		assert.Equal(t, map[string]int{"first": 1, "second": 1}, applied)
+		var records []schemaMigration
+		db.Order("version").Find(&records)
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, 1, records[0].Version)
+			assert.Equal(t, "first", records[0].Name)
+			assert.Equal(t, 2, records[1].Version)
+			assert.Equal(t, "second", records[1].Name)
+		}
+	}
+}
+
+// TestMigrateNew Given the migrations are applied, when a new migration is appended and Migrate is called again, then only the new migration should be applied.
+func TestMigrateNew(t *testing.T) {
+	// arrange
+	db := openTestDb(t)
+	var appliedNames []string
+	record := func(name string) migration {
+		return migration{name: name, up: func(*gorm.DB) error {
+			appliedNames = append(appliedNames, name)
+			return nil
+		}}
+	}
+	original := migrations
+	migrations = []migration{record("first")}
+	t.Cleanup(func() { migrations = original })
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	migrations = append(migrations, record("second"))
+
+	// act
+	err := Migrate(db)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"first", "second"}, appliedNames)
+		var count int64
+		db.Model(&schemaMigration{}).Count(&count)
+		assert.Equal(t, int64(2), count)
+	}
+}
+
+// TestMigrateSchema Given the migrations of the application, when Migrate is called, then the table of the todo items should be created.
+func TestMigrateSchema(t *testing.T) {
+	// arrange
+	db := openTestDb(t)
+
+	// act
+	err := Migrate(db)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.True(t, db.Migrator().HasTable(&TodoItemModel{}))
+		assert.True(t, db.Migrator().HasColumn(&TodoItemModel{}, "DescriptionKey"))
+		var count int64
+		db.Model(&schemaMigration{}).Count(&count)
+		assert.Equal(t, int64(len(migrations)), count)
+	}
+}
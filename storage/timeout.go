@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"todolist/core"
+
+	"gorm.io/gorm"
+)
+
+// SetQueryTimeout sets the maximum duration of each statement sent to the database, after which the statement is cancelled and fails with context.DeadlineExceeded.
+// It's independent of the deadline of the request being served, so that a runaway query doesn't hold a connection for long. A zero duration means no limit.
+// NOTE: The streaming of ReadStream is not limited, as it legitimately takes long to go through many TodoItems.
+func (dba *DatabaseAccessor) SetQueryTimeout(d time.Duration) {
+	dba.queryTimeout.Store(int64(d))
+}
+
+// WithContext returns an accessor that sends the statements to the same database as dba, but with ctx, e.g., the context of the request being served,
+// so that they are cancelled once ctx is done. The query timeout still applies on top of ctx.
+func (dba *DatabaseAccessor) WithContext(ctx context.Context) core.StorageAccessor {
+	origin := dba
+	if dba.origin != nil {
+		origin = dba.origin
+	}
+	return &DatabaseAccessor{origin: origin, ctx: ctx}
+}
+
+// queryTimeoutKey is the key of the instance setting that carries the state of the timeout of a statement between the callbacks.
+const queryTimeoutKey = "todolist:query_timeout"
+
+// queryTimeout is the state of the timeout of a statement.
+type queryTimeout struct {
+	// parent is the context of the statement before the timeout is applied, which is restored once the statement is done,
+	// as the statement may be reused by the chained methods.
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// registerQueryTimeout registers the GORM callbacks that apply the query timeout around the statements of db, except for the ones of Rows.
+func (dba *DatabaseAccessor) registerQueryTimeout(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("todolist:start_timeout_create", dba.startQueryTimeout),
+		callbacks.Create().After("gorm:create").Register("todolist:stop_timeout_create", stopQueryTimeout),
+		callbacks.Query().Before("gorm:query").Register("todolist:start_timeout_query", dba.startQueryTimeout),
+		callbacks.Query().After("gorm:query").Register("todolist:stop_timeout_query", stopQueryTimeout),
+		callbacks.Update().Before("gorm:update").Register("todolist:start_timeout_update", dba.startQueryTimeout),
+		callbacks.Update().After("gorm:update").Register("todolist:stop_timeout_update", stopQueryTimeout),
+		callbacks.Delete().Before("gorm:delete").Register("todolist:start_timeout_delete", dba.startQueryTimeout),
+		callbacks.Delete().After("gorm:delete").Register("todolist:stop_timeout_delete", stopQueryTimeout),
+		callbacks.Raw().Before("gorm:raw").Register("todolist:start_timeout_raw", dba.startQueryTimeout),
+		callbacks.Raw().After("gorm:raw").Register("todolist:stop_timeout_raw", stopQueryTimeout),
+	)
+}
+
+// startQueryTimeout is a GORM callback that limits the context of the statement with the query timeout, if any.
+func (dba *DatabaseAccessor) startQueryTimeout(db *gorm.DB) {
+	timeout := time.Duration(dba.queryTimeout.Load())
+	if timeout <= 0 {
+		return
+	}
+	parent := db.Statement.Context
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(queryTimeoutKey, queryTimeout{parent: parent, cancel: cancel})
+}
+
+// stopQueryTimeout is a GORM callback that releases the timeout started by startQueryTimeout, if any.
+func stopQueryTimeout(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryTimeoutKey)
+	if !ok {
+		return
+	}
+	timeout := value.(queryTimeout)
+	timeout.cancel()
+	db.Statement.Context = timeout.parent
+}
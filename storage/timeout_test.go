@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"todolist/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowQuery is a query that takes seconds to run on SQLite.
+const slowQuery = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000000) SELECT count(*) FROM c"
+
+// TestQueryTimeout Given a query timeout, when a query runs longer than the timeout, then the query should be cancelled with context.DeadlineExceeded.
+func TestQueryTimeout(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.SetQueryTimeout(50 * time.Millisecond)
+
+	// act
+	start := time.Now()
+	err := dba.conn().Exec(slowQuery).Error
+
+	// assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "the query should have been cancelled early")
+}
+
+// TestQueryTimeoutFastQueries Given a query timeout, when the queries finish within the timeout, then they should succeed,
+// including the ones chained on the same statement.
+func TestQueryTimeoutFastQueries(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	dba.SetQueryTimeout(time.Second)
+
+	// act
+	todo := core.TodoItem{Description: "Test description"}
+	_, createErr := dba.Create(&todo)
+	todos, total, readErr := dba.ReadPage(0, 10)
+
+	// assert
+	assert.NoError(t, createErr)
+	if assert.NoError(t, readErr) {
+		assert.Equal(t, 1, total)
+		assert.Len(t, todos, 1)
+	}
+}
+
+// TestWithContext Given an accessor with the context of a request, when a query runs longer than the deadline of the context,
+// then the query should be cancelled with context.DeadlineExceeded, even without a query timeout.
+func TestWithContext(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ctxAccessor := dba.WithContext(ctx).(*DatabaseAccessor)
+
+	// act
+	start := time.Now()
+	err := ctxAccessor.conn().Exec(slowQuery).Error
+
+	// assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "the query should have been cancelled early")
+}
+
+// TestWithContextCanceled Given an accessor with a context that is already canceled, when the TodoItems are read,
+// then the read should fail with context.Canceled, while the accessor it's derived from still works.
+func TestWithContextCanceled(t *testing.T) {
+	// arrange
+	dba := DatabaseAccessor{}
+	initTestDb(&dba)
+	defer closeTestDb(&dba)
+	_, err := dba.Create(&core.TodoItem{Description: "Test description"})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// act
+	_, _, ctxErr := dba.WithContext(ctx).ReadPage(0, 10)
+	todos, _, err := dba.ReadPage(0, 10)
+
+	// assert
+	assert.ErrorIs(t, ctxErr, context.Canceled)
+	if assert.NoError(t, err) {
+		assert.Len(t, todos, 1)
+	}
+}
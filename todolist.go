@@ -1,48 +1,132 @@
 package main
 
 import (
-	"net/http"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"todolist/config"
 	"todolist/core"
 	"todolist/endpoint"
+	"todolist/logging"
 	"todolist/storage"
 
-	"github.com/gorilla/mux"
-	"github.com/rs/cors"
 	log "github.com/sirupsen/logrus"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
 )
 
-// init is executed when the program first begins (before main).
-func init() {
-	// Set up our logger settings.
-	log.SetFormatter(&log.TextFormatter{})
-	log.SetReportCaller(true)
-}
-
 func main() {
-	accessor := &storage.DatabaseAccessor{}
-	accessor.InitDb(mysql.Open("root:root@/todolist?charset=utf8&parseTime=True&loc=Local"), &gorm.Config{})
-	defer accessor.CloseDb()
-	theCore := core.NewCore(accessor)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Set up our logger settings.
+	err = logging.Configure(cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	accessor, err := storage.NewAccessor(storage.Config{
+		Driver:          cfg.DBDriver,
+		DSN:             cfg.DBDSN,
+		ConnectAttempts: cfg.DBConnectAttempts,
+		ConnectBackoff:  cfg.DBConnectBackoff,
+		QueryTimeout:    cfg.DBQueryTimeout,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if dba, ok := accessor.(*storage.DatabaseAccessor); ok {
+		defer dba.CloseDb()
+	}
+	duplicatePolicy, err := core.ParseDuplicatePolicy(cfg.UniqueDescriptions)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listDeletePolicy, err := core.ParseListDeletePolicy(cfg.ListDelete)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var sinks []core.EventSink
+	if cfg.ItemsCacheTTL > 0 {
+		itemsCache := endpoint.NewItemsCache(cfg.ItemsCacheTTL)
+		endpoint.SetItemsCache(itemsCache)
+		sinks = append(sinks, itemsCache)
+	}
+	theCore := core.NewCore(accessor, sinks...)
+	theCore.SetDuplicatePolicy(duplicatePolicy)
 	endpoint.SetCore(theCore)
+	listAccessor, ok := accessor.(core.ListAccessor)
+	if !ok {
+		log.Fatal("the storage does not support lists")
+	}
+	theListCore := core.NewListCore(listAccessor, sinks...)
+	theListCore.SetDeletePolicy(listDeletePolicy)
+	endpoint.SetListCore(theListCore)
+	endpoint.SetReadOnly(cfg.ReadOnly)
+	endpoint.SetAdminToken(cfg.AdminToken)
+	endpoint.SetAllowReset(cfg.AllowReset)
+	endpoint.SetStrictJSON(cfg.StrictJSON)
+	endpoint.SetPrettyJSON(cfg.PrettyJSON)
+	endpoint.SetEffectiveConfig(cfg.Effective())
+	endpoint.SetWriteBreaker(cfg.WriteBreakerThreshold, cfg.WriteBreakerCooldown)
+	if cfg.AllowReset {
+		log.Warn("TODOLIST_ALLOW_RESET is on, all the TodoItems can be permanently deleted with POST /admin/reset")
+	}
+	err = endpoint.SetDefaultFilter(cfg.DefaultFilter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = endpoint.SetDefaultSort(cfg.DefaultSort)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = endpoint.SetDefaultTimezone(cfg.Timezone)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = endpoint.SetMassDeleteThreshold(cfg.MassDeleteThreshold)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = endpoint.SetMaxPageSize(cfg.MaxPageSize)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = endpoint.SetTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.AdminToken == "" {
+		log.Warn("TODOLIST_ADMIN_TOKEN is not set, the administrative endpoints are not guarded")
+	}
 
 	log.Info("Starting Todolist API server")
-	router := mux.NewRouter()
-	// NOTE: The endpoint are not entirely the same as the blog post.
-	router.HandleFunc("/healthz", endpoint.Healthz).Methods("GET")
-	router.HandleFunc("/todo", endpoint.CreateItem).Methods("POST")
-	router.HandleFunc("/todo", endpoint.GetItems).Methods("GET")
-	router.HandleFunc("/todo/{id}", endpoint.UpdateItem).Methods("POST")
-	router.HandleFunc("/todo/{id}", endpoint.DeleteItem).Methods("DELETE")
-
-	handler := cors.New(cors.Options{
-		// NOTE: "OPTIONS" is not included in comparison with the blog post since it's not necessary.
-		// See https://stackoverflow.com/questions/66926518/should-access-control-allow-methods-include-options.
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
-	}).Handler(router)
-	err := http.ListenAndServe(":8000", handler)
+	router := endpoint.NewRouter(cfg.BasePath)
+	router.Use(endpoint.Gzip(endpoint.GzipMinSize))
+	// NOTE: Inside Gzip, so that the indented response is what gets compressed.
+	router.Use(endpoint.PrettyJSON)
+	router.Use(endpoint.Timeout(cfg.RequestTimeout))
+	// NOTE: Inside Timeout, so that the slot of a request is held until its handler returns, even if it times out.
+	router.Use(endpoint.ConcurrencyLimit(cfg.MaxConcurrentPerIP))
+	router.Use(endpoint.ReadOnly)
+	router.Use(endpoint.WriteBreaker)
+	router.Use(endpoint.MaxBodyBytes(cfg.MaxBodyBytes, cfg.MaxImportBytes))
+
+	handler := endpoint.InFlight(endpoint.CORS(cfg.CORSMaxAge)(router))
+
+	// The background jobs and the server stop on SIGINT or SIGTERM.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go core.PruneCompleted(ctx, theCore, cfg.CompletedRetention, core.DefaultPruneInterval)
+
+	server, err := endpoint.NewServer(":8000", handler, cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// NOTE: Run waits for the requests in flight to be drained, so that the database isn't closed under them by the deferred CloseDb.
+	err = endpoint.Run(ctx, server, func() error {
+		return endpoint.Serve(server)
+	}, cfg.DrainTimeout)
 	if err != nil {
 		log.Fatal(err)
 	}